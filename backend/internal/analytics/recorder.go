@@ -0,0 +1,112 @@
+// Package analytics records searches off the request path.
+//
+// Handlers hand entries to a Recorder, which queues them in a bounded buffer and
+// writes them from a single background worker. When the buffer is full, entries
+// are dropped rather than slowing down requests.
+package analytics
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+)
+
+const (
+	// DefaultBufferSize is the number of searches that can be queued for writing
+	DefaultBufferSize = 1024
+	// writeTimeout bounds a single write to the sink
+	writeTimeout = 2 * time.Second
+)
+
+// Sink stores recorded searches, such as the search_history table
+type Sink interface {
+	RecordSearch(ctx context.Context, entry models.SearchHistoryEntry) error
+}
+
+// Recorder queues searches and writes them to a Sink in the background
+type Recorder struct {
+	sink    Sink
+	entries chan models.SearchHistoryEntry
+	done    chan struct{}
+	mu      sync.RWMutex // Guards closing entries against concurrent sends
+	closed  bool
+	dropped atomic.Int64
+}
+
+// NewRecorder creates a Recorder and starts its background worker. Close must be
+// called to flush queued entries and stop the worker.
+func NewRecorder(sink Sink, bufferSize int) *Recorder {
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+	r := &Recorder{
+		sink:    sink,
+		entries: make(chan models.SearchHistoryEntry, bufferSize),
+		done:    make(chan struct{}),
+	}
+	go r.run()
+	return r
+}
+
+// Record queues a search for writing without blocking. It reports false if the
+// entry was dropped because the buffer is full or the recorder is closed.
+func (r *Recorder) Record(entry models.SearchHistoryEntry) bool {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		r.dropped.Add(1)
+		return false
+	}
+	select {
+	case r.entries <- entry:
+		return true
+	default:
+		if dropped := r.dropped.Add(1); dropped%100 == 1 {
+			log.Printf("Search history buffer full, dropped %d entries so far", dropped)
+		}
+		return false
+	}
+}
+
+// Dropped returns the number of entries dropped so far
+func (r *Recorder) Dropped() int64 {
+	return r.dropped.Load()
+}
+
+// Close stops accepting entries and waits for queued entries to be written, or
+// until ctx is done
+func (r *Recorder) Close(ctx context.Context) error {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.entries)
+	}
+	r.mu.Unlock()
+
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run writes queued entries until the queue is closed and drained
+func (r *Recorder) run() {
+	defer close(r.done)
+	for entry := range r.entries {
+		ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+		if err := r.sink.RecordSearch(ctx, entry); err != nil {
+			log.Printf("Failed to record search %q: %v", entry.Query, err)
+		}
+		cancel()
+	}
+}
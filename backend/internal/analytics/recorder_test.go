@@ -0,0 +1,89 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+)
+
+// memorySink collects entries, optionally blocking until release is closed
+type memorySink struct {
+	mu      sync.Mutex
+	entries []models.SearchHistoryEntry
+	release chan struct{}
+	err     error
+}
+
+func (s *memorySink) RecordSearch(ctx context.Context, entry models.SearchHistoryEntry) error {
+	if s.release != nil {
+		<-s.release
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entry)
+	return s.err
+}
+
+func (s *memorySink) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+// TestRecorderFlushesOnClose tests that queued entries are written before Close returns
+func TestRecorderFlushesOnClose(t *testing.T) {
+	sink := &memorySink{err: errors.New("write failed")}
+	recorder := NewRecorder(sink, 10)
+
+	for _, query := range []string{"matrix", "inception", "akira"} {
+		if !recorder.Record(models.SearchHistoryEntry{Query: query}) {
+			t.Fatalf("Expected %q to be queued", query)
+		}
+	}
+
+	if err := recorder.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if sink.count() != 3 {
+		t.Errorf("Expected 3 recorded entries, got %d", sink.count())
+	}
+	if sink.entries[0].CreatedAt.IsZero() {
+		t.Error("Expected CreatedAt to be set when recording")
+	}
+
+	if recorder.Record(models.SearchHistoryEntry{Query: "late"}) {
+		t.Error("Expected entries after Close to be dropped")
+	}
+}
+
+// TestRecorderDropsWhenFull tests that a full buffer drops entries instead of blocking
+func TestRecorderDropsWhenFull(t *testing.T) {
+	sink := &memorySink{release: make(chan struct{})}
+	recorder := NewRecorder(sink, 2)
+
+	queued := 0
+	for i := 0; i < 10; i++ {
+		if recorder.Record(models.SearchHistoryEntry{Query: "query"}) {
+			queued++
+		}
+	}
+
+	// One entry may be held by the worker, plus two in the buffer
+	if queued < 2 || queued > 3 {
+		t.Errorf("Expected 2-3 queued entries, got %d", queued)
+	}
+	if recorder.Dropped() != int64(10-queued) {
+		t.Errorf("Expected %d dropped entries, got %d", 10-queued, recorder.Dropped())
+	}
+
+	close(sink.release)
+	if err := recorder.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if sink.count() != queued {
+		t.Errorf("Expected %d recorded entries, got %d", queued, sink.count())
+	}
+}
@@ -0,0 +1,21 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+)
+
+// userKey is the context key for the authenticated user
+type userKey struct{}
+
+// WithUser returns a copy of ctx carrying the authenticated user
+func WithUser(ctx context.Context, user *models.User) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// UserFromContext returns the authenticated user, if the request was authenticated
+func UserFromContext(ctx context.Context) (*models.User, bool) {
+	user, ok := ctx.Value(userKey{}).(*models.User)
+	return user, ok && user != nil
+}
@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"fmt"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// passwordCost is the bcrypt cost for new password hashes
+var passwordCost = 12
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     []byte
+)
+
+// HashPassword returns the bcrypt hash of password
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), passwordCost)
+	if err != nil {
+		return "", fmt.Errorf("hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// CheckPassword reports whether password matches the bcrypt hash
+func CheckPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// checkDummyPassword spends as long as CheckPassword on a real account, so that a
+// login for an unknown user does not reveal that the account does not exist
+func checkDummyPassword(password string) {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = bcrypt.GenerateFromPassword([]byte("movie-api dummy password"), passwordCost)
+	})
+	bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+}
@@ -0,0 +1,243 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+	"github.com/takeshi-arihori/movie-api/internal/store"
+)
+
+var (
+	// ErrInvalidCredentials is returned when a login does not match an account
+	ErrInvalidCredentials = errors.New("invalid login or password")
+	// ErrInvalidToken is returned for access or refresh tokens that are malformed,
+	// expired, revoked or otherwise not acceptable
+	ErrInvalidToken = errors.New("invalid or expired token")
+	// ErrUserExists is returned when registering a taken email or username
+	ErrUserExists = errors.New("email or username already registered")
+	// ErrInactiveUser is returned when a deactivated account logs in or uses a session
+	ErrInactiveUser = errors.New("account is disabled")
+)
+
+// UserStore reads and creates user accounts
+type UserStore interface {
+	Create(ctx context.Context, user *models.User) error
+	GetByID(ctx context.Context, id string) (*models.User, error)
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	GetByUsername(ctx context.Context, username string) (*models.User, error)
+}
+
+// RefreshTokenStore persists refresh tokens
+type RefreshTokenStore interface {
+	Create(ctx context.Context, token *models.RefreshToken) error
+	GetByHash(ctx context.Context, hash string) (*models.RefreshToken, error)
+	Revoke(ctx context.Context, id string) (bool, error)
+	RevokeFamily(ctx context.Context, familyID string) error
+}
+
+// Service registers users and manages their sessions
+type Service struct {
+	users      UserStore
+	tokens     RefreshTokenStore
+	access     *TokenIssuer
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	now        func() time.Time
+}
+
+// NewService creates a Service issuing access tokens signed with secret that
+// expire after accessTTL, and refresh tokens that expire after refreshTTL
+func NewService(users UserStore, tokens RefreshTokenStore, secret string, accessTTL, refreshTTL time.Duration) *Service {
+	return &Service{
+		users:      users,
+		tokens:     tokens,
+		access:     NewTokenIssuer(secret, accessTTL),
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+		now:        time.Now,
+	}
+}
+
+// Register creates an account and starts a session for it. The request must
+// already have been validated.
+func (s *Service) Register(ctx context.Context, req models.RegisterRequest) (*models.AuthResponse, error) {
+	hash, err := HashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		Email:        strings.TrimSpace(req.Email),
+		Username:     strings.TrimSpace(req.Username),
+		PasswordHash: hash,
+		IsActive:     true,
+	}
+	if err := s.users.Create(ctx, user); err != nil {
+		if errors.Is(err, store.ErrConflict) {
+			return nil, ErrUserExists
+		}
+		return nil, err
+	}
+
+	return s.startSession(ctx, user, "")
+}
+
+// Login checks a user's password and starts a new session
+func (s *Service) Login(ctx context.Context, req models.LoginRequest) (*models.AuthResponse, error) {
+	login := strings.TrimSpace(req.Login)
+
+	var user *models.User
+	var err error
+	if strings.Contains(login, "@") {
+		user, err = s.users.GetByEmail(ctx, login)
+	} else {
+		user, err = s.users.GetByUsername(ctx, login)
+	}
+	if errors.Is(err, store.ErrNotFound) {
+		checkDummyPassword(req.Password)
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !CheckPassword(user.PasswordHash, req.Password) {
+		return nil, ErrInvalidCredentials
+	}
+	if !user.IsActive {
+		return nil, ErrInactiveUser
+	}
+
+	return s.startSession(ctx, user, "")
+}
+
+// Refresh exchanges a refresh token for a new session. The presented token is
+// revoked; if it had already been revoked, its whole family is revoked too.
+func (s *Service) Refresh(ctx context.Context, refreshToken string) (*models.AuthResponse, error) {
+	token, err := s.lookupRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if token.RevokedAt != nil {
+		s.revokeReusedFamily(ctx, token)
+		return nil, ErrInvalidToken
+	}
+	if !s.now().Before(token.ExpiresAt) {
+		return nil, ErrInvalidToken
+	}
+
+	// Only one concurrent refresh with a token may win; a loser is treated as reuse
+	revoked, err := s.tokens.Revoke(ctx, token.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !revoked {
+		s.revokeReusedFamily(ctx, token)
+		return nil, ErrInvalidToken
+	}
+
+	user, err := s.activeUser(ctx, token.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return s.startSession(ctx, user, token.FamilyID)
+}
+
+// Logout ends the session a refresh token belongs to. Unknown tokens are ignored,
+// so logging out twice is not an error.
+func (s *Service) Logout(ctx context.Context, refreshToken string) error {
+	token, err := s.lookupRefreshToken(ctx, refreshToken)
+	if errors.Is(err, ErrInvalidToken) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return s.tokens.RevokeFamily(ctx, token.FamilyID)
+}
+
+// Authenticate verifies an access token and returns its user. Users deactivated
+// since the token was issued are rejected.
+func (s *Service) Authenticate(ctx context.Context, accessToken string) (*models.User, error) {
+	claims, err := s.access.Parse(accessToken)
+	if err != nil {
+		return nil, err
+	}
+	return s.activeUser(ctx, claims.Subject)
+}
+
+// startSession issues an access token and a refresh token in the given family,
+// or in a new family when familyID is empty
+func (s *Service) startSession(ctx context.Context, user *models.User, familyID string) (*models.AuthResponse, error) {
+	accessToken, err := s.access.Issue(user.ID, user.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, hash, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	stored := &models.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: hash,
+		ExpiresAt: s.now().Add(s.refreshTTL),
+	}
+	if err := s.tokens.Create(ctx, stored); err != nil {
+		return nil, err
+	}
+
+	return &models.AuthResponse{
+		AccessToken:      accessToken,
+		TokenType:        "Bearer",
+		ExpiresIn:        int(s.accessTTL.Seconds()),
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: stored.ExpiresAt,
+		User:             user,
+	}, nil
+}
+
+// lookupRefreshToken returns the stored token for a presented refresh token
+func (s *Service) lookupRefreshToken(ctx context.Context, refreshToken string) (*models.RefreshToken, error) {
+	if refreshToken == "" {
+		return nil, ErrInvalidToken
+	}
+	token, err := s.tokens.GetByHash(ctx, hashRefreshToken(refreshToken))
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// revokeReusedFamily ends the session of a refresh token that was used twice
+func (s *Service) revokeReusedFamily(ctx context.Context, token *models.RefreshToken) {
+	log.Printf("Refresh token reuse detected for user %s, revoking session %s", token.UserID, token.FamilyID)
+	if err := s.tokens.RevokeFamily(ctx, token.FamilyID); err != nil {
+		log.Printf("Failed to revoke session %s: %v", token.FamilyID, err)
+	}
+}
+
+// activeUser loads a user by ID, rejecting missing and deactivated accounts
+func (s *Service) activeUser(ctx context.Context, id string) (*models.User, error) {
+	user, err := s.users.GetByID(ctx, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load user: %w", err)
+	}
+	if !user.IsActive {
+		return nil, ErrInactiveUser
+	}
+	return user, nil
+}
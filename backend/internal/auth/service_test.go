@@ -0,0 +1,254 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+	"github.com/takeshi-arihori/movie-api/internal/store"
+)
+
+func init() {
+	// Keep password hashing fast in tests
+	passwordCost = bcrypt.MinCost
+}
+
+// fakeUsers is an in-memory UserStore
+type fakeUsers struct {
+	mu    sync.Mutex
+	users []*models.User
+}
+
+func (f *fakeUsers) Create(ctx context.Context, user *models.User) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, existing := range f.users {
+		if strings.EqualFold(existing.Email, user.Email) || existing.Username == user.Username {
+			return store.ErrConflict
+		}
+	}
+	user.ID = fmt.Sprintf("user-%d", len(f.users)+1)
+	f.users = append(f.users, user)
+	return nil
+}
+
+func (f *fakeUsers) find(match func(*models.User) bool) (*models.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, user := range f.users {
+		if match(user) {
+			return user, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+func (f *fakeUsers) GetByID(ctx context.Context, id string) (*models.User, error) {
+	return f.find(func(u *models.User) bool { return u.ID == id })
+}
+
+func (f *fakeUsers) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	return f.find(func(u *models.User) bool { return strings.EqualFold(u.Email, email) })
+}
+
+func (f *fakeUsers) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	return f.find(func(u *models.User) bool { return u.Username == username })
+}
+
+// fakeRefreshTokens is an in-memory RefreshTokenStore
+type fakeRefreshTokens struct {
+	mu     sync.Mutex
+	tokens []*models.RefreshToken
+}
+
+func (f *fakeRefreshTokens) Create(ctx context.Context, token *models.RefreshToken) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	token.ID = fmt.Sprintf("token-%d", len(f.tokens)+1)
+	if token.FamilyID == "" {
+		token.FamilyID = "family-" + token.ID
+	}
+	stored := *token
+	f.tokens = append(f.tokens, &stored)
+	return nil
+}
+
+func (f *fakeRefreshTokens) GetByHash(ctx context.Context, hash string) (*models.RefreshToken, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, token := range f.tokens {
+		if token.TokenHash == hash {
+			copied := *token
+			return &copied, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+func (f *fakeRefreshTokens) Revoke(ctx context.Context, id string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, token := range f.tokens {
+		if token.ID == id && token.RevokedAt == nil {
+			now := time.Now()
+			token.RevokedAt = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeRefreshTokens) RevokeFamily(ctx context.Context, familyID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, token := range f.tokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			now := time.Now()
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+const testSecret = "this-is-a-very-long-secret-key-for-testing-purposes-32-chars"
+
+func newTestService() (*Service, *fakeUsers) {
+	users := &fakeUsers{}
+	return NewService(users, &fakeRefreshTokens{}, testSecret, 15*time.Minute, 24*time.Hour), users
+}
+
+func TestRegisterAndLogin(t *testing.T) {
+	service, users := newTestService()
+	ctx := context.Background()
+
+	session, err := service.Register(ctx, models.RegisterRequest{Email: "neo@example.com", Username: "neo", Password: "followthewhiterabbit"})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if session.AccessToken == "" || session.RefreshToken == "" || session.TokenType != "Bearer" || session.ExpiresIn != 900 {
+		t.Errorf("Unexpected session: %+v", session)
+	}
+	if users.users[0].PasswordHash == "followthewhiterabbit" || !CheckPassword(users.users[0].PasswordHash, "followthewhiterabbit") {
+		t.Error("Expected the password to be stored as a bcrypt hash")
+	}
+
+	if _, err := service.Register(ctx, models.RegisterRequest{Email: "NEO@example.com", Username: "other", Password: "password123"}); !errors.Is(err, ErrUserExists) {
+		t.Errorf("Expected ErrUserExists for a taken email, got %v", err)
+	}
+
+	for _, login := range []string{"neo", "neo@example.com"} {
+		if _, err := service.Login(ctx, models.LoginRequest{Login: login, Password: "followthewhiterabbit"}); err != nil {
+			t.Errorf("Login as %q failed: %v", login, err)
+		}
+	}
+	if _, err := service.Login(ctx, models.LoginRequest{Login: "neo", Password: "wrong password"}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected ErrInvalidCredentials for a wrong password, got %v", err)
+	}
+	if _, err := service.Login(ctx, models.LoginRequest{Login: "trinity", Password: "followthewhiterabbit"}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected ErrInvalidCredentials for an unknown user, got %v", err)
+	}
+
+	user, err := service.Authenticate(ctx, session.AccessToken)
+	if err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+	if user.Username != "neo" {
+		t.Errorf("Expected user neo, got %q", user.Username)
+	}
+
+	users.users[0].IsActive = false
+	if _, err := service.Authenticate(ctx, session.AccessToken); !errors.Is(err, ErrInactiveUser) {
+		t.Errorf("Expected ErrInactiveUser for a deactivated account, got %v", err)
+	}
+}
+
+func TestRefreshRotation(t *testing.T) {
+	service, _ := newTestService()
+	ctx := context.Background()
+
+	first, err := service.Register(ctx, models.RegisterRequest{Email: "neo@example.com", Username: "neo", Password: "followthewhiterabbit"})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	second, err := service.Refresh(ctx, first.RefreshToken)
+	if err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken {
+		t.Error("Expected a new refresh token")
+	}
+
+	// Reusing the rotated token is rejected and ends the session
+	if _, err := service.Refresh(ctx, first.RefreshToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected ErrInvalidToken for a reused token, got %v", err)
+	}
+	if _, err := service.Refresh(ctx, second.RefreshToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected the session to be revoked after reuse, got %v", err)
+	}
+
+	// Logout revokes the session and is idempotent
+	third, err := service.Login(ctx, models.LoginRequest{Login: "neo", Password: "followthewhiterabbit"})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := service.Logout(ctx, third.RefreshToken); err != nil {
+			t.Errorf("Logout failed: %v", err)
+		}
+	}
+	if _, err := service.Refresh(ctx, third.RefreshToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected ErrInvalidToken after logout, got %v", err)
+	}
+
+	// Expired tokens are rejected
+	fourth, err := service.Login(ctx, models.LoginRequest{Login: "neo", Password: "followthewhiterabbit"})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	service.now = func() time.Time { return time.Now().Add(48 * time.Hour) }
+	if _, err := service.Refresh(ctx, fourth.RefreshToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected ErrInvalidToken for an expired token, got %v", err)
+	}
+}
+
+func TestTokenIssuer(t *testing.T) {
+	issuer := NewTokenIssuer(testSecret, time.Minute)
+
+	token, err := issuer.Issue("user-1", "neo")
+	if err != nil {
+		t.Fatalf("Issue failed: %v", err)
+	}
+	claims, err := issuer.Parse(token)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if claims.Subject != "user-1" || claims.Username != "neo" {
+		t.Errorf("Unexpected claims: %+v", claims)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		parse *TokenIssuer
+	}{
+		{"wrong secret", token, NewTokenIssuer("another-secret-that-is-also-at-least-32-chars", time.Minute)},
+		{"tampered", token[:len(token)-2] + "xx", issuer},
+		{"unsigned", "eyJhbGciOiJub25lIiwidHlwIjoiSldUIn0.eyJzdWIiOiJ1c2VyLTEiLCJpc3MiOiJtb3ZpZS1hcGkifQ.", issuer},
+		{"expired", token, &TokenIssuer{secret: []byte(testSecret), now: func() time.Time { return time.Now().Add(time.Hour) }}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.parse.Parse(tt.token); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("Expected ErrInvalidToken, got %v", err)
+			}
+		})
+	}
+}
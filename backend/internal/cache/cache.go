@@ -0,0 +1,105 @@
+// Package cache provides a small in-memory cache with per-entry expiry.
+//
+// It is meant for short-lived response caching in front of slower lookups, where a
+// bounded size and a fixed TTL are enough and no cross-process sharing is needed.
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// Cache is a concurrency-safe TTL cache holding at most a fixed number of entries
+type Cache[K comparable, V any] struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[K]entry[V]
+	now        func() time.Time
+}
+
+type entry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// New creates a cache whose entries expire after ttl. When maxEntries is reached,
+// expired entries are dropped first and then the entry closest to expiry.
+func New[K comparable, V any](ttl time.Duration, maxEntries int) *Cache[K, V] {
+	return &Cache[K, V]{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[K]entry[V]),
+		now:        time.Now,
+	}
+}
+
+// Get returns the cached value for key, if present and not expired
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	if !c.now().Before(e.expiresAt) {
+		delete(c.entries, key)
+		var zero V
+		return zero, false
+	}
+	return e.value, true
+}
+
+// Set stores value under key for the cache's TTL
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if _, exists := c.entries[key]; !exists && c.maxEntries > 0 && len(c.entries) >= c.maxEntries {
+		c.evict(now)
+	}
+	c.entries[key] = entry[V]{value: value, expiresAt: now.Add(c.ttl)}
+}
+
+// Delete removes key from the cache
+func (c *Cache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
+// Clear removes every entry from the cache
+func (c *Cache[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[K]entry[V])
+}
+
+// Len returns the number of entries, including expired ones not yet evicted
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// evict makes room for one entry. The caller must hold c.mu.
+func (c *Cache[K, V]) evict(now time.Time) {
+	var oldestKey K
+	var oldest time.Time
+	found := false
+	for key, e := range c.entries {
+		if !now.Before(e.expiresAt) {
+			delete(c.entries, key)
+			continue
+		}
+		if !found || e.expiresAt.Before(oldest) {
+			oldestKey, oldest, found = key, e.expiresAt, true
+		}
+	}
+	if len(c.entries) >= c.maxEntries && found {
+		delete(c.entries, oldestKey)
+	}
+}
@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+// TestCacheExpiry tests that entries expire after the TTL
+func TestCacheExpiry(t *testing.T) {
+	now := time.Unix(0, 0)
+	c := New[string, int](time.Minute, 0)
+	c.now = func() time.Time { return now }
+
+	c.Set("a", 1)
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatalf("Expected cached value 1, got %d (ok=%v)", v, ok)
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := c.Get("a"); ok {
+		t.Error("Expected entry to expire after TTL")
+	}
+	if c.Len() != 0 {
+		t.Errorf("Expected expired entry to be removed, got %d entries", c.Len())
+	}
+}
+
+// TestCacheEviction tests that a full cache evicts the entry closest to expiry
+func TestCacheEviction(t *testing.T) {
+	now := time.Unix(0, 0)
+	c := New[string, int](time.Minute, 2)
+	c.now = func() time.Time { return now }
+
+	c.Set("a", 1)
+	now = now.Add(time.Second)
+	c.Set("b", 2)
+	now = now.Add(time.Second)
+	c.Set("c", 3)
+
+	if c.Len() != 2 {
+		t.Fatalf("Expected 2 entries, got %d", c.Len())
+	}
+	if _, ok := c.Get("a"); ok {
+		t.Error("Expected oldest entry to be evicted")
+	}
+	if _, ok := c.Get("c"); !ok {
+		t.Error("Expected newest entry to be cached")
+	}
+
+	// Overwriting an existing key does not evict
+	c.Set("b", 20)
+	if v, ok := c.Get("b"); !ok || v != 20 {
+		t.Errorf("Expected overwritten value 20, got %d", v)
+	}
+	if _, ok := c.Get("c"); !ok {
+		t.Error("Expected overwrite to keep other entries")
+	}
+}
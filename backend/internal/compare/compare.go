@@ -0,0 +1,303 @@
+// Package compare lines up movies and TV shows side by side for comparison.
+package compare
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+)
+
+// MinTitles and MaxTitles bound the number of titles compared at once
+const (
+	MinTitles = 2
+	MaxTitles = 5
+)
+
+// Title holds the compared fields of a movie or TV show and its credits
+type Title struct {
+	models.ComparedTitle
+	ReleaseDate         string
+	Runtime             *int
+	Budget              int64 // 0 when unknown, as TMDb reports it
+	Revenue             int64 // 0 when unknown, as TMDb reports it
+	VoteAverage         float64
+	VoteCount           int
+	Popularity          float64
+	Genres              []string
+	ProductionCountries []string
+	Cast                []Credit
+	Crew                []Credit
+}
+
+// Credit is a cast or crew credit on a title. Role is the character or job.
+type Credit struct {
+	ID          int
+	Name        string
+	ProfilePath *string
+	Role        string
+}
+
+// FromMovie returns the compared fields of a movie
+func FromMovie(details *models.MovieDetails, credits *models.MovieCredits) Title {
+	title := Title{
+		ComparedTitle: models.ComparedTitle{
+			ID:         details.ID,
+			MediaType:  string(models.SearchItemTypeMovie),
+			Title:      details.Title,
+			PosterPath: details.PosterPath,
+		},
+		Runtime:             details.Runtime,
+		Budget:              details.Budget,
+		Revenue:             details.Revenue,
+		VoteAverage:         details.VoteAverage,
+		VoteCount:           details.VoteCount,
+		Popularity:          details.Popularity,
+		Genres:              genreNames(details.Genres),
+		ProductionCountries: countryNames(details.ProductionCountries),
+	}
+	if details.ReleaseDate != nil {
+		title.ReleaseDate = *details.ReleaseDate
+	}
+	for _, member := range credits.Cast {
+		title.Cast = append(title.Cast, Credit{member.ID, member.Name, member.ProfilePath, member.Character})
+	}
+	for _, member := range credits.Crew {
+		title.Crew = append(title.Crew, Credit{member.ID, member.Name, member.ProfilePath, member.Job})
+	}
+	return title
+}
+
+// FromTV returns the compared fields of a TV show. Its runtime is the shortest
+// listed episode runtime, and TMDb has no budget or revenue for TV shows.
+func FromTV(details *models.TVShowDetails, credits *models.TVCredits) Title {
+	title := Title{
+		ComparedTitle: models.ComparedTitle{
+			ID:         details.ID,
+			MediaType:  string(models.SearchItemTypeTV),
+			Title:      details.Name,
+			PosterPath: details.PosterPath,
+		},
+		VoteAverage:         details.VoteAverage,
+		VoteCount:           details.VoteCount,
+		Popularity:          details.Popularity,
+		Genres:              genreNames(details.Genres),
+		ProductionCountries: countryNames(details.ProductionCountries),
+	}
+	if details.FirstAirDate != nil {
+		title.ReleaseDate = *details.FirstAirDate
+	}
+	if len(details.EpisodeRunTime) > 0 {
+		runtime := slices.Min(details.EpisodeRunTime)
+		title.Runtime = &runtime
+	}
+	for _, member := range credits.Cast {
+		title.Cast = append(title.Cast, Credit{member.ID, member.Name, member.ProfilePath, member.Character})
+	}
+	for _, member := range credits.Crew {
+		title.Crew = append(title.Crew, Credit{member.ID, member.Name, member.ProfilePath, member.Job})
+	}
+	return title
+}
+
+func genreNames(genres []models.Genre) []string {
+	names := []string{}
+	for _, genre := range genres {
+		names = append(names, genre.Name)
+	}
+	return names
+}
+
+func countryNames(countries []models.ProductionCountry) []string {
+	names := []string{}
+	for _, country := range countries {
+		names = append(names, country.Name)
+	}
+	return names
+}
+
+// Compare lines up the fields of the titles and finds the people credited on
+// more than one of them
+func Compare(titles []Title) *models.Comparison {
+	comparison := &models.Comparison{
+		Titles:     make([]models.ComparedTitle, len(titles)),
+		SharedCast: sharedPeople(titles, func(title Title) []Credit { return title.Cast }),
+		SharedCrew: sharedPeople(titles, func(title Title) []Credit { return title.Crew }),
+	}
+	for i, title := range titles {
+		comparison.Titles[i] = title.ComparedTitle
+	}
+
+	fields := &comparison.Fields
+	fields.Runtime = numeric(titles, func(title Title) *float64 {
+		if title.Runtime == nil || *title.Runtime == 0 {
+			return nil
+		}
+		return float(*title.Runtime)
+	})
+	fields.ReleaseDate = dates(titles)
+	fields.Budget = numeric(titles, func(title Title) *float64 { return known(title.Budget) })
+	fields.Revenue = numeric(titles, func(title Title) *float64 { return known(title.Revenue) })
+	fields.ROI = numeric(titles, func(title Title) *float64 {
+		if title.Budget <= 0 || title.Revenue <= 0 {
+			return nil
+		}
+		roi := float64(title.Revenue-title.Budget) / float64(title.Budget)
+		return float(math.Round(roi*100) / 100)
+	})
+	fields.VoteAverage = numeric(titles, func(title Title) *float64 { return float(title.VoteAverage) })
+	fields.VoteCount = numeric(titles, func(title Title) *float64 { return float(title.VoteCount) })
+	fields.Popularity = numeric(titles, func(title Title) *float64 { return float(title.Popularity) })
+	fields.Genres = set(titles, func(title Title) []string { return title.Genres })
+	fields.ProductionCountries = set(titles, func(title Title) []string { return title.ProductionCountries })
+	return comparison
+}
+
+func float[N int | int64 | float64](value N) *float64 {
+	f := float64(value)
+	return &f
+}
+
+// known returns a budget or revenue, or nil when TMDb reports it as 0
+func known(amount int64) *float64 {
+	if amount <= 0 {
+		return nil
+	}
+	return float(amount)
+}
+
+// extremes returns the indexes of the lowest and highest known values, or nil
+// for both when the known values do not differ
+func extremes[V cmp.Ordered](values []*V) (lowest, highest []int, differs bool) {
+	var low, high *V
+	for _, value := range values {
+		if value == nil {
+			continue
+		}
+		if low == nil || *value < *low {
+			low = value
+		}
+		if high == nil || *value > *high {
+			high = value
+		}
+	}
+	if low == nil || *low == *high {
+		return nil, nil, false
+	}
+	for i, value := range values {
+		if value != nil && *value == *low {
+			lowest = append(lowest, i)
+		}
+		if value != nil && *value == *high {
+			highest = append(highest, i)
+		}
+	}
+	return lowest, highest, true
+}
+
+func numeric(titles []Title, value func(Title) *float64) models.NumericComparison {
+	values := make([]*float64, len(titles))
+	for i, title := range titles {
+		values[i] = value(title)
+	}
+	lowest, highest, differs := extremes(values)
+	return models.NumericComparison{Values: values, Differs: differs, Highest: highest, Lowest: lowest}
+}
+
+// dates compares release dates, which sort as strings in YYYY-MM-DD format
+func dates(titles []Title) models.DateComparison {
+	values := make([]*string, len(titles))
+	for i, title := range titles {
+		if title.ReleaseDate != "" {
+			values[i] = &title.ReleaseDate
+		}
+	}
+	earliest, latest, differs := extremes(values)
+	return models.DateComparison{Values: values, Differs: differs, Earliest: earliest, Latest: latest}
+}
+
+// set compares names case-insensitively, keeping them in the order of the first
+// title that has them
+func set(titles []Title, names func(Title) []string) models.SetComparison {
+	comparison := models.SetComparison{
+		Values: make([][]string, len(titles)),
+		Common: []string{},
+		Unique: make([][]string, len(titles)),
+	}
+	counts := map[string]int{}
+	var order []string
+	for i, title := range titles {
+		seen := map[string]bool{}
+		comparison.Values[i] = []string{}
+		for _, name := range names(title) {
+			key := strings.ToLower(name)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			comparison.Values[i] = append(comparison.Values[i], name)
+			if counts[key] == 0 {
+				order = append(order, name)
+			}
+			counts[key]++
+		}
+	}
+
+	for _, name := range order {
+		if counts[strings.ToLower(name)] == len(titles) {
+			comparison.Common = append(comparison.Common, name)
+		}
+	}
+	for i, values := range comparison.Values {
+		comparison.Unique[i] = []string{}
+		for _, name := range values {
+			if counts[strings.ToLower(name)] < len(titles) {
+				comparison.Unique[i] = append(comparison.Unique[i], name)
+				comparison.Differs = true
+			}
+		}
+	}
+	return comparison
+}
+
+// sharedPeople returns the people credited on more than one title, those on the
+// most titles first, then by name
+func sharedPeople(titles []Title, credits func(Title) []Credit) []models.SharedPerson {
+	people := map[int]*models.SharedPerson{}
+	credited := map[int][]bool{}
+	var order []int
+	for i, title := range titles {
+		for _, credit := range credits(title) {
+			person, ok := people[credit.ID]
+			if !ok {
+				person = &models.SharedPerson{ID: credit.ID, Name: credit.Name, ProfilePath: credit.ProfilePath, Roles: make([][]string, len(titles))}
+				for j := range person.Roles {
+					person.Roles[j] = []string{}
+				}
+				people[credit.ID] = person
+				credited[credit.ID] = make([]bool, len(titles))
+				order = append(order, credit.ID)
+			}
+			if !credited[credit.ID][i] {
+				credited[credit.ID][i] = true
+				person.Count++
+			}
+			if credit.Role != "" && !slices.Contains(person.Roles[i], credit.Role) {
+				person.Roles[i] = append(person.Roles[i], credit.Role)
+			}
+		}
+	}
+
+	shared := []models.SharedPerson{}
+	for _, id := range order {
+		if people[id].Count > 1 {
+			shared = append(shared, *people[id])
+		}
+	}
+	slices.SortStableFunc(shared, func(a, b models.SharedPerson) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), strings.Compare(a.Name, b.Name))
+	})
+	return shared
+}
@@ -0,0 +1,158 @@
+package compare
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+)
+
+func stringPtr(s string) *string { return &s }
+
+func intPtr(i int) *int { return &i }
+
+func testTitles() []Title {
+	fightClub := FromMovie(&models.MovieDetails{
+		ID:                  550,
+		Title:               "Fight Club",
+		ReleaseDate:         stringPtr("1999-10-15"),
+		Runtime:             intPtr(139),
+		Budget:              63000000,
+		Revenue:             100853753,
+		VoteAverage:         8.4,
+		VoteCount:           26280,
+		Genres:              []models.Genre{{ID: 18, Name: "Drama"}},
+		ProductionCountries: []models.ProductionCountry{{ISO31661: "US", Name: "United States of America"}, {ISO31661: "DE", Name: "Germany"}},
+	}, &models.MovieCredits{
+		Cast: []models.CastMember{{ID: 287, Name: "Brad Pitt", Character: "Tyler Durden"}, {ID: 819, Name: "Edward Norton", Character: "Narrator"}},
+		Crew: []models.CrewMember{{ID: 7467, Name: "David Fincher", Job: "Director"}},
+	})
+	se7en := FromMovie(&models.MovieDetails{
+		ID:                  807,
+		Title:               "Se7en",
+		ReleaseDate:         stringPtr("1995-09-22"),
+		Runtime:             intPtr(127),
+		Budget:              33000000,
+		Revenue:             327311859,
+		VoteAverage:         8.4,
+		VoteCount:           20000,
+		Genres:              []models.Genre{{ID: 80, Name: "Crime"}, {ID: 18, Name: "Drama"}},
+		ProductionCountries: []models.ProductionCountry{{ISO31661: "US", Name: "United States of America"}},
+	}, &models.MovieCredits{
+		Cast: []models.CastMember{{ID: 287, Name: "Brad Pitt", Character: "Detective David Mills"}},
+		Crew: []models.CrewMember{{ID: 7467, Name: "David Fincher", Job: "Director"}, {ID: 7467, Name: "David Fincher", Job: "Director"}},
+	})
+	mindhunter := FromTV(&models.TVShowDetails{
+		ID:             67744,
+		Name:           "Mindhunter",
+		FirstAirDate:   stringPtr("2017-10-13"),
+		EpisodeRunTime: []int{60, 34},
+		VoteAverage:    8.3,
+		VoteCount:      3000,
+		Genres:         []models.Genre{{ID: 80, Name: "Crime"}, {ID: 18, Name: "Drama"}},
+	}, &models.TVCredits{
+		Crew: []models.TVCrewMember{{ID: 7467, Name: "David Fincher", Job: "Executive Producer"}},
+	})
+	return []Title{fightClub, se7en, mindhunter}
+}
+
+func values(comparison models.NumericComparison) []float64 {
+	result := []float64{}
+	for _, value := range comparison.Values {
+		if value == nil {
+			result = append(result, -1)
+			continue
+		}
+		result = append(result, *value)
+	}
+	return result
+}
+
+func TestCompare_Fields(t *testing.T) {
+	comparison := Compare(testTitles())
+
+	if len(comparison.Titles) != 3 || comparison.Titles[2].MediaType != "tv" || comparison.Titles[2].Title != "Mindhunter" {
+		t.Fatalf("Expected the three titles in order, got %+v", comparison.Titles)
+	}
+
+	fields := comparison.Fields
+	tests := []struct {
+		name            string
+		comparison      models.NumericComparison
+		expectedValues  []float64 // -1 when unknown
+		expectedDiffers bool
+		expectedHighest []int
+		expectedLowest  []int
+	}{
+		{"runtime", fields.Runtime, []float64{139, 127, 34}, true, []int{0}, []int{2}},
+		{"budget", fields.Budget, []float64{63000000, 33000000, -1}, true, []int{0}, []int{1}},
+		{"ROI", fields.ROI, []float64{0.6, 8.92, -1}, true, []int{1}, []int{0}},
+		{"vote average", fields.VoteAverage, []float64{8.4, 8.4, 8.3}, true, []int{0, 1}, []int{2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := values(tt.comparison); !slices.Equal(got, tt.expectedValues) {
+				t.Errorf("Expected values %v, got %v", tt.expectedValues, got)
+			}
+			if tt.comparison.Differs != tt.expectedDiffers {
+				t.Errorf("Expected differs %t, got %t", tt.expectedDiffers, tt.comparison.Differs)
+			}
+			if !slices.Equal(tt.comparison.Highest, tt.expectedHighest) || !slices.Equal(tt.comparison.Lowest, tt.expectedLowest) {
+				t.Errorf("Expected highest %v and lowest %v, got %v and %v", tt.expectedHighest, tt.expectedLowest, tt.comparison.Highest, tt.comparison.Lowest)
+			}
+		})
+	}
+
+	if dates := fields.ReleaseDate; !dates.Differs || !slices.Equal(dates.Earliest, []int{1}) || !slices.Equal(dates.Latest, []int{2}) {
+		t.Errorf("Expected Se7en earliest and Mindhunter latest, got %+v", dates)
+	}
+
+	genres := fields.Genres
+	if !genres.Differs || !slices.Equal(genres.Common, []string{"Drama"}) {
+		t.Errorf("Expected Drama in common, got %+v", genres)
+	}
+	if len(genres.Unique[0]) != 0 || !slices.Equal(genres.Unique[1], []string{"Crime"}) {
+		t.Errorf("Expected Crime unique to the crime titles, got %v", genres.Unique)
+	}
+	if countries := fields.ProductionCountries; len(countries.Common) != 0 || !slices.Equal(countries.Unique[0], []string{"United States of America", "Germany"}) {
+		t.Errorf("Expected no country in common, got %+v", countries)
+	}
+}
+
+func TestCompare_SameValues(t *testing.T) {
+	titles := testTitles()[:2]
+	titles[1].VoteCount = titles[0].VoteCount
+	titles[1].Genres = []string{"drama"}
+	titles[1].ProductionCountries = titles[0].ProductionCountries
+
+	fields := Compare(titles).Fields
+	if fields.VoteCount.Differs || fields.VoteCount.Highest != nil || fields.VoteCount.Lowest != nil {
+		t.Errorf("Expected equal vote counts without highlights, got %+v", fields.VoteCount)
+	}
+	if fields.Genres.Differs || !slices.Equal(fields.Genres.Common, []string{"Drama"}) {
+		t.Errorf("Expected genres matched case-insensitively, got %+v", fields.Genres)
+	}
+	if fields.ProductionCountries.Differs {
+		t.Errorf("Expected the same production countries, got %+v", fields.ProductionCountries)
+	}
+}
+
+func TestCompare_SharedPeople(t *testing.T) {
+	comparison := Compare(testTitles())
+
+	if len(comparison.SharedCast) != 1 {
+		t.Fatalf("Expected one shared cast member, got %+v", comparison.SharedCast)
+	}
+	pitt := comparison.SharedCast[0]
+	if pitt.ID != 287 || pitt.Count != 2 || pitt.Roles[1][0] != "Detective David Mills" || len(pitt.Roles[2]) != 0 {
+		t.Errorf("Expected Brad Pitt in both movies, got %+v", pitt)
+	}
+
+	if len(comparison.SharedCrew) != 1 {
+		t.Fatalf("Expected one shared crew member, got %+v", comparison.SharedCrew)
+	}
+	fincher := comparison.SharedCrew[0]
+	if fincher.Count != 3 || !slices.Equal(fincher.Roles[1], []string{"Director"}) || fincher.Roles[2][0] != "Executive Producer" {
+		t.Errorf("Expected David Fincher on all three titles, got %+v", fincher)
+	}
+}
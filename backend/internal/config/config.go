@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"net/netip"
 	"os"
 	"strconv"
 	"strings"
@@ -17,9 +18,10 @@ type Config struct {
 }
 
 type ServerConfig struct {
-	Port        string
-	Environment string
-	CORSOrigins []string
+	Port           string
+	Environment    string
+	CORSOrigins    []string
+	TrustedProxies []string // IP addresses or CIDR ranges whose X-Forwarded-For headers are believed
 }
 
 type TMDbConfig struct {
@@ -33,15 +35,36 @@ type DatabaseConfig struct {
 	User     string
 	Password string
 	DBName   string
+	SSLMode  string
+
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime int // Seconds; 0 means connections are reused forever
+	ConnMaxIdleTime int // Seconds; 0 means idle connections are kept forever
+	AutoMigrate     bool
+}
+
+// DSN returns the lib/pq connection string for the database, with the movieapi
+// schema first on the search path
+func (d DatabaseConfig) DSN() string {
+	quote := func(v string) string {
+		return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(v) + "'"
+	}
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s search_path=movieapi,public",
+		quote(d.Host), d.Port, quote(d.User), quote(d.Password), quote(d.DBName), quote(d.SSLMode))
 }
 
 type SecurityConfig struct {
-	JWTSecret string
+	JWTSecret       string
+	AccessTokenTTL  int    // Seconds before access tokens expire
+	RefreshTokenTTL int    // Seconds before refresh tokens expire
+	AdminToken      string // Bearer token for /api/v1/admin endpoints; admin endpoints are disabled when empty
 }
 
 type CacheConfig struct {
-	Enabled bool
-	TTL     int // Time to live in seconds
+	Enabled    bool
+	TTL        int // Time to live in seconds
+	StaleAfter int // Seconds before titles cached in the database are refreshed from TMDb
 }
 
 type LoggingConfig struct {
@@ -51,9 +74,10 @@ type LoggingConfig struct {
 func Load() (*Config, error) {
 	config := &Config{
 		Server: ServerConfig{
-			Port:        getEnv("PORT", "8080"),
-			Environment: getEnv("ENV", "development"),
-			CORSOrigins: strings.Split(getEnv("CORS_ORIGINS", "http://localhost:3000,http://localhost:3005"), ","),
+			Port:           getEnv("PORT", "8080"),
+			Environment:    getEnv("ENV", "development"),
+			CORSOrigins:    strings.Split(getEnv("CORS_ORIGINS", "http://localhost:3000,http://localhost:3005"), ","),
+			TrustedProxies: getEnvAsList("TRUSTED_PROXIES"),
 		},
 		TMDb: TMDbConfig{
 			APIKey:  getEnv("TMDB_API_KEY", ""),
@@ -65,13 +89,24 @@ func Load() (*Config, error) {
 			User:     getEnv("POSTGRES_USER", "developer"),
 			Password: getEnv("POSTGRES_PASSWORD", "password"),
 			DBName:   getEnv("POSTGRES_DB", "movieapi"),
+			SSLMode:  getEnv("POSTGRES_SSLMODE", "disable"),
+
+			MaxOpenConns:    getEnvAsInt("POSTGRES_MAX_OPEN_CONNS", 25),
+			MaxIdleConns:    getEnvAsInt("POSTGRES_MAX_IDLE_CONNS", 5),
+			ConnMaxLifetime: getEnvAsInt("POSTGRES_CONN_MAX_LIFETIME", 1800), // 30 minutes default
+			ConnMaxIdleTime: getEnvAsInt("POSTGRES_CONN_MAX_IDLE_TIME", 300), // 5 minutes default
+			AutoMigrate:     getEnvAsBool("POSTGRES_AUTO_MIGRATE", true),
 		},
 		Security: SecurityConfig{
-			JWTSecret: getEnv("JWT_SECRET", "your-secret-key"),
+			JWTSecret:       getEnv("JWT_SECRET", "your-secret-key"),
+			AccessTokenTTL:  getEnvAsInt("JWT_ACCESS_TTL", 900),      // 15 minutes default
+			RefreshTokenTTL: getEnvAsInt("JWT_REFRESH_TTL", 2592000), // 30 days default
+			AdminToken:      getEnv("ADMIN_TOKEN", ""),
 		},
 		Cache: CacheConfig{
-			Enabled: getEnvAsBool("CACHE_ENABLED", true),
-			TTL:     getEnvAsInt("CACHE_TTL", 300), // 5 minutes default
+			Enabled:    getEnvAsBool("CACHE_ENABLED", true),
+			TTL:        getEnvAsInt("CACHE_TTL", 300),           // 5 minutes default
+			StaleAfter: getEnvAsInt("CACHE_STALE_AFTER", 86400), // 24 hours default
 		},
 		Logging: LoggingConfig{
 			Level: getEnv("LOG_LEVEL", "info"),
@@ -92,6 +127,18 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+// getEnvAsList returns the comma-separated values of an environment variable,
+// or nil when it is unset
+func getEnvAsList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 func getEnvAsInt(key string, fallback int) int {
 	if value := os.Getenv(key); value != "" {
 		if intValue, err := strconv.Atoi(value); err == nil {
@@ -122,6 +169,10 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("server port cannot be empty")
 	}
 
+	if _, err := c.Server.TrustedProxyPrefixes(); err != nil {
+		return err
+	}
+
 	// Log level validation
 	validLogLevels := []string{"debug", "info", "warn", "error"}
 	validLevel := false
@@ -139,6 +190,52 @@ func (c *Config) Validate() error {
 	if len(c.Security.JWTSecret) < 32 {
 		return fmt.Errorf("JWT secret must be at least 32 characters long")
 	}
+	if c.Security.AccessTokenTTL < 1 {
+		return fmt.Errorf("access token TTL must be at least 1 second")
+	}
+	if c.Security.RefreshTokenTTL <= c.Security.AccessTokenTTL {
+		return fmt.Errorf("refresh token TTL must be longer than access token TTL (%d)", c.Security.AccessTokenTTL)
+	}
+
+	// Database pool validation
+	if c.Database.MaxOpenConns < 1 {
+		return fmt.Errorf("database max open connections must be at least 1")
+	}
+	if c.Database.MaxIdleConns < 0 || c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		return fmt.Errorf("database max idle connections must be between 0 and max open connections (%d)", c.Database.MaxOpenConns)
+	}
+	if c.Database.ConnMaxLifetime < 0 || c.Database.ConnMaxIdleTime < 0 {
+		return fmt.Errorf("database connection lifetimes cannot be negative")
+	}
+
+	// Cache validation
+	if c.Cache.StaleAfter < 1 {
+		return fmt.Errorf("cache stale-after window must be at least 1 second")
+	}
+
+	// Admin token validation (optional, but must not be guessable when set)
+	if c.Security.AdminToken != "" && len(c.Security.AdminToken) < 32 {
+		return fmt.Errorf("admin token must be at least 32 characters long")
+	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// TrustedProxyPrefixes parses the trusted proxies. A single IP address is
+// trusted on its own.
+func (s ServerConfig) TrustedProxyPrefixes() ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(s.TrustedProxies))
+	for _, proxy := range s.TrustedProxies {
+		if addr, err := netip.ParseAddr(proxy); err == nil {
+			addr = addr.Unmap()
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: must be an IP address or CIDR range", proxy)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
@@ -12,7 +12,8 @@ func TestLoad(t *testing.T) {
 	envKeys := []string{
 		"PORT", "ENV", "CORS_ORIGINS", "TMDB_API_KEY", "TMDB_BASE_URL",
 		"POSTGRES_HOST", "POSTGRES_PORT", "POSTGRES_USER", "POSTGRES_PASSWORD", "POSTGRES_DB",
-		"JWT_SECRET", "CACHE_ENABLED", "CACHE_TTL", "LOG_LEVEL",
+		"JWT_SECRET", "JWT_ACCESS_TTL", "JWT_REFRESH_TTL", "CACHE_ENABLED", "CACHE_TTL", "CACHE_STALE_AFTER", "LOG_LEVEL",
+		"POSTGRES_MAX_OPEN_CONNS", "POSTGRES_MAX_IDLE_CONNS", "TRUSTED_PROXIES",
 	}
 	
 	for _, key := range envKeys {
@@ -74,6 +75,47 @@ func TestLoad(t *testing.T) {
 			expectError: true,
 			errorMsg:    "invalid log level",
 		},
+		{
+			name: "idle connections exceed open connections",
+			envVars: map[string]string{
+				"TMDB_API_KEY":            "test-api-key-12345",
+				"JWT_SECRET":              "this-is-a-very-long-secret-key-for-testing-purposes-32-chars",
+				"POSTGRES_MAX_OPEN_CONNS": "2",
+				"POSTGRES_MAX_IDLE_CONNS": "5",
+			},
+			expectError: true,
+			errorMsg:    "database max idle connections",
+		},
+		{
+			name: "refresh token TTL not longer than access token TTL",
+			envVars: map[string]string{
+				"TMDB_API_KEY":    "test-api-key-12345",
+				"JWT_SECRET":      "this-is-a-very-long-secret-key-for-testing-purposes-32-chars",
+				"JWT_ACCESS_TTL":  "3600",
+				"JWT_REFRESH_TTL": "600",
+			},
+			expectError: true,
+			errorMsg:    "refresh token TTL must be longer",
+		},
+		{
+			name: "trusted proxies",
+			envVars: map[string]string{
+				"TMDB_API_KEY":    "test-api-key-12345",
+				"JWT_SECRET":      "this-is-a-very-long-secret-key-for-testing-purposes-32-chars",
+				"TRUSTED_PROXIES": "10.0.0.0/8, 192.0.2.1,::1",
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid trusted proxy",
+			envVars: map[string]string{
+				"TMDB_API_KEY":    "test-api-key-12345",
+				"JWT_SECRET":      "this-is-a-very-long-secret-key-for-testing-purposes-32-chars",
+				"TRUSTED_PROXIES": "10.0.0.0/8,proxy.internal",
+			},
+			expectError: true,
+			errorMsg:    "invalid trusted proxy",
+		},
 	}
 
 	for _, tt := range tests {
@@ -113,7 +155,7 @@ func TestConfigDefaults(t *testing.T) {
 	envKeys := []string{
 		"PORT", "ENV", "CORS_ORIGINS", "TMDB_API_KEY", "TMDB_BASE_URL",
 		"POSTGRES_HOST", "POSTGRES_PORT", "POSTGRES_USER", "POSTGRES_PASSWORD", "POSTGRES_DB",
-		"JWT_SECRET", "CACHE_ENABLED", "CACHE_TTL", "LOG_LEVEL",
+		"JWT_SECRET", "JWT_ACCESS_TTL", "JWT_REFRESH_TTL", "CACHE_ENABLED", "CACHE_TTL", "CACHE_STALE_AFTER", "LOG_LEVEL",
 	}
 	
 	for _, key := range envKeys {
@@ -156,6 +198,14 @@ func TestConfigDefaults(t *testing.T) {
 		t.Errorf("expected default cache TTL 300, got %d", config.Cache.TTL)
 	}
 	
+	if config.Cache.StaleAfter != 86400 {
+		t.Errorf("expected default cache stale-after 86400, got %d", config.Cache.StaleAfter)
+	}
+	
+	if config.Security.AccessTokenTTL != 900 || config.Security.RefreshTokenTTL != 2592000 {
+		t.Errorf("expected default token TTLs 900/2592000, got %d/%d", config.Security.AccessTokenTTL, config.Security.RefreshTokenTTL)
+	}
+	
 	if config.Logging.Level != "info" {
 		t.Errorf("expected default log level 'info', got %s", config.Logging.Level)
 	}
@@ -224,4 +274,19 @@ func TestGetEnvAsInt(t *testing.T) {
 			os.Unsetenv("TEST_INT")
 		})
 	}
-}
\ No newline at end of file
+}
+func TestDatabaseDSN(t *testing.T) {
+	db := DatabaseConfig{
+		Host:     "localhost",
+		Port:     5432,
+		User:     "movieapi",
+		Password: `p'ss\word`,
+		DBName:   "movieapi",
+		SSLMode:  "disable",
+	}
+
+	expected := `host='localhost' port=5432 user='movieapi' password='p\'ss\\word' dbname='movieapi' sslmode='disable' search_path=movieapi,public`
+	if dsn := db.DSN(); dsn != expected {
+		t.Errorf("expected DSN %q, got %q", expected, dsn)
+	}
+}
@@ -0,0 +1,169 @@
+package connections
+
+import (
+	"context"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+)
+
+// maxCollaboratorTitles is the number of a person's movies, most popular first,
+// scanned for collaborators
+const maxCollaboratorTitles = 50
+
+// Collaborators returns the limit people personID has made the most movies with,
+// in front of or behind the camera. Movies whose credits cannot be fetched are
+// skipped; an error is only returned when the person's own credits cannot be.
+func (s *Service) Collaborators(ctx context.Context, personID, limit int) (*models.Collaborators, error) {
+	credits, err := s.personCredits(ctx, personID)
+	if err != nil {
+		return nil, &PersonError{PersonID: personID, Err: err}
+	}
+
+	titles := personTitles(credits)
+	result := &models.Collaborators{PersonID: personID, Results: []models.Collaborator{}}
+	if len(titles) > maxCollaboratorTitles {
+		titles = titles[:maxCollaboratorTitles]
+		result.Truncated = true
+	}
+
+	ids := make([]int, len(titles))
+	for i, title := range titles {
+		ids[i] = title.ID
+	}
+	movies, errs := fetchAll(ctx, ids, s.movieCredits)
+
+	collaborators := map[int]*models.Collaborator{}
+	var order []int
+	for i, movie := range movies {
+		if errs[i] != nil {
+			if isCanceled(errs[i]) {
+				return nil, errs[i]
+			}
+			log.Printf("Failed to get credits of movie %d: %v", ids[i], errs[i])
+			continue
+		}
+		result.TitlesScanned++
+
+		for _, credit := range movieRoles(movie, personID) {
+			c, ok := collaborators[credit.id]
+			if !ok {
+				c = &models.Collaborator{ID: credit.id, Name: credit.name, ProfilePath: credit.profilePath, KnownForDepartment: credit.department}
+				collaborators[credit.id] = c
+				order = append(order, credit.id)
+			}
+			shared := titles[i]
+			shared.Characters, shared.Jobs = credit.characters, credit.jobs
+			c.SharedTitles = append(c.SharedTitles, shared)
+			c.SharedCount++
+		}
+	}
+
+	for _, id := range order {
+		c := collaborators[id]
+		slices.SortStableFunc(c.SharedTitles, func(a, b models.SharedTitle) int {
+			return strings.Compare(b.ReleaseDate, a.ReleaseDate)
+		})
+		result.Results = append(result.Results, *c)
+	}
+	slices.SortStableFunc(result.Results, func(a, b models.Collaborator) int {
+		if a.SharedCount != b.SharedCount {
+			return b.SharedCount - a.SharedCount
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	result.Results = result.Results[:min(len(result.Results), limit)]
+
+	return result, nil
+}
+
+// personTitles returns each movie a person has a cast or crew credit on once,
+// most popular first
+func personTitles(credits *models.PersonMovieCredits) []models.SharedTitle {
+	type title struct {
+		models.SharedTitle
+		popularity float64
+	}
+	seen := map[int]bool{}
+	var titles []title
+	add := func(id int, name string, date, posterPath *string, popularity float64) {
+		if seen[id] {
+			return
+		}
+		seen[id] = true
+		titles = append(titles, title{
+			SharedTitle: models.SharedTitle{ID: id, Title: name, ReleaseDate: releaseDate(date), PosterPath: posterPath},
+			popularity:  popularity,
+		})
+	}
+	for _, cast := range credits.Cast {
+		add(cast.ID, cast.Title, cast.ReleaseDate, cast.PosterPath, cast.Popularity)
+	}
+	for _, crew := range credits.Crew {
+		add(crew.ID, crew.Title, crew.ReleaseDate, crew.PosterPath, crew.Popularity)
+	}
+
+	slices.SortStableFunc(titles, func(a, b title) int {
+		switch {
+		case a.popularity > b.popularity:
+			return -1
+		case a.popularity < b.popularity:
+			return 1
+		}
+		return a.ID - b.ID
+	})
+	shared := make([]models.SharedTitle, len(titles))
+	for i, t := range titles {
+		shared[i] = t.SharedTitle
+	}
+	return shared
+}
+
+// role is what one person did on a movie
+type role struct {
+	id          int
+	name        string
+	profilePath *string
+	department  string
+	characters  []string
+	jobs        []string
+}
+
+// movieRoles returns what everyone but personID did on a movie, in the order of
+// its credits, with the characters and jobs of each person merged
+func movieRoles(credits *models.MovieCredits, personID int) []role {
+	index := map[int]int{}
+	var roles []role
+	find := func(id int, name string, profilePath *string, department string) *role {
+		i, ok := index[id]
+		if !ok {
+			i = len(roles)
+			index[id] = i
+			roles = append(roles, role{id: id, name: name, profilePath: profilePath, department: department})
+		}
+		return &roles[i]
+	}
+	for _, cast := range credits.Cast {
+		if cast.ID != personID {
+			r := find(cast.ID, cast.Name, cast.ProfilePath, cast.KnownForDepartment)
+			r.characters = appendUnique(r.characters, cast.Character)
+		}
+	}
+	for _, crew := range credits.Crew {
+		if crew.ID != personID {
+			r := find(crew.ID, crew.Name, crew.ProfilePath, crew.KnownForDepartment)
+			r.jobs = appendUnique(r.jobs, crew.Job)
+		}
+	}
+	return roles
+}
+
+// appendUnique appends s to list unless it is empty or already listed
+func appendUnique(list []string, s string) []string {
+	if s == "" || slices.Contains(list, s) {
+		return list
+	}
+	return append(list, s)
+}
@@ -0,0 +1,204 @@
+package connections
+
+import (
+	"cmp"
+	"context"
+	"log"
+	"slices"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+)
+
+// hop is one step of a search: person, in the cast of movie with prev
+type hop struct {
+	prev       int
+	movie      models.ConnectionMovie
+	prevPerson models.ConnectionPerson
+	person     models.ConnectionPerson
+}
+
+// frontier is one direction of a bidirectional search
+type frontier struct {
+	reached map[int]*hop // How each person was reached; nil for where the search started
+	people  []int        // The people reached by the last expansion
+	depth   int          // The number of expansions so far
+}
+
+func newFrontier(personID int) *frontier {
+	return &frontier{reached: map[int]*hop{personID: nil}, people: []int{personID}}
+}
+
+// Connect finds the shortest chain of movies linking two actors, in which each
+// movie shares a cast member with the next. The search runs from both actors at
+// once, always expanding the side with fewer people to explore. It follows each
+// person's req.FanOut most popular movies and each movie's req.FanOut top-billed
+// cast members, gives up after req.MaxDepth movies, and runs out of time after
+// the service's budget. A search that gives up is not an error: the result is
+// returned with Found false and the reason.
+func (s *Service) Connect(ctx context.Context, req models.ConnectionRequest) (*models.PersonConnection, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.budget)
+	defer cancel()
+
+	// Checks both people exist before searching, and warms the cache
+	for _, personID := range []int{req.From, req.To} {
+		if _, err := s.personCredits(ctx, personID); err != nil {
+			return nil, &PersonError{PersonID: personID, Err: err}
+		}
+	}
+
+	result := &models.PersonConnection{From: req.From, To: req.To, Links: []models.ConnectionLink{}}
+	if req.From == req.To {
+		result.Found = true
+		result.PeopleExplored = 1
+		return result, nil
+	}
+
+	forward, backward := newFrontier(req.From), newFrontier(req.To)
+	result.PeopleExplored = 2
+	for {
+		switch {
+		case len(forward.people) == 0 || len(backward.people) == 0:
+			result.Reason = models.ConnectionUnreachable
+			return result, nil
+		case forward.depth+backward.depth >= req.MaxDepth:
+			result.Reason = models.ConnectionDepthLimit
+			return result, nil
+		}
+
+		side, other := forward, backward
+		if len(backward.people) < len(forward.people) {
+			side, other = backward, forward
+		}
+		meeting, err := s.expand(ctx, side, other, req.FanOut)
+		result.PeopleExplored = len(forward.reached) + len(backward.reached)
+		if isCanceled(err) {
+			result.Reason = models.ConnectionTimeBudget
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if meeting != 0 {
+			result.Found = true
+			result.Links = path(forward, backward, meeting)
+			result.Degrees = len(result.Links)
+			return result, nil
+		}
+	}
+}
+
+// expand reaches the co-stars of the people side reached last. It returns the
+// first person reached who other has reached too, or 0 if there is none yet.
+// Every co-star is reached before returning, so that the people side reaches
+// next are complete.
+func (s *Service) expand(ctx context.Context, side, other *frontier, fanOut int) (meeting int, err error) {
+	people, errs := fetchAll(ctx, side.people, s.personCredits)
+	var movieIDs []int
+	seen := map[int]bool{}
+	personMovies := make([][]models.PersonMovieCast, len(people))
+	for i, credits := range people {
+		if errs[i] != nil {
+			if isCanceled(errs[i]) {
+				return 0, errs[i]
+			}
+			log.Printf("Failed to get movie credits of person %d: %v", side.people[i], errs[i])
+			continue
+		}
+		personMovies[i] = topMovies(credits.Cast, fanOut)
+		for _, movie := range personMovies[i] {
+			if !seen[movie.ID] {
+				seen[movie.ID] = true
+				movieIDs = append(movieIDs, movie.ID)
+			}
+		}
+	}
+
+	movies, errs := fetchAll(ctx, movieIDs, s.movieCredits)
+	credits := map[int]*models.MovieCredits{}
+	for i, movie := range movies {
+		if errs[i] != nil {
+			if isCanceled(errs[i]) {
+				return 0, errs[i]
+			}
+			log.Printf("Failed to get credits of movie %d: %v", movieIDs[i], errs[i])
+			continue
+		}
+		credits[movieIDs[i]] = movie
+	}
+
+	var next []int
+	for i, personID := range side.people {
+		for _, movie := range personMovies[i] {
+			movieCredits, ok := credits[movie.ID]
+			if !ok {
+				continue
+			}
+			linking := models.ConnectionMovie{ID: movie.ID, Title: movie.Title, ReleaseDate: releaseDate(movie.ReleaseDate), PosterPath: movie.PosterPath}
+			prevPerson := models.ConnectionPerson{ID: personID}
+			if member, ok := castMember(movieCredits.Cast, personID); ok {
+				prevPerson = connectionPerson(member)
+			}
+
+			for _, member := range topCast(movieCredits.Cast, fanOut) {
+				if _, ok := side.reached[member.ID]; ok {
+					continue
+				}
+				side.reached[member.ID] = &hop{prev: personID, movie: linking, prevPerson: prevPerson, person: connectionPerson(member)}
+				next = append(next, member.ID)
+				if _, ok := other.reached[member.ID]; ok && meeting == 0 {
+					meeting = member.ID
+				}
+			}
+		}
+	}
+	side.people = next
+	side.depth++
+	return meeting, nil
+}
+
+// path returns the chain of links from where forward started to where backward
+// started, through meeting
+func path(forward, backward *frontier, meeting int) []models.ConnectionLink {
+	var links []models.ConnectionLink
+	for h := forward.reached[meeting]; h != nil; h = forward.reached[h.prev] {
+		links = append(links, models.ConnectionLink{From: h.prevPerson, Movie: h.movie, To: h.person})
+	}
+	slices.Reverse(links)
+	// Backward hops point towards where backward started, so they are turned around
+	for h := backward.reached[meeting]; h != nil; h = backward.reached[h.prev] {
+		links = append(links, models.ConnectionLink{From: h.person, Movie: h.movie, To: h.prevPerson})
+	}
+	return links
+}
+
+// topMovies returns the fanOut most popular movies of a person's cast credits
+func topMovies(cast []models.PersonMovieCast, fanOut int) []models.PersonMovieCast {
+	movies := slices.Clone(cast)
+	slices.SortStableFunc(movies, func(a, b models.PersonMovieCast) int {
+		return cmp.Compare(b.Popularity, a.Popularity)
+	})
+	return movies[:min(len(movies), fanOut)]
+}
+
+// topCast returns the fanOut top-billed members of a movie's cast
+func topCast(cast []models.CastMember, fanOut int) []models.CastMember {
+	members := slices.Clone(cast)
+	slices.SortStableFunc(members, func(a, b models.CastMember) int { return a.Order - b.Order })
+	return members[:min(len(members), fanOut)]
+}
+
+// castMember finds a person in a movie's cast
+func castMember(cast []models.CastMember, personID int) (models.CastMember, bool) {
+	for _, member := range cast {
+		if member.ID == personID {
+			return member, true
+		}
+	}
+	return models.CastMember{}, false
+}
+
+// connectionPerson returns a cast member as part of a chain
+func connectionPerson(member models.CastMember) models.ConnectionPerson {
+	return models.ConnectionPerson{ID: member.ID, Name: member.Name, ProfilePath: member.ProfilePath, Character: member.Character}
+}
@@ -0,0 +1,145 @@
+// Package connections explores how people are connected through the movies they
+// made: the collaborators a person has worked with most, and the shortest chain
+// of movies linking two actors.
+//
+// Both walk the credits graph one TMDb request per person or movie, so credits are
+// memoized: a popular movie is fetched once however many searches pass through it.
+package connections
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/takeshi-arihori/movie-api/internal/cache"
+	"github.com/takeshi-arihori/movie-api/internal/models"
+)
+
+const (
+	// CacheTTL is how long fetched credits are reused before TMDb is asked again
+	CacheTTL = 6 * time.Hour
+
+	// DefaultBudget bounds the time spent searching for a connection
+	DefaultBudget = 10 * time.Second
+
+	// DefaultMaxDepth and DefaultFanOut are the search limits used when a request
+	// does not set them
+	DefaultMaxDepth = 4
+	DefaultFanOut   = 20
+
+	// personCacheSize and movieCacheSize bound the number of people and movies
+	// whose credits are cached
+	personCacheSize = 5000
+	movieCacheSize  = 20000
+
+	// fetchConcurrency bounds the concurrent credit requests of one search
+	fetchConcurrency = 8
+)
+
+// Client fetches the credits connections are built from
+type Client interface {
+	GetPersonMovieCredits(ctx context.Context, personID int) (*models.PersonMovieCredits, error)
+	GetMovieCredits(ctx context.Context, movieID int) (*models.MovieCredits, error)
+}
+
+// PersonError reports that the credits of a person asked about could not be fetched
+type PersonError struct {
+	PersonID int
+	Err      error
+}
+
+func (e *PersonError) Error() string {
+	return fmt.Sprintf("person %d: %v", e.PersonID, e.Err)
+}
+
+func (e *PersonError) Unwrap() error {
+	return e.Err
+}
+
+// Service finds collaborators and connections, memoizing the credits it fetches
+type Service struct {
+	client Client
+	budget time.Duration
+
+	people *cache.Cache[int, *models.PersonMovieCredits]
+	movies *cache.Cache[int, *models.MovieCredits]
+}
+
+// NewService creates a Service that fetches credits from client
+func NewService(client Client) *Service {
+	return &Service{
+		client: client,
+		budget: DefaultBudget,
+		people: cache.New[int, *models.PersonMovieCredits](CacheTTL, personCacheSize),
+		movies: cache.New[int, *models.MovieCredits](CacheTTL, movieCacheSize),
+	}
+}
+
+// personCredits returns a person's movie credits, from the cache if possible
+func (s *Service) personCredits(ctx context.Context, personID int) (*models.PersonMovieCredits, error) {
+	if credits, ok := s.people.Get(personID); ok {
+		return credits, nil
+	}
+	credits, err := s.client.GetPersonMovieCredits(ctx, personID)
+	if err != nil {
+		return nil, err
+	}
+	s.people.Set(personID, credits)
+	return credits, nil
+}
+
+// movieCredits returns a movie's credits, from the cache if possible
+func (s *Service) movieCredits(ctx context.Context, movieID int) (*models.MovieCredits, error) {
+	if credits, ok := s.movies.Get(movieID); ok {
+		return credits, nil
+	}
+	credits, err := s.client.GetMovieCredits(ctx, movieID)
+	if err != nil {
+		return nil, err
+	}
+	s.movies.Set(movieID, credits)
+	return credits, nil
+}
+
+// fetchAll calls fetch for each ID concurrently. Results and errors are in the
+// order of ids.
+func fetchAll[T any](ctx context.Context, ids []int, fetch func(ctx context.Context, id int) (T, error)) ([]T, []error) {
+	results := make([]T, len(ids))
+	errs := make([]error, len(ids))
+	sem := make(chan struct{}, fetchConcurrency)
+
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i, id int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if err := ctx.Err(); err != nil {
+				errs[i] = err
+				return
+			}
+			results[i], errs[i] = fetch(ctx, id)
+		}(i, id)
+	}
+	wg.Wait()
+
+	return results, errs
+}
+
+// isCanceled reports whether err comes from a context being canceled or running
+// out of time
+func isCanceled(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// releaseDate returns a release date, or "" when it is unknown
+func releaseDate(date *string) string {
+	if date == nil {
+		return ""
+	}
+	return *date
+}
@@ -0,0 +1,270 @@
+package connections
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+	"github.com/takeshi-arihori/movie-api/internal/services"
+)
+
+// fakeClient serves credits from a fixed set of movies. A movie's popularity is
+// its ID.
+type fakeClient struct {
+	cast  map[int][]int // Movie ID to the IDs of its cast, top-billed first
+	crew  map[int][]int // Movie ID to the IDs of its crew
+	block bool          // Movie credits wait until the request is canceled
+
+	mu    sync.Mutex
+	calls int
+}
+
+func (c *fakeClient) GetPersonMovieCredits(ctx context.Context, personID int) (*models.PersonMovieCredits, error) {
+	c.mu.Lock()
+	c.calls++
+	c.mu.Unlock()
+
+	credits := &models.PersonMovieCredits{ID: personID}
+	for movieID := 1; movieID <= len(c.cast); movieID++ {
+		for _, id := range c.cast[movieID] {
+			if id == personID {
+				credits.Cast = append(credits.Cast, models.PersonMovieCast{ID: movieID, Title: fmt.Sprintf("Movie %d", movieID), Popularity: float64(movieID)})
+			}
+		}
+		for _, id := range c.crew[movieID] {
+			if id == personID {
+				credits.Crew = append(credits.Crew, models.PersonMovieCrew{ID: movieID, Title: fmt.Sprintf("Movie %d", movieID), Job: "Director"})
+			}
+		}
+	}
+	if len(credits.Cast) == 0 && len(credits.Crew) == 0 {
+		return nil, &services.TMDbError{StatusCode: 404, StatusMessage: "Not found"}
+	}
+	return credits, nil
+}
+
+func (c *fakeClient) GetMovieCredits(ctx context.Context, movieID int) (*models.MovieCredits, error) {
+	c.mu.Lock()
+	c.calls++
+	c.mu.Unlock()
+
+	if c.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	credits := &models.MovieCredits{ID: movieID}
+	for order, id := range c.cast[movieID] {
+		credits.Cast = append(credits.Cast, models.CastMember{ID: id, Name: fmt.Sprintf("Person %d", id), Character: fmt.Sprintf("Role %d", id), Order: order})
+	}
+	for _, id := range c.crew[movieID] {
+		credits.Crew = append(credits.Crew, models.CrewMember{ID: id, Name: fmt.Sprintf("Person %d", id), Job: "Director"})
+	}
+	return credits, nil
+}
+
+func (c *fakeClient) callCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls
+}
+
+// chainClient links person 1 to person 4 through movies 2 to 4. Movie 1 is a
+// shortcut from person 1 to person 3, but it is person 3's least popular movie and
+// they are billed last in it, so small fan-outs miss it.
+func chainClient() *fakeClient {
+	return &fakeClient{
+		cast: map[int][]int{
+			1: {1, 5, 6, 3},
+			2: {1, 2},
+			3: {2, 3},
+			4: {3, 4},
+			5: {7, 8},
+		},
+		crew: map[int][]int{
+			2: {9},
+			3: {9},
+		},
+	}
+}
+
+func TestCollaborators(t *testing.T) {
+	service := NewService(chainClient())
+
+	result, err := service.Collaborators(context.Background(), 2, 10)
+	if err != nil {
+		t.Fatalf("Collaborators returned error: %v", err)
+	}
+	if result.PersonID != 2 || result.TitlesScanned != 2 || result.Truncated {
+		t.Errorf("Expected 2 titles scanned for person 2, got %+v", result)
+	}
+	if len(result.Results) != 3 {
+		t.Fatalf("Expected 3 collaborators, got %+v", result.Results)
+	}
+
+	director := result.Results[0]
+	if director.ID != 9 || director.SharedCount != 2 || len(director.SharedTitles) != 2 {
+		t.Fatalf("Expected person 9 first with 2 shared titles, got %+v", director)
+	}
+	if len(director.SharedTitles[0].Jobs) != 1 || director.SharedTitles[0].Jobs[0] != "Director" {
+		t.Errorf("Expected the director's job, got %+v", director.SharedTitles[0])
+	}
+	for _, collaborator := range result.Results {
+		if collaborator.ID == 2 {
+			t.Error("Expected a person not to be their own collaborator")
+		}
+	}
+	if actor := result.Results[1]; actor.ID != 1 || actor.SharedCount != 1 || actor.SharedTitles[0].Characters[0] != "Role 1" {
+		t.Errorf("Expected person 1 as Role 1, got %+v", actor)
+	}
+
+	limited, err := service.Collaborators(context.Background(), 2, 1)
+	if err != nil {
+		t.Fatalf("Collaborators returned error: %v", err)
+	}
+	if len(limited.Results) != 1 || limited.Results[0].ID != 9 {
+		t.Errorf("Expected only person 9, got %+v", limited.Results)
+	}
+}
+
+func TestCollaborators_NotFound(t *testing.T) {
+	service := NewService(chainClient())
+
+	_, err := service.Collaborators(context.Background(), 99, 10)
+	var personErr *PersonError
+	if !errors.As(err, &personErr) || personErr.PersonID != 99 {
+		t.Fatalf("Expected a PersonError for person 99, got %v", err)
+	}
+	var tmdbErr *services.TMDbError
+	if !errors.As(err, &tmdbErr) || tmdbErr.StatusCode != 404 {
+		t.Errorf("Expected the TMDb error to be wrapped, got %v", err)
+	}
+}
+
+func TestConnect(t *testing.T) {
+	tests := []struct {
+		name           string
+		req            models.ConnectionRequest
+		expectedFound  bool
+		expectedPath   []int // People along the chain
+		expectedMovies []int
+		expectedReason string
+	}{
+		{
+			name:           "chain of movies",
+			req:            models.ConnectionRequest{From: 1, To: 4, MaxDepth: 6, FanOut: 2},
+			expectedFound:  true,
+			expectedPath:   []int{1, 2, 3, 4},
+			expectedMovies: []int{2, 3, 4},
+		},
+		{
+			name:           "shortcut within the fan-out",
+			req:            models.ConnectionRequest{From: 1, To: 4, MaxDepth: 6, FanOut: 4},
+			expectedFound:  true,
+			expectedPath:   []int{1, 3, 4},
+			expectedMovies: []int{1, 4},
+		},
+		{
+			name:           "reversed",
+			req:            models.ConnectionRequest{From: 4, To: 1, MaxDepth: 6, FanOut: 2},
+			expectedFound:  true,
+			expectedPath:   []int{4, 3, 2, 1},
+			expectedMovies: []int{4, 3, 2},
+		},
+		{
+			name:           "same person",
+			req:            models.ConnectionRequest{From: 2, To: 2, MaxDepth: 6, FanOut: 2},
+			expectedFound:  true,
+			expectedPath:   []int{},
+			expectedMovies: []int{},
+		},
+		{
+			name:           "depth limit",
+			req:            models.ConnectionRequest{From: 1, To: 4, MaxDepth: 2, FanOut: 2},
+			expectedReason: models.ConnectionDepthLimit,
+		},
+		{
+			name:           "unreachable",
+			req:            models.ConnectionRequest{From: 1, To: 7, MaxDepth: 6, FanOut: 10},
+			expectedReason: models.ConnectionUnreachable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewService(chainClient())
+
+			result, err := service.Connect(context.Background(), tt.req)
+			if err != nil {
+				t.Fatalf("Connect returned error: %v", err)
+			}
+			if result.Found != tt.expectedFound || result.Reason != tt.expectedReason {
+				t.Fatalf("Expected found %v with reason %q, got %+v", tt.expectedFound, tt.expectedReason, result)
+			}
+			if !tt.expectedFound {
+				if len(result.Links) != 0 {
+					t.Errorf("Expected no links, got %+v", result.Links)
+				}
+				return
+			}
+
+			if result.Degrees != len(tt.expectedMovies) || len(result.Links) != len(tt.expectedMovies) {
+				t.Fatalf("Expected %d degrees, got %+v", len(tt.expectedMovies), result)
+			}
+			for i, link := range result.Links {
+				if link.From.ID != tt.expectedPath[i] || link.To.ID != tt.expectedPath[i+1] || link.Movie.ID != tt.expectedMovies[i] {
+					t.Errorf("Expected person %d to person %d in movie %d, got %+v",
+						tt.expectedPath[i], tt.expectedPath[i+1], tt.expectedMovies[i], link)
+				}
+				if link.From.Name == "" || link.From.Character == "" || link.Movie.Title == "" {
+					t.Errorf("Expected names, characters and titles, got %+v", link)
+				}
+			}
+		})
+	}
+}
+
+func TestConnect_NotFound(t *testing.T) {
+	service := NewService(chainClient())
+
+	_, err := service.Connect(context.Background(), models.ConnectionRequest{From: 1, To: 99, MaxDepth: 6, FanOut: 2})
+	var personErr *PersonError
+	if !errors.As(err, &personErr) || personErr.PersonID != 99 {
+		t.Fatalf("Expected a PersonError for person 99, got %v", err)
+	}
+}
+
+func TestConnect_TimeBudget(t *testing.T) {
+	client := chainClient()
+	client.block = true
+	service := NewService(client)
+	service.budget = 20 * time.Millisecond
+
+	result, err := service.Connect(context.Background(), models.ConnectionRequest{From: 1, To: 4, MaxDepth: 6, FanOut: 2})
+	if err != nil {
+		t.Fatalf("Connect returned error: %v", err)
+	}
+	if result.Found || result.Reason != models.ConnectionTimeBudget {
+		t.Errorf("Expected the search to run out of time, got %+v", result)
+	}
+}
+
+func TestConnect_Memoized(t *testing.T) {
+	client := chainClient()
+	service := NewService(client)
+	req := models.ConnectionRequest{From: 1, To: 4, MaxDepth: 6, FanOut: 2}
+
+	if _, err := service.Connect(context.Background(), req); err != nil {
+		t.Fatalf("Connect returned error: %v", err)
+	}
+	calls := client.callCount()
+	if _, err := service.Connect(context.Background(), req); err != nil {
+		t.Fatalf("Connect returned error: %v", err)
+	}
+	if client.callCount() != calls {
+		t.Errorf("Expected the second search to use cached credits, got %d more calls", client.callCount()-calls)
+	}
+}
@@ -0,0 +1,94 @@
+// Package credits filters and summarizes the cast and crew of movies and TV shows.
+//
+// TMDb lists every credit separately, so a title's crew often runs to hundreds of
+// entries, with the same person once per job. Crew are matched by department and
+// job; cast members count as the "Acting" department and have no job.
+package credits
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+)
+
+// Acting is the department of cast members
+const Acting = "Acting"
+
+// Cast returns the cast members matching opts, top-billed first. order returns
+// the billing order of a cast member.
+func Cast[C any](cast []C, order func(C) int, opts models.CreditsOptions) []C {
+	if opts.Job != "" || (opts.Department != "" && !strings.EqualFold(opts.Department, Acting)) {
+		return []C{}
+	}
+
+	matched := slices.Clone(cast)
+	slices.SortStableFunc(matched, func(a, b C) int { return order(a) - order(b) })
+	if opts.Limit > 0 {
+		matched = matched[:min(len(matched), opts.Limit)]
+	}
+	if matched == nil {
+		matched = []C{}
+	}
+	return matched
+}
+
+// Crew returns the crew members matching opts, in their original order
+func Crew(crew []models.CrewMember, opts models.CreditsOptions) []models.CrewMember {
+	matched := []models.CrewMember{}
+	for _, member := range crew {
+		if opts.Department != "" && !strings.EqualFold(member.Department, opts.Department) {
+			continue
+		}
+		if opts.Job != "" && !strings.EqualFold(member.Job, opts.Job) {
+			continue
+		}
+		matched = append(matched, member)
+	}
+	return matched
+}
+
+// MergeCrew lists each crew member once with all their departments and jobs, in
+// the order they first appear
+func MergeCrew(crew []models.CrewMember) []models.MergedCrewMember {
+	index := map[int]int{}
+	merged := []models.MergedCrewMember{}
+	for _, member := range crew {
+		i, ok := index[member.ID]
+		if !ok {
+			i = len(merged)
+			index[member.ID] = i
+			merged = append(merged, models.MergedCrewMember{
+				ID:                 member.ID,
+				Name:               member.Name,
+				OriginalName:       member.OriginalName,
+				Gender:             member.Gender,
+				KnownForDepartment: member.KnownForDepartment,
+				Popularity:         member.Popularity,
+				ProfilePath:        member.ProfilePath,
+			})
+		}
+		m := &merged[i]
+		m.Departments = appendUnique(m.Departments, member.Department)
+		m.Jobs = appendUnique(m.Jobs, member.Job)
+		m.CreditIDs = appendUnique(m.CreditIDs, member.CreditID)
+	}
+	return merged
+}
+
+// appendUnique appends s to list unless it is empty or already listed
+func appendUnique(list []string, s string) []string {
+	if s == "" || slices.Contains(list, s) {
+		return list
+	}
+	return append(list, s)
+}
+
+// TVCrew returns the crew of a TV show as movie crew members, which have the same fields
+func TVCrew(crew []models.TVCrewMember) []models.CrewMember {
+	converted := make([]models.CrewMember, len(crew))
+	for i, member := range crew {
+		converted[i] = models.CrewMember(member)
+	}
+	return converted
+}
@@ -0,0 +1,174 @@
+package credits
+
+import (
+	"testing"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+)
+
+func testCast() []models.CastMember {
+	return []models.CastMember{
+		{ID: 3, Name: "Third", Order: 2},
+		{ID: 1, Name: "First", Order: 0},
+		{ID: 2, Name: "Second", Order: 1},
+	}
+}
+
+func testCrew() []models.CrewMember {
+	return []models.CrewMember{
+		{ID: 10, Name: "Director", Department: "Directing", Job: "Director", CreditID: "a"},
+		{ID: 10, Name: "Director", Department: "Writing", Job: "Screenplay", CreditID: "b"},
+		{ID: 11, Name: "Writer", Department: "Writing", Job: "Novel", CreditID: "c"},
+		{ID: 12, Name: "Composer", Department: "Sound", Job: "Original Music Composer", CreditID: "d"},
+		{ID: 13, Name: "Cinematographer", Department: "Camera", Job: "Director of Photography", CreditID: "e"},
+		{ID: 14, Name: "Producer", Department: "Production", Job: "Producer", CreditID: "f"},
+		{ID: 10, Name: "Director", Department: "Production", Job: "Producer", CreditID: "g"},
+		{ID: 15, Name: "Grip", Department: "Camera", Job: "Key Grip", CreditID: "h"},
+	}
+}
+
+func castIDs(cast []models.CastMember) []int {
+	ids := []int{}
+	for _, member := range cast {
+		ids = append(ids, member.ID)
+	}
+	return ids
+}
+
+func crewIDs(crew []models.CrewMember) []int {
+	ids := []int{}
+	for _, member := range crew {
+		ids = append(ids, member.ID)
+	}
+	return ids
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestCastAndCrew(t *testing.T) {
+	order := func(member models.CastMember) int { return member.Order }
+
+	tests := []struct {
+		name         string
+		opts         models.CreditsOptions
+		expectedCast []int
+		expectedCrew []int
+	}{
+		{
+			name:         "no options",
+			expectedCast: []int{1, 2, 3},
+			expectedCrew: []int{10, 10, 11, 12, 13, 14, 10, 15},
+		},
+		{
+			name:         "top-billed cast",
+			opts:         models.CreditsOptions{Limit: 2},
+			expectedCast: []int{1, 2},
+			expectedCrew: []int{10, 10, 11, 12, 13, 14, 10, 15},
+		},
+		{
+			name:         "department, case-insensitively",
+			opts:         models.CreditsOptions{Department: "camera"},
+			expectedCast: []int{},
+			expectedCrew: []int{13, 15},
+		},
+		{
+			name:         "acting department",
+			opts:         models.CreditsOptions{Department: "Acting"},
+			expectedCast: []int{1, 2, 3},
+			expectedCrew: []int{},
+		},
+		{
+			name:         "job",
+			opts:         models.CreditsOptions{Job: "producer"},
+			expectedCast: []int{},
+			expectedCrew: []int{14, 10},
+		},
+		{
+			name:         "department and job",
+			opts:         models.CreditsOptions{Department: "Writing", Job: "Novel"},
+			expectedCast: []int{},
+			expectedCrew: []int{11},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cast := Cast(testCast(), order, tt.opts)
+			if !equalInts(castIDs(cast), tt.expectedCast) {
+				t.Errorf("Expected cast %v, got %v", tt.expectedCast, castIDs(cast))
+			}
+			crew := Crew(testCrew(), tt.opts)
+			if !equalInts(crewIDs(crew), tt.expectedCrew) {
+				t.Errorf("Expected crew %v, got %v", tt.expectedCrew, crewIDs(crew))
+			}
+		})
+	}
+}
+
+func TestMergeCrew(t *testing.T) {
+	merged := MergeCrew(testCrew())
+
+	if len(merged) != 6 {
+		t.Fatalf("Expected 6 crew members, got %+v", merged)
+	}
+	director := merged[0]
+	if director.ID != 10 || len(director.Jobs) != 3 || director.Jobs[1] != "Screenplay" || director.Jobs[2] != "Producer" {
+		t.Errorf("Expected the director's three jobs, got %+v", director)
+	}
+	if len(director.Departments) != 3 || len(director.CreditIDs) != 3 {
+		t.Errorf("Expected three departments and credits, got %+v", director)
+	}
+	if merged[1].ID != 11 || len(merged[1].Jobs) != 1 {
+		t.Errorf("Expected the writer next with one job, got %+v", merged[1])
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	summary := Summarize(550, testCrew())
+
+	if summary.ID != 550 {
+		t.Errorf("Expected ID 550, got %d", summary.ID)
+	}
+	check := func(role string, members []models.KeyCrewMember, expected []int) {
+		t.Helper()
+		ids := []int{}
+		for _, member := range members {
+			ids = append(ids, member.ID)
+		}
+		if !equalInts(ids, expected) {
+			t.Errorf("Expected %s %v, got %v", role, expected, ids)
+		}
+	}
+	check("directors", summary.Directors, []int{10})
+	check("writers", summary.Writers, []int{10, 11})
+	check("composers", summary.Composers, []int{12})
+	check("cinematographers", summary.Cinematographers, []int{13})
+	check("producers", summary.Producers, []int{14, 10})
+
+	if jobs := summary.Writers[1].Jobs; len(jobs) != 1 || jobs[0] != "Novel" {
+		t.Errorf("Expected the writer's job, got %v", jobs)
+	}
+
+	empty := Summarize(1, nil)
+	if empty.Directors == nil || len(empty.Directors) != 0 {
+		t.Errorf("Expected no directors as an empty list, got %v", empty.Directors)
+	}
+}
+
+func TestTVCrew(t *testing.T) {
+	crew := TVCrew([]models.TVCrewMember{{ID: 1, Name: "Showrunner", Department: "Production", Job: "Executive Producer"}})
+
+	if len(crew) != 1 || crew[0].ID != 1 || crew[0].Job != "Executive Producer" {
+		t.Errorf("Expected the TV crew member converted, got %+v", crew)
+	}
+}
@@ -0,0 +1,48 @@
+package credits
+
+import (
+	"slices"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+)
+
+// Jobs pulled into each role of a summary, as TMDb names them
+var (
+	directorJobs        = []string{"Director", "Co-Director"}
+	writerJobs          = []string{"Screenplay", "Writer", "Story", "Teleplay", "Novel", "Author", "Book", "Original Story", "Comic Book", "Characters"}
+	composerJobs        = []string{"Original Music Composer", "Music", "Composer"}
+	cinematographerJobs = []string{"Director of Photography", "Cinematography"}
+	producerJobs        = []string{"Producer", "Executive Producer", "Co-Producer"}
+)
+
+// Summarize returns the directors, writers, composers, cinematographers and
+// producers of a title, each person once per role in the order of the crew
+func Summarize(id int, crew []models.CrewMember) models.CreditsSummary {
+	return models.CreditsSummary{
+		ID:               id,
+		Directors:        keyCrew(crew, directorJobs),
+		Writers:          keyCrew(crew, writerJobs),
+		Composers:        keyCrew(crew, composerJobs),
+		Cinematographers: keyCrew(crew, cinematographerJobs),
+		Producers:        keyCrew(crew, producerJobs),
+	}
+}
+
+// keyCrew returns the crew members holding any of jobs
+func keyCrew(crew []models.CrewMember, jobs []string) []models.KeyCrewMember {
+	index := map[int]int{}
+	members := []models.KeyCrewMember{}
+	for _, member := range crew {
+		if !slices.Contains(jobs, member.Job) {
+			continue
+		}
+		i, ok := index[member.ID]
+		if !ok {
+			i = len(members)
+			index[member.ID] = i
+			members = append(members, models.KeyCrewMember{ID: member.ID, Name: member.Name, ProfilePath: member.ProfilePath})
+		}
+		members[i].Jobs = appendUnique(members[i].Jobs, member.Job)
+	}
+	return members
+}
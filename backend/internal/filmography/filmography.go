@@ -0,0 +1,202 @@
+// Package filmography turns a person's combined credits into a timeline: every
+// cast and crew credit on a title is merged into one entry, and entries are
+// grouped by year and department.
+package filmography
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+)
+
+const (
+	// Acting is the department of cast credits, as TMDb names it for people
+	Acting = "Acting"
+	// Crew is the department of crew credits TMDb lists without one
+	Crew = "Crew"
+)
+
+// dateLayout is the layout of TMDb release and first air dates
+const dateLayout = "2006-01-02"
+
+// key identifies a title across movies and TV shows, whose IDs overlap
+type key struct {
+	mediaType string
+	id        int
+}
+
+// Build returns the filmography of the person credits belong to, keeping the
+// entries that match filter. Titles released after now, or without a release
+// date, are upcoming and come first.
+//
+// Each entry is listed once, under the department the person is best known for
+// among those they worked in on it; when filter names a department, entries are
+// listed under that department instead.
+func Build(credits *models.PersonCombinedCredits, filter models.FilmographyFilter, now time.Time) *models.Filmography {
+	entries := map[key]*models.FilmographyEntry{}
+	var order []key
+	departmentCredits := map[string]int{}
+
+	entry := func(k key) *models.FilmographyEntry {
+		if e, ok := entries[k]; ok {
+			return e
+		}
+		entries[k] = &models.FilmographyEntry{ID: k.id, MediaType: k.mediaType}
+		order = append(order, k)
+		return entries[k]
+	}
+
+	for _, cast := range credits.Cast {
+		e := entry(key{cast.MediaType, cast.ID})
+		fillTitle(e, cast.Title, cast.Name, cast.OriginalTitle, cast.OriginalName, cast.ReleaseDate, cast.FirstAirDate,
+			cast.PosterPath, cast.VoteAverage, cast.VoteCount, cast.EpisodeCount)
+		e.Characters = appendUnique(e.Characters, strings.TrimSpace(cast.Character))
+		e.Departments = appendUnique(e.Departments, Acting)
+		departmentCredits[Acting]++
+	}
+	for _, crew := range credits.Crew {
+		e := entry(key{crew.MediaType, crew.ID})
+		fillTitle(e, crew.Title, crew.Name, crew.OriginalTitle, crew.OriginalName, crew.ReleaseDate, crew.FirstAirDate,
+			crew.PosterPath, crew.VoteAverage, crew.VoteCount, crew.EpisodeCount)
+		department := crew.Department
+		if department == "" {
+			department = Crew
+		}
+		e.Jobs = appendUnique(e.Jobs, strings.TrimSpace(crew.Job))
+		e.Departments = appendUnique(e.Departments, department)
+		departmentCredits[department]++
+	}
+
+	// byDepartment ranks departments by how many credits the person has in them
+	byDepartment := func(a, b string) int {
+		if departmentCredits[a] != departmentCredits[b] {
+			return departmentCredits[b] - departmentCredits[a]
+		}
+		return strings.Compare(a, b)
+	}
+
+	today := now.Format(dateLayout)
+	years := map[int]map[string][]models.FilmographyEntry{}
+	filmography := &models.Filmography{PersonID: credits.ID, Years: []models.FilmographyYear{}}
+	for _, k := range order {
+		e := entries[k]
+		department, ok := groupDepartment(e, filter, byDepartment)
+		if !ok {
+			continue
+		}
+		e.Upcoming = e.ReleaseDate == "" || e.ReleaseDate > today
+
+		year := releaseYear(e.ReleaseDate)
+		if years[year] == nil {
+			years[year] = map[string][]models.FilmographyEntry{}
+		}
+		years[year][department] = append(years[year][department], *e)
+		filmography.TotalEntries++
+	}
+
+	for year, departments := range years {
+		group := models.FilmographyYear{Year: year}
+		for department, list := range departments {
+			slices.SortFunc(list, compareEntries)
+			group.Departments = append(group.Departments, models.FilmographyDepartment{Department: department, Entries: list})
+		}
+		slices.SortFunc(group.Departments, func(a, b models.FilmographyDepartment) int {
+			return byDepartment(a.Department, b.Department)
+		})
+		filmography.Years = append(filmography.Years, group)
+	}
+	slices.SortFunc(filmography.Years, func(a, b models.FilmographyYear) int {
+		// Undated titles first, then the latest years
+		switch {
+		case a.Year == 0:
+			return -1
+		case b.Year == 0:
+			return 1
+		}
+		return b.Year - a.Year
+	})
+	return filmography
+}
+
+// fillTitle sets the details of the title an entry is about from one of its
+// credits, the first time they are known
+func fillTitle(e *models.FilmographyEntry, title, name, originalTitle, originalName, releaseDate, firstAirDate, posterPath *string,
+	voteAverage float64, voteCount int, episodeCount *int) {
+	if e.Title == "" {
+		e.Title = firstOf(title, name)
+		e.OriginalTitle = firstOf(originalTitle, originalName)
+		e.ReleaseDate = firstOf(releaseDate, firstAirDate)
+		e.PosterPath = posterPath
+		e.VoteAverage = voteAverage
+		e.VoteCount = voteCount
+	}
+	// Cast and crew credits on a TV show may span different episodes
+	if episodeCount != nil {
+		e.EpisodeCount = max(e.EpisodeCount, *episodeCount)
+	}
+}
+
+// groupDepartment returns the department an entry is listed under, or false when
+// the entry does not match filter
+func groupDepartment(e *models.FilmographyEntry, filter models.FilmographyFilter, byDepartment func(a, b string) int) (string, bool) {
+	if filter.MediaType != "" && e.MediaType != filter.MediaType {
+		return "", false
+	}
+	if e.VoteCount < filter.MinVoteCount {
+		return "", false
+	}
+	if filter.Department != "" {
+		for _, department := range e.Departments {
+			if strings.EqualFold(department, filter.Department) {
+				return department, true
+			}
+		}
+		return "", false
+	}
+	return slices.MinFunc(e.Departments, byDepartment), true
+}
+
+// compareEntries orders entries of the same year, latest first. Undated entries
+// come first; entries released on the same day are ordered by title.
+func compareEntries(a, b models.FilmographyEntry) int {
+	switch {
+	case a.ReleaseDate != b.ReleaseDate && a.ReleaseDate == "":
+		return -1
+	case a.ReleaseDate != b.ReleaseDate && b.ReleaseDate == "":
+		return 1
+	case a.ReleaseDate != b.ReleaseDate:
+		return strings.Compare(b.ReleaseDate, a.ReleaseDate)
+	case a.Title != b.Title:
+		return strings.Compare(a.Title, b.Title)
+	}
+	return a.ID - b.ID
+}
+
+// releaseYear returns the year of a release date, or 0 when it is missing or malformed
+func releaseYear(date string) int {
+	t, err := time.Parse(dateLayout, date)
+	if err != nil {
+		return 0
+	}
+	return t.Year()
+}
+
+// firstOf returns the first of values that is set and not empty
+func firstOf(values ...*string) string {
+	for _, v := range values {
+		if v != nil && *v != "" {
+			return *v
+		}
+	}
+	return ""
+}
+
+// appendUnique appends s to list unless it is empty or already listed
+func appendUnique(list []string, s string) []string {
+	if s == "" || slices.Contains(list, s) {
+		return list
+	}
+	return append(list, s)
+}
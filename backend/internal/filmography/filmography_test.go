@@ -0,0 +1,213 @@
+package filmography
+
+import (
+	"testing"
+	"time"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+)
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+var now = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func movieCast(id int, title, date, character string, votes int) models.PersonCombinedCast {
+	return models.PersonCombinedCast{ID: id, MediaType: "movie", Title: strPtr(title), ReleaseDate: strPtr(date), Character: character, VoteCount: votes}
+}
+
+func movieCrew(id int, title, date, department, job string, votes int) models.PersonCombinedCrew {
+	return models.PersonCombinedCrew{ID: id, MediaType: "movie", Title: strPtr(title), ReleaseDate: strPtr(date), Department: department, Job: job, VoteCount: votes}
+}
+
+// testCredits are the credits of a director who sometimes acts in their films
+func testCredits() *models.PersonCombinedCredits {
+	return &models.PersonCombinedCredits{
+		ID: 7,
+		Cast: []models.PersonCombinedCast{
+			movieCast(1, "Old Film", "2001-05-01", "Cameo", 500),
+			movieCast(3, "Next Film", "", "Lead", 0),
+			{ID: 1, MediaType: "tv", Name: strPtr("Old Show"), FirstAirDate: strPtr("2001-09-01"), Character: "Host", EpisodeCount: intPtr(4), VoteCount: 80},
+		},
+		Crew: []models.PersonCombinedCrew{
+			movieCrew(1, "Old Film", "2001-05-01", "Directing", "Director", 500),
+			movieCrew(1, "Old Film", "2001-05-01", "Writing", "Screenplay", 500),
+			movieCrew(1, "Old Film", "2001-05-01", "Writing", "Screenplay", 500),
+			movieCrew(2, "Late Film", "2024-12-25", "Directing", "Director", 10),
+			movieCrew(4, "Recent Film", "2023-03-10", "Directing", "Director", 900),
+			movieCrew(5, "Another Recent Film", "2023-08-10", "Directing", "Director", 300),
+			{ID: 1, MediaType: "tv", Name: strPtr("Old Show"), FirstAirDate: strPtr("2001-09-01"), Department: "Production", Job: "Executive Producer", EpisodeCount: intPtr(10), VoteCount: 80},
+		},
+	}
+}
+
+func entryIDs(year models.FilmographyYear) map[string][]int {
+	ids := map[string][]int{}
+	for _, department := range year.Departments {
+		for _, entry := range department.Entries {
+			ids[department.Department] = append(ids[department.Department], entry.ID)
+		}
+	}
+	return ids
+}
+
+func TestBuild(t *testing.T) {
+	filmography := Build(testCredits(), models.FilmographyFilter{}, now)
+
+	if filmography.PersonID != 7 {
+		t.Errorf("Expected person ID 7, got %d", filmography.PersonID)
+	}
+	if filmography.TotalEntries != 6 {
+		t.Errorf("Expected 6 entries, got %d", filmography.TotalEntries)
+	}
+
+	var years []int
+	for _, year := range filmography.Years {
+		years = append(years, year.Year)
+	}
+	expectedYears := []int{0, 2024, 2023, 2001}
+	if len(years) != len(expectedYears) {
+		t.Fatalf("Expected years %v, got %v", expectedYears, years)
+	}
+	for i := range years {
+		if years[i] != expectedYears[i] {
+			t.Fatalf("Expected years %v, got %v", expectedYears, years)
+		}
+	}
+
+	// The movie and TV show with ID 1 are different titles
+	old := filmography.Years[3]
+	ids := entryIDs(old)
+	if len(ids["Directing"]) != 1 || ids["Directing"][0] != 1 {
+		t.Errorf("Expected movie 1 under Directing, got %v", ids)
+	}
+	if len(ids["Acting"]) != 1 || ids["Acting"][0] != 1 {
+		t.Errorf("Expected TV show 1 under Acting, got %v", ids)
+	}
+	if old.Departments[0].Department != "Directing" {
+		t.Errorf("Expected Directing first, got %s", old.Departments[0].Department)
+	}
+
+	film := old.Departments[0].Entries[0]
+	if film.MediaType != "movie" || film.Title != "Old Film" {
+		t.Fatalf("Expected Old Film, got %+v", film)
+	}
+	if len(film.Jobs) != 2 || film.Jobs[0] != "Director" || film.Jobs[1] != "Screenplay" {
+		t.Errorf("Expected jobs collapsed into Director and Screenplay, got %v", film.Jobs)
+	}
+	if len(film.Characters) != 1 || film.Characters[0] != "Cameo" {
+		t.Errorf("Expected character Cameo, got %v", film.Characters)
+	}
+	if len(film.Departments) != 3 {
+		t.Errorf("Expected three departments, got %v", film.Departments)
+	}
+	if film.Upcoming {
+		t.Error("Expected a released film not to be upcoming")
+	}
+
+	show := old.Departments[1].Entries[0]
+	if show.Title != "Old Show" || show.ReleaseDate != "2001-09-01" || show.EpisodeCount != 10 {
+		t.Errorf("Expected Old Show first aired 2001-09-01 with 10 episodes, got %+v", show)
+	}
+
+	if entry := filmography.Years[0].Departments[0].Entries[0]; entry.ID != 3 || !entry.Upcoming {
+		t.Errorf("Expected undated film 3 to be upcoming, got %+v", entry)
+	}
+	if entry := filmography.Years[1].Departments[0].Entries[0]; entry.ID != 2 || !entry.Upcoming {
+		t.Errorf("Expected film 2 released after now to be upcoming, got %+v", entry)
+	}
+
+	recent := filmography.Years[2].Departments[0].Entries
+	if len(recent) != 2 || recent[0].ID != 5 || recent[1].ID != 4 {
+		t.Errorf("Expected films of 2023 latest first, got %+v", recent)
+	}
+}
+
+func TestBuild_Filters(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   models.FilmographyFilter
+		expected map[int]map[string][]int
+	}{
+		{
+			name:   "media type",
+			filter: models.FilmographyFilter{MediaType: "tv"},
+			expected: map[int]map[string][]int{
+				2001: {"Acting": {1}},
+			},
+		},
+		{
+			name:   "department, case-insensitively",
+			filter: models.FilmographyFilter{Department: "writing"},
+			expected: map[int]map[string][]int{
+				2001: {"Writing": {1}},
+			},
+		},
+		{
+			name:   "department listed under the filter",
+			filter: models.FilmographyFilter{Department: "Acting", MediaType: "movie"},
+			expected: map[int]map[string][]int{
+				0:    {"Acting": {3}},
+				2001: {"Acting": {1}},
+			},
+		},
+		{
+			name:   "minimum vote count",
+			filter: models.FilmographyFilter{MinVoteCount: 300},
+			expected: map[int]map[string][]int{
+				2023: {"Directing": {5, 4}},
+				2001: {"Directing": {1}},
+			},
+		},
+		{
+			name:     "no match",
+			filter:   models.FilmographyFilter{Department: "Sound"},
+			expected: map[int]map[string][]int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filmography := Build(testCredits(), tt.filter, now)
+
+			if len(filmography.Years) != len(tt.expected) {
+				t.Fatalf("Expected %d years, got %+v", len(tt.expected), filmography.Years)
+			}
+			total := 0
+			for _, year := range filmography.Years {
+				ids := entryIDs(year)
+				expected := tt.expected[year.Year]
+				if len(ids) != len(expected) {
+					t.Fatalf("Expected %v in %d, got %v", expected, year.Year, ids)
+				}
+				for department, expectedIDs := range expected {
+					if len(ids[department]) != len(expectedIDs) {
+						t.Fatalf("Expected %v in %d, got %v", expected, year.Year, ids)
+					}
+					for i := range expectedIDs {
+						if ids[department][i] != expectedIDs[i] {
+							t.Errorf("Expected %v in %d, got %v", expected, year.Year, ids)
+						}
+					}
+					total += len(expectedIDs)
+				}
+			}
+			if filmography.TotalEntries != total {
+				t.Errorf("Expected %d entries, got %d", total, filmography.TotalEntries)
+			}
+		})
+	}
+}
+
+func TestBuild_Empty(t *testing.T) {
+	filmography := Build(&models.PersonCombinedCredits{ID: 1}, models.FilmographyFilter{}, now)
+
+	if filmography.TotalEntries != 0 || filmography.Years == nil || len(filmography.Years) != 0 {
+		t.Errorf("Expected an empty timeline, got %+v", filmography)
+	}
+}
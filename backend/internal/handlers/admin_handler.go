@@ -0,0 +1,160 @@
+// Package handlers provides HTTP handlers for admin-only endpoints.
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+)
+
+const (
+	// defaultAnalyticsDays is the analytics window when no days parameter is given
+	defaultAnalyticsDays = 7
+	// maxAnalyticsDays is the largest analytics window that may be requested
+	maxAnalyticsDays = 365
+	// defaultAnalyticsLimit is the number of queries returned when no limit is given
+	defaultAnalyticsLimit = 20
+	// maxAnalyticsLimit is the largest number of queries that may be requested
+	maxAnalyticsLimit = 100
+)
+
+// SearchAnalytics defines the search history reports served to admins
+type SearchAnalytics interface {
+	TopQueries(ctx context.Context, since time.Time, limit int) ([]models.QueryStat, error)
+	ZeroResultQueries(ctx context.Context, since time.Time, limit int) ([]models.QueryStat, error)
+	Volume(ctx context.Context, since time.Time, interval string) ([]models.VolumeBucket, error)
+}
+
+// AdminHandler handles admin HTTP requests
+type AdminHandler struct {
+	analytics SearchAnalytics
+	token     string
+}
+
+// NewAdminHandler creates a new AdminHandler instance. Requests must present token
+// as a bearer token; with an empty token every request is rejected.
+func NewAdminHandler(analytics SearchAnalytics, token string) *AdminHandler {
+	return &AdminHandler{
+		analytics: analytics,
+		token:     token,
+	}
+}
+
+// RequireAdmin rejects requests that do not carry the admin bearer token
+func (h *AdminHandler) RequireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !found || h.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+			writeErrorResponse(w, http.StatusUnauthorized, "unauthorized", "Valid admin token required")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// GetTopQueries handles GET /api/v1/admin/search/top-queries?days=<days>&limit=<limit> requests
+func (h *AdminHandler) GetTopQueries(w http.ResponseWriter, r *http.Request) {
+	h.serveQueryStats(w, r, "top queries", h.analytics.TopQueries)
+}
+
+// GetZeroResultQueries handles GET /api/v1/admin/search/zero-results?days=<days>&limit=<limit> requests
+func (h *AdminHandler) GetZeroResultQueries(w http.ResponseWriter, r *http.Request) {
+	h.serveQueryStats(w, r, "zero-result queries", h.analytics.ZeroResultQueries)
+}
+
+// GetSearchVolume handles GET /api/v1/admin/search/volume?days=<days>&interval=<hour|day|week> requests
+func (h *AdminHandler) GetSearchVolume(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+
+	since, err := parseSince(r.URL.Query().Get("days"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", err.Error())
+		return
+	}
+
+	interval := strings.ToLower(r.URL.Query().Get("interval"))
+	switch interval {
+	case "":
+		interval = "day"
+	case "hour", "day", "week":
+	default:
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", "interval must be 'hour', 'day' or 'week'")
+		return
+	}
+
+	buckets, err := h.analytics.Volume(r.Context(), since, interval)
+	if err != nil {
+		log.Printf("Failed to get search volume: %v", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "database_error", "Failed to get search volume")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, models.AnalyticsResponse[models.VolumeBucket]{
+		Since:    since,
+		Interval: interval,
+		Results:  buckets,
+	})
+}
+
+// serveQueryStats serves a ranked list of queries from the search history
+func (h *AdminHandler) serveQueryStats(w http.ResponseWriter, r *http.Request, name string, report func(ctx context.Context, since time.Time, limit int) ([]models.QueryStat, error)) {
+	if !allowGet(w, r) {
+		return
+	}
+
+	since, err := parseSince(r.URL.Query().Get("days"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", err.Error())
+		return
+	}
+
+	limit := defaultAnalyticsLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > maxAnalyticsLimit {
+			writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter",
+				fmt.Sprintf("limit must be an integer between 1 and %d", maxAnalyticsLimit))
+			return
+		}
+	}
+
+	stats, err := report(r.Context(), since, limit)
+	if err != nil {
+		log.Printf("Failed to get %s: %v", name, err)
+		writeErrorResponse(w, http.StatusInternalServerError, "database_error", "Failed to get "+name)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, models.AnalyticsResponse[models.QueryStat]{
+		Since:   since,
+		Results: stats,
+	})
+}
+
+// parseSince converts the days query parameter into the start of the analytics window
+func parseSince(value string) (time.Time, error) {
+	days := defaultAnalyticsDays
+	if value != "" {
+		var err error
+		days, err = strconv.Atoi(value)
+		if err != nil || days < 1 || days > maxAnalyticsDays {
+			return time.Time{}, fmt.Errorf("days must be an integer between 1 and %d", maxAnalyticsDays)
+		}
+	}
+	return time.Now().UTC().AddDate(0, 0, -days).Truncate(time.Second), nil
+}
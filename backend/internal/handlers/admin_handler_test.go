@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+)
+
+const testAdminToken = "test-admin-token-0123456789abcdef"
+
+// MockSearchAnalytics is a mock implementation of SearchAnalytics
+type MockSearchAnalytics struct {
+	since    time.Time
+	limit    int
+	interval string
+	err      error
+}
+
+func (m *MockSearchAnalytics) TopQueries(ctx context.Context, since time.Time, limit int) ([]models.QueryStat, error) {
+	m.since, m.limit = since, limit
+	if m.err != nil {
+		return nil, m.err
+	}
+	return []models.QueryStat{{Query: "matrix", SearchCount: 42, AverageResults: 12.5}}, nil
+}
+
+func (m *MockSearchAnalytics) ZeroResultQueries(ctx context.Context, since time.Time, limit int) ([]models.QueryStat, error) {
+	m.since, m.limit = since, limit
+	if m.err != nil {
+		return nil, m.err
+	}
+	return []models.QueryStat{{Query: "matirx", SearchCount: 7}}, nil
+}
+
+func (m *MockSearchAnalytics) Volume(ctx context.Context, since time.Time, interval string) ([]models.VolumeBucket, error) {
+	m.since, m.interval = since, interval
+	if m.err != nil {
+		return nil, m.err
+	}
+	return []models.VolumeBucket{{Bucket: since, Searches: 100, ZeroResults: 3, UniqueQueries: 60}}, nil
+}
+
+func TestAdminHandler_RequireAdmin(t *testing.T) {
+	handler := NewAdminHandler(&MockSearchAnalytics{}, testAdminToken)
+	protected := handler.RequireAdmin(http.HandlerFunc(handler.GetTopQueries))
+
+	tests := []struct {
+		name           string
+		authorization  string
+		expectedStatus int
+	}{
+		{"valid token", "Bearer " + testAdminToken, http.StatusOK},
+		{"missing token", "", http.StatusUnauthorized},
+		{"wrong token", "Bearer wrong-token", http.StatusUnauthorized},
+		{"wrong scheme", "Basic " + testAdminToken, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/admin/search/top-queries", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+
+			protected.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+
+	// An empty configured token never authorizes
+	disabled := NewAdminHandler(&MockSearchAnalytics{}, "")
+	req := httptest.NewRequest("GET", "/api/v1/admin/search/top-queries", nil)
+	req.Header.Set("Authorization", "Bearer ")
+	w := httptest.NewRecorder()
+	disabled.RequireAdmin(http.HandlerFunc(disabled.GetTopQueries)).ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status %d with no admin token configured, got %d", http.StatusUnauthorized, w.Code)
+	}
+}
+
+func TestAdminHandler_QueryReports(t *testing.T) {
+	tests := []struct {
+		name           string
+		url            string
+		serve          func(h *AdminHandler) http.HandlerFunc
+		expectedStatus int
+		expectedQuery  string
+		expectedLimit  int
+		expectedDays   int
+	}{
+		{
+			name:           "top queries with defaults",
+			url:            "/api/v1/admin/search/top-queries",
+			serve:          func(h *AdminHandler) http.HandlerFunc { return h.GetTopQueries },
+			expectedStatus: http.StatusOK,
+			expectedQuery:  "matrix",
+			expectedLimit:  defaultAnalyticsLimit,
+			expectedDays:   defaultAnalyticsDays,
+		},
+		{
+			name:           "zero-result queries",
+			url:            "/api/v1/admin/search/zero-results?days=30&limit=5",
+			serve:          func(h *AdminHandler) http.HandlerFunc { return h.GetZeroResultQueries },
+			expectedStatus: http.StatusOK,
+			expectedQuery:  "matirx",
+			expectedLimit:  5,
+			expectedDays:   30,
+		},
+		{
+			name:           "invalid days",
+			url:            "/api/v1/admin/search/top-queries?days=0",
+			serve:          func(h *AdminHandler) http.HandlerFunc { return h.GetTopQueries },
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid limit",
+			url:            "/api/v1/admin/search/top-queries?limit=1000",
+			serve:          func(h *AdminHandler) http.HandlerFunc { return h.GetTopQueries },
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockSearchAnalytics{}
+			handler := NewAdminHandler(mock, testAdminToken)
+
+			req := httptest.NewRequest("GET", tt.url, nil)
+			w := httptest.NewRecorder()
+			tt.serve(handler)(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response models.AnalyticsResponse[models.QueryStat]
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if len(response.Results) != 1 || response.Results[0].Query != tt.expectedQuery {
+				t.Errorf("Expected query %q, got %+v", tt.expectedQuery, response.Results)
+			}
+			if mock.limit != tt.expectedLimit {
+				t.Errorf("Expected limit %d, got %d", tt.expectedLimit, mock.limit)
+			}
+			days := time.Since(mock.since).Hours() / 24
+			if days < float64(tt.expectedDays)-0.01 || days > float64(tt.expectedDays)+0.01 {
+				t.Errorf("Expected a %d day window, got %.2f days", tt.expectedDays, days)
+			}
+		})
+	}
+}
+
+func TestAdminHandler_GetSearchVolume(t *testing.T) {
+	mock := &MockSearchAnalytics{}
+	handler := NewAdminHandler(mock, testAdminToken)
+
+	req := httptest.NewRequest("GET", "/api/v1/admin/search/volume?interval=hour&days=1", nil)
+	w := httptest.NewRecorder()
+	handler.GetSearchVolume(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	var response models.AnalyticsResponse[models.VolumeBucket]
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Interval != "hour" || mock.interval != "hour" {
+		t.Errorf("Expected hour interval, got %q", response.Interval)
+	}
+	if len(response.Results) != 1 || response.Results[0].Searches != 100 {
+		t.Errorf("Expected one bucket with 100 searches, got %+v", response.Results)
+	}
+
+	// Invalid interval
+	req = httptest.NewRequest("GET", "/api/v1/admin/search/volume?interval=minute", nil)
+	w = httptest.NewRecorder()
+	handler.GetSearchVolume(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for invalid interval, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	// Database error
+	mock.err = errors.New("connection refused")
+	req = httptest.NewRequest("GET", "/api/v1/admin/search/volume", nil)
+	w = httptest.NewRecorder()
+	handler.GetSearchVolume(w, req)
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status %d on database error, got %d", http.StatusInternalServerError, w.Code)
+	}
+}
@@ -0,0 +1,187 @@
+// Package handlers provides HTTP handlers for account registration and sessions.
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/takeshi-arihori/movie-api/internal/auth"
+	"github.com/takeshi-arihori/movie-api/internal/models"
+)
+
+// AuthService defines the account and session operations behind the auth endpoints
+type AuthService interface {
+	Register(ctx context.Context, req models.RegisterRequest) (*models.AuthResponse, error)
+	Login(ctx context.Context, req models.LoginRequest) (*models.AuthResponse, error)
+	Refresh(ctx context.Context, refreshToken string) (*models.AuthResponse, error)
+	Logout(ctx context.Context, refreshToken string) error
+	Authenticate(ctx context.Context, accessToken string) (*models.User, error)
+}
+
+// AuthHandler handles registration, login and session HTTP requests
+type AuthHandler struct {
+	auth AuthService
+}
+
+// NewAuthHandler creates a new AuthHandler instance
+func NewAuthHandler(auth AuthService) *AuthHandler {
+	return &AuthHandler{
+		auth: auth,
+	}
+}
+
+// Register handles POST /api/v1/auth/register requests
+func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	var req models.RegisterRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	session, err := h.auth.Register(r.Context(), req)
+	if err != nil {
+		writeAuthError(w, "register", err)
+		return
+	}
+
+	log.Printf("Registered user %s", session.User.ID)
+	writeJSONResponse(w, http.StatusCreated, session)
+}
+
+// Login handles POST /api/v1/auth/login requests
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	var req models.LoginRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	session, err := h.auth.Login(r.Context(), req)
+	if err != nil {
+		writeAuthError(w, "login", err)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, session)
+}
+
+// Refresh handles POST /api/v1/auth/refresh requests, exchanging a refresh token
+// for a new access token and refresh token
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	var req models.RefreshRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	session, err := h.auth.Refresh(r.Context(), req.RefreshToken)
+	if err != nil {
+		writeAuthError(w, "refresh", err)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, session)
+}
+
+// Logout handles POST /api/v1/auth/logout requests, revoking the session the
+// refresh token belongs to
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	var req models.RefreshRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if err := h.auth.Logout(r.Context(), req.RefreshToken); err != nil {
+		writeAuthError(w, "logout", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RequireAuth rejects requests without a valid access token and otherwise puts
+// the authenticated user in the request context, for auth.UserFromContext
+func (h *AuthHandler) RequireAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !found || token == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="movie-api"`)
+			writeErrorResponse(w, http.StatusUnauthorized, "unauthorized", "Valid access token required")
+			return
+		}
+
+		user, err := h.auth.Authenticate(r.Context(), token)
+		if err != nil {
+			if errors.Is(err, auth.ErrInvalidToken) {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="movie-api", error="invalid_token"`)
+			}
+			writeAuthError(w, "authenticate", err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(auth.WithUser(r.Context(), user)))
+	})
+}
+
+// OptionalAuth is RequireAuth for routes that anonymous users may also call:
+// requests without an Authorization header are passed on without a user, but a
+// token that is sent must be valid
+func (h *AuthHandler) OptionalAuth(next http.Handler) http.Handler {
+	authenticated := h.RequireAuth(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		authenticated.ServeHTTP(w, r)
+	})
+}
+
+// requireUser returns the user authenticated by RequireAuth. It writes a 401
+// response and returns false if the route was not wrapped in RequireAuth.
+func requireUser(w http.ResponseWriter, r *http.Request) (*models.User, bool) {
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		writeErrorResponse(w, http.StatusUnauthorized, "unauthorized", "Valid access token required")
+		return nil, false
+	}
+	return user, true
+}
+
+// writeAuthError maps an AuthService error to an error response
+func writeAuthError(w http.ResponseWriter, op string, err error) {
+	switch {
+	case errors.Is(err, auth.ErrUserExists):
+		writeErrorResponse(w, http.StatusConflict, "user_exists", "Email or username is already registered")
+	case errors.Is(err, auth.ErrInvalidCredentials):
+		writeErrorResponse(w, http.StatusUnauthorized, "invalid_credentials", "Invalid login or password")
+	case errors.Is(err, auth.ErrInvalidToken):
+		writeErrorResponse(w, http.StatusUnauthorized, "invalid_token", "Token is invalid or expired")
+	case errors.Is(err, auth.ErrInactiveUser):
+		writeErrorResponse(w, http.StatusForbidden, "account_disabled", "Account is disabled")
+	default:
+		log.Printf("Auth %s failed: %v", op, err)
+		writeErrorResponse(w, http.StatusInternalServerError, "internal_error", "Authentication failed")
+	}
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/takeshi-arihori/movie-api/internal/auth"
+	"github.com/takeshi-arihori/movie-api/internal/models"
+)
+
+// MockAuthService is a mock implementation of AuthService
+type MockAuthService struct {
+	err error
+}
+
+func (m *MockAuthService) session() (*models.AuthResponse, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &models.AuthResponse{
+		AccessToken:  "access-token",
+		TokenType:    "Bearer",
+		ExpiresIn:    900,
+		RefreshToken: "refresh-token",
+		User:         &models.User{ID: "user-1", Username: "neo"},
+	}, nil
+}
+
+func (m *MockAuthService) Register(ctx context.Context, req models.RegisterRequest) (*models.AuthResponse, error) {
+	return m.session()
+}
+
+func (m *MockAuthService) Login(ctx context.Context, req models.LoginRequest) (*models.AuthResponse, error) {
+	return m.session()
+}
+
+func (m *MockAuthService) Refresh(ctx context.Context, refreshToken string) (*models.AuthResponse, error) {
+	return m.session()
+}
+
+func (m *MockAuthService) Logout(ctx context.Context, refreshToken string) error {
+	return m.err
+}
+
+func (m *MockAuthService) Authenticate(ctx context.Context, accessToken string) (*models.User, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	if accessToken != "access-token" {
+		return nil, auth.ErrInvalidToken
+	}
+	return &models.User{ID: "user-1", Username: "neo"}, nil
+}
+
+func TestAuthHandler_Register(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		err            error
+		expectedStatus int
+		expectedError  string
+	}{
+		{"valid", "POST", `{"email":"neo@example.com","username":"neo","password":"followthewhiterabbit"}`, nil, http.StatusCreated, ""},
+		{"taken", "POST", `{"email":"neo@example.com","username":"neo","password":"followthewhiterabbit"}`, auth.ErrUserExists, http.StatusConflict, "user_exists"},
+		{"invalid email", "POST", `{"email":"neo","username":"neo","password":"followthewhiterabbit"}`, nil, http.StatusBadRequest, "validation_error"},
+		{"short password", "POST", `{"email":"neo@example.com","username":"neo","password":"short"}`, nil, http.StatusBadRequest, "validation_error"},
+		{"multibyte password", "POST", `{"email":"neo@example.com","username":"neo","password":"` + strings.Repeat("白兎", 12) + `"}`, nil, http.StatusCreated, ""},
+		{"password over 72 bytes", "POST", `{"email":"neo@example.com","username":"neo","password":"` + strings.Repeat("白兎を追いかけろ", 5) + `"}`, nil, http.StatusBadRequest, "validation_error"},
+		{"unknown field", "POST", `{"email":"neo@example.com","username":"neo","password":"followthewhiterabbit","admin":true}`, nil, http.StatusBadRequest, "invalid_request"},
+		{"malformed JSON", "POST", `{"email":`, nil, http.StatusBadRequest, "invalid_request"},
+		{"wrong method", "GET", "", nil, http.StatusMethodNotAllowed, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewAuthHandler(&MockAuthService{err: tt.err})
+			req := httptest.NewRequest(tt.method, "/api/v1/auth/register", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.Register(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedError != "" {
+				var response ErrorResponse
+				if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+					t.Fatalf("Failed to decode response: %v", err)
+				}
+				if response.Error != tt.expectedError {
+					t.Errorf("Expected error %q, got %q", tt.expectedError, response.Error)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthHandler_Sessions(t *testing.T) {
+	tests := []struct {
+		name           string
+		handle         func(*AuthHandler) http.HandlerFunc
+		body           string
+		err            error
+		expectedStatus int
+	}{
+		{"login", func(h *AuthHandler) http.HandlerFunc { return h.Login }, `{"login":"neo","password":"followthewhiterabbit"}`, nil, http.StatusOK},
+		{"login wrong password", func(h *AuthHandler) http.HandlerFunc { return h.Login }, `{"login":"neo","password":"wrong"}`, auth.ErrInvalidCredentials, http.StatusUnauthorized},
+		{"login disabled account", func(h *AuthHandler) http.HandlerFunc { return h.Login }, `{"login":"neo","password":"followthewhiterabbit"}`, auth.ErrInactiveUser, http.StatusForbidden},
+		{"login missing password", func(h *AuthHandler) http.HandlerFunc { return h.Login }, `{"login":"neo"}`, nil, http.StatusBadRequest},
+		{"login password over 72 bytes", func(h *AuthHandler) http.HandlerFunc { return h.Login }, `{"login":"neo","password":"` + strings.Repeat("白兎を追いかけろ", 5) + `"}`, nil, http.StatusBadRequest},
+		{"refresh", func(h *AuthHandler) http.HandlerFunc { return h.Refresh }, `{"refresh_token":"refresh-token"}`, nil, http.StatusOK},
+		{"refresh reused token", func(h *AuthHandler) http.HandlerFunc { return h.Refresh }, `{"refresh_token":"refresh-token"}`, auth.ErrInvalidToken, http.StatusUnauthorized},
+		{"refresh store failure", func(h *AuthHandler) http.HandlerFunc { return h.Refresh }, `{"refresh_token":"refresh-token"}`, errors.New("connection refused"), http.StatusInternalServerError},
+		{"logout", func(h *AuthHandler) http.HandlerFunc { return h.Logout }, `{"refresh_token":"refresh-token"}`, nil, http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewAuthHandler(&MockAuthService{err: tt.err})
+			req := httptest.NewRequest("POST", "/api/v1/auth", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			tt.handle(handler)(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthHandler_RequireAuth(t *testing.T) {
+	handler := NewAuthHandler(&MockAuthService{})
+	protected := handler.RequireAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := auth.UserFromContext(r.Context())
+		if !ok {
+			t.Error("Expected the user in the request context")
+			return
+		}
+		writeJSONResponse(w, http.StatusOK, user)
+	}))
+
+	tests := []struct {
+		name           string
+		authorization  string
+		expectedStatus int
+	}{
+		{"valid token", "Bearer access-token", http.StatusOK},
+		{"missing token", "", http.StatusUnauthorized},
+		{"invalid token", "Bearer forged-token", http.StatusUnauthorized},
+		{"wrong scheme", "Basic access-token", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/me/favorites", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+
+			protected.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if w.Code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("Expected a WWW-Authenticate challenge")
+			}
+		})
+	}
+}
+
+func TestAuthHandler_OptionalAuth(t *testing.T) {
+	handler := NewAuthHandler(&MockAuthService{})
+	optional := handler.OptionalAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username := "anonymous"
+		if user, ok := auth.UserFromContext(r.Context()); ok {
+			username = user.Username
+		}
+		w.Write([]byte(username))
+	}))
+
+	tests := []struct {
+		name           string
+		authorization  string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"valid token", "Bearer access-token", http.StatusOK, "neo"},
+		{"anonymous", "", http.StatusOK, "anonymous"},
+		{"invalid token", "Bearer forged-token", http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/reviews", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+
+			optional.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedBody != "" && w.Body.String() != tt.expectedBody {
+				t.Errorf("Expected %q, got %q", tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -3,10 +3,59 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"net/netip"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/takeshi-arihori/movie-api/internal/services"
 )
 
+// maxRequestBodySize bounds JSON request bodies
+const maxRequestBodySize = 1 << 20
+
+// validate checks request bodies against their struct tags, reporting fields by
+// their JSON names
+var validate = newValidator()
+
+// newValidator creates a validator that names fields by their json tags. Besides
+// the built-in tags it understands maxbytes, which bounds the length of a string
+// in bytes rather than characters.
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterValidation("maxbytes", func(fl validator.FieldLevel) bool {
+		limit, err := strconv.Atoi(fl.Param())
+		return err == nil && len(fl.Field().String()) <= limit
+	})
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	return v
+}
+
+// DataSourceHeader reports where a response's data came from when it is not TMDb
+const DataSourceHeader = "X-Data-Source"
+
+// DataSourceStaleCache marks responses served from the local catalogue because
+// TMDb was unavailable. Only search, the popular, top rated and trending lists
+// and movie details fall back to the catalogue; other TMDb-backed endpoints
+// fail with DataSourceUnavailable instead.
+const DataSourceStaleCache = "stale-cache"
+
+// DataSourceUnavailable marks error responses for requests that needed TMDb
+// while it was unavailable, with no local copy to serve instead
+const DataSourceUnavailable = "unavailable"
+
 // ErrorResponse represents an API error response
 type ErrorResponse struct {
 	Error   string `json:"error"`
@@ -32,4 +81,172 @@ func writeErrorResponse(w http.ResponseWriter, statusCode int, errorType, messag
 		Code:    statusCode,
 	}
 	writeJSONResponse(w, statusCode, errorResp)
-}
\ No newline at end of file
+}
+
+// markStale flags a response as served from the local catalogue
+func markStale(w http.ResponseWriter) {
+	w.Header().Set(DataSourceHeader, DataSourceStaleCache)
+}
+
+// canServeStale reports whether err is an upstream failure that the local
+// catalogue can stand in for. TMDb answering that a resource does not exist or
+// that the request is invalid is not a failure.
+func canServeStale(err error) bool {
+	var tmdbErr *services.TMDbError
+	if errors.As(err, &tmdbErr) {
+		return tmdbErr.StatusCode != http.StatusNotFound && tmdbErr.StatusCode != http.StatusBadRequest &&
+			tmdbErr.StatusCode != http.StatusUnprocessableEntity
+	}
+	return true
+}
+
+// isTMDbUnavailable reports whether err means TMDb could not be reached, or
+// answered that it is overloaded or temporarily down
+func isTMDbUnavailable(err error) bool {
+	var tmdbErr *services.TMDbError
+	if errors.As(err, &tmdbErr) {
+		switch tmdbErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// writeUpstreamError writes the error response for a failed TMDb request on an
+// endpoint the local catalogue cannot stand in for. While TMDb is unavailable
+// this is 503 tmdb_unavailable with X-Data-Source: unavailable, so clients can
+// retry later; any other failure is 500 api_error.
+func writeUpstreamError(w http.ResponseWriter, err error, message string) {
+	if isTMDbUnavailable(err) {
+		w.Header().Set(DataSourceHeader, DataSourceUnavailable)
+		writeErrorResponse(w, http.StatusServiceUnavailable, "tmdb_unavailable", message)
+		return
+	}
+	writeErrorResponse(w, http.StatusInternalServerError, "api_error", message)
+}
+
+// allowGet sets the CORS headers for a GET endpoint and handles preflight and
+// method checks. It returns false when the response has already been written.
+func allowGet(w http.ResponseWriter, r *http.Request) bool {
+	return allowMethod(w, r, http.MethodGet)
+}
+
+// allowMethod sets the CORS headers for an endpoint accepting method and handles
+// preflight and method checks. It returns false when the response has already
+// been written.
+func allowMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	// Enable CORS
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", method+", OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+
+	// Handle preflight requests
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return false
+	}
+
+	// Only allow the endpoint's method
+	if r.Method != method {
+		writeErrorResponse(w, http.StatusMethodNotAllowed, "method_not_allowed", fmt.Sprintf("Only %s method is allowed", method))
+		return false
+	}
+
+	return true
+}
+
+// decodeJSONBody decodes a JSON request body into dst and validates it with its
+// struct tags. On failure an error response has already been written and false
+// is returned.
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dst); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_request", "Request body must be a valid JSON object")
+		return false
+	}
+
+	if err := validate.Struct(dst); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "validation_error", validationMessage(err))
+		return false
+	}
+	return true
+}
+
+// validationMessage describes the first failed validation of a request body
+func validationMessage(err error) string {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) || len(validationErrs) == 0 {
+		return "Request body is invalid"
+	}
+
+	field := validationErrs[0]
+	switch field.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", field.Field())
+	case "email":
+		return fmt.Sprintf("%s must be a valid email address", field.Field())
+	case "min", "max":
+		return fmt.Sprintf("%s must satisfy %s=%s", field.Field(), field.Tag(), field.Param())
+	case "maxbytes":
+		return fmt.Sprintf("%s must be at most %s bytes long", field.Field(), field.Param())
+	case "uuid":
+		return fmt.Sprintf("%s must be a valid UUID", field.Field())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", field.Field(), strings.ReplaceAll(field.Param(), " ", ", "))
+	default:
+		return fmt.Sprintf("%s is invalid (%s)", field.Field(), field.Tag())
+	}
+}
+
+// clientIP returns the client address for logging and analytics. The
+// X-Forwarded-For and X-Real-IP headers can be set by anyone, so they are only
+// honoured when the request comes from one of the trusted reverse proxies; the
+// client is then the last X-Forwarded-For hop that is not a trusted proxy. It
+// returns "" when no valid IP address is available.
+func clientIP(r *http.Request, trustedProxies []netip.Prefix) string {
+	remote := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+	addr, err := netip.ParseAddr(remote)
+	if err != nil {
+		return ""
+	}
+	if !isTrustedProxy(addr, trustedProxies) {
+		return addr.String()
+	}
+
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		hops := strings.Split(forwarded, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+			if err != nil {
+				return ""
+			}
+			addr = hop
+			if !isTrustedProxy(hop, trustedProxies) {
+				break
+			}
+		}
+		return addr.String()
+	}
+	if realIP, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); err == nil {
+		return realIP.String()
+	}
+	return addr.String()
+}
+
+// isTrustedProxy reports whether addr belongs to one of the trusted proxies
+func isTrustedProxy(addr netip.Addr, trustedProxies []netip.Prefix) bool {
+	addr = addr.Unmap()
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+// Package handlers provides the HTTP handler for comparing movies and TV shows.
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/takeshi-arihori/movie-api/internal/compare"
+	"github.com/takeshi-arihori/movie-api/internal/models"
+)
+
+// CompareClient defines the TMDb operations needed to compare titles
+type CompareClient interface {
+	GetMovieDetails(ctx context.Context, movieID int) (*models.MovieDetails, error)
+	GetMovieCredits(ctx context.Context, movieID int) (*models.MovieCredits, error)
+	GetTVShowDetails(ctx context.Context, tvID int) (*models.TVShowDetails, error)
+	GetTVShowCredits(ctx context.Context, tvID int) (*models.TVCredits, error)
+}
+
+// CompareHandler handles title comparison HTTP requests
+type CompareHandler struct {
+	tmdbClient CompareClient
+}
+
+// NewCompareHandler creates a new CompareHandler instance
+func NewCompareHandler(tmdbClient CompareClient) *CompareHandler {
+	return &CompareHandler{
+		tmdbClient: tmdbClient,
+	}
+}
+
+// Compare handles GET /api/v1/compare requests. movies and tv list the IDs of the
+// titles to compare, e.g. movies=550,807 or movies=550&tv=1399.
+func (h *CompareHandler) Compare(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+
+	refs, err := parseCompareRequest(r.URL.Query())
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", err.Error())
+		return
+	}
+
+	titles, failed, err := h.fetchTitles(r.Context(), refs)
+	if err != nil {
+		log.Printf("Failed to get %s %d to compare: %v", failed.mediaType, failed.mediaID, err)
+		if isTMDbNotFound(err) {
+			writeErrorResponse(w, http.StatusNotFound, string(failed.mediaType)+"_not_found",
+				fmt.Sprintf("%s with ID %d not found", mediaTypeName(failed.mediaType), failed.mediaID))
+			return
+		}
+		writeUpstreamError(w, err, "Failed to retrieve titles to compare")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, compare.Compare(titles))
+}
+
+// parseCompareRequest reads the movies and tv query parameters. Movies are
+// listed before TV shows.
+func parseCompareRequest(query url.Values) ([]titleRef, error) {
+	movies, tv := query.Get("movies"), query.Get("tv")
+	if movies == "" && tv == "" {
+		return nil, fmt.Errorf("the movies or tv parameter is required")
+	}
+
+	var refs []titleRef
+	params := []struct {
+		name      string
+		mediaType models.SearchItemType
+		ids       string
+	}{
+		{"movies", models.SearchItemTypeMovie, movies},
+		{"tv", models.SearchItemTypeTV, tv},
+	}
+	for _, param := range params {
+		if param.ids == "" {
+			continue
+		}
+		for _, value := range strings.Split(param.ids, ",") {
+			id, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || id < 1 {
+				return nil, fmt.Errorf("invalid %s parameter: must be a comma-separated list of IDs", param.name)
+			}
+			ref := titleRef{mediaType: param.mediaType, mediaID: id}
+			for _, listed := range refs {
+				if listed == ref {
+					return nil, fmt.Errorf("invalid %s parameter: %d is listed twice", param.name, id)
+				}
+			}
+			refs = append(refs, ref)
+		}
+	}
+	if len(refs) < compare.MinTitles || len(refs) > compare.MaxTitles {
+		return nil, fmt.Errorf("the movies and tv parameters must list between %d and %d IDs in total", compare.MinTitles, compare.MaxTitles)
+	}
+	return refs, nil
+}
+
+// fetchTitles fetches the details and credits of each title concurrently. On
+// failure, the first title that could not be fetched is returned with the error.
+func (h *CompareHandler) fetchTitles(ctx context.Context, refs []titleRef) (titles []compare.Title, failed titleRef, err error) {
+	movieDetails := make([]*models.MovieDetails, len(refs))
+	movieCredits := make([]*models.MovieCredits, len(refs))
+	tvDetails := make([]*models.TVShowDetails, len(refs))
+	tvCredits := make([]*models.TVCredits, len(refs))
+	detailsErrs := make([]error, len(refs))
+	creditsErrs := make([]error, len(refs))
+
+	var wg sync.WaitGroup
+	for i, ref := range refs {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if ref.mediaType == models.SearchItemTypeTV {
+				tvDetails[i], detailsErrs[i] = h.tmdbClient.GetTVShowDetails(ctx, ref.mediaID)
+				return
+			}
+			movieDetails[i], detailsErrs[i] = h.tmdbClient.GetMovieDetails(ctx, ref.mediaID)
+		}()
+		go func() {
+			defer wg.Done()
+			if ref.mediaType == models.SearchItemTypeTV {
+				tvCredits[i], creditsErrs[i] = h.tmdbClient.GetTVShowCredits(ctx, ref.mediaID)
+				return
+			}
+			movieCredits[i], creditsErrs[i] = h.tmdbClient.GetMovieCredits(ctx, ref.mediaID)
+		}()
+	}
+	wg.Wait()
+
+	titles = make([]compare.Title, len(refs))
+	for i, ref := range refs {
+		for _, err := range []error{detailsErrs[i], creditsErrs[i]} {
+			if err != nil {
+				return nil, ref, err
+			}
+		}
+		if ref.mediaType == models.SearchItemTypeTV {
+			titles[i] = compare.FromTV(tvDetails[i], tvCredits[i])
+		} else {
+			titles[i] = compare.FromMovie(movieDetails[i], movieCredits[i])
+		}
+	}
+	return titles, titleRef{}, nil
+}
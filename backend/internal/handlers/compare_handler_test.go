@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+	"github.com/takeshi-arihori/movie-api/internal/services"
+)
+
+// fakeCompareClient knows movies 550 and 807 and TV shows 1399 and 1396. Credits
+// for ID 500 make TMDb fail.
+type fakeCompareClient struct{}
+
+func compareLookup(id int, known ...int) error {
+	if id == 500 {
+		return errors.New("connection refused")
+	}
+	for _, knownID := range known {
+		if id == knownID {
+			return nil
+		}
+	}
+	return &services.TMDbError{StatusCode: 404, StatusMessage: "Not found"}
+}
+
+func (c *fakeCompareClient) GetMovieDetails(ctx context.Context, movieID int) (*models.MovieDetails, error) {
+	if movieID == 500 {
+		return &models.MovieDetails{ID: movieID}, nil
+	}
+	if err := compareLookup(movieID, 550, 807); err != nil {
+		return nil, err
+	}
+	return &models.MovieDetails{ID: movieID, Title: "Movie", Budget: int64(movieID) * 100000, Revenue: 100000000}, nil
+}
+
+func (c *fakeCompareClient) GetMovieCredits(ctx context.Context, movieID int) (*models.MovieCredits, error) {
+	if err := compareLookup(movieID, 550, 807); err != nil {
+		return nil, err
+	}
+	return &models.MovieCredits{ID: movieID, Cast: []models.CastMember{{ID: 287, Name: "Brad Pitt", Character: "Lead"}}}, nil
+}
+
+func (c *fakeCompareClient) GetTVShowDetails(ctx context.Context, tvID int) (*models.TVShowDetails, error) {
+	if err := compareLookup(tvID, 1399, 1396); err != nil {
+		return nil, err
+	}
+	return &models.TVShowDetails{ID: tvID, Name: "Show", EpisodeRunTime: []int{tvID / 25}}, nil
+}
+
+func (c *fakeCompareClient) GetTVShowCredits(ctx context.Context, tvID int) (*models.TVCredits, error) {
+	if err := compareLookup(tvID, 1399, 1396); err != nil {
+		return nil, err
+	}
+	return &models.TVCredits{ID: tvID}, nil
+}
+
+func TestCompareHandler_Compare(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedError  string
+		expectedTitles []int
+	}{
+		{"movies", "?movies=550,807", http.StatusOK, "", []int{550, 807}},
+		{"TV shows", "?tv=1399,%201396", http.StatusOK, "", []int{1399, 1396}},
+		{"missing titles", "", http.StatusBadRequest, "invalid_parameter", nil},
+		{"movies and TV shows", "?movies=550,807&tv=1399,1396", http.StatusOK, "", []int{550, 807, 1399, 1396}},
+		{"one movie and one TV show", "?tv=1399&movies=550", http.StatusOK, "", []int{550, 1399}},
+		{"one title", "?movies=550", http.StatusBadRequest, "invalid_parameter", nil},
+		{"too many titles in total", "?movies=550,807,1,2&tv=1399,1396", http.StatusBadRequest, "invalid_parameter", nil},
+		{"too many titles", "?movies=1,2,3,4,5,6", http.StatusBadRequest, "invalid_parameter", nil},
+		{"repeated title", "?movies=550,550", http.StatusBadRequest, "invalid_parameter", nil},
+		{"invalid ID", "?tv=1399,abc", http.StatusBadRequest, "invalid_parameter", nil},
+		{"movie not found", "?movies=550,999", http.StatusNotFound, "movie_not_found", nil},
+		{"TV show not found", "?tv=999,1396", http.StatusNotFound, "tv_not_found", nil},
+		{"TMDb unavailable", "?movies=550,500", http.StatusInternalServerError, "api_error", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewCompareHandler(&fakeCompareClient{})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/compare"+tt.query, nil)
+			w := httptest.NewRecorder()
+			handler.Compare(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedError != "" {
+				var errorResp ErrorResponse
+				if err := json.NewDecoder(w.Body).Decode(&errorResp); err != nil {
+					t.Fatalf("failed to decode error response: %v", err)
+				}
+				if errorResp.Error != tt.expectedError {
+					t.Errorf("expected error %q, got %q", tt.expectedError, errorResp.Error)
+				}
+				return
+			}
+
+			var comparison models.Comparison
+			if err := json.NewDecoder(w.Body).Decode(&comparison); err != nil {
+				t.Fatalf("failed to decode comparison response: %v", err)
+			}
+			if len(comparison.Titles) != len(tt.expectedTitles) {
+				t.Fatalf("expected titles %v, got %+v", tt.expectedTitles, comparison.Titles)
+			}
+			for i, title := range comparison.Titles {
+				if title.ID != tt.expectedTitles[i] {
+					t.Errorf("expected titles %v, got %+v", tt.expectedTitles, comparison.Titles)
+				}
+			}
+			if len(comparison.Fields.Runtime.Values) != len(tt.expectedTitles) {
+				t.Errorf("expected a runtime for each title, got %+v", comparison.Fields.Runtime)
+			}
+		})
+	}
+}
+
+func TestCompareHandler_Compare_Movies(t *testing.T) {
+	handler := NewCompareHandler(&fakeCompareClient{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/compare?movies=550,807", nil)
+	w := httptest.NewRecorder()
+	handler.Compare(w, req)
+
+	var comparison models.Comparison
+	if err := json.NewDecoder(w.Body).Decode(&comparison); err != nil {
+		t.Fatalf("failed to decode comparison response: %v", err)
+	}
+	if comparison.Titles[0].MediaType != "movie" {
+		t.Errorf("expected movies, got %+v", comparison.Titles)
+	}
+	budget := comparison.Fields.Budget
+	if !budget.Differs || len(budget.Highest) != 1 || budget.Highest[0] != 1 {
+		t.Errorf("expected the second movie's budget highest, got %+v", budget)
+	}
+	if roi := comparison.Fields.ROI.Values; roi[0] == nil || *roi[0] != 0.82 {
+		t.Errorf("expected an ROI of 0.82 for the first movie, got %+v", comparison.Fields.ROI)
+	}
+	if len(comparison.SharedCast) != 1 || comparison.SharedCast[0].Count != 2 {
+		t.Errorf("expected Brad Pitt in both movies, got %+v", comparison.SharedCast)
+	}
+}
@@ -0,0 +1,135 @@
+// Package handlers provides HTTP handlers for collaborators and connections between people.
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/takeshi-arihori/movie-api/internal/connections"
+	"github.com/takeshi-arihori/movie-api/internal/models"
+	"github.com/takeshi-arihori/movie-api/internal/pagination"
+)
+
+// ConnectionsService finds the collaborators of a person and chains of movies
+// between two actors
+type ConnectionsService interface {
+	Collaborators(ctx context.Context, personID, limit int) (*models.Collaborators, error)
+	Connect(ctx context.Context, req models.ConnectionRequest) (*models.PersonConnection, error)
+}
+
+// ConnectionsHandler handles collaborator and connection HTTP requests
+type ConnectionsHandler struct {
+	connections ConnectionsService
+}
+
+// NewConnectionsHandler creates a new ConnectionsHandler instance
+func NewConnectionsHandler(connections ConnectionsService) *ConnectionsHandler {
+	return &ConnectionsHandler{
+		connections: connections,
+	}
+}
+
+// GetCollaborators handles GET /api/v1/people/{id}/collaborators requests
+func (h *ConnectionsHandler) GetCollaborators(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+
+	personID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || personID <= 0 {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", "Person ID must be a positive integer")
+		return
+	}
+	limit, err := parseLimit(r.URL.Query().Get("limit"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", err.Error())
+		return
+	}
+	if limit == 0 {
+		limit = pagination.DefaultLimit
+	}
+
+	collaborators, err := h.connections.Collaborators(r.Context(), personID, limit)
+	if err != nil {
+		h.writeError(w, err, "Failed to retrieve collaborators")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, collaborators)
+}
+
+// Connect handles GET /api/v1/people/connect requests
+func (h *ConnectionsHandler) Connect(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+
+	req, err := parseConnectionRequest(r.URL.Query())
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", err.Error())
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", validationMessage(err))
+		return
+	}
+
+	connection, err := h.connections.Connect(r.Context(), req)
+	if err != nil {
+		h.writeError(w, err, "Failed to search for a connection")
+		return
+	}
+
+	log.Printf("Connection from person %d to person %d: found=%t degrees=%d explored=%d",
+		req.From, req.To, connection.Found, connection.Degrees, connection.PeopleExplored)
+	writeJSONResponse(w, http.StatusOK, connection)
+}
+
+// writeError writes the response for a failed collaborator or connection lookup
+func (h *ConnectionsHandler) writeError(w http.ResponseWriter, err error, message string) {
+	var personErr *connections.PersonError
+	if errors.As(err, &personErr) && isTMDbNotFound(err) {
+		writeErrorResponse(w, http.StatusNotFound, "person_not_found", fmt.Sprintf("Person with ID %d not found", personErr.PersonID))
+		return
+	}
+	log.Printf("%s: %v", message, err)
+	writeUpstreamError(w, err, message)
+}
+
+// parseConnectionRequest reads the query parameters of a connection search.
+// Missing people and out-of-range limits are caught by validating the request
+// afterwards.
+func parseConnectionRequest(query url.Values) (models.ConnectionRequest, error) {
+	req := models.ConnectionRequest{
+		MaxDepth: connections.DefaultMaxDepth,
+		FanOut:   connections.DefaultFanOut,
+	}
+
+	for _, param := range []struct {
+		name string
+		dst  *int
+	}{
+		{"from", &req.From},
+		{"to", &req.To},
+		{"max_depth", &req.MaxDepth},
+		{"fan_out", &req.FanOut},
+	} {
+		value := query.Get(param.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return req, fmt.Errorf("invalid %s parameter: must be an integer", param.name)
+		}
+		*param.dst = parsed
+	}
+
+	return req, nil
+}
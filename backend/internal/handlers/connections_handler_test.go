@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/takeshi-arihori/movie-api/internal/connections"
+	"github.com/takeshi-arihori/movie-api/internal/models"
+	"github.com/takeshi-arihori/movie-api/internal/services"
+)
+
+// mockConnectionsService knows person 287 and person 819; person 500 makes TMDb fail
+type mockConnectionsService struct {
+	lastLimit   int
+	lastRequest models.ConnectionRequest
+}
+
+func (m *mockConnectionsService) check(personID int) error {
+	switch personID {
+	case 287, 819:
+		return nil
+	case 500:
+		return &connections.PersonError{PersonID: personID, Err: errors.New("connection refused")}
+	default:
+		return &connections.PersonError{PersonID: personID, Err: &services.TMDbError{StatusCode: 404, StatusMessage: "Not found"}}
+	}
+}
+
+func (m *mockConnectionsService) Collaborators(ctx context.Context, personID, limit int) (*models.Collaborators, error) {
+	m.lastLimit = limit
+	if err := m.check(personID); err != nil {
+		return nil, err
+	}
+	return &models.Collaborators{PersonID: personID, Results: []models.Collaborator{{ID: 819, Name: "Edward Norton", SharedCount: 1}}, TitlesScanned: 1}, nil
+}
+
+func (m *mockConnectionsService) Connect(ctx context.Context, req models.ConnectionRequest) (*models.PersonConnection, error) {
+	m.lastRequest = req
+	for _, personID := range []int{req.From, req.To} {
+		if err := m.check(personID); err != nil {
+			return nil, err
+		}
+	}
+	return &models.PersonConnection{From: req.From, To: req.To, Found: true, Degrees: 1, Links: []models.ConnectionLink{
+		{From: models.ConnectionPerson{ID: req.From}, Movie: models.ConnectionMovie{ID: 550, Title: "Fight Club"}, To: models.ConnectionPerson{ID: req.To}},
+	}}, nil
+}
+
+func TestConnectionsHandler_GetCollaborators(t *testing.T) {
+	tests := []struct {
+		name           string
+		id             string
+		query          string
+		expectedStatus int
+		expectedError  string
+		expectedLimit  int
+	}{
+		{"default limit", "287", "", http.StatusOK, "", 20},
+		{"limit", "287", "?limit=5", http.StatusOK, "", 5},
+		{"invalid limit", "287", "?limit=1000", http.StatusBadRequest, "invalid_parameter", 0},
+		{"invalid ID", "0", "", http.StatusBadRequest, "invalid_parameter", 0},
+		{"person not found", "999", "", http.StatusNotFound, "person_not_found", 20},
+		{"TMDb unavailable", "500", "", http.StatusInternalServerError, "api_error", 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &mockConnectionsService{}
+			handler := NewConnectionsHandler(service)
+
+			req := httptest.NewRequest("GET", "/api/v1/people/"+tt.id+"/collaborators"+tt.query, nil)
+			req = mux.SetURLVars(req, map[string]string{"id": tt.id})
+			w := httptest.NewRecorder()
+			handler.GetCollaborators(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if service.lastLimit != tt.expectedLimit {
+				t.Errorf("Expected limit %d, got %d", tt.expectedLimit, service.lastLimit)
+			}
+			if tt.expectedError != "" {
+				var errorResp ErrorResponse
+				if err := json.NewDecoder(w.Body).Decode(&errorResp); err != nil {
+					t.Fatalf("Failed to decode error response: %v", err)
+				}
+				if errorResp.Error != tt.expectedError {
+					t.Errorf("Expected error %q, got %q", tt.expectedError, errorResp.Error)
+				}
+				return
+			}
+
+			var collaborators models.Collaborators
+			if err := json.NewDecoder(w.Body).Decode(&collaborators); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if collaborators.PersonID != 287 || len(collaborators.Results) != 1 {
+				t.Errorf("Unexpected collaborators: %+v", collaborators)
+			}
+		})
+	}
+}
+
+func TestConnectionsHandler_Connect(t *testing.T) {
+	tests := []struct {
+		name            string
+		query           string
+		expectedStatus  int
+		expectedError   string
+		expectedRequest models.ConnectionRequest
+	}{
+		{
+			name:            "default limits",
+			query:           "?from=287&to=819",
+			expectedStatus:  http.StatusOK,
+			expectedRequest: models.ConnectionRequest{From: 287, To: 819, MaxDepth: connections.DefaultMaxDepth, FanOut: connections.DefaultFanOut},
+		},
+		{
+			name:            "limits",
+			query:           "?from=287&to=819&max_depth=2&fan_out=5",
+			expectedStatus:  http.StatusOK,
+			expectedRequest: models.ConnectionRequest{From: 287, To: 819, MaxDepth: 2, FanOut: 5},
+		},
+		{
+			name:           "missing person",
+			query:          "?from=287",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid_parameter",
+		},
+		{
+			name:           "invalid person",
+			query:          "?from=brad&to=819",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid_parameter",
+		},
+		{
+			name:           "depth too large",
+			query:          "?from=287&to=819&max_depth=7",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid_parameter",
+		},
+		{
+			name:           "fan-out too small",
+			query:          "?from=287&to=819&fan_out=0",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid_parameter",
+		},
+		{
+			name:           "person not found",
+			query:          "?from=287&to=999",
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "person_not_found",
+		},
+		{
+			name:           "TMDb unavailable",
+			query:          "?from=500&to=819",
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "api_error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &mockConnectionsService{}
+			handler := NewConnectionsHandler(service)
+
+			req := httptest.NewRequest("GET", "/api/v1/people/connect"+tt.query, nil)
+			w := httptest.NewRecorder()
+			handler.Connect(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedError != "" {
+				var errorResp ErrorResponse
+				if err := json.NewDecoder(w.Body).Decode(&errorResp); err != nil {
+					t.Fatalf("Failed to decode error response: %v", err)
+				}
+				if errorResp.Error != tt.expectedError {
+					t.Errorf("Expected error %q, got %q", tt.expectedError, errorResp.Error)
+				}
+				return
+			}
+
+			if service.lastRequest != tt.expectedRequest {
+				t.Errorf("Expected request %+v, got %+v", tt.expectedRequest, service.lastRequest)
+			}
+			var connection models.PersonConnection
+			if err := json.NewDecoder(w.Body).Decode(&connection); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if !connection.Found || len(connection.Links) != 1 {
+				t.Errorf("Unexpected connection: %+v", connection)
+			}
+		})
+	}
+}
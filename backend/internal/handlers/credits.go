@@ -0,0 +1,73 @@
+// Package handlers provides helpers for filtering the credits of movies and TV shows.
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/takeshi-arihori/movie-api/internal/credits"
+	"github.com/takeshi-arihori/movie-api/internal/models"
+)
+
+// parseCreditsOptions reads the department, job, limit, dedupe_crew and view query
+// parameters of a credits request. On failure an error response has already been
+// written and ok is false.
+func parseCreditsOptions(w http.ResponseWriter, r *http.Request) (opts models.CreditsOptions, ok bool) {
+	query := r.URL.Query()
+	opts = models.CreditsOptions{
+		Department: strings.TrimSpace(query.Get("department")),
+		Job:        strings.TrimSpace(query.Get("job")),
+		View:       query.Get("view"),
+	}
+
+	limit, err := parseLimit(query.Get("limit"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", err.Error())
+		return opts, false
+	}
+	opts.Limit = limit
+	if value := query.Get("dedupe_crew"); value != "" {
+		opts.MergeCrew, err = strconv.ParseBool(value)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", "invalid dedupe_crew parameter: must be true or false")
+			return opts, false
+		}
+	}
+	if err := validate.Struct(opts); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", validationMessage(err))
+		return opts, false
+	}
+
+	return opts, true
+}
+
+// writeCredits writes a title's credits with opts applied: the key crew for the
+// summary view, which ignores the filters, or else the matching cast and crew.
+// order returns the billing order of a cast member.
+func writeCredits[C any](w http.ResponseWriter, id int, cast []C, order func(C) int, crew []models.CrewMember, opts models.CreditsOptions) {
+	if opts.View == models.CreditsViewSummary {
+		writeJSONResponse(w, http.StatusOK, credits.Summarize(id, crew))
+		return
+	}
+
+	matchedCast := credits.Cast(cast, order, opts)
+	matchedCrew := credits.Crew(crew, opts)
+	if opts.MergeCrew {
+		writeJSONResponse(w, http.StatusOK, models.FilteredCredits[C, models.MergedCrewMember]{
+			ID:        id,
+			Cast:      matchedCast,
+			Crew:      credits.MergeCrew(matchedCrew),
+			TotalCast: len(cast),
+			TotalCrew: len(crew),
+		})
+		return
+	}
+	writeJSONResponse(w, http.StatusOK, models.FilteredCredits[C, models.CrewMember]{
+		ID:        id,
+		Cast:      matchedCast,
+		Crew:      matchedCrew,
+		TotalCast: len(cast),
+		TotalCrew: len(crew),
+	})
+}
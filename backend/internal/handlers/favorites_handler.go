@@ -0,0 +1,204 @@
+// Package handlers provides HTTP handlers for the signed-in user's favorites.
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/takeshi-arihori/movie-api/internal/models"
+	"github.com/takeshi-arihori/movie-api/internal/pagination"
+	"github.com/takeshi-arihori/movie-api/internal/store"
+)
+
+// FavoriteStore reads and writes users' favorites
+type FavoriteStore interface {
+	Add(ctx context.Context, favorite *models.Favorite) error
+	Get(ctx context.Context, userID string, mediaType models.SearchItemType, mediaID int) (*models.Favorite, error)
+	Remove(ctx context.Context, userID string, mediaType models.SearchItemType, mediaID int) error
+	List(ctx context.Context, userID string) ([]models.Favorite, error)
+	ListByRating(ctx context.Context, userID string) ([]models.Favorite, error)
+}
+
+// FavoritesHandler handles the signed-in user's favorites. Its routes must be
+// wrapped in AuthHandler.RequireAuth.
+type FavoritesHandler struct {
+	favorites FavoriteStore
+	titles    TitleDetailsClient
+}
+
+// NewFavoritesHandler creates a new FavoritesHandler instance
+func NewFavoritesHandler(favorites FavoriteStore, titles TitleDetailsClient) *FavoritesHandler {
+	return &FavoritesHandler{
+		favorites: favorites,
+		titles:    titles,
+	}
+}
+
+// ListFavorites handles GET /api/v1/me/favorites requests
+func (h *FavoritesHandler) ListFavorites(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	mediaType := models.SearchItemType(query.Get("media_type"))
+	if mediaType != "" && mediaType != models.SearchItemTypeMovie && mediaType != models.SearchItemTypeTV {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", "Invalid media_type parameter: must be 'movie' or 'tv'")
+		return
+	}
+	order := models.FavoriteSort(query.Get("sort"))
+	if order == "" {
+		order = models.FavoriteSortDateAdded
+	}
+	if order != models.FavoriteSortDateAdded && order != models.FavoriteSortRating {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", "Invalid sort parameter: must be 'date_added' or 'rating'")
+		return
+	}
+
+	cursor, limit, ok := parseCursorRequest(w, r, "favorites", user.ID, string(mediaType), string(order))
+	if !ok {
+		return
+	}
+
+	// Ratings come from the titles cache, so only the requested page is looked up
+	// on TMDb; titles that have never been cached sort last
+	list := h.favorites.List
+	if order == models.FavoriteSortRating {
+		list = h.favorites.ListByRating
+	}
+	all, err := list(r.Context(), user.ID)
+	if err != nil {
+		log.Printf("Failed to list favorites for user %s: %v", user.ID, err)
+		writeErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to retrieve favorites")
+		return
+	}
+	favorites := all[:0]
+	for _, favorite := range all {
+		if mediaType == "" || favorite.MediaType == mediaType {
+			favorites = append(favorites, favorite)
+		}
+	}
+
+	start := min(cursor.Offset, len(favorites))
+	end := min(start+limit, len(favorites))
+	items := h.hydrate(r.Context(), favorites[start:end])
+
+	page := models.CursorPage[models.FavoriteItem]{
+		Results:      items,
+		TotalResults: len(favorites),
+		Limit:        limit,
+		Sort:         string(order),
+	}
+	if end < len(favorites) {
+		page.NextCursor = pagination.Cursor{Offset: end, Scope: cursor.Scope}.Encode()
+	}
+	writeJSONResponse(w, http.StatusOK, page)
+}
+
+// AddFavorite handles POST /api/v1/me/favorites requests. Adding a title that is
+// already a favorite returns the existing favorite with 200 OK instead of 201.
+func (h *FavoritesHandler) AddFavorite(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.FavoriteRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	summary, ok := checkTitle(w, r, h.titles, req.MediaType, req.MediaID)
+	if !ok {
+		return
+	}
+
+	status := http.StatusCreated
+	favorite := &models.Favorite{UserID: user.ID, MediaType: req.MediaType, MediaID: req.MediaID}
+	err := h.favorites.Add(r.Context(), favorite)
+	if errors.Is(err, store.ErrConflict) {
+		status = http.StatusOK
+		favorite, err = h.favorites.Get(r.Context(), user.ID, req.MediaType, req.MediaID)
+	}
+	if err != nil {
+		log.Printf("Failed to add favorite %s %d for user %s: %v", req.MediaType, req.MediaID, user.ID, err)
+		writeErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to add favorite")
+		return
+	}
+
+	writeJSONResponse(w, status, favoriteItem(*favorite, summary))
+}
+
+// RemoveFavorite handles DELETE /api/v1/me/favorites/{media_type}/{id} requests
+func (h *FavoritesHandler) RemoveFavorite(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodDelete) {
+		return
+	}
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	mediaType := models.SearchItemType(vars["media_type"])
+	if mediaType != models.SearchItemTypeMovie && mediaType != models.SearchItemTypeTV {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", "Media type must be 'movie' or 'tv'")
+		return
+	}
+	mediaID, err := strconv.Atoi(vars["id"])
+	if err != nil || mediaID <= 0 {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", "ID must be a positive integer")
+		return
+	}
+
+	err = h.favorites.Remove(r.Context(), user.ID, mediaType, mediaID)
+	if errors.Is(err, store.ErrNotFound) {
+		writeErrorResponse(w, http.StatusNotFound, "favorite_not_found", fmt.Sprintf("%s %d is not a favorite", mediaTypeName(mediaType), mediaID))
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to remove favorite %s %d for user %s: %v", mediaType, mediaID, user.ID, err)
+		writeErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to remove favorite")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// hydrate looks up the details of each favorite's title
+func (h *FavoritesHandler) hydrate(ctx context.Context, favorites []models.Favorite) []models.FavoriteItem {
+	refs := make([]titleRef, len(favorites))
+	for i, favorite := range favorites {
+		refs[i] = titleRef{mediaType: favorite.MediaType, mediaID: favorite.MediaID}
+	}
+	summaries := lookupTitles(ctx, h.titles, refs)
+
+	items := make([]models.FavoriteItem, len(favorites))
+	for i, favorite := range favorites {
+		items[i] = favoriteItem(favorite, summaries[i])
+	}
+	return items
+}
+
+// favoriteItem combines a favorite with its title's display fields
+func favoriteItem(favorite models.Favorite, summary models.TitleSummary) models.FavoriteItem {
+	return models.FavoriteItem{
+		ID:           favorite.ID,
+		MediaType:    favorite.MediaType,
+		MediaID:      favorite.MediaID,
+		TitleSummary: summary,
+		AddedAt:      favorite.CreatedAt,
+	}
+}
@@ -0,0 +1,299 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/takeshi-arihori/movie-api/internal/auth"
+	"github.com/takeshi-arihori/movie-api/internal/models"
+	"github.com/takeshi-arihori/movie-api/internal/services"
+	"github.com/takeshi-arihori/movie-api/internal/store"
+)
+
+// fakeFavoriteStore is an in-memory FavoriteStore. ratings stands in for the
+// titles cache, by media ID.
+type fakeFavoriteStore struct {
+	mu        sync.Mutex
+	favorites []models.Favorite
+	ratings   map[int]float64
+}
+
+func (s *fakeFavoriteStore) Add(ctx context.Context, favorite *models.Favorite) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, f := range s.favorites {
+		if f.UserID == favorite.UserID && f.MediaType == favorite.MediaType && f.MediaID == favorite.MediaID {
+			return store.ErrConflict
+		}
+	}
+	favorite.ID = fmt.Sprintf("favorite-%d", len(s.favorites)+1)
+	favorite.CreatedAt = time.Date(2024, 1, 1, 0, 0, len(s.favorites), 0, time.UTC)
+	// Newest first, as the store lists them
+	s.favorites = append([]models.Favorite{*favorite}, s.favorites...)
+	return nil
+}
+
+func (s *fakeFavoriteStore) Get(ctx context.Context, userID string, mediaType models.SearchItemType, mediaID int) (*models.Favorite, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, f := range s.favorites {
+		if f.UserID == userID && f.MediaType == mediaType && f.MediaID == mediaID {
+			return &f, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+func (s *fakeFavoriteStore) Remove(ctx context.Context, userID string, mediaType models.SearchItemType, mediaID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, f := range s.favorites {
+		if f.UserID == userID && f.MediaType == mediaType && f.MediaID == mediaID {
+			s.favorites = append(s.favorites[:i], s.favorites[i+1:]...)
+			return nil
+		}
+	}
+	return store.ErrNotFound
+}
+
+func (s *fakeFavoriteStore) List(ctx context.Context, userID string) ([]models.Favorite, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var favorites []models.Favorite
+	for _, f := range s.favorites {
+		if f.UserID == userID {
+			favorites = append(favorites, f)
+		}
+	}
+	return favorites, nil
+}
+
+func (s *fakeFavoriteStore) ListByRating(ctx context.Context, userID string) ([]models.Favorite, error) {
+	favorites, _ := s.List(ctx, userID)
+	sort.SliceStable(favorites, func(i, j int) bool {
+		a, aCached := s.ratings[favorites[i].MediaID]
+		b, bCached := s.ratings[favorites[j].MediaID]
+		if aCached != bCached {
+			return aCached
+		}
+		return a > b
+	})
+	return favorites, nil
+}
+
+// MockTitleDetailsClient is a mock implementation of TitleDetailsClient
+type MockTitleDetailsClient struct {
+	movies  map[int]models.MovieDetails
+	tvShows map[int]models.TVShowDetails
+	lookups atomic.Int32
+}
+
+func (m *MockTitleDetailsClient) GetMovieDetails(ctx context.Context, movieID int) (*models.MovieDetails, error) {
+	m.lookups.Add(1)
+	details, ok := m.movies[movieID]
+	if !ok {
+		return nil, &services.TMDbError{StatusCode: 404, StatusMessage: "The resource you requested could not be found."}
+	}
+	return &details, nil
+}
+
+func (m *MockTitleDetailsClient) GetTVShowDetails(ctx context.Context, tvID int) (*models.TVShowDetails, error) {
+	m.lookups.Add(1)
+	if tvID == 500 {
+		return nil, fmt.Errorf("connection refused")
+	}
+	details, ok := m.tvShows[tvID]
+	if !ok {
+		return nil, &services.TMDbError{StatusCode: 404, StatusMessage: "The resource you requested could not be found."}
+	}
+	return &details, nil
+}
+
+// newMockTitleDetailsClient knows Fight Club, The Matrix and Game of Thrones, and
+// fails as if TMDb were down for TV show 500
+func newMockTitleDetailsClient() *MockTitleDetailsClient {
+	return &MockTitleDetailsClient{
+		movies: map[int]models.MovieDetails{
+			550: {ID: 550, Title: "Fight Club", PosterPath: stringPtr("/fight.jpg"), VoteAverage: 8.4, VoteCount: 26000},
+			603: {ID: 603, Title: "The Matrix", PosterPath: stringPtr("/matrix.jpg"), VoteAverage: 8.2, VoteCount: 24000},
+		},
+		tvShows: map[int]models.TVShowDetails{
+			1399: {ID: 1399, Name: "Game of Thrones", VoteAverage: 8.5, VoteCount: 21000},
+		},
+	}
+}
+
+func newTestFavoritesHandler() (*FavoritesHandler, *fakeFavoriteStore) {
+	favorites := &fakeFavoriteStore{}
+	return NewFavoritesHandler(favorites, newMockTitleDetailsClient()), favorites
+}
+
+// withUser returns req with user set as the authenticated user
+func withUser(req *http.Request, userID string) *http.Request {
+	return req.WithContext(auth.WithUser(req.Context(), &models.User{ID: userID, Username: "neo", IsActive: true}))
+}
+
+func TestFavoritesHandler_AddFavorite(t *testing.T) {
+	handler, favorites := newTestFavoritesHandler()
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedTitle  string
+	}{
+		{"new movie", `{"media_type":"movie","media_id":550}`, http.StatusCreated, "Fight Club"},
+		{"duplicate is idempotent", `{"media_type":"movie","media_id":550}`, http.StatusOK, "Fight Club"},
+		{"TV show", `{"media_type":"tv","media_id":1399}`, http.StatusCreated, "Game of Thrones"},
+		{"TMDb unavailable", `{"media_type":"tv","media_id":500}`, http.StatusCreated, ""},
+		{"unknown title", `{"media_type":"movie","media_id":999999}`, http.StatusNotFound, ""},
+		{"invalid media type", `{"media_type":"person","media_id":287}`, http.StatusBadRequest, ""},
+		{"missing media id", `{"media_type":"movie"}`, http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := withUser(httptest.NewRequest("POST", "/api/v1/me/favorites", strings.NewReader(tt.body)), "user-1")
+			w := httptest.NewRecorder()
+
+			handler.AddFavorite(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedTitle != "" {
+				var item models.FavoriteItem
+				if err := json.NewDecoder(w.Body).Decode(&item); err != nil {
+					t.Fatalf("Failed to decode response: %v", err)
+				}
+				if item.Title != tt.expectedTitle || item.ID == "" {
+					t.Errorf("Expected favorite %q with an ID, got %+v", tt.expectedTitle, item)
+				}
+			}
+		})
+	}
+
+	if len(favorites.favorites) != 3 {
+		t.Errorf("Expected 3 stored favorites, got %d", len(favorites.favorites))
+	}
+}
+
+func TestFavoritesHandler_ListFavorites(t *testing.T) {
+	handler, favorites := newTestFavoritesHandler()
+	for _, f := range []models.Favorite{
+		{UserID: "user-1", MediaType: models.SearchItemTypeMovie, MediaID: 603},
+		{UserID: "user-1", MediaType: models.SearchItemTypeTV, MediaID: 1399},
+		{UserID: "user-1", MediaType: models.SearchItemTypeMovie, MediaID: 550},
+		{UserID: "user-2", MediaType: models.SearchItemTypeMovie, MediaID: 550},
+	} {
+		favorites.Add(context.Background(), &f)
+	}
+
+	list := func(t *testing.T, query string) models.CursorPage[models.FavoriteItem] {
+		t.Helper()
+		req := withUser(httptest.NewRequest("GET", "/api/v1/me/favorites"+query, nil), "user-1")
+		w := httptest.NewRecorder()
+		handler.ListFavorites(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var page models.CursorPage[models.FavoriteItem]
+		if err := json.NewDecoder(w.Body).Decode(&page); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return page
+	}
+	ids := func(page models.CursorPage[models.FavoriteItem]) []int {
+		var ids []int
+		for _, item := range page.Results {
+			ids = append(ids, item.MediaID)
+		}
+		return ids
+	}
+
+	t.Run("date added", func(t *testing.T) {
+		page := list(t, "")
+		if got := fmt.Sprint(ids(page)); got != "[550 1399 603]" || page.TotalResults != 3 {
+			t.Errorf("Expected newest first [550 1399 603] of 3, got %s of %d", got, page.TotalResults)
+		}
+		if page.Results[0].Title != "Fight Club" || page.Results[0].PosterPath == nil {
+			t.Errorf("Expected hydrated favorite, got %+v", page.Results[0])
+		}
+	})
+
+	t.Run("rating with cursor", func(t *testing.T) {
+		favorites.ratings = map[int]float64{1399: 8.5, 550: 8.4}
+		titles := handler.titles.(*MockTitleDetailsClient)
+		titles.lookups.Store(0)
+		first := list(t, "?sort=rating&limit=2")
+		if got := fmt.Sprint(ids(first)); got != "[1399 550]" || first.NextCursor == "" {
+			t.Fatalf("Expected [1399 550] and a next cursor, got %s %q", got, first.NextCursor)
+		}
+		if n := titles.lookups.Load(); n != 2 {
+			t.Errorf("Expected only the page to be looked up, got %d lookups", n)
+		}
+		second := list(t, "?sort=rating&limit=2&cursor="+first.NextCursor)
+		if got := fmt.Sprint(ids(second)); got != "[603]" || second.NextCursor != "" {
+			t.Errorf("Expected [603] and no next cursor, got %s %q", got, second.NextCursor)
+		}
+	})
+
+	t.Run("media type", func(t *testing.T) {
+		page := list(t, "?media_type=tv")
+		if got := fmt.Sprint(ids(page)); got != "[1399]" {
+			t.Errorf("Expected [1399], got %s", got)
+		}
+	})
+
+	for _, query := range []string{"?sort=title", "?media_type=person", "?limit=0", "?sort=rating&cursor=bogus"} {
+		t.Run("invalid "+query, func(t *testing.T) {
+			req := withUser(httptest.NewRequest("GET", "/api/v1/me/favorites"+query, nil), "user-1")
+			w := httptest.NewRecorder()
+			handler.ListFavorites(w, req)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400, got %d", w.Code)
+			}
+		})
+	}
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ListFavorites(w, httptest.NewRequest("GET", "/api/v1/me/favorites", nil))
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401, got %d", w.Code)
+		}
+	})
+}
+
+func TestFavoritesHandler_RemoveFavorite(t *testing.T) {
+	handler, favorites := newTestFavoritesHandler()
+	favorites.Add(context.Background(), &models.Favorite{UserID: "user-1", MediaType: models.SearchItemTypeMovie, MediaID: 550})
+
+	remove := func(userID string) int {
+		req := withUser(httptest.NewRequest("DELETE", "/api/v1/me/favorites/movie/550", nil), userID)
+		req = mux.SetURLVars(req, map[string]string{"media_type": "movie", "id": "550"})
+		w := httptest.NewRecorder()
+		handler.RemoveFavorite(w, req)
+		return w.Code
+	}
+
+	if code := remove("user-2"); code != http.StatusNotFound {
+		t.Errorf("Expected another user's remove to return 404, got %d", code)
+	}
+	if code := remove("user-1"); code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", code)
+	}
+	if code := remove("user-1"); code != http.StatusNotFound {
+		t.Errorf("Expected status 404 removing twice, got %d", code)
+	}
+}
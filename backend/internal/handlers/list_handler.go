@@ -0,0 +1,223 @@
+// Package handlers provides HTTP handlers for movie and TV list endpoints.
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+	"github.com/takeshi-arihori/movie-api/internal/pagination"
+	"github.com/takeshi-arihori/movie-api/internal/ranking"
+	"github.com/takeshi-arihori/movie-api/internal/store"
+)
+
+// ListClient defines the interface for list-related TMDb operations
+type ListClient interface {
+	GetPopularMovies(ctx context.Context, page int) (*models.PopularMovies, error)
+	GetTopRatedMovies(ctx context.Context, page int) (*models.TopRatedMovies, error)
+	GetTrendingMovies(ctx context.Context, timeWindow string, page int) (*models.SearchResponse[models.Movie], error)
+	GetTrendingTVShows(ctx context.Context, timeWindow string, page int) (*models.SearchResponse[models.TVShow], error)
+}
+
+// ListCatalogue serves lists from the local database when TMDb is unavailable
+type ListCatalogue interface {
+	ListMovies(ctx context.Context, order store.TitleOrder, page int) (*models.SearchResponse[models.Movie], error)
+	ListTVShows(ctx context.Context, order store.TitleOrder, page int) (*models.SearchResponse[models.TVShow], error)
+}
+
+// ListHandler handles movie and TV list HTTP requests
+type ListHandler struct {
+	tmdbClient ListClient
+	catalogue  ListCatalogue
+}
+
+// NewListHandler creates a new ListHandler instance
+func NewListHandler(tmdbClient ListClient) *ListHandler {
+	return &ListHandler{
+		tmdbClient: tmdbClient,
+	}
+}
+
+// SetCatalogue enables serving lists from the local catalogue when TMDb is
+// unavailable. It must be called before the handler starts serving requests.
+func (h *ListHandler) SetCatalogue(catalogue ListCatalogue) {
+	h.catalogue = catalogue
+}
+
+// GetPopular handles GET /api/v1/popular?limit=<limit>&cursor=<cursor>&sort=<sort> requests
+func (h *ListHandler) GetPopular(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+
+	cursor, limit, ok := parseCursorRequest(w, r, "popular")
+	if !ok {
+		return
+	}
+	mode, ok := parseListSort(w, r)
+	if !ok {
+		return
+	}
+
+	log.Printf("Fetching popular movies: offset %d, limit %d", cursor.Offset, limit)
+	serveList(w, r, "popular movies", cursor, limit, mode, h.tmdbClient.GetPopularMovies,
+		h.staleMovies(store.OrderPopularity), movieKey, ranking.MovieFields)
+}
+
+// GetTopRated handles GET /api/v1/top-rated?limit=<limit>&cursor=<cursor>&sort=<sort> requests
+func (h *ListHandler) GetTopRated(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+
+	cursor, limit, ok := parseCursorRequest(w, r, "top-rated")
+	if !ok {
+		return
+	}
+	mode, ok := parseListSort(w, r)
+	if !ok {
+		return
+	}
+
+	log.Printf("Fetching top rated movies: offset %d, limit %d", cursor.Offset, limit)
+	serveList(w, r, "top rated movies", cursor, limit, mode, h.tmdbClient.GetTopRatedMovies,
+		h.staleMovies(store.OrderRating), movieKey, ranking.MovieFields)
+}
+
+// GetTrending handles GET /api/v1/trending?media_type=<movie|tv>&time_window=<day|week> requests
+func (h *ListHandler) GetTrending(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+
+	query := r.URL.Query()
+	mediaType := strings.ToLower(strings.TrimSpace(query.Get("media_type")))
+	if mediaType == "" {
+		mediaType = "movie"
+	}
+	timeWindow := strings.ToLower(strings.TrimSpace(query.Get("time_window")))
+	if timeWindow == "" {
+		timeWindow = "week"
+	}
+	if timeWindow != "day" && timeWindow != "week" {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", "time_window must be one of: day, week")
+		return
+	}
+
+	cursor, limit, ok := parseCursorRequest(w, r, "trending", mediaType, timeWindow)
+	if !ok {
+		return
+	}
+	mode, ok := parseListSort(w, r)
+	if !ok {
+		return
+	}
+
+	log.Printf("Fetching trending %s (%s): offset %d, limit %d", mediaType, timeWindow, cursor.Offset, limit)
+
+	switch mediaType {
+	case "movie":
+		fetch := func(ctx context.Context, page int) (*models.SearchResponse[models.Movie], error) {
+			return h.tmdbClient.GetTrendingMovies(ctx, timeWindow, page)
+		}
+		serveList(w, r, "trending movies", cursor, limit, mode, fetch, h.staleMovies(store.OrderPopularity), movieKey, ranking.MovieFields)
+	case "tv":
+		fetch := func(ctx context.Context, page int) (*models.SearchResponse[models.TVShow], error) {
+			return h.tmdbClient.GetTrendingTVShows(ctx, timeWindow, page)
+		}
+		serveList(w, r, "trending TV shows", cursor, limit, mode, fetch, h.staleTVShows(store.OrderPopularity), tvShowKey, ranking.TVShowFields)
+	default:
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", "media_type must be one of: movie, tv")
+	}
+}
+
+// parseListSort parses the sort parameter for list endpoints. Lists have no query,
+// so relevance ranking is not available.
+func parseListSort(w http.ResponseWriter, r *http.Request) (ranking.Mode, bool) {
+	mode, err := ranking.ParseMode(r.URL.Query().Get("sort"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", err.Error())
+		return "", false
+	}
+	if mode == ranking.Relevance {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", "relevance sort requires a search query")
+		return "", false
+	}
+	return mode, true
+}
+
+// staleMovies returns the catalogue fallback for a movie list, or nil without a catalogue
+func (h *ListHandler) staleMovies(order store.TitleOrder) func(ctx context.Context, page int) (*models.SearchResponse[models.Movie], error) {
+	if h.catalogue == nil {
+		return nil
+	}
+	return func(ctx context.Context, page int) (*models.SearchResponse[models.Movie], error) {
+		return h.catalogue.ListMovies(ctx, order, page)
+	}
+}
+
+// staleTVShows returns the catalogue fallback for a TV show list, or nil without a catalogue
+func (h *ListHandler) staleTVShows(order store.TitleOrder) func(ctx context.Context, page int) (*models.SearchResponse[models.TVShow], error) {
+	if h.catalogue == nil {
+		return nil
+	}
+	return func(ctx context.Context, page int) (*models.SearchResponse[models.TVShow], error) {
+		return h.catalogue.ListTVShows(ctx, order, page)
+	}
+}
+
+// serveList fetches a cursor window from a paged TMDb list, re-ranks it and writes it as a CursorPage.
+// Pages TMDb fails to serve are taken from fallback, if given, and the response is marked stale.
+func serveList[T any](w http.ResponseWriter, r *http.Request, name string, cursor pagination.Cursor, limit int, mode ranking.Mode,
+	fetch, fallback func(ctx context.Context, page int) (*models.SearchResponse[T], error), key func(T) string, fields func(T) ranking.Fields) {
+	var stale atomic.Bool
+	fetchPage := func(ctx context.Context, page int) (*pagination.Page[T], error) {
+		result, err := fetch(ctx, page)
+		if err != nil && fallback != nil && canServeStale(err) {
+			log.Printf("Failed to get %s page %d, serving the local catalogue: %v", name, page, err)
+			stale.Store(true)
+			result, err = fallback(ctx, page)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &pagination.Page[T]{
+			Results:      result.Results,
+			TotalPages:   result.TotalPages,
+			TotalResults: result.TotalResults,
+		}, nil
+	}
+
+	window, err := pagination.Fetch(r.Context(), cursor, limit, fetchPage, key)
+	if err != nil {
+		log.Printf("Failed to get %s: %v", name, err)
+		writeUpstreamError(w, err, fmt.Sprintf("Failed to retrieve %s", name))
+		return
+	}
+
+	ranking.Sort(window.Results, mode, "", fields)
+
+	log.Printf("Successfully retrieved %s: %d results (sort=%s)", name, len(window.Results), mode)
+
+	page := newCursorPage(window, limit)
+	page.Sort = string(mode)
+	if stale.Load() {
+		page.Stale = true
+		markStale(w)
+	}
+	writeJSONResponse(w, http.StatusOK, page)
+}
+
+// movieKey identifies a movie across pages for de-duplication
+func movieKey(movie models.Movie) string {
+	return fmt.Sprintf("movie:%d", movie.ID)
+}
+
+// tvShowKey identifies a TV show across pages for de-duplication
+func tvShowKey(tvShow models.TVShow) string {
+	return fmt.Sprintf("tv:%d", tvShow.ID)
+}
@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+	"github.com/takeshi-arihori/movie-api/internal/services"
+	"github.com/takeshi-arihori/movie-api/internal/store"
+)
+
+// MockListClient is a mock implementation of ListClient serving numbered pages
+type MockListClient struct {
+	total      int
+	timeWindow string
+	err        error
+}
+
+func (m *MockListClient) moviePage(page int) (*models.SearchResponse[models.Movie], error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	var results []models.Movie
+	for id := (page-1)*20 + 1; id <= page*20 && id <= m.total; id++ {
+		results = append(results, models.Movie{ID: id, Title: fmt.Sprintf("Movie %d", id)})
+	}
+	return &models.SearchResponse[models.Movie]{
+		Page:         page,
+		Results:      results,
+		TotalPages:   (m.total + 19) / 20,
+		TotalResults: m.total,
+	}, nil
+}
+
+func (m *MockListClient) GetPopularMovies(ctx context.Context, page int) (*models.PopularMovies, error) {
+	return m.moviePage(page)
+}
+
+func (m *MockListClient) GetTopRatedMovies(ctx context.Context, page int) (*models.TopRatedMovies, error) {
+	return m.moviePage(page)
+}
+
+func (m *MockListClient) GetTrendingMovies(ctx context.Context, timeWindow string, page int) (*models.SearchResponse[models.Movie], error) {
+	m.timeWindow = timeWindow
+	return m.moviePage(page)
+}
+
+func (m *MockListClient) GetTrendingTVShows(ctx context.Context, timeWindow string, page int) (*models.SearchResponse[models.TVShow], error) {
+	m.timeWindow = timeWindow
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &models.SearchResponse[models.TVShow]{
+		Page:         1,
+		Results:      []models.TVShow{{ID: 1396, Name: "Breaking Bad"}},
+		TotalPages:   1,
+		TotalResults: 1,
+	}, nil
+}
+
+func TestListHandler_GetPopular(t *testing.T) {
+	handler := NewListHandler(&MockListClient{total: 70})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/popular?limit=50", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetPopular(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var page models.CursorPage[models.Movie]
+	if err := json.NewDecoder(w.Body).Decode(&page); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(page.Results) != 50 {
+		t.Fatalf("expected 50 results, got %d", len(page.Results))
+	}
+	if page.Limit != 50 || page.TotalResults != 70 {
+		t.Errorf("expected limit 50 and total 70, got %d and %d", page.Limit, page.TotalResults)
+	}
+	if page.NextCursor == "" {
+		t.Fatal("expected next_cursor")
+	}
+
+	// Follow the cursor
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/popular?limit=50&cursor="+page.NextCursor, nil)
+	w = httptest.NewRecorder()
+	handler.GetPopular(w, req)
+
+	var next models.CursorPage[models.Movie]
+	if err := json.NewDecoder(w.Body).Decode(&next); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(next.Results) != 20 || next.Results[0].ID != 51 {
+		t.Errorf("expected 20 results starting at ID 51, got %d", len(next.Results))
+	}
+	if next.NextCursor != "" {
+		t.Errorf("expected no next_cursor at end of list, got %q", next.NextCursor)
+	}
+
+	// A cursor from another list is rejected
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/top-rated?cursor="+page.NextCursor, nil)
+	w = httptest.NewRecorder()
+	handler.GetTopRated(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for foreign cursor, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestListHandler_GetTrending(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedWindow string
+	}{
+		{name: "default movie week", query: "", expectedStatus: http.StatusOK, expectedWindow: "week"},
+		{name: "tv day", query: "?media_type=tv&time_window=day", expectedStatus: http.StatusOK, expectedWindow: "day"},
+		{name: "invalid media type", query: "?media_type=person", expectedStatus: http.StatusBadRequest},
+		{name: "invalid time window", query: "?time_window=month", expectedStatus: http.StatusBadRequest},
+		{name: "invalid limit", query: "?limit=500", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &MockListClient{total: 5}
+			handler := NewListHandler(client)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/trending"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			handler.GetTrending(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedWindow != "" && client.timeWindow != tt.expectedWindow {
+				t.Errorf("expected time window %q, got %q", tt.expectedWindow, client.timeWindow)
+			}
+		})
+	}
+}
+
+func TestListHandler_Sort(t *testing.T) {
+	handler := NewListHandler(&MockListClient{total: 30})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/popular?limit=30&sort=release_date", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetPopular(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var page models.CursorPage[models.Movie]
+	if err := json.NewDecoder(w.Body).Decode(&page); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if page.Sort != "release_date" {
+		t.Errorf("expected sort 'release_date', got %q", page.Sort)
+	}
+
+	// Relevance needs a query and is rejected for lists
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/popular?sort=relevance", nil)
+	w = httptest.NewRecorder()
+	handler.GetPopular(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for relevance sort, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestListHandler_Error(t *testing.T) {
+	handler := NewListHandler(&MockListClient{err: fmt.Errorf("network error")})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/top-rated", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetTopRated(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+}
+
+// fakeListCatalogue is a ListCatalogue holding a single movie and TV show
+type fakeListCatalogue struct{}
+
+func (fakeListCatalogue) ListMovies(ctx context.Context, order store.TitleOrder, page int) (*models.SearchResponse[models.Movie], error) {
+	return &models.SearchResponse[models.Movie]{
+		Page:         page,
+		Results:      []models.Movie{{ID: 603, Title: "The Matrix"}},
+		TotalPages:   1,
+		TotalResults: 1,
+	}, nil
+}
+
+func (fakeListCatalogue) ListTVShows(ctx context.Context, order store.TitleOrder, page int) (*models.SearchResponse[models.TVShow], error) {
+	return &models.SearchResponse[models.TVShow]{
+		Page:         page,
+		Results:      []models.TVShow{{ID: 1396, Name: "Breaking Bad"}},
+		TotalPages:   1,
+		TotalResults: 1,
+	}, nil
+}
+
+func TestListHandler_StaleCatalogue(t *testing.T) {
+	handler := NewListHandler(&MockListClient{err: &services.TMDbError{StatusCode: 401, StatusMessage: "Invalid API key"}})
+	handler.SetCatalogue(fakeListCatalogue{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/popular", nil)
+	w := httptest.NewRecorder()
+
+	handler.GetPopular(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if source := w.Header().Get(DataSourceHeader); source != DataSourceStaleCache {
+		t.Errorf("expected %s header %q, got %q", DataSourceHeader, DataSourceStaleCache, source)
+	}
+
+	var page models.CursorPage[models.Movie]
+	if err := json.NewDecoder(w.Body).Decode(&page); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !page.Stale || len(page.Results) != 1 || page.Results[0].ID != 603 {
+		t.Errorf("expected stale page with the cached movie, got %+v", page)
+	}
+}
@@ -0,0 +1,169 @@
+// Package handlers provides HTTP handlers for moderating user reviews.
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/takeshi-arihori/movie-api/internal/models"
+	"github.com/takeshi-arihori/movie-api/internal/store"
+)
+
+// ReviewModerationStore reads the moderation queue and records decisions
+type ReviewModerationStore interface {
+	Get(ctx context.Context, id string) (*models.UserReview, error)
+	Queue(ctx context.Context, offset, limit int) ([]models.UserReview, int, error)
+	Moderate(ctx context.Context, entry *models.ReviewModerationEntry, notification *models.Notification) (previous, moderated *models.UserReview, err error)
+	History(ctx context.Context, reviewID string) ([]models.ReviewModerationEntry, error)
+}
+
+// ModerationHandler handles review moderation HTTP requests. Its routes must be
+// wrapped in AuthHandler.RequireAuth and then RequireModerator.
+type ModerationHandler struct {
+	reviews ReviewModerationStore
+}
+
+// NewModerationHandler creates a new ModerationHandler instance
+func NewModerationHandler(reviews ReviewModerationStore) *ModerationHandler {
+	return &ModerationHandler{
+		reviews: reviews,
+	}
+}
+
+// RequireModerator rejects requests from users who are not moderators. It must run
+// after AuthHandler.RequireAuth.
+func (h *ModerationHandler) RequireModerator(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		user, ok := requireUser(w, r)
+		if !ok {
+			return
+		}
+		if !user.IsModerator {
+			writeErrorResponse(w, http.StatusForbidden, "forbidden", "Moderator access required")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// GetQueue handles GET /api/v1/moderation/reviews requests, listing pending
+// reviews with flagged reviews first and then oldest first
+func (h *ModerationHandler) GetQueue(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+	page, limit, ok := parsePageRequest(w, r)
+	if !ok {
+		return
+	}
+
+	reviews, total, err := h.reviews.Queue(r.Context(), (page-1)*limit, limit)
+	if err != nil {
+		log.Printf("Failed to list moderation queue: %v", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to retrieve moderation queue")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, newReviewListResponse(reviews, page, limit, total))
+}
+
+// ModerateReview handles POST /api/v1/moderation/decisions requests. Rejections
+// need a reason, which is sent to the author along with the decision.
+func (h *ModerationHandler) ModerateReview(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	moderator, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.ReviewModerationRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Action == models.ReviewActionReject && req.Reason == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "validation_error", "reason is required to reject a review")
+		return
+	}
+
+	review, err := h.reviews.Get(r.Context(), req.ReviewID)
+	if err != nil {
+		h.writeStoreError(w, "get review", err)
+		return
+	}
+	if review.UserID == moderator.ID {
+		writeErrorResponse(w, http.StatusForbidden, "forbidden", "Moderators cannot moderate their own reviews")
+		return
+	}
+
+	entry := &models.ReviewModerationEntry{
+		ReviewID:    review.ID,
+		ModeratorID: moderator.ID,
+		Action:      req.Action,
+		Reason:      req.Reason,
+	}
+	_, moderated, err := h.reviews.Moderate(r.Context(), entry, decisionNotification(review, req.Action, req.Reason))
+	if err != nil {
+		h.writeStoreError(w, "moderate review", err)
+		return
+	}
+
+	message := "Review approved"
+	if req.Action == models.ReviewActionReject {
+		message = "Review rejected"
+	}
+	writeJSONResponse(w, http.StatusOK, models.ReviewResponse{Review: *moderated, Message: message, Success: true})
+}
+
+// GetHistory handles GET /api/v1/moderation/reviews/{id}/history requests,
+// returning the audit log of decisions on a review
+func (h *ModerationHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+
+	entries, err := h.reviews.History(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeStoreError(w, "get moderation history", err)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, entries)
+}
+
+// writeStoreError maps a ReviewModerationStore error to an error response
+func (h *ModerationHandler) writeStoreError(w http.ResponseWriter, op string, err error) {
+	if errors.Is(err, store.ErrNotFound) {
+		writeErrorResponse(w, http.StatusNotFound, "review_not_found", "Review not found")
+		return
+	}
+	log.Printf("Failed to %s: %v", op, err)
+	writeErrorResponse(w, http.StatusInternalServerError, "internal_error", fmt.Sprintf("Failed to %s", op))
+}
+
+// decisionNotification tells a review's author about a moderator's decision
+func decisionNotification(review *models.UserReview, action, reason string) *models.Notification {
+	notification := &models.Notification{
+		Type:     models.NotificationReviewApproved,
+		Message:  fmt.Sprintf("Your review %q has been published.", review.Title),
+		ReviewID: &review.ID,
+	}
+	if action == models.ReviewActionReject {
+		notification.Type = models.NotificationReviewRejected
+		notification.Message = fmt.Sprintf("Your review %q was not published: %s", review.Title, reason)
+	}
+	return notification
+}
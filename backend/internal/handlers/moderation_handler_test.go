@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/takeshi-arihori/movie-api/internal/auth"
+	"github.com/takeshi-arihori/movie-api/internal/models"
+)
+
+// withModerator returns req with a moderator as the authenticated user
+func withModerator(req *http.Request, userID string) *http.Request {
+	return req.WithContext(auth.WithUser(req.Context(), &models.User{ID: userID, Username: "morpheus", IsActive: true, IsModerator: true}))
+}
+
+func TestModerationHandler_RequireModerator(t *testing.T) {
+	handler := NewModerationHandler(&fakeUserReviewStore{})
+	protected := handler.RequireModerator(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name           string
+		req            *http.Request
+		expectedStatus int
+	}{
+		{"moderator", withModerator(httptest.NewRequest("GET", "/api/v1/moderation/reviews", nil), "mod-1"), http.StatusOK},
+		{"regular user", withUser(httptest.NewRequest("GET", "/api/v1/moderation/reviews", nil), "user-1"), http.StatusForbidden},
+		{"unauthenticated", httptest.NewRequest("GET", "/api/v1/moderation/reviews", nil), http.StatusUnauthorized},
+		{"preflight", httptest.NewRequest("OPTIONS", "/api/v1/moderation/reviews", nil), http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			protected.ServeHTTP(w, tt.req)
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestModerationHandler_Queue(t *testing.T) {
+	reviews := &fakeUserReviewStore{}
+	handler := NewModerationHandler(reviews)
+	for _, review := range []models.UserReview{
+		{UserID: "user-1", MediaID: 550, Title: "Oldest", Status: models.ReviewStatusPending},
+		{UserID: "user-2", MediaID: 550, Title: "Published", Status: models.ReviewStatusApproved},
+		{UserID: "user-3", MediaID: 550, Title: "Spam", Status: models.ReviewStatusPending, Flagged: true, FlagReasons: []string{"link_spam"}},
+		{UserID: "user-4", MediaID: 550, Title: "Newest", Status: models.ReviewStatusPending},
+	} {
+		reviews.Create(context.Background(), &review)
+	}
+
+	w := httptest.NewRecorder()
+	handler.GetQueue(w, withModerator(httptest.NewRequest("GET", "/api/v1/moderation/reviews", nil), "mod-1"))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp models.ReviewListResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	var titles []string
+	for _, review := range resp.Reviews {
+		titles = append(titles, review.Title)
+	}
+	if got := strings.Join(titles, ","); got != "Spam,Oldest,Newest" {
+		t.Errorf("Expected flagged reviews first and then oldest first, got %s", got)
+	}
+	if !resp.Reviews[0].Flagged || len(resp.Reviews[0].FlagReasons) != 1 {
+		t.Errorf("Expected moderators to see flags, got %+v", resp.Reviews[0])
+	}
+}
+
+func TestModerationHandler_ModerateReview(t *testing.T) {
+	reviews := &fakeUserReviewStore{}
+	handler := NewModerationHandler(reviews)
+	review := &models.UserReview{UserID: "user-1", MediaID: 550, MediaType: "movie", Title: "Spam", Status: models.ReviewStatusPending}
+	own := &models.UserReview{UserID: "mod-1", MediaID: 550, MediaType: "movie", Title: "Mine", Status: models.ReviewStatusPending}
+	reviews.Create(context.Background(), review)
+	reviews.Create(context.Background(), own)
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{"reject without reason", `{"review_id":"` + review.ID + `","action":"reject","reason":"  "}`, http.StatusBadRequest},
+		{"unknown action", `{"review_id":"` + review.ID + `","action":"delete"}`, http.StatusBadRequest},
+		{"unknown review", `{"review_id":"00000000-0000-0000-0000-000000000099","action":"approve"}`, http.StatusNotFound},
+		{"malformed review ID", `{"review_id":"not-a-uuid","action":"approve"}`, http.StatusBadRequest},
+		{"own review", `{"review_id":"` + own.ID + `","action":"approve"}`, http.StatusForbidden},
+		{"reject", `{"review_id":"` + review.ID + `","action":"reject","reason":"Advertising"}`, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := withModerator(httptest.NewRequest("POST", "/api/v1/moderation/decisions", strings.NewReader(tt.body)), "mod-1")
+			w := httptest.NewRecorder()
+
+			handler.ModerateReview(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	stored, _ := reviews.Get(context.Background(), review.ID)
+	if stored.Status != models.ReviewStatusRejected || stored.ModerationReason != "Advertising" {
+		t.Errorf("Expected the review to be rejected with the reason, got %+v", stored)
+	}
+	if len(reviews.notifications) != 1 {
+		t.Fatalf("Expected 1 notification, got %d", len(reviews.notifications))
+	}
+	notification := reviews.notifications[0]
+	if notification.UserID != "user-1" || notification.Type != models.NotificationReviewRejected || !strings.Contains(notification.Message, "Advertising") {
+		t.Errorf("Expected the author to be told the reason, got %+v", notification)
+	}
+
+	req := withModerator(httptest.NewRequest("GET", "/api/v1/moderation/reviews/"+review.ID+"/history", nil), "mod-1")
+	req = mux.SetURLVars(req, map[string]string{"id": review.ID})
+	w := httptest.NewRecorder()
+	handler.GetHistory(w, req)
+	var history []models.ReviewModerationEntry
+	json.NewDecoder(w.Body).Decode(&history)
+	if len(history) != 1 || history[0].ModeratorID != "mod-1" || history[0].Action != models.ReviewActionReject {
+		t.Errorf("Expected the rejection in the audit log, got %+v", history)
+	}
+}
@@ -3,6 +3,7 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -11,6 +12,7 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/takeshi-arihori/movie-api/internal/models"
 	"github.com/takeshi-arihori/movie-api/internal/services"
+	"github.com/takeshi-arihori/movie-api/internal/store"
 )
 
 // MovieClient defines the interface for movie-related TMDb operations
@@ -20,9 +22,16 @@ type MovieClient interface {
 	GetMovieReviews(ctx context.Context, movieID int, page int) (*models.MovieReviews, error)
 }
 
+// MovieCatalogue serves movies from the local database when TMDb is unavailable
+type MovieCatalogue interface {
+	GetMovieDetails(ctx context.Context, id int) (*store.CachedMovieDetails, error)
+	GetMovie(ctx context.Context, id int) (*store.CachedMovie, error)
+}
+
 // MovieHandler handles movie-related HTTP requests
 type MovieHandler struct {
 	tmdbClient MovieClient
+	catalogue  MovieCatalogue
 }
 
 // NewMovieHandler creates a new MovieHandler instance
@@ -32,6 +41,12 @@ func NewMovieHandler(tmdbClient MovieClient) *MovieHandler {
 	}
 }
 
+// SetCatalogue enables serving movie details from the local catalogue when TMDb
+// is unavailable. It must be called before the handler starts serving requests.
+func (h *MovieHandler) SetCatalogue(catalogue MovieCatalogue) {
+	h.catalogue = catalogue
+}
+
 // GetMovieDetails handles GET /api/v1/movies/{id} requests
 func (h *MovieHandler) GetMovieDetails(w http.ResponseWriter, r *http.Request) {
 	// Enable CORS
@@ -80,8 +95,15 @@ func (h *MovieHandler) GetMovieDetails(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 		}
+
+		if stale := h.staleMovieDetails(r.Context(), movieID, err); stale != nil {
+			log.Printf("Serving movie %d from the local catalogue", movieID)
+			markStale(w)
+			writeJSONResponse(w, http.StatusOK, stale)
+			return
+		}
 		
-		writeErrorResponse(w, http.StatusInternalServerError, "api_error", "Failed to retrieve movie details")
+		writeUpstreamError(w, err, "Failed to retrieve movie details")
 		return
 	}
 
@@ -91,7 +113,8 @@ func (h *MovieHandler) GetMovieDetails(w http.ResponseWriter, r *http.Request) {
 	writeJSONResponse(w, http.StatusOK, movieDetails)
 }
 
-// GetMovieCredits handles GET /api/v1/movies/{id}/credits requests
+// GetMovieCredits handles GET /api/v1/movies/{id}/credits requests. See
+// models.CreditsOptions for the filters and views.
 func (h *MovieHandler) GetMovieCredits(w http.ResponseWriter, r *http.Request) {
 	// Enable CORS
 	w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -125,6 +148,11 @@ func (h *MovieHandler) GetMovieCredits(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	opts, ok := parseCreditsOptions(w, r)
+	if !ok {
+		return
+	}
+
 	log.Printf("Fetching movie credits for ID: %d", movieID)
 
 	// Get movie credits from TMDb API
@@ -140,7 +168,7 @@ func (h *MovieHandler) GetMovieCredits(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 		
-		writeErrorResponse(w, http.StatusInternalServerError, "api_error", "Failed to retrieve movie credits")
+		writeUpstreamError(w, err, "Failed to retrieve movie credits")
 		return
 	}
 
@@ -148,7 +176,7 @@ func (h *MovieHandler) GetMovieCredits(w http.ResponseWriter, r *http.Request) {
 		movieCredits.ID, len(movieCredits.Cast), len(movieCredits.Crew))
 
 	// Return movie credits
-	writeJSONResponse(w, http.StatusOK, movieCredits)
+	writeCredits(w, movieCredits.ID, movieCredits.Cast, func(member models.CastMember) int { return member.Order }, movieCredits.Crew, opts)
 }
 
 // GetMovieReviews handles GET /api/v1/movies/{id}/reviews requests
@@ -208,7 +236,7 @@ func (h *MovieHandler) GetMovieReviews(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 		
-		writeErrorResponse(w, http.StatusInternalServerError, "api_error", "Failed to retrieve movie reviews")
+		writeUpstreamError(w, err, "Failed to retrieve movie reviews")
 		return
 	}
 
@@ -219,3 +247,46 @@ func (h *MovieHandler) GetMovieReviews(w http.ResponseWriter, r *http.Request) {
 	writeJSONResponse(w, http.StatusOK, movieReviews)
 }
 
+// staleMovieDetails returns a movie's details from the local catalogue after TMDb
+// failed with err, or nil if there is no usable copy. Movies cached only from lists
+// lack the detail fields (genres, runtime, budget, ...), which are left empty.
+func (h *MovieHandler) staleMovieDetails(ctx context.Context, movieID int, err error) *models.MovieDetails {
+	if h.catalogue == nil || !canServeStale(err) {
+		return nil
+	}
+
+	if cached, err := h.catalogue.GetMovieDetails(ctx, movieID); err == nil {
+		details := cached.Details
+		details.Stale = true
+		return &details
+	} else if !errors.Is(err, store.ErrNotFound) {
+		log.Printf("Failed to read movie %d details from the local catalogue: %v", movieID, err)
+		return nil
+	}
+
+	cached, err := h.catalogue.GetMovie(ctx, movieID)
+	if err != nil {
+		if !errors.Is(err, store.ErrNotFound) {
+			log.Printf("Failed to read movie %d from the local catalogue: %v", movieID, err)
+		}
+		return nil
+	}
+	movie := cached.Movie
+	details := &models.MovieDetails{
+		Adult:            movie.Adult,
+		BackdropPath:     movie.BackdropPath,
+		ID:               movie.ID,
+		OriginalLanguage: movie.OriginalLanguage,
+		OriginalTitle:    movie.OriginalTitle,
+		Overview:         &movie.Overview,
+		Popularity:       movie.Popularity,
+		PosterPath:       movie.PosterPath,
+		ReleaseDate:      movie.ReleaseDate,
+		Title:            movie.Title,
+		Video:            movie.Video,
+		VoteAverage:      movie.VoteAverage,
+		VoteCount:        movie.VoteCount,
+		Stale:            true,
+	}
+	return details
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/takeshi-arihori/movie-api/internal/models"
 	"github.com/takeshi-arihori/movie-api/internal/services"
+	"github.com/takeshi-arihori/movie-api/internal/store"
 )
 
 // MockTMDbClient is a mock implementation of TMDbClient for testing
@@ -391,4 +392,134 @@ func TestMovieHandler_CORS(t *testing.T) {
 			t.Errorf("expected header %s: %q, got %q", header, expectedValue, actualValue)
 		}
 	}
-}
\ No newline at end of file
+}
+// fakeMovieCatalogue is a MovieCatalogue holding movie 603 without stored details
+type fakeMovieCatalogue struct{}
+
+func (fakeMovieCatalogue) GetMovieDetails(ctx context.Context, id int) (*store.CachedMovieDetails, error) {
+	return nil, store.ErrNotFound
+}
+
+func (fakeMovieCatalogue) GetMovie(ctx context.Context, id int) (*store.CachedMovie, error) {
+	if id != 603 {
+		return nil, store.ErrNotFound
+	}
+	return &store.CachedMovie{Movie: models.Movie{ID: 603, Title: "The Matrix", VoteAverage: 8.2}}, nil
+}
+
+func TestMovieHandler_StaleCatalogue(t *testing.T) {
+	tests := []struct {
+		name           string
+		movieID        string
+		err            error
+		expectedStatus int
+		expectStale    bool
+	}{
+		{"revoked API key serves cached movie", "603", &services.TMDbError{StatusCode: 401, StatusMessage: "Invalid API key"}, http.StatusOK, true},
+		{"network error serves cached movie", "603", fmt.Errorf("connection refused"), http.StatusOK, true},
+		{"uncached movie still fails", "604", fmt.Errorf("connection refused"), http.StatusInternalServerError, false},
+		{"not found is not masked", "603", &services.TMDbError{StatusCode: 404, StatusMessage: "Not found"}, http.StatusNotFound, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewMovieHandler(&MockTMDbClient{err: tt.err})
+			handler.SetCatalogue(fakeMovieCatalogue{})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/movies/"+tt.movieID, nil)
+			req = mux.SetURLVars(req, map[string]string{"id": tt.movieID})
+			w := httptest.NewRecorder()
+
+			handler.GetMovieDetails(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if stale := w.Header().Get(DataSourceHeader) == DataSourceStaleCache; stale != tt.expectStale {
+				t.Errorf("expected stale header %v, got %v", tt.expectStale, stale)
+			}
+			if !tt.expectStale {
+				return
+			}
+
+			var details models.MovieDetails
+			if err := json.NewDecoder(w.Body).Decode(&details); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if !details.Stale || details.Title != "The Matrix" {
+				t.Errorf("expected stale details for The Matrix, got %+v", details)
+			}
+		})
+	}
+}
+
+func TestMovieHandler_GetMovieCredits_Options(t *testing.T) {
+	mockClient := &MockTMDbClient{
+		movieCredits: &models.MovieCredits{
+			ID: 550,
+			Cast: []models.CastMember{
+				{ID: 2, Name: "Actor 2", Character: "Character 2", Order: 1},
+				{ID: 1, Name: "Actor 1", Character: "Character 1", Order: 0},
+			},
+			Crew: []models.CrewMember{
+				{ID: 3, Name: "Director", Department: "Directing", Job: "Director", CreditID: "a"},
+				{ID: 3, Name: "Director", Department: "Writing", Job: "Screenplay", CreditID: "b"},
+				{ID: 4, Name: "Composer", Department: "Sound", Job: "Original Music Composer", CreditID: "c"},
+			},
+		},
+	}
+	handler := NewMovieHandler(mockClient)
+
+	serve := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/movies/550/credits"+query, nil)
+		w := httptest.NewRecorder()
+		router := mux.NewRouter()
+		router.HandleFunc("/api/v1/movies/{id}/credits", handler.GetMovieCredits).Methods("GET")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("top-billed cast", func(t *testing.T) {
+		w := serve("?limit=1")
+		var credits models.FilteredCredits[models.CastMember, models.CrewMember]
+		if err := json.NewDecoder(w.Body).Decode(&credits); err != nil {
+			t.Fatalf("failed to decode credits response: %v", err)
+		}
+		if len(credits.Cast) != 1 || credits.Cast[0].ID != 1 || credits.TotalCast != 2 {
+			t.Errorf("expected only the top-billed actor out of 2, got %+v", credits)
+		}
+		if len(credits.Crew) != 3 || credits.TotalCrew != 3 {
+			t.Errorf("expected the full crew, got %+v", credits.Crew)
+		}
+	})
+
+	t.Run("department with merged crew", func(t *testing.T) {
+		w := serve("?department=writing&dedupe_crew=true")
+		var credits models.FilteredCredits[models.CastMember, models.MergedCrewMember]
+		if err := json.NewDecoder(w.Body).Decode(&credits); err != nil {
+			t.Fatalf("failed to decode credits response: %v", err)
+		}
+		if len(credits.Cast) != 0 || len(credits.Crew) != 1 || credits.Crew[0].Jobs[0] != "Screenplay" {
+			t.Errorf("expected only the screenplay credit, got %+v", credits)
+		}
+	})
+
+	t.Run("summary", func(t *testing.T) {
+		w := serve("?view=summary")
+		var summary models.CreditsSummary
+		if err := json.NewDecoder(w.Body).Decode(&summary); err != nil {
+			t.Fatalf("failed to decode summary response: %v", err)
+		}
+		if summary.ID != 550 || len(summary.Directors) != 1 || len(summary.Writers) != 1 || len(summary.Composers) != 1 {
+			t.Errorf("expected the director, writer and composer, got %+v", summary)
+		}
+	})
+
+	for _, query := range []string{"?limit=0", "?dedupe_crew=maybe", "?view=poster"} {
+		t.Run("invalid "+query, func(t *testing.T) {
+			if w := serve(query); w.Code != http.StatusBadRequest {
+				t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+// Package handlers provides HTTP handlers for users' notifications.
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/takeshi-arihori/movie-api/internal/models"
+	"github.com/takeshi-arihori/movie-api/internal/store"
+)
+
+// NotificationStore reads a user's notifications
+type NotificationStore interface {
+	List(ctx context.Context, userID string, offset, limit int) ([]models.Notification, int, int, error)
+	MarkRead(ctx context.Context, userID, id string) error
+}
+
+// NotificationHandler handles notification HTTP requests. Its routes must be
+// wrapped in AuthHandler.RequireAuth.
+type NotificationHandler struct {
+	notifications NotificationStore
+}
+
+// NewNotificationHandler creates a new NotificationHandler instance
+func NewNotificationHandler(notifications NotificationStore) *NotificationHandler {
+	return &NotificationHandler{
+		notifications: notifications,
+	}
+}
+
+// ListNotifications handles GET /api/v1/me/notifications requests
+func (h *NotificationHandler) ListNotifications(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	page, limit, ok := parsePageRequest(w, r)
+	if !ok {
+		return
+	}
+
+	notifications, total, unread, err := h.notifications.List(r.Context(), user.ID, (page-1)*limit, limit)
+	if err != nil {
+		log.Printf("Failed to list notifications for user %s: %v", user.ID, err)
+		writeErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to retrieve notifications")
+		return
+	}
+
+	pages := totalPages(total, limit)
+	writeJSONResponse(w, http.StatusOK, models.NotificationListResponse{
+		Notifications: notifications,
+		Page:          page,
+		TotalPages:    pages,
+		TotalResults:  total,
+		Unread:        unread,
+		HasNext:       page < pages,
+		HasPrevious:   page > 1,
+	})
+}
+
+// MarkRead handles POST /api/v1/me/notifications/{id}/read requests
+func (h *NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.notifications.MarkRead(r.Context(), user.ID, mux.Vars(r)["id"])
+	if errors.Is(err, store.ErrNotFound) {
+		writeErrorResponse(w, http.StatusNotFound, "notification_not_found", "Notification not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to mark notification read for user %s: %v", user.ID, err)
+		writeErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to update notification")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
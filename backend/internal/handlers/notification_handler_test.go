@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/takeshi-arihori/movie-api/internal/models"
+	"github.com/takeshi-arihori/movie-api/internal/store"
+)
+
+// fakeNotificationStore is an in-memory NotificationStore
+type fakeNotificationStore struct {
+	notifications []models.Notification
+}
+
+func (s *fakeNotificationStore) List(ctx context.Context, userID string, offset, limit int) ([]models.Notification, int, int, error) {
+	var matched []models.Notification
+	unread := 0
+	for _, n := range s.notifications {
+		if n.UserID == userID {
+			matched = append(matched, n)
+			if n.ReadAt == nil {
+				unread++
+			}
+		}
+	}
+	page := []models.Notification{}
+	for i := offset; i < len(matched) && i < offset+limit; i++ {
+		page = append(page, matched[i])
+	}
+	return page, len(matched), unread, nil
+}
+
+func (s *fakeNotificationStore) MarkRead(ctx context.Context, userID, id string) error {
+	for i, n := range s.notifications {
+		if n.ID == id && n.UserID == userID {
+			now := time.Now()
+			s.notifications[i].ReadAt = &now
+			return nil
+		}
+	}
+	return store.ErrNotFound
+}
+
+func TestNotificationHandler(t *testing.T) {
+	notifications := &fakeNotificationStore{notifications: []models.Notification{
+		{ID: "n-1", UserID: "user-1", Type: models.NotificationReviewApproved, Message: "Published"},
+		{ID: "n-2", UserID: "user-1", Type: models.NotificationReviewRejected, Message: "Rejected"},
+		{ID: "n-3", UserID: "user-2", Type: models.NotificationReviewApproved, Message: "Published"},
+	}}
+	handler := NewNotificationHandler(notifications)
+
+	list := func(t *testing.T) models.NotificationListResponse {
+		t.Helper()
+		w := httptest.NewRecorder()
+		handler.ListNotifications(w, withUser(httptest.NewRequest("GET", "/api/v1/me/notifications?limit=1", nil), "user-1"))
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp models.NotificationListResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return resp
+	}
+	markRead := func(userID, id string) int {
+		req := withUser(httptest.NewRequest("POST", "/api/v1/me/notifications/"+id+"/read", nil), userID)
+		req = mux.SetURLVars(req, map[string]string{"id": id})
+		w := httptest.NewRecorder()
+		handler.MarkRead(w, req)
+		return w.Code
+	}
+
+	if resp := list(t); resp.TotalResults != 2 || resp.Unread != 2 || len(resp.Notifications) != 1 || !resp.HasNext {
+		t.Errorf("Expected the first of user-1's 2 unread notifications, got %+v", resp)
+	}
+	if code := markRead("user-2", "n-1"); code != http.StatusNotFound {
+		t.Errorf("Expected another user's notification to return 404, got %d", code)
+	}
+	if code := markRead("user-1", "n-1"); code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", code)
+	}
+	if resp := list(t); resp.Unread != 1 {
+		t.Errorf("Expected 1 unread notification, got %d", resp.Unread)
+	}
+}
@@ -0,0 +1,95 @@
+// Package handlers provides helpers for cursor-paginated endpoints.
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+	"github.com/takeshi-arihori/movie-api/internal/pagination"
+)
+
+// parseLimit parses the limit query parameter used for cursor pagination
+func parseLimit(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 || limit > pagination.MaxLimit {
+		return 0, fmt.Errorf("invalid limit parameter: must be between 1 and %d", pagination.MaxLimit)
+	}
+	return limit, nil
+}
+
+// usesCursor reports whether the request asks for cursor-based pagination
+func usesCursor(r *http.Request) bool {
+	query := r.URL.Query()
+	return query.Get("limit") != "" || query.Get("cursor") != ""
+}
+
+// parseCursorRequest reads the limit and cursor query parameters. The cursor must
+// have been issued for the same scope; on failure an error response has already
+// been written and ok is false.
+func parseCursorRequest(w http.ResponseWriter, r *http.Request, scope ...string) (cursor pagination.Cursor, limit int, ok bool) {
+	query := r.URL.Query()
+
+	limit, err := parseLimit(query.Get("limit"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", err.Error())
+		return cursor, 0, false
+	}
+	if limit == 0 {
+		limit = pagination.DefaultLimit
+	}
+
+	cursor, err = pagination.Parse(query.Get("cursor"), scope...)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_cursor", "Cursor is invalid or does not match this request")
+		return cursor, 0, false
+	}
+
+	return cursor, limit, true
+}
+
+// newCursorPage converts a fetched window into the API response type
+func newCursorPage[T any](window *pagination.Window[T], limit int) models.CursorPage[T] {
+	return models.CursorPage[T]{
+		Results:      window.Results,
+		TotalResults: window.TotalResults,
+		Limit:        limit,
+		NextCursor:   window.NextCursor,
+	}
+}
+
+// parsePageRequest reads the page and limit query parameters of page-numbered
+// endpoints. On failure an error response has already been written and ok is false.
+func parsePageRequest(w http.ResponseWriter, r *http.Request) (page, limit int, ok bool) {
+	query := r.URL.Query()
+
+	page = 1
+	if value := query.Get("page"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", "invalid page parameter: must be a positive integer")
+			return 0, 0, false
+		}
+		page = parsed
+	}
+
+	limit, err := parseLimit(query.Get("limit"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", err.Error())
+		return 0, 0, false
+	}
+	if limit == 0 {
+		limit = pagination.DefaultLimit
+	}
+
+	return page, limit, true
+}
+
+// totalPages returns the number of pages of limit results needed for total results
+func totalPages(total, limit int) int {
+	return (total + limit - 1) / limit
+}
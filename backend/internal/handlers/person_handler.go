@@ -7,8 +7,11 @@ import (
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/takeshi-arihori/movie-api/internal/filmography"
 	"github.com/takeshi-arihori/movie-api/internal/models"
 	"github.com/takeshi-arihori/movie-api/internal/services"
 )
@@ -82,7 +85,7 @@ func (h *PersonHandler) GetPersonDetails(w http.ResponseWriter, r *http.Request)
 			}
 		}
 		
-		writeErrorResponse(w, http.StatusInternalServerError, "api_error", "Failed to retrieve person details")
+		writeUpstreamError(w, err, "Failed to retrieve person details")
 		return
 	}
 
@@ -141,7 +144,7 @@ func (h *PersonHandler) GetPersonMovieCredits(w http.ResponseWriter, r *http.Req
 			}
 		}
 		
-		writeErrorResponse(w, http.StatusInternalServerError, "api_error", "Failed to retrieve person movie credits")
+		writeUpstreamError(w, err, "Failed to retrieve person movie credits")
 		return
 	}
 
@@ -201,7 +204,7 @@ func (h *PersonHandler) GetPersonTVCredits(w http.ResponseWriter, r *http.Reques
 			}
 		}
 		
-		writeErrorResponse(w, http.StatusInternalServerError, "api_error", "Failed to retrieve person TV credits")
+		writeUpstreamError(w, err, "Failed to retrieve person TV credits")
 		return
 	}
 
@@ -261,7 +264,7 @@ func (h *PersonHandler) GetPersonCombinedCredits(w http.ResponseWriter, r *http.
 			}
 		}
 		
-		writeErrorResponse(w, http.StatusInternalServerError, "api_error", "Failed to retrieve person combined credits")
+		writeUpstreamError(w, err, "Failed to retrieve person combined credits")
 		return
 	}
 
@@ -270,4 +273,84 @@ func (h *PersonHandler) GetPersonCombinedCredits(w http.ResponseWriter, r *http.
 
 	// Return person combined credits
 	writeJSONResponse(w, http.StatusOK, combinedCredits)
-}
\ No newline at end of file
+}
+
+// GetPersonFilmography handles GET /api/v1/people/{id}/filmography requests
+func (h *PersonHandler) GetPersonFilmography(w http.ResponseWriter, r *http.Request) {
+	// Enable CORS
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	// Handle preflight requests
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Only allow GET requests
+	if r.Method != http.MethodGet {
+		writeErrorResponse(w, http.StatusMethodNotAllowed, "method_not_allowed", "Only GET method is allowed")
+		return
+	}
+
+	// Extract person ID from URL path
+	vars := mux.Vars(r)
+	personIDStr, exists := vars["id"]
+	if !exists {
+		writeErrorResponse(w, http.StatusBadRequest, "missing_parameter", "Person ID is required")
+		return
+	}
+
+	// Parse person ID
+	personID, err := strconv.Atoi(personIDStr)
+	if err != nil || personID <= 0 {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", "Person ID must be a positive integer")
+		return
+	}
+
+	// Parse filters
+	query := r.URL.Query()
+	filter := models.FilmographyFilter{
+		MediaType:  query.Get("media_type"),
+		Department: strings.TrimSpace(query.Get("department")),
+	}
+	if minVoteCount := query.Get("min_vote_count"); minVoteCount != "" {
+		filter.MinVoteCount, err = strconv.Atoi(minVoteCount)
+		if err != nil || filter.MinVoteCount < 0 {
+			writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", "invalid min_vote_count parameter: must be a non-negative integer")
+			return
+		}
+	}
+	if err := validate.Struct(filter); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", validationMessage(err))
+		return
+	}
+
+	log.Printf("Fetching person filmography for ID: %d", personID)
+
+	// Get person combined credits from TMDb API
+	combinedCredits, err := h.tmdbClient.GetPersonCombinedCredits(r.Context(), personID)
+	if err != nil {
+		log.Printf("Failed to get person combined credits for ID %d: %v", personID, err)
+
+		// Check if it's a TMDb API error
+		if tmdbErr, ok := err.(*services.TMDbError); ok {
+			if tmdbErr.StatusCode == 404 {
+				writeErrorResponse(w, http.StatusNotFound, "person_not_found", fmt.Sprintf("Person with ID %d not found", personID))
+				return
+			}
+		}
+
+		writeUpstreamError(w, err, "Failed to retrieve person filmography")
+		return
+	}
+
+	result := filmography.Build(combinedCredits, filter, time.Now())
+
+	log.Printf("Successfully built filmography for person ID %d: %d entries in %d years",
+		personID, result.TotalEntries, len(result.Years))
+
+	// Return person filmography
+	writeJSONResponse(w, http.StatusOK, result)
+}
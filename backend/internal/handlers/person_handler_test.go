@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -515,4 +516,113 @@ func stringPtr(s string) *string {
 
 func intPtr(i int) *int {
 	return &i
-}
\ No newline at end of file
+}
+
+func TestPersonHandler_GetPersonFilmography(t *testing.T) {
+	credits := &models.PersonCombinedCredits{
+		ID: 123,
+		Cast: []models.PersonCombinedCast{
+			{ID: 1, Title: stringPtr("Test Movie"), ReleaseDate: stringPtr("2010-01-01"), Character: "Test Character", CreditID: "credit1", MediaType: "movie", VoteCount: 50},
+		},
+		Crew: []models.PersonCombinedCrew{
+			{ID: 1, Title: stringPtr("Test Movie"), ReleaseDate: stringPtr("2010-01-01"), Job: "Director", Department: "Directing", CreditID: "credit2", MediaType: "movie", VoteCount: 50},
+			{ID: 1, Title: stringPtr("Test Movie"), ReleaseDate: stringPtr("2010-01-01"), Job: "Writer", Department: "Writing", CreditID: "credit3", MediaType: "movie", VoteCount: 50},
+			{ID: 2, Name: stringPtr("Test Show"), FirstAirDate: stringPtr("2015-01-01"), Job: "Director", Department: "Directing", CreditID: "credit4", MediaType: "tv", VoteCount: 5},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		personID        string
+		query           string
+		mockError       error
+		expectedStatus  int
+		expectedError   string
+		expectedEntries int
+	}{
+		{
+			name:            "credits merged per title",
+			personID:        "123",
+			expectedStatus:  http.StatusOK,
+			expectedEntries: 2,
+		},
+		{
+			name:            "filtered by media type, department and vote count",
+			personID:        "123",
+			query:           "?media_type=movie&department=writing&min_vote_count=10",
+			expectedStatus:  http.StatusOK,
+			expectedEntries: 1,
+		},
+		{
+			name:           "invalid media type",
+			personID:       "123",
+			query:          "?media_type=person",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid_parameter",
+		},
+		{
+			name:           "invalid minimum vote count",
+			personID:       "123",
+			query:          "?min_vote_count=-1",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid_parameter",
+		},
+		{
+			name:           "person not found",
+			personID:       "999",
+			mockError:      &services.TMDbError{StatusCode: 404, StatusMessage: "Not found"},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "person_not_found",
+		},
+		{
+			name:           "API error",
+			personID:       "123",
+			mockError:      errors.New("connection refused"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "api_error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPersonClient{
+				combinedCredits: credits,
+				err:             tt.mockError,
+			}
+			handler := NewPersonHandler(mockClient)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/people/"+tt.personID+"/filmography"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc("/api/v1/people/{id}/filmography", handler.GetPersonFilmography).Methods("GET")
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if tt.expectedError != "" {
+				var errorResp ErrorResponse
+				if err := json.NewDecoder(w.Body).Decode(&errorResp); err != nil {
+					t.Fatalf("failed to decode error response: %v", err)
+				}
+				if errorResp.Error != tt.expectedError {
+					t.Errorf("expected error %q, got %q", tt.expectedError, errorResp.Error)
+				}
+				return
+			}
+
+			var filmography models.Filmography
+			if err := json.NewDecoder(w.Body).Decode(&filmography); err != nil {
+				t.Fatalf("failed to decode filmography response: %v", err)
+			}
+			if filmography.PersonID != 123 {
+				t.Errorf("expected person ID 123, got %d", filmography.PersonID)
+			}
+			if filmography.TotalEntries != tt.expectedEntries {
+				t.Errorf("expected %d entries, got %d", tt.expectedEntries, filmography.TotalEntries)
+			}
+		})
+	}
+}
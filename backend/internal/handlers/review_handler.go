@@ -3,13 +3,19 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"sort"
 	"strconv"
+	"unicode/utf8"
 
 	"github.com/gorilla/mux"
 	"github.com/takeshi-arihori/movie-api/internal/models"
+	"github.com/takeshi-arihori/movie-api/internal/pagination"
+	"github.com/takeshi-arihori/movie-api/internal/reviewanalysis"
+	"github.com/takeshi-arihori/movie-api/internal/reviewtext"
 	"github.com/takeshi-arihori/movie-api/internal/services"
 )
 
@@ -19,9 +25,16 @@ type ReviewClient interface {
 	GetTVShowReviews(ctx context.Context, tvID int, page int) (*models.TVReviews, error)
 }
 
+// CreditsClient looks up the cast of movies and TV shows
+type CreditsClient interface {
+	GetMovieCredits(ctx context.Context, movieID int) (*models.MovieCredits, error)
+	GetTVShowCredits(ctx context.Context, tvID int) (*models.TVCredits, error)
+}
+
 // ReviewHandler handles review-related HTTP requests
 type ReviewHandler struct {
 	tmdbClient ReviewClient
+	credits    CreditsClient
 }
 
 // NewReviewHandler creates a new ReviewHandler instance
@@ -31,6 +44,12 @@ func NewReviewHandler(tmdbClient ReviewClient) *ReviewHandler {
 	}
 }
 
+// SetCredits lets spoiler detection look for the names of a title's characters.
+// It must be called before the handler starts serving requests.
+func (h *ReviewHandler) SetCredits(credits CreditsClient) {
+	h.credits = credits
+}
+
 // GetMovieReviews handles GET /api/v1/movies/{id}/reviews requests
 func (h *ReviewHandler) GetMovieReviews(w http.ResponseWriter, r *http.Request) {
 	// Enable CORS
@@ -65,6 +84,42 @@ func (h *ReviewHandler) GetMovieReviews(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Every page at once, rendered for display
+	opts, ok := parseReviewOptions(w, r)
+	if !ok {
+		return
+	}
+	if opts.all {
+		h.writeAllReviews(w, r, models.SearchItemTypeMovie, movieID, opts)
+		return
+	}
+	if opts.hideSpoilers {
+		h.writeSpoilerFreeReviews(w, r, models.SearchItemTypeMovie, movieID, opts)
+		return
+	}
+
+	// Cursor-based pagination stitches multiple TMDb pages together
+	if usesCursor(r) {
+		cursor, limit, ok := parseCursorRequest(w, r, "movie-reviews", strconv.Itoa(movieID))
+		if !ok {
+			return
+		}
+		log.Printf("Fetching movie reviews for ID: %d, offset: %d, limit: %d", movieID, cursor.Offset, limit)
+		fetchPage := func(ctx context.Context, page int) (*pagination.Page[models.Review], error) {
+			reviews, err := h.tmdbClient.GetMovieReviews(ctx, movieID, page)
+			if err != nil {
+				return nil, err
+			}
+			return &pagination.Page[models.Review]{
+				Results:      reviews.Results,
+				TotalPages:   reviews.TotalPages,
+				TotalResults: reviews.TotalResults,
+			}, nil
+		}
+		h.writeReviewWindow(w, r, "movie", movieID, cursor, limit, fetchPage, opts)
+		return
+	}
+
 	// Parse page parameter
 	page := 1
 	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
@@ -88,13 +143,18 @@ func (h *ReviewHandler) GetMovieReviews(w http.ResponseWriter, r *http.Request)
 			}
 		}
 		
-		writeErrorResponse(w, http.StatusInternalServerError, "api_error", "Failed to retrieve movie reviews")
+		writeUpstreamError(w, err, "Failed to retrieve movie reviews")
 		return
 	}
 
 	log.Printf("Successfully retrieved movie reviews for movie ID %d: %d reviews (page %d/%d)", 
 		movieReviews.ID, len(movieReviews.Results), movieReviews.Page, movieReviews.TotalPages)
 
+	// Analysis works on a copy, since the client may share its responses
+	analyzed := *movieReviews
+	analyzed.Results = h.analyzeReviews(r.Context(), models.SearchItemTypeMovie, movieID, movieReviews.Results, opts)
+	movieReviews = &analyzed
+
 	// Return movie reviews
 	writeJSONResponse(w, http.StatusOK, movieReviews)
 }
@@ -133,6 +193,42 @@ func (h *ReviewHandler) GetTVReviews(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Every page at once, rendered for display
+	opts, ok := parseReviewOptions(w, r)
+	if !ok {
+		return
+	}
+	if opts.all {
+		h.writeAllReviews(w, r, models.SearchItemTypeTV, tvID, opts)
+		return
+	}
+	if opts.hideSpoilers {
+		h.writeSpoilerFreeReviews(w, r, models.SearchItemTypeTV, tvID, opts)
+		return
+	}
+
+	// Cursor-based pagination stitches multiple TMDb pages together
+	if usesCursor(r) {
+		cursor, limit, ok := parseCursorRequest(w, r, "tv-reviews", strconv.Itoa(tvID))
+		if !ok {
+			return
+		}
+		log.Printf("Fetching TV show reviews for ID: %d, offset: %d, limit: %d", tvID, cursor.Offset, limit)
+		fetchPage := func(ctx context.Context, page int) (*pagination.Page[models.Review], error) {
+			reviews, err := h.tmdbClient.GetTVShowReviews(ctx, tvID, page)
+			if err != nil {
+				return nil, err
+			}
+			return &pagination.Page[models.Review]{
+				Results:      reviews.Results,
+				TotalPages:   reviews.TotalPages,
+				TotalResults: reviews.TotalResults,
+			}, nil
+		}
+		h.writeReviewWindow(w, r, "tv", tvID, cursor, limit, fetchPage, opts)
+		return
+	}
+
 	// Parse page parameter
 	page := 1
 	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
@@ -156,13 +252,280 @@ func (h *ReviewHandler) GetTVReviews(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 		
-		writeErrorResponse(w, http.StatusInternalServerError, "api_error", "Failed to retrieve TV show reviews")
+		writeUpstreamError(w, err, "Failed to retrieve TV show reviews")
 		return
 	}
 
 	log.Printf("Successfully retrieved TV show reviews for TV ID %d: %d reviews (page %d/%d)", 
 		tvReviews.ID, len(tvReviews.Results), tvReviews.Page, tvReviews.TotalPages)
 
+	// Analysis works on a copy, since the client may share its responses
+	analyzed := *tvReviews
+	analyzed.Results = h.analyzeReviews(r.Context(), models.SearchItemTypeTV, tvID, tvReviews.Results, opts)
+	tvReviews = &analyzed
+
 	// Return TV show reviews
 	writeJSONResponse(w, http.StatusOK, tvReviews)
-}
\ No newline at end of file
+}
+
+// writeReviewWindow fetches a cursor window of TMDb reviews and writes it as a CursorPage
+func (h *ReviewHandler) writeReviewWindow(w http.ResponseWriter, r *http.Request, mediaType string, mediaID int,
+	cursor pagination.Cursor, limit int, fetchPage pagination.PageFunc[models.Review], opts reviewOptions) {
+	window, err := pagination.Fetch(r.Context(), cursor, limit, fetchPage, func(review models.Review) string {
+		return review.ID
+	})
+	if err != nil {
+		log.Printf("Failed to get %s reviews for ID %d: %v", mediaType, mediaID, err)
+		writeReviewError(w, err, mediaType, mediaID)
+		return
+	}
+
+	log.Printf("Successfully retrieved %s reviews for ID %d: %d reviews", mediaType, mediaID, len(window.Results))
+
+	window.Results = h.analyzeReviews(r.Context(), models.SearchItemType(mediaType), mediaID, window.Results, opts)
+	writeJSONResponse(w, http.StatusOK, newCursorPage(window, limit))
+}
+
+// writeSpoilerFreeReviews writes a page, or a cursor window, of a title's TMDb
+// reviews with likely spoilers left out. Spoilers are filtered out of all of the
+// title's reviews, up to services.MaxReviewPages pages of them, before paginating,
+// so pages are full and the totals count only the reviews that are not hidden.
+func (h *ReviewHandler) writeSpoilerFreeReviews(w http.ResponseWriter, r *http.Request, mediaType models.SearchItemType, mediaID int, opts reviewOptions) {
+	cursorMode := usesCursor(r)
+	var cursor pagination.Cursor
+	var limit int
+	if cursorMode {
+		var ok bool
+		cursor, limit, ok = parseCursorRequest(w, r, string(mediaType)+"-reviews", strconv.Itoa(mediaID), "hide_spoilers")
+		if !ok {
+			return
+		}
+	}
+
+	log.Printf("Fetching spoiler-free %s reviews for ID: %d", mediaType, mediaID)
+	all, err := services.AllReviews(r.Context(), h.tmdbClient, mediaType, mediaID)
+	if err != nil {
+		log.Printf("Failed to get all %s reviews for ID %d: %v", mediaType, mediaID, err)
+		writeReviewError(w, err, string(mediaType), mediaID)
+		return
+	}
+	kept := h.analyzeReviews(r.Context(), mediaType, mediaID, all.Results, opts)
+	totalPages := (len(kept) + pagination.PageSize - 1) / pagination.PageSize
+	fetchPage := func(ctx context.Context, page int) (*pagination.Page[models.Review], error) {
+		start := min((page-1)*pagination.PageSize, len(kept))
+		end := min(start+pagination.PageSize, len(kept))
+		return &pagination.Page[models.Review]{Results: kept[start:end], TotalPages: totalPages, TotalResults: len(kept)}, nil
+	}
+
+	log.Printf("Successfully retrieved spoiler-free %s reviews for ID %d: %d of %d reviews", mediaType, mediaID, len(kept), len(all.Results))
+
+	if cursorMode {
+		window, _ := pagination.Fetch(r.Context(), cursor, limit, fetchPage, func(review models.Review) string {
+			return review.ID
+		})
+		writeJSONResponse(w, http.StatusOK, newCursorPage(window, limit))
+		return
+	}
+
+	page := 1
+	if parsedPage, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && parsedPage > 0 {
+		page = parsedPage
+	}
+	reviews, _ := fetchPage(r.Context(), page)
+	if mediaType == models.SearchItemTypeTV {
+		writeJSONResponse(w, http.StatusOK, models.TVReviews{ID: mediaID, Page: page, Results: reviews.Results, TotalPages: totalPages, TotalResults: len(kept)})
+		return
+	}
+	writeJSONResponse(w, http.StatusOK, models.MovieReviews{ID: mediaID, Page: page, Results: reviews.Results, TotalPages: totalPages, TotalResults: len(kept)})
+}
+
+// writeReviewError writes the error response for a failed review lookup
+func writeReviewError(w http.ResponseWriter, err error, mediaType string, mediaID int) {
+	var tmdbErr *services.TMDbError
+	if errors.As(err, &tmdbErr) && tmdbErr.StatusCode == 404 {
+		if mediaType == "tv" {
+			writeErrorResponse(w, http.StatusNotFound, "tv_not_found", fmt.Sprintf("TV show with ID %d not found", mediaID))
+		} else {
+			writeErrorResponse(w, http.StatusNotFound, "movie_not_found", fmt.Sprintf("Movie with ID %d not found", mediaID))
+		}
+		return
+	}
+
+	if mediaType == "tv" {
+		writeUpstreamError(w, err, "Failed to retrieve TV show reviews")
+	} else {
+		writeUpstreamError(w, err, "Failed to retrieve movie reviews")
+	}
+}
+
+// reviewOptions are the query parameters that shape TMDb review responses
+type reviewOptions struct {
+	all          bool // Every page at once, rendered for display
+	analyze      bool // Attach an offline analysis to each review
+	hideSpoilers bool // Leave out likely spoilers before paginating; implies analyze
+}
+
+// parseReviewOptions reads the all, analyze and hide_spoilers query parameters;
+// on failure an error response has already been written and ok is false
+func parseReviewOptions(w http.ResponseWriter, r *http.Request) (opts reviewOptions, ok bool) {
+	params := []struct {
+		name  string
+		value *bool
+	}{
+		{"all", &opts.all},
+		{"analyze", &opts.analyze},
+		{"hide_spoilers", &opts.hideSpoilers},
+	}
+	for _, param := range params {
+		value := r.URL.Query().Get(param.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", fmt.Sprintf("invalid %s parameter: must be true or false", param.name))
+			return opts, false
+		}
+		*param.value = parsed
+	}
+	opts.analyze = opts.analyze || opts.hideSpoilers
+	return opts, true
+}
+
+// analyzeReviews attaches an offline analysis to each review when opts asks for
+// one, leaving out likely spoilers when they are hidden
+func (h *ReviewHandler) analyzeReviews(ctx context.Context, mediaType models.SearchItemType, mediaID int, reviews []models.Review, opts reviewOptions) []models.Review {
+	if !opts.analyze {
+		return reviews
+	}
+
+	analyzer := reviewanalysis.NewAnalyzer(h.characters(ctx, mediaType, mediaID))
+	kept := make([]models.Review, 0, len(reviews))
+	for _, review := range reviews {
+		analysis := analyzer.Analyze(review.Content)
+		if opts.hideSpoilers && analysis.SpoilerLikelihood >= reviewanalysis.SpoilerThreshold {
+			continue
+		}
+		review.Analysis = &analysis
+		kept = append(kept, review)
+	}
+	return kept
+}
+
+// characters returns the characters of a title's cast for spoiler detection. Without
+// credits, only spoiler keywords are looked for.
+func (h *ReviewHandler) characters(ctx context.Context, mediaType models.SearchItemType, mediaID int) []string {
+	if h.credits == nil {
+		return nil
+	}
+
+	if mediaType == models.SearchItemTypeTV {
+		credits, err := h.credits.GetTVShowCredits(ctx, mediaID)
+		if err != nil {
+			log.Printf("Failed to get credits of tv %d for spoiler detection: %v", mediaID, err)
+			return nil
+		}
+		return reviewanalysis.TVCharacters(credits)
+	}
+
+	credits, err := h.credits.GetMovieCredits(ctx, mediaID)
+	if err != nil {
+		log.Printf("Failed to get credits of movie %d for spoiler detection: %v", mediaID, err)
+		return nil
+	}
+	return reviewanalysis.MovieCharacters(credits)
+}
+
+// writeAllReviews fetches every page of a title's TMDb reviews, up to
+// services.MaxReviewPages of them, and writes them rendered for display in the
+// order the sort_by and sort_order query parameters ask for. With hidden
+// spoilers, the total counts only the reviews kept.
+func (h *ReviewHandler) writeAllReviews(w http.ResponseWriter, r *http.Request, mediaType models.SearchItemType, mediaID int, opts reviewOptions) {
+	order := models.RenderedReviewSort{
+		SortBy:    r.URL.Query().Get("sort_by"),
+		SortOrder: r.URL.Query().Get("sort_order"),
+	}
+	if err := validate.Struct(order); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", validationMessage(err))
+		return
+	}
+
+	log.Printf("Fetching all %s reviews for ID: %d", mediaType, mediaID)
+	all, err := services.AllReviews(r.Context(), h.tmdbClient, mediaType, mediaID)
+	if err != nil {
+		log.Printf("Failed to get all %s reviews for ID %d: %v", mediaType, mediaID, err)
+		writeReviewError(w, err, string(mediaType), mediaID)
+		return
+	}
+
+	analyzed := h.analyzeReviews(r.Context(), mediaType, mediaID, all.Results, opts)
+	reviews := make([]models.RenderedReview, len(analyzed))
+	for i, review := range analyzed {
+		reviews[i] = renderReview(review)
+	}
+	sortRenderedReviews(reviews, order)
+
+	// Hidden spoilers are not counted, so the total is what is left of the
+	// fetched reviews
+	total := all.TotalResults
+	if opts.hideSpoilers {
+		total = len(reviews)
+	}
+
+	log.Printf("Successfully retrieved all %s reviews for ID %d: %d of %d reviews", mediaType, mediaID, len(reviews), total)
+
+	writeJSONResponse(w, http.StatusOK, models.RenderedReviewList{
+		ID:           mediaID,
+		Results:      reviews,
+		TotalResults: total,
+		Truncated:    all.TotalPages > services.MaxReviewPages,
+	})
+}
+
+// renderReview renders a TMDb review's Markdown content for display
+func renderReview(review models.Review) models.RenderedReview {
+	rendered := reviewtext.Render(review.Content)
+	return models.RenderedReview{
+		Review:      review,
+		ContentHTML: rendered.HTML,
+		ContentText: rendered.Text,
+		Excerpt:     reviewtext.Excerpt(rendered.Text, reviewtext.ExcerptLength),
+		Length:      utf8.RuneCountInString(rendered.Text),
+	}
+}
+
+// sortRenderedReviews orders reviews as order asks for, newest, highest rated or
+// longest first by default. Reviews without a rating go last when sorting by rating.
+func sortRenderedReviews(reviews []models.RenderedReview, order models.RenderedReviewSort) {
+	if order.SortBy == "" {
+		return
+	}
+
+	key := func(review models.RenderedReview) (float64, bool) {
+		switch order.SortBy {
+		case "updated_at":
+			return float64(review.UpdatedAt.UnixNano()), true
+		case "rating":
+			if review.AuthorDetails.Rating == nil {
+				return 0, false
+			}
+			return *review.AuthorDetails.Rating, true
+		case "length":
+			return float64(review.Length), true
+		default:
+			return float64(review.CreatedAt.UnixNano()), true
+		}
+	}
+
+	sort.SliceStable(reviews, func(i, j int) bool {
+		a, aOK := key(reviews[i])
+		b, bOK := key(reviews[j])
+		if aOK != bOK {
+			return aOK
+		}
+		if order.SortOrder == "asc" {
+			return a < b
+		}
+		return a > b
+	})
+}
@@ -6,11 +6,14 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/takeshi-arihori/movie-api/internal/models"
+	"github.com/takeshi-arihori/movie-api/internal/reviewanalysis"
+	"github.com/takeshi-arihori/movie-api/internal/reviewtext"
 	"github.com/takeshi-arihori/movie-api/internal/services"
 )
 
@@ -334,6 +337,329 @@ func TestReviewHandler_GetTVReviews(t *testing.T) {
 	}
 }
 
+// pagedReviewClient serves numbered review pages for cursor pagination tests.
+// When spoilerEvery is set, every spoilerEvery-th review gives the ending away.
+type pagedReviewClient struct {
+	total        int
+	spoilerEvery int
+}
+
+func (c *pagedReviewClient) GetMovieReviews(ctx context.Context, movieID int, page int) (*models.MovieReviews, error) {
+	var results []models.Review
+	for i := (page-1)*20 + 1; i <= page*20 && i <= c.total; i++ {
+		review := models.Review{ID: fmt.Sprintf("review-%d", i), Author: "author"}
+		if c.spoilerEvery > 0 && i%c.spoilerEvery == 0 {
+			review.Content = "Spoiler alert: the killer is the narrator all along."
+		}
+		results = append(results, review)
+	}
+	return &models.MovieReviews{
+		ID:           movieID,
+		Page:         page,
+		Results:      results,
+		TotalPages:   (c.total + 19) / 20,
+		TotalResults: c.total,
+	}, nil
+}
+
+func (c *pagedReviewClient) GetTVShowReviews(ctx context.Context, tvID int, page int) (*models.TVReviews, error) {
+	return nil, &services.TMDbError{StatusCode: 404, StatusMessage: "Not found"}
+}
+
+func TestReviewHandler_CursorPagination(t *testing.T) {
+	handler := NewReviewHandler(&pagedReviewClient{total: 45})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/movies/123/reviews?limit=30", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "123"})
+	w := httptest.NewRecorder()
+
+	handler.GetMovieReviews(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var page models.CursorPage[models.Review]
+	if err := json.NewDecoder(w.Body).Decode(&page); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(page.Results) != 30 || page.TotalResults != 45 || page.NextCursor == "" {
+		t.Errorf("expected 30 of 45 reviews with next_cursor, got %d of %d (cursor %q)", len(page.Results), page.TotalResults, page.NextCursor)
+	}
+
+	// Cursor is bound to the movie it was issued for
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/movies/456/reviews?cursor="+page.NextCursor, nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "456"})
+	w = httptest.NewRecorder()
+	handler.GetMovieReviews(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for foreign cursor, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	// Not found is reported in cursor mode too
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/tv/999/reviews?limit=10", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "999"})
+	w = httptest.NewRecorder()
+	handler.GetTVReviews(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestReviewHandler_AllReviews(t *testing.T) {
+	get := func(t *testing.T, handler *ReviewHandler, query string) (int, models.RenderedReviewList) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/movies/123/reviews"+query, nil)
+		req = mux.SetURLVars(req, map[string]string{"id": "123"})
+		w := httptest.NewRecorder()
+		handler.GetMovieReviews(w, req)
+
+		var list models.RenderedReviewList
+		if w.Code == http.StatusOK {
+			if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+		}
+		return w.Code, list
+	}
+
+	// Every page is fetched
+	code, list := get(t, NewReviewHandler(&pagedReviewClient{total: 45}), "?all=true")
+	if code != http.StatusOK || len(list.Results) != 45 || list.Results[44].ID != "review-45" || list.Truncated {
+		t.Errorf("expected all 45 reviews in order, got status %d with %d reviews", code, len(list.Results))
+	}
+
+	// Pages beyond the cap are left out
+	code, list = get(t, NewReviewHandler(&pagedReviewClient{total: 20*services.MaxReviewPages + 5}), "?all=true")
+	if code != http.StatusOK || len(list.Results) != 20*services.MaxReviewPages || !list.Truncated {
+		t.Errorf("expected %d truncated reviews, got status %d with %d reviews (truncated %v)", 20*services.MaxReviewPages, code, len(list.Results), list.Truncated)
+	}
+
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	handler := NewReviewHandler(&MockReviewClient{movieReviews: &models.MovieReviews{
+		ID: 123, Page: 1, TotalPages: 1, TotalResults: 3,
+		Results: []models.Review{
+			{ID: "short", Content: "**Great**", CreatedAt: day(2), AuthorDetails: models.AuthorDetails{Rating: floatPtr(9)}},
+			{ID: "unrated", Content: "No rating, but <em>plenty</em> to say about it", CreatedAt: day(3)},
+			{ID: "long", Content: strings.Repeat("A long review. ", 30), CreatedAt: day(1), AuthorDetails: models.AuthorDetails{Rating: floatPtr(6)}},
+		},
+	}})
+
+	code, list = get(t, handler, "?all=true")
+	if code != http.StatusOK || len(list.Results) != 3 {
+		t.Fatalf("expected 3 reviews, got status %d with %d reviews", code, len(list.Results))
+	}
+	if r := list.Results[0]; r.ContentHTML != "<p><strong>Great</strong></p>" || r.ContentText != "Great" || r.Excerpt != "Great" || r.Length != 5 {
+		t.Errorf("expected rendered content, got %+v", r)
+	}
+	if r := list.Results[2]; len([]rune(r.Excerpt)) > reviewtext.ExcerptLength || !strings.HasSuffix(r.Excerpt, "…") {
+		t.Errorf("expected a shortened excerpt, got %q", r.Excerpt)
+	}
+
+	tests := []struct {
+		query          string
+		expectedStatus int
+		order          string
+	}{
+		{"?all=true&sort_by=rating", http.StatusOK, "short,long,unrated"},
+		{"?all=true&sort_by=rating&sort_order=asc", http.StatusOK, "long,short,unrated"},
+		{"?all=true&sort_by=created_at", http.StatusOK, "unrated,short,long"},
+		{"?all=true&sort_by=length&sort_order=asc", http.StatusOK, "short,unrated,long"},
+		{"?all=true&sort_by=votes", http.StatusBadRequest, ""},
+		{"?all=maybe", http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			code, list := get(t, handler, tt.query)
+			if code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, code)
+			}
+			var ids []string
+			for _, review := range list.Results {
+				ids = append(ids, review.ID)
+			}
+			if got := strings.Join(ids, ","); got != tt.order {
+				t.Errorf("expected %s, got %s", tt.order, got)
+			}
+		})
+	}
+
+	// Not found is reported in all mode too
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/tv/999/reviews?all=true", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "999"})
+	w := httptest.NewRecorder()
+	NewReviewHandler(&pagedReviewClient{total: 45}).GetTVReviews(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+// fakeCreditsClient is a CreditsClient with a fixed cast
+type fakeCreditsClient struct {
+	characters []string
+}
+
+func (c *fakeCreditsClient) GetMovieCredits(ctx context.Context, movieID int) (*models.MovieCredits, error) {
+	credits := &models.MovieCredits{ID: movieID}
+	for i, character := range c.characters {
+		credits.Cast = append(credits.Cast, models.CastMember{Character: character, Order: i})
+	}
+	return credits, nil
+}
+
+func (c *fakeCreditsClient) GetTVShowCredits(ctx context.Context, tvID int) (*models.TVCredits, error) {
+	return nil, &services.TMDbError{StatusCode: 500, StatusMessage: "Internal error"}
+}
+
+func TestReviewHandler_Analysis(t *testing.T) {
+	reviews := []models.Review{
+		{ID: "praise", Content: "An absolutely brilliant film with a great cast."},
+		{ID: "named", Content: "In the end, Tyler Durden is not who he seems."},
+		{ID: "spoiler", Content: "Spoiler alert: the killer is the narrator all along."},
+		{ID: "japanese", Content: "つまらなかった。時間の無駄でした。"},
+	}
+	handler := NewReviewHandler(&MockReviewClient{
+		movieReviews: &models.MovieReviews{ID: 550, Page: 1, TotalPages: 1, TotalResults: len(reviews), Results: reviews},
+		tvReviews:    &models.TVReviews{ID: 1399, Page: 1, TotalPages: 1, TotalResults: len(reviews), Results: reviews},
+	})
+	handler.SetCredits(&fakeCreditsClient{characters: []string{"The Narrator", "Tyler Durden"}})
+
+	get := func(t *testing.T, path, query string, serve http.HandlerFunc) []models.Review {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, path+query, nil)
+		req = mux.SetURLVars(req, map[string]string{"id": "550"})
+		w := httptest.NewRecorder()
+		serve(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+
+		var resp struct {
+			Results []models.Review `json:"results"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return resp.Results
+	}
+	ids := func(reviews []models.Review) string {
+		var ids []string
+		for _, review := range reviews {
+			ids = append(ids, review.ID)
+		}
+		return strings.Join(ids, ",")
+	}
+
+	// Analysis is only attached when asked for
+	for _, review := range get(t, "/api/v1/movies/550/reviews", "", handler.GetMovieReviews) {
+		if review.Analysis != nil {
+			t.Errorf("expected no analysis by default, got %+v", review.Analysis)
+		}
+	}
+
+	analyzed := get(t, "/api/v1/movies/550/reviews", "?analyze=true", handler.GetMovieReviews)
+	if len(analyzed) != len(reviews) {
+		t.Fatalf("expected %d reviews, got %d", len(reviews), len(analyzed))
+	}
+	for _, review := range analyzed {
+		if review.Analysis == nil {
+			t.Fatalf("expected analysis of %s", review.ID)
+		}
+	}
+	if a := analyzed[0].Analysis; a.Language != "en" || a.SentimentLabel != models.SentimentPositive || a.SpoilerLikelihood >= 0.5 {
+		t.Errorf("expected a positive English review without spoilers, got %+v", a)
+	}
+	if a := analyzed[3].Analysis; a.Language != "ja" || a.SentimentLabel != models.SentimentNegative {
+		t.Errorf("expected a negative Japanese review, got %+v", a)
+	}
+
+	// Hidden spoilers include those that only name a character, in every mode
+	for _, query := range []string{"?hide_spoilers=true", "?hide_spoilers=true&limit=10", "?hide_spoilers=true&all=true"} {
+		if got := ids(get(t, "/api/v1/movies/550/reviews", query, handler.GetMovieReviews)); got != "praise,japanese" {
+			t.Errorf("expected spoilers to be hidden with %s, got %s", query, got)
+		}
+	}
+
+	// Without credits, only keywords count
+	if got := ids(get(t, "/api/v1/tv/1399/reviews", "?hide_spoilers=true", handler.GetTVReviews)); got != "praise,named,japanese" {
+		t.Errorf("expected keyword spoilers to be hidden without credits, got %s", got)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/movies/550/reviews?hide_spoilers=yes", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "550"})
+	w := httptest.NewRecorder()
+	handler.GetMovieReviews(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestReviewHandler_HideSpoilersTotals(t *testing.T) {
+	// 15 of the 45 reviews are spoilers
+	handler := NewReviewHandler(&pagedReviewClient{total: 45, spoilerEvery: 3})
+	get := func(t *testing.T, query string, resp interface{}) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/movies/550/reviews"+query, nil)
+		req = mux.SetURLVars(req, map[string]string{"id": "550"})
+		w := httptest.NewRecorder()
+		handler.GetMovieReviews(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		if err := json.NewDecoder(w.Body).Decode(resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+	}
+
+	// Pages are filled from the reviews that are kept and count only them
+	for _, tt := range []struct {
+		query   string
+		results int
+	}{
+		{"?hide_spoilers=true", 20},
+		{"?hide_spoilers=true&page=2", 10},
+		{"?hide_spoilers=true&page=3", 0},
+	} {
+		var page models.MovieReviews
+		get(t, tt.query, &page)
+		if len(page.Results) != tt.results || page.TotalResults != 30 || page.TotalPages != 2 {
+			t.Errorf("%s: expected %d of 30 reviews on 2 pages, got %d of %d on %d pages",
+				tt.query, tt.results, len(page.Results), page.TotalResults, page.TotalPages)
+		}
+	}
+
+	var first models.CursorPage[models.Review]
+	get(t, "?hide_spoilers=true&limit=25", &first)
+	if len(first.Results) != 25 || first.TotalResults != 30 || first.NextCursor == "" {
+		t.Fatalf("expected 25 of 30 reviews with next_cursor, got %d of %d (cursor %q)", len(first.Results), first.TotalResults, first.NextCursor)
+	}
+	var second models.CursorPage[models.Review]
+	get(t, "?hide_spoilers=true&cursor="+first.NextCursor, &second)
+	if len(second.Results) != 5 || second.TotalResults != 30 || second.NextCursor != "" {
+		t.Errorf("expected the last 5 of 30 reviews, got %d of %d (cursor %q)", len(second.Results), second.TotalResults, second.NextCursor)
+	}
+
+	var list models.RenderedReviewList
+	get(t, "?hide_spoilers=true&all=true", &list)
+	if len(list.Results) != 30 || list.TotalResults != 30 {
+		t.Errorf("expected all 30 kept reviews counted, got %d of %d", len(list.Results), list.TotalResults)
+	}
+	for _, review := range append(first.Results, second.Results...) {
+		if review.Analysis == nil || review.Analysis.SpoilerLikelihood >= reviewanalysis.SpoilerThreshold {
+			t.Errorf("expected %s to be analyzed and spoiler-free, got %+v", review.ID, review.Analysis)
+		}
+	}
+
+	// Offsets into the filtered reviews do not carry over to the unfiltered ones
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/movies/550/reviews?cursor="+first.NextCursor, nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "550"})
+	w := httptest.NewRecorder()
+	handler.GetMovieReviews(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for a hide_spoilers cursor without hide_spoilers, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
 func TestReviewHandler_MethodNotAllowed(t *testing.T) {
 	mockClient := &MockReviewClient{}
 	handler := NewReviewHandler(mockClient)
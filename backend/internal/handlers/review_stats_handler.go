@@ -0,0 +1,66 @@
+// Package handlers provides HTTP handlers for combined review statistics.
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/takeshi-arihori/movie-api/internal/models"
+)
+
+// ReviewStatsService computes the review statistics of a title
+type ReviewStatsService interface {
+	Stats(ctx context.Context, mediaType string, mediaID int) (*models.ReviewStats, error)
+}
+
+// ReviewStatsHandler handles review statistics HTTP requests
+type ReviewStatsHandler struct {
+	stats ReviewStatsService
+}
+
+// NewReviewStatsHandler creates a new ReviewStatsHandler instance
+func NewReviewStatsHandler(stats ReviewStatsService) *ReviewStatsHandler {
+	return &ReviewStatsHandler{
+		stats: stats,
+	}
+}
+
+// GetMovieReviewStats handles GET /api/v1/movies/{id}/review_stats requests
+func (h *ReviewStatsHandler) GetMovieReviewStats(w http.ResponseWriter, r *http.Request) {
+	h.writeStats(w, r, models.SearchItemTypeMovie)
+}
+
+// GetTVReviewStats handles GET /api/v1/tv/{id}/review_stats requests
+func (h *ReviewStatsHandler) GetTVReviewStats(w http.ResponseWriter, r *http.Request) {
+	h.writeStats(w, r, models.SearchItemTypeTV)
+}
+
+// writeStats writes the statistics of a title's TMDb and approved user reviews
+func (h *ReviewStatsHandler) writeStats(w http.ResponseWriter, r *http.Request, mediaType models.SearchItemType) {
+	if !allowGet(w, r) {
+		return
+	}
+
+	mediaID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || mediaID <= 0 {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", fmt.Sprintf("%s ID must be a positive integer", mediaTypeName(mediaType)))
+		return
+	}
+
+	stats, err := h.stats.Stats(r.Context(), string(mediaType), mediaID)
+	if isTMDbNotFound(err) {
+		writeErrorResponse(w, http.StatusNotFound, "title_not_found", fmt.Sprintf("%s with ID %d not found", mediaTypeName(mediaType), mediaID))
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to get review stats for %s %d: %v", mediaType, mediaID, err)
+		writeUpstreamError(w, err, "Failed to retrieve review statistics")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, stats)
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/takeshi-arihori/movie-api/internal/models"
+	"github.com/takeshi-arihori/movie-api/internal/services"
+)
+
+// mockReviewStatsService returns fixed statistics for movie 550 and TV show 1399
+type mockReviewStatsService struct{}
+
+func (m *mockReviewStatsService) Stats(ctx context.Context, mediaType string, mediaID int) (*models.ReviewStats, error) {
+	switch {
+	case mediaType == "movie" && mediaID == 550, mediaType == "tv" && mediaID == 1399:
+		return &models.ReviewStats{MediaID: mediaID, MediaType: mediaType, TotalReviews: 3, AverageRating: 7.5, TMDbReviews: 2, UserReviews: 1}, nil
+	case mediaID == 500:
+		return nil, errors.New("connection refused")
+	default:
+		return nil, &services.TMDbError{StatusCode: 404, StatusMessage: "Not found"}
+	}
+}
+
+func TestReviewStatsHandler(t *testing.T) {
+	handler := NewReviewStatsHandler(&mockReviewStatsService{})
+
+	tests := []struct {
+		name           string
+		tv             bool
+		id             string
+		expectedStatus int
+	}{
+		{"movie", false, "550", http.StatusOK},
+		{"TV show", true, "1399", http.StatusOK},
+		{"unknown movie", false, "999999", http.StatusNotFound},
+		{"TMDb unavailable", true, "500", http.StatusInternalServerError},
+		{"invalid ID", false, "0", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/movies/"+tt.id+"/review_stats", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": tt.id})
+			w := httptest.NewRecorder()
+
+			if tt.tv {
+				handler.GetTVReviewStats(w, req)
+			} else {
+				handler.GetMovieReviewStats(w, req)
+			}
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+			var stats models.ReviewStats
+			if err := json.NewDecoder(w.Body).Decode(&stats); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if stats.TotalReviews != 3 || (tt.tv && stats.MediaType != "tv") {
+				t.Errorf("Unexpected stats: %+v", stats)
+			}
+		})
+	}
+}
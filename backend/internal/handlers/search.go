@@ -2,31 +2,102 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
+	"net/netip"
 	"strconv"
 	"strings"
+	"sync/atomic"
 
 	"github.com/takeshi-arihori/movie-api/internal/models"
+	"github.com/takeshi-arihori/movie-api/internal/pagination"
+	"github.com/takeshi-arihori/movie-api/internal/ranking"
 	"github.com/takeshi-arihori/movie-api/internal/services"
+	"github.com/takeshi-arihori/movie-api/internal/suggest"
+	"github.com/takeshi-arihori/movie-api/internal/textnorm"
 )
 
+// SearchRecorder records searches for analytics without blocking the request
+type SearchRecorder interface {
+	Record(entry models.SearchHistoryEntry) bool
+}
+
+// DatabasePinger checks database connectivity for the health check
+type DatabasePinger interface {
+	Ping(ctx context.Context) error
+}
+
+// SearchCatalogue searches the local database when TMDb is unavailable
+type SearchCatalogue interface {
+	SearchTitles(ctx context.Context, opts models.SearchOptions) (*models.MultiSearchResponse, error)
+}
+
 // SearchHandler handles search-related HTTP requests
 type SearchHandler struct {
-	tmdbClient *services.TMDbClient
+	tmdbClient  *services.TMDbClient
+	suggestions *suggest.Service
+	recorder    SearchRecorder
+	database    DatabasePinger
+	catalogue   SearchCatalogue
+	proxies     []netip.Prefix
 }
 
 // NewSearchHandler creates a new SearchHandler instance
-func NewSearchHandler(tmdbClient *services.TMDbClient) *SearchHandler {
+func NewSearchHandler(tmdbClient *services.TMDbClient, suggestions *suggest.Service) *SearchHandler {
 	return &SearchHandler{
-		tmdbClient: tmdbClient,
+		tmdbClient:  tmdbClient,
+		suggestions: suggestions,
 	}
 }
 
+// SetDatabase includes database connectivity in the health check. It must be
+// called before the handler starts serving requests.
+func (h *SearchHandler) SetDatabase(database DatabasePinger) {
+	h.database = database
+}
+
+// SetRecorder enables search history recording. It must be called before the
+// handler starts serving requests.
+func (h *SearchHandler) SetRecorder(recorder SearchRecorder) {
+	h.recorder = recorder
+}
+
+// SetTrustedProxies sets the reverse proxies whose X-Forwarded-For and X-Real-IP
+// headers are believed when recording the client IP of searches. It must be
+// called before the handler starts serving requests.
+func (h *SearchHandler) SetTrustedProxies(proxies []netip.Prefix) {
+	h.proxies = proxies
+}
+
+// SetCatalogue enables searching the local catalogue when TMDb is unavailable.
+// It must be called before the handler starts serving requests.
+func (h *SearchHandler) SetCatalogue(catalogue SearchCatalogue) {
+	h.catalogue = catalogue
+}
+
 
 // Search handles multi-search requests
 // GET /api/v1/search?query=<query>&type=<type>&page=<page>&language=<language>
+//
+// Optional filters: year and primary_release_year (movie), first_air_date_year (tv),
+// include_adult and region (all types)
+//
+// With type=all, facets=true runs the movie, TV and person searches concurrently and
+// returns per-type totals with the top per_type results of each
+//
+// limit and cursor switch to cursor-based pagination: the response contains up to
+// limit results stitched from as many TMDb pages as needed, plus a next_cursor
+//
+// sort re-ranks the fetched results: relevance, popularity, weighted_rating or
+// release_date (default: tmdb, the upstream order)
+//
+// A query with no results is retried with normalized variants (NFKC, kana folding,
+// long-vowel trimming, romaji); query_variant and matched_query report which matched
+//
+// When TMDb is unavailable, movies and TV shows are searched in the local catalogue;
+// such responses set stale and the X-Data-Source: stale-cache header
 func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
 	// Enable CORS
 	w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -64,20 +135,28 @@ func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Search request: query=%s, type=%s, page=%d, language=%s", 
 		searchReq.Query, searchReq.Type, searchReq.Page, searchReq.Language)
 
-	// Perform search based on type
-	var result *models.MultiSearchResponse
-	if searchReq.Type == "all" {
-		result, err = h.tmdbClient.MultiSearch(r.Context(), searchReq.Query, searchReq.Page, searchReq.Language)
-	} else {
-		result, err = h.tmdbClient.SearchByType(r.Context(), searchReq.Type, searchReq.Query, searchReq.Page, searchReq.Language)
+	opts := searchReq.ToSearchOptions()
+
+	if searchReq.Facets {
+		h.facetedSearch(w, r, searchReq, opts)
+		return
 	}
 
+	if searchReq.UsesCursor() {
+		h.cursorSearch(w, r, searchReq, opts)
+		return
+	}
+
+	// Perform search, retrying normalized variants of the query if nothing matched
+	result, variant, stale, err := h.searchVariants(r.Context(), opts)
 	if err != nil {
 		log.Printf("Search failed: %v", err)
 		writeErrorResponse(w, http.StatusInternalServerError, "search_error", "Failed to perform search")
 		return
 	}
 
+	ranking.Sort(result.Results, ranking.Mode(searchReq.Sort), variant.Query, ranking.SearchResultFields)
+
 	// Build response
 	response := models.APISearchResponse{
 		Query:        searchReq.Query,
@@ -87,6 +166,16 @@ func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
 		TotalResults: result.TotalResults,
 		Results:      result.Results,
 		Language:     searchReq.Language,
+		Sort:         searchReq.Sort,
+		QueryVariant: string(variant.Kind),
+		MatchedQuery: matchedQuery(searchReq.Query, variant),
+		Stale:        stale,
+	}
+
+	if stale {
+		markStale(w)
+	} else if searchReq.Page == 1 {
+		h.recordSearch(r, searchReq, response.TotalResults)
 	}
 
 	log.Printf("Search completed: found %d results (page %d/%d)", 
@@ -95,6 +184,270 @@ func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
 	writeJSONResponse(w, http.StatusOK, response)
 }
 
+// facetedSearch performs a faceted search and writes the response
+func (h *SearchHandler) facetedSearch(w http.ResponseWriter, r *http.Request, searchReq *models.SearchRequest, opts models.SearchOptions) {
+	var result *models.FacetedSearchResponse
+	var variant textnorm.Variant
+	for _, variant = range textnorm.Variants(opts.Query) {
+		variantOpts := opts
+		variantOpts.Query = variant.Query
+
+		var err error
+		result, err = h.tmdbClient.FacetedSearch(r.Context(), variantOpts, searchReq.PerType)
+		if err != nil && h.catalogue != nil && canServeStale(err) {
+			log.Printf("Faceted search failed, searching the local catalogue: %v", err)
+			result, err = h.staleFacetedSearch(r.Context(), variantOpts, searchReq.PerType)
+		}
+		if err != nil {
+			log.Printf("Faceted search failed: %v", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "search_error", "Failed to perform search")
+			return
+		}
+		if result.TotalResults > 0 || result.Partial {
+			break
+		}
+	}
+	if result.TotalResults == 0 {
+		variant = textnorm.Variant{Kind: textnorm.Original, Query: opts.Query}
+	}
+
+	result.Query = searchReq.Query
+	result.QueryVariant = string(variant.Kind)
+	result.MatchedQuery = matchedQuery(searchReq.Query, variant)
+
+	if result.Stale {
+		markStale(w)
+	} else {
+		h.recordSearch(r, searchReq, result.TotalResults)
+	}
+
+	log.Printf("Faceted search completed: %d total results (partial=%v)", result.TotalResults, result.Partial)
+
+	writeJSONResponse(w, http.StatusOK, result)
+}
+
+// cursorSearch performs a cursor-paginated search and writes the response
+func (h *SearchHandler) cursorSearch(w http.ResponseWriter, r *http.Request, searchReq *models.SearchRequest, opts models.SearchOptions) {
+	cursor, err := pagination.Parse(searchReq.Cursor, "search", opts.Type, opts.Query, opts.Language, fmt.Sprintf("%+v", opts.Filter))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_cursor", "Cursor is invalid or does not match this search")
+		return
+	}
+
+	limit := searchReq.Limit
+	if limit == 0 {
+		limit = pagination.DefaultLimit
+	}
+
+	var stale atomic.Bool
+	fetchWindow := func(cursor pagination.Cursor) (*pagination.Window[models.MultiSearchResult], error) {
+		pageOpts := opts
+		if cursor.Query != "" {
+			pageOpts.Query = cursor.Query
+		}
+		fetchPage := func(ctx context.Context, page int) (*pagination.Page[models.MultiSearchResult], error) {
+			pageOpts := pageOpts
+			pageOpts.Page = page
+
+			result, pageStale, err := h.search(ctx, pageOpts)
+			if err != nil {
+				return nil, err
+			}
+			if pageStale {
+				stale.Store(true)
+			}
+			return &pagination.Page[models.MultiSearchResult]{
+				Results:      result.Results,
+				TotalPages:   result.TotalPages,
+				TotalResults: result.TotalResults,
+			}, nil
+		}
+		return pagination.Fetch(r.Context(), cursor, limit, fetchPage, searchResultKey)
+	}
+
+	// Variants are only tried on the first window; later windows keep the query
+	// that matched, which the cursor carries
+	variants := textnorm.Variants(opts.Query)
+	if searchReq.Cursor != "" {
+		variants = []textnorm.Variant{variantFor(opts.Query, cursor.Query)}
+	}
+
+	var window *pagination.Window[models.MultiSearchResult]
+	var variant textnorm.Variant
+	for _, variant = range variants {
+		if variant.Kind != textnorm.Original {
+			cursor.Query = variant.Query
+		}
+		window, err = fetchWindow(cursor)
+		if err != nil {
+			log.Printf("Search failed: %v", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "search_error", "Failed to perform search")
+			return
+		}
+		if window.TotalResults > 0 {
+			break
+		}
+	}
+	if window.TotalResults == 0 {
+		variant = variants[0]
+	}
+
+	ranking.Sort(window.Results, ranking.Mode(searchReq.Sort), variant.Query, ranking.SearchResultFields)
+
+	response := models.APISearchResponse{
+		Query:        searchReq.Query,
+		Type:         searchReq.Type,
+		TotalResults: window.TotalResults,
+		Results:      window.Results,
+		Language:     searchReq.Language,
+		Limit:        limit,
+		NextCursor:   window.NextCursor,
+		Sort:         searchReq.Sort,
+		QueryVariant: string(variant.Kind),
+		MatchedQuery: matchedQuery(searchReq.Query, variant),
+		Stale:        stale.Load(),
+	}
+
+	if response.Stale {
+		markStale(w)
+	} else if searchReq.Cursor == "" {
+		h.recordSearch(r, searchReq, response.TotalResults)
+	}
+
+	log.Printf("Search completed: found %d results (offset %d, limit %d)", len(response.Results), cursor.Offset, limit)
+
+	writeJSONResponse(w, http.StatusOK, response)
+}
+
+// recordSearch queues a search for the search history. Only the first page or
+// window of a search is recorded, so paging through results counts once. Searches
+// served from the local catalogue are not recorded, as their result counts would
+// skew the zero-result statistics.
+func (h *SearchHandler) recordSearch(r *http.Request, searchReq *models.SearchRequest, totalResults int) {
+	if h.recorder == nil {
+		return
+	}
+	h.recorder.Record(models.SearchHistoryEntry{
+		Query:        searchReq.Query,
+		SearchType:   searchReq.Type,
+		ResultsCount: totalResults,
+		IPAddress:    clientIP(r, h.proxies),
+		UserAgent:    r.UserAgent(),
+	})
+}
+
+// search runs a single TMDb search for the options' type. If TMDb fails, the local
+// catalogue is searched instead and stale is true.
+func (h *SearchHandler) search(ctx context.Context, opts models.SearchOptions) (result *models.MultiSearchResponse, stale bool, err error) {
+	if opts.Type == "all" {
+		result, err = h.tmdbClient.MultiSearch(ctx, opts)
+	} else {
+		result, err = h.tmdbClient.SearchByType(ctx, opts)
+	}
+	if err == nil || h.catalogue == nil || !canServeStale(err) {
+		return result, false, err
+	}
+
+	log.Printf("Search failed, searching the local catalogue: %v", err)
+	result, err = h.catalogue.SearchTitles(ctx, opts)
+	return result, err == nil, err
+}
+
+// staleFacetedSearch builds a faceted search from the local catalogue. People are
+// not cached, so the person facet reports an error and the response is partial.
+func (h *SearchHandler) staleFacetedSearch(ctx context.Context, opts models.SearchOptions, perType int) (*models.FacetedSearchResponse, error) {
+	response := &models.FacetedSearchResponse{
+		Query:    opts.Query,
+		Type:     "all",
+		Language: opts.Language,
+		PerType:  perType,
+		Partial:  true,
+		Stale:    true,
+	}
+	for _, itemType := range []models.SearchItemType{models.SearchItemTypeMovie, models.SearchItemTypeTV} {
+		typeOpts := opts
+		typeOpts.Type = string(itemType)
+		result, err := h.catalogue.SearchTitles(ctx, typeOpts)
+		if err != nil {
+			return nil, err
+		}
+		facet := models.SearchFacet{
+			Type:         itemType,
+			TotalResults: result.TotalResults,
+			TotalPages:   result.TotalPages,
+			Results:      result.Results,
+		}
+		if perType > 0 && len(facet.Results) > perType {
+			facet.Results = facet.Results[:perType]
+		}
+		response.Facets = append(response.Facets, facet)
+		response.TotalResults += facet.TotalResults
+	}
+	response.Facets = append(response.Facets, models.SearchFacet{
+		Type:    models.SearchItemTypePerson,
+		Results: []models.MultiSearchResult{},
+		Error:   "person search is unavailable while TMDb is unreachable",
+	})
+	return response, nil
+}
+
+// searchVariants searches for the original query and, while nothing matches, each
+// normalized variant of it in turn. It returns the first result with matches, or
+// the original query's empty result when no variant matched. stale reports whether
+// the returned result came from the local catalogue.
+func (h *SearchHandler) searchVariants(ctx context.Context, opts models.SearchOptions) (*models.MultiSearchResponse, textnorm.Variant, bool, error) {
+	variants := textnorm.Variants(opts.Query)
+	var empty *models.MultiSearchResponse
+	var emptyStale bool
+	for _, variant := range variants {
+		variantOpts := opts
+		variantOpts.Query = variant.Query
+
+		result, stale, err := h.search(ctx, variantOpts)
+		if err != nil {
+			return nil, variant, false, err
+		}
+		if result.TotalResults > 0 {
+			if variant.Kind != textnorm.Original {
+				log.Printf("Search: no results for %q, matched %s variant %q", opts.Query, variant.Kind, variant.Query)
+			}
+			return result, variant, stale, nil
+		}
+		if empty == nil {
+			empty, emptyStale = result, stale
+		}
+	}
+	return empty, variants[0], emptyStale, nil
+}
+
+// variantFor identifies the variant of query that produced matched, as carried in a
+// cursor. An empty matched query means the original query was used.
+func variantFor(query, matched string) textnorm.Variant {
+	for _, variant := range textnorm.Variants(query) {
+		if variant.Query == matched {
+			return variant
+		}
+	}
+	if matched == "" {
+		return textnorm.Variant{Kind: textnorm.Original, Query: query}
+	}
+	return textnorm.Variant{Kind: textnorm.Normalized, Query: matched}
+}
+
+// matchedQuery returns the variant's query when it differs from the one the client
+// sent, for the matched_query response field
+func matchedQuery(query string, variant textnorm.Variant) string {
+	if variant.Kind == textnorm.Original || variant.Query == query {
+		return ""
+	}
+	return variant.Query
+}
+
+// searchResultKey identifies a search result across pages for de-duplication
+func searchResultKey(result models.MultiSearchResult) string {
+	return fmt.Sprintf("%s:%d", result.MediaType, result.ID)
+}
+
 // parseSearchRequest parses HTTP request parameters into SearchRequest
 func (h *SearchHandler) parseSearchRequest(r *http.Request) (*models.SearchRequest, error) {
 	query := r.URL.Query()
@@ -116,16 +469,90 @@ func (h *SearchHandler) parseSearchRequest(r *http.Request) (*models.SearchReque
 
 	// Parse year parameter (for movies)
 	if yearStr := query.Get("year"); yearStr != "" {
-		year, err := strconv.Atoi(yearStr)
-		if err != nil || year < 1900 || year > 2100 {
-			return nil, fmt.Errorf("invalid year parameter: must be between 1900 and 2100")
+		year, err := parseYear(yearStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid year parameter: %w", err)
 		}
 		searchReq.Year = year
 	}
 
+	// Parse primary_release_year parameter (for movies)
+	if yearStr := query.Get("primary_release_year"); yearStr != "" {
+		year, err := parseYear(yearStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid primary_release_year parameter: %w", err)
+		}
+		searchReq.PrimaryReleaseYear = year
+	}
+
+	// Parse first_air_date_year parameter (for TV shows)
+	if yearStr := query.Get("first_air_date_year"); yearStr != "" {
+		year, err := parseYear(yearStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid first_air_date_year parameter: %w", err)
+		}
+		searchReq.FirstAirDateYear = year
+	}
+
+	// Parse include_adult parameter
+	if adultStr := query.Get("include_adult"); adultStr != "" {
+		includeAdult, err := strconv.ParseBool(adultStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid include_adult parameter: must be true or false")
+		}
+		searchReq.IncludeAdult = includeAdult
+	}
+
+	// Parse facets parameter
+	if facetsStr := query.Get("facets"); facetsStr != "" {
+		facets, err := strconv.ParseBool(facetsStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid facets parameter: must be true or false")
+		}
+		searchReq.Facets = facets
+	}
+
+	// Parse per_type parameter (range validated later)
+	if perTypeStr := query.Get("per_type"); perTypeStr != "" {
+		perType, err := strconv.Atoi(perTypeStr)
+		if err != nil || perType < 1 {
+			return nil, fmt.Errorf("invalid per_type parameter: must be a positive integer")
+		}
+		searchReq.PerType = perType
+	}
+
+	// Parse cursor pagination parameters
+	limit, err := parseLimit(query.Get("limit"))
+	if err != nil {
+		return nil, err
+	}
+	searchReq.Limit = limit
+	searchReq.Cursor = strings.TrimSpace(query.Get("cursor"))
+
+	// Parse sort parameter
+	if sortStr := query.Get("sort"); sortStr != "" {
+		mode, err := ranking.ParseMode(sortStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sort parameter: %w", err)
+		}
+		searchReq.Sort = string(mode)
+	}
+
+	// Parse region parameter (ISO 3166-1, validated later)
+	searchReq.Region = strings.ToUpper(strings.TrimSpace(query.Get("region")))
+
 	return searchReq, nil
 }
 
+// parseYear parses a release year query parameter
+func parseYear(value string) (int, error) {
+	year, err := strconv.Atoi(value)
+	if err != nil || year < 1900 || year > 2100 {
+		return 0, fmt.Errorf("must be between 1900 and 2100")
+	}
+	return year, nil
+}
+
 // HealthCheck provides a health check endpoint
 func (h *SearchHandler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 	// Enable CORS
@@ -139,8 +566,20 @@ func (h *SearchHandler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The API keeps serving TMDb data without the database, so a database outage
+	// degrades rather than fails the health check
+	status, databaseStatus := "healthy", "disabled"
+	if h.database != nil {
+		databaseStatus = "up"
+		if err := h.database.Ping(r.Context()); err != nil {
+			log.Printf("Health check: database ping failed: %v", err)
+			status, databaseStatus = "degraded", "down"
+		}
+	}
+
 	healthStatus := map[string]interface{}{
-		"status":    "healthy",
+		"status":    status,
+		"database":  databaseStatus,
 		"service":   "movie-api",
 		"version":   "1.0.0",
 		"timestamp": "2023-01-01T00:00:00Z", // In production, use actual timestamp
@@ -152,24 +591,32 @@ func (h *SearchHandler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 	writeJSONResponse(w, http.StatusOK, healthStatus)
 }
 
-// GetSearchSuggestions provides search suggestions (placeholder for future implementation)
+// GetSearchSuggestions handles autocomplete requests
+// GET /api/v1/search/suggestions?query=<prefix>&limit=<limit>
+//
+// Suggestions are ranked and typed (movie, tv, person, or query for past searches).
+// Responses are cached per prefix and marked cacheable so clients can debounce freely.
 func (h *SearchHandler) GetSearchSuggestions(w http.ResponseWriter, r *http.Request) {
-	// Enable CORS
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-
-	// Handle preflight requests
-	if r.Method == http.MethodOptions {
-		w.WriteHeader(http.StatusOK)
+	if !allowGet(w, r) {
 		return
 	}
 
-	// For now, return a placeholder response
-	suggestions := map[string]interface{}{
-		"suggestions": []string{},
-		"message":     "Search suggestions feature coming soon",
+	limit := suggest.DefaultLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > suggest.MaxLimit {
+			writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter",
+				fmt.Sprintf("limit must be an integer between 1 and %d", suggest.MaxLimit))
+			return
+		}
+		limit = parsed
 	}
 
-	writeJSONResponse(w, http.StatusOK, suggestions)
-}
\ No newline at end of file
+	query := r.URL.Query().Get("query")
+	response := h.suggestions.Suggest(r.Context(), query, limit)
+
+	if !response.Partial {
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(suggest.CacheTTL.Seconds())))
+	}
+	writeJSONResponse(w, http.StatusOK, response)
+}
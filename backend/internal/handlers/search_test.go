@@ -2,14 +2,21 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/takeshi-arihori/movie-api/internal/config"
 	"github.com/takeshi-arihori/movie-api/internal/models"
 	"github.com/takeshi-arihori/movie-api/internal/services"
+	"github.com/takeshi-arihori/movie-api/internal/suggest"
 )
 
 // createTestSearchHandler creates a SearchHandler configured for testing
@@ -21,7 +28,7 @@ func createTestSearchHandler(mockServer *httptest.Server) *SearchHandler {
 		},
 	}
 	tmdbClient := services.NewTMDbClient(cfg)
-	return NewSearchHandler(tmdbClient)
+	return NewSearchHandler(tmdbClient, suggest.NewService(tmdbClient))
 }
 
 // createMockTMDbServer creates a test HTTP server with predefined TMDb responses
@@ -169,6 +176,445 @@ func TestSearchHandler_SearchWithType(t *testing.T) {
 	}
 }
 
+// TestSearchHandler_SearchWithFilters tests that search filters reach TMDb
+func TestSearchHandler_SearchWithFilters(t *testing.T) {
+	var received url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(models.MovieSearchResponse{Page: 1, Results: []models.Movie{}})
+	}))
+	defer server.Close()
+
+	handler := createTestSearchHandler(server)
+
+	req := httptest.NewRequest("GET", "/api/v1/search?query=Matrix&type=movie&year=1999&include_adult=true&region=jp", nil)
+	w := httptest.NewRecorder()
+
+	handler.Search(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if got := received.Get("year"); got != "1999" {
+		t.Errorf("Expected year '1999', got '%s'", got)
+	}
+	if got := received.Get("include_adult"); got != "true" {
+		t.Errorf("Expected include_adult 'true', got '%s'", got)
+	}
+	if got := received.Get("region"); got != "JP" {
+		t.Errorf("Expected region 'JP', got '%s'", got)
+	}
+}
+
+// TestSearchHandler_FacetedSearch tests faceted "all" search
+func TestSearchHandler_FacetedSearch(t *testing.T) {
+	responses := map[string]interface{}{
+		"/search/movie": models.MovieSearchResponse{
+			Page:         1,
+			Results:      []models.Movie{{ID: 550, Title: "Fight Club", OriginalTitle: "Fight Club", OriginalLanguage: "en"}},
+			TotalPages:   1,
+			TotalResults: 1,
+		},
+		"/search/person": models.PersonSearchResponse{
+			Page:         1,
+			Results:      []models.Person{{ID: 819, Name: "Edward Norton"}},
+			TotalPages:   1,
+			TotalResults: 1,
+		},
+	}
+
+	server := createMockTMDbServer(t, responses)
+	defer server.Close()
+
+	handler := createTestSearchHandler(server)
+
+	req := httptest.NewRequest("GET", "/api/v1/search?query=Fight+Club&facets=true&per_type=3", nil)
+	w := httptest.NewRecorder()
+
+	handler.Search(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response models.FacetedSearchResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if response.PerType != 3 {
+		t.Errorf("Expected per_type 3, got %d", response.PerType)
+	}
+	if len(response.Facets) != 3 {
+		t.Fatalf("Expected 3 facets, got %d", len(response.Facets))
+	}
+	if !response.Partial {
+		t.Error("Expected partial response when TV search fails")
+	}
+	if response.Facets[1].Type != models.SearchItemTypeTV || response.Facets[1].Error == "" {
+		t.Errorf("Expected TV facet to report an error, got %+v", response.Facets[1])
+	}
+	if response.TotalResults != 2 {
+		t.Errorf("Expected total results 2, got %d", response.TotalResults)
+	}
+}
+
+// TestSearchHandler_FacetedSearchHidesTransportErrors tests that a failed facet
+// does not expose the request URL, which carries the API key
+func TestSearchHandler_FacetedSearchHidesTransportErrors(t *testing.T) {
+	mock := createMockTMDbServer(t, map[string]interface{}{
+		"/search/movie": models.MovieSearchResponse{Page: 1, Results: []models.Movie{{ID: 550, Title: "Fight Club"}}, TotalPages: 1, TotalResults: 1},
+	})
+	defer mock.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/search/tv" {
+			// Drop the connection so the client sees a transport error
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				conn.Close()
+			}
+			return
+		}
+		mock.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	handler := createTestSearchHandler(server)
+	req := httptest.NewRequest("GET", "/api/v1/search?query=Fight+Club&facets=true", nil)
+	w := httptest.NewRecorder()
+	handler.Search(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	body := w.Body.String()
+	if strings.Contains(body, "api_key") || strings.Contains(body, "test-api-key") {
+		t.Errorf("Expected the API key to stay out of the response, got %s", body)
+	}
+	var response models.FacetedSearchResponse
+	if err := json.NewDecoder(strings.NewReader(body)).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Facets[1].Error != "tv search failed" {
+		t.Errorf("Expected a generic TV facet error, got %q", response.Facets[1].Error)
+	}
+}
+
+// TestSearchHandler_CursorSearch tests cursor-based pagination across TMDb pages
+func TestSearchHandler_CursorSearch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		results := make([]models.Movie, 0, 20)
+		for id := (page-1)*20 + 1; id <= page*20; id++ {
+			results = append(results, models.Movie{ID: id, Title: "Movie"})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(models.MovieSearchResponse{
+			Page:         page,
+			Results:      results,
+			TotalPages:   10,
+			TotalResults: 200,
+		})
+	}))
+	defer server.Close()
+
+	handler := createTestSearchHandler(server)
+
+	req := httptest.NewRequest("GET", "/api/v1/search?query=Movie&type=movie&limit=50", nil)
+	w := httptest.NewRecorder()
+
+	handler.Search(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response models.APISearchResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if len(response.Results) != 50 {
+		t.Errorf("Expected 50 results, got %d", len(response.Results))
+	}
+	if response.Limit != 50 || response.NextCursor == "" {
+		t.Errorf("Expected limit 50 with next_cursor, got limit %d cursor %q", response.Limit, response.NextCursor)
+	}
+	if response.Page != 0 {
+		t.Errorf("Expected no page number in cursor mode, got %d", response.Page)
+	}
+
+	// Next window continues where the previous one ended
+	req = httptest.NewRequest("GET", "/api/v1/search?query=Movie&type=movie&limit=50&cursor="+response.NextCursor, nil)
+	w = httptest.NewRecorder()
+	handler.Search(w, req)
+
+	var next models.APISearchResponse
+	if err := json.NewDecoder(w.Body).Decode(&next); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(next.Results) == 0 || next.Results[0].ID != 51 {
+		t.Errorf("Expected next window to start at ID 51")
+	}
+
+	// Cursor cannot be reused for a different query
+	req = httptest.NewRequest("GET", "/api/v1/search?query=Other&type=movie&cursor="+response.NextCursor, nil)
+	w = httptest.NewRecorder()
+	handler.Search(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for mismatched cursor, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+// TestSearchHandler_SearchVariants tests retrying zero-result queries with normalized variants
+func TestSearchHandler_SearchVariants(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query().Get("query")
+		queries = append(queries, query)
+
+		response := models.MovieSearchResponse{Page: 1, Results: []models.Movie{}}
+		if query == "ドラゴンボール" || query == "matrix" {
+			response.Results = []models.Movie{{ID: 1, Title: query}}
+			response.TotalPages = 1
+			response.TotalResults = 1
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	handler := createTestSearchHandler(server)
+
+	tests := []struct {
+		name            string
+		query           string
+		expectedVariant string
+		expectedMatched string
+		expectedResults int
+	}{
+		{
+			name:            "original query matches",
+			query:           "ドラゴンボール",
+			expectedVariant: "original",
+			expectedResults: 1,
+		},
+		{
+			name:            "hiragana folded to katakana",
+			query:           "どらごんぼーる",
+			expectedVariant: "katakana",
+			expectedMatched: "ドラゴンボール",
+			expectedResults: 1,
+		},
+		{
+			name:            "full-width latin normalized",
+			query:           "ＭＡＴＲＩＸ",
+			expectedVariant: "normalized",
+			expectedMatched: "matrix",
+			expectedResults: 1,
+		},
+		{
+			name:            "no variant matches",
+			query:           "存在しない",
+			expectedVariant: "original",
+			expectedResults: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queries = nil
+			req := httptest.NewRequest("GET", "/api/v1/search?type=movie&query="+url.QueryEscape(tt.query), nil)
+			w := httptest.NewRecorder()
+
+			handler.Search(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+			}
+
+			var response models.APISearchResponse
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+
+			if response.Query != tt.query {
+				t.Errorf("Expected query %q, got %q", tt.query, response.Query)
+			}
+			if response.QueryVariant != tt.expectedVariant {
+				t.Errorf("Expected query_variant %q, got %q (tried %v)", tt.expectedVariant, response.QueryVariant, queries)
+			}
+			if response.MatchedQuery != tt.expectedMatched {
+				t.Errorf("Expected matched_query %q, got %q", tt.expectedMatched, response.MatchedQuery)
+			}
+			if len(response.Results) != tt.expectedResults {
+				t.Errorf("Expected %d results, got %d", tt.expectedResults, len(response.Results))
+			}
+		})
+	}
+
+	// Cursor mode keeps the matched variant for later windows
+	req := httptest.NewRequest("GET", "/api/v1/search?type=movie&limit=1&query="+url.QueryEscape("どらごんぼーる"), nil)
+	w := httptest.NewRecorder()
+	handler.Search(w, req)
+
+	var response models.APISearchResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.QueryVariant != "katakana" || response.MatchedQuery != "ドラゴンボール" {
+		t.Errorf("Expected katakana variant in cursor mode, got %q %q", response.QueryVariant, response.MatchedQuery)
+	}
+}
+
+// mockSearchRecorder collects recorded searches
+type mockSearchRecorder struct {
+	entries []models.SearchHistoryEntry
+}
+
+func (m *mockSearchRecorder) Record(entry models.SearchHistoryEntry) bool {
+	m.entries = append(m.entries, entry)
+	return true
+}
+
+// TestSearchHandler_RecordsHistory tests that first pages of searches are recorded
+func TestSearchHandler_RecordsHistory(t *testing.T) {
+	responses := map[string]interface{}{
+		"/search/movie": models.MovieSearchResponse{
+			Page:         1,
+			Results:      []models.Movie{{ID: 603, Title: "The Matrix"}},
+			TotalPages:   2,
+			TotalResults: 21,
+		},
+	}
+
+	server := createMockTMDbServer(t, responses)
+	defer server.Close()
+
+	handler := createTestSearchHandler(server)
+	recorder := &mockSearchRecorder{}
+	handler.SetRecorder(recorder)
+	// httptest requests come from 192.0.2.1
+	handler.SetTrustedProxies([]netip.Prefix{netip.MustParsePrefix("192.0.2.0/24"), netip.MustParsePrefix("10.0.0.0/8")})
+
+	req := httptest.NewRequest("GET", "/api/v1/search?query=Matrix&type=movie", nil)
+	req.Header.Set("User-Agent", "test-agent")
+	req.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.1")
+	w := httptest.NewRecorder()
+	handler.Search(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if len(recorder.entries) != 1 {
+		t.Fatalf("Expected 1 recorded search, got %d", len(recorder.entries))
+	}
+	entry := recorder.entries[0]
+	if entry.Query != "Matrix" || entry.SearchType != "movie" || entry.ResultsCount != 21 {
+		t.Errorf("Unexpected recorded search: %+v", entry)
+	}
+	if entry.IPAddress != "203.0.113.7" || entry.UserAgent != "test-agent" {
+		t.Errorf("Expected client IP and user agent, got %q %q", entry.IPAddress, entry.UserAgent)
+	}
+
+	// Later pages of the same search are not recorded again
+	req = httptest.NewRequest("GET", "/api/v1/search?query=Matrix&type=movie&page=2", nil)
+	w = httptest.NewRecorder()
+	handler.Search(w, req)
+	if len(recorder.entries) != 1 {
+		t.Errorf("Expected page 2 not to be recorded, got %d entries", len(recorder.entries))
+	}
+}
+
+// TestClientIP tests that forwarding headers are only believed from trusted proxies
+func TestClientIP(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		realIP     string
+		expected   string
+	}{
+		{"direct client", "203.0.113.7:1234", "", "", "203.0.113.7"},
+		{"forged header from a client", "203.0.113.7:1234", "198.51.100.1", "198.51.100.1", "203.0.113.7"},
+		{"trusted proxy", "10.0.0.1:1234", "203.0.113.7", "", "203.0.113.7"},
+		{"chain of trusted proxies", "10.0.0.1:1234", "203.0.113.7, 10.0.0.2", "", "203.0.113.7"},
+		{"hop forged before the proxy", "10.0.0.1:1234", "198.51.100.1, 203.0.113.7", "", "203.0.113.7"},
+		{"X-Real-IP from a trusted proxy", "10.0.0.1:1234", "", "203.0.113.7", "203.0.113.7"},
+		{"invalid forwarded address", "10.0.0.1:1234", "unknown", "", ""},
+		{"invalid remote address", "pipe", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/search", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if tt.realIP != "" {
+				req.Header.Set("X-Real-IP", tt.realIP)
+			}
+			if got := clientIP(req, trusted); got != tt.expected {
+				t.Errorf("Expected client IP %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestSearchHandler_SearchSort tests server-side re-ranking of search results
+func TestSearchHandler_SearchSort(t *testing.T) {
+	responses := map[string]interface{}{
+		"/search/movie": models.MovieSearchResponse{
+			Page: 1,
+			Results: []models.Movie{
+				{ID: 1, Title: "Obscure", VoteAverage: 10.0, VoteCount: 1},
+				{ID: 2, Title: "Classic", VoteAverage: 8.5, VoteCount: 20000},
+			},
+			TotalPages:   1,
+			TotalResults: 2,
+		},
+	}
+
+	server := createMockTMDbServer(t, responses)
+	defer server.Close()
+
+	handler := createTestSearchHandler(server)
+
+	req := httptest.NewRequest("GET", "/api/v1/search?query=test&type=movie&sort=weighted_rating", nil)
+	w := httptest.NewRecorder()
+
+	handler.Search(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response models.APISearchResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if response.Sort != "weighted_rating" {
+		t.Errorf("Expected sort 'weighted_rating', got '%s'", response.Sort)
+	}
+	if len(response.Results) != 2 || response.Results[0].ID != 2 {
+		t.Errorf("Expected well-rated classic first, got %+v", response.Results)
+	}
+
+	// Default ranking is reported as tmdb
+	req = httptest.NewRequest("GET", "/api/v1/search?query=test&type=movie", nil)
+	w = httptest.NewRecorder()
+	handler.Search(w, req)
+	json.NewDecoder(w.Body).Decode(&response)
+	if response.Sort != "tmdb" {
+		t.Errorf("Expected default sort 'tmdb', got '%s'", response.Sort)
+	}
+}
+
 // TestSearchHandler_SearchValidation tests request validation
 func TestSearchHandler_SearchValidation(t *testing.T) {
 	server := createMockTMDbServer(t, map[string]interface{}{})
@@ -212,6 +658,72 @@ func TestSearchHandler_SearchValidation(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedError:  "invalid_request",
 		},
+		{
+			name:           "Year on TV search",
+			query:          "query=test&type=tv&year=2008",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "validation_error",
+		},
+		{
+			name:           "Year on multi search",
+			query:          "query=test&year=1999",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "validation_error",
+		},
+		{
+			name:           "First air date year on movie search",
+			query:          "query=test&type=movie&first_air_date_year=2008",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "validation_error",
+		},
+		{
+			name:           "Invalid include_adult",
+			query:          "query=test&include_adult=maybe",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid_request",
+		},
+		{
+			name:           "Facets with specific type",
+			query:          "query=test&type=movie&facets=true",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "validation_error",
+		},
+		{
+			name:           "Per type too large",
+			query:          "query=test&facets=true&per_type=50",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "validation_error",
+		},
+		{
+			name:           "Page with limit",
+			query:          "query=test&page=2&limit=50",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "validation_error",
+		},
+		{
+			name:           "Limit too large",
+			query:          "query=test&limit=1000",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid_request",
+		},
+		{
+			name:           "Invalid sort",
+			query:          "query=test&sort=vote_average",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid_request",
+		},
+		{
+			name:           "Sort with facets",
+			query:          "query=test&facets=true&sort=popularity",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "validation_error",
+		},
+		{
+			name:           "Invalid region",
+			query:          "query=test&region=japan",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "validation_error",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -317,31 +829,118 @@ func TestSearchHandler_HealthCheck(t *testing.T) {
 	if service, ok := response["service"].(string); !ok || service != "movie-api" {
 		t.Errorf("Expected service 'movie-api', got %v", response["service"])
 	}
+	if database, ok := response["database"].(string); !ok || database != "disabled" {
+		t.Errorf("Expected database 'disabled', got %v", response["database"])
+	}
+
+	// A failing database degrades the service without failing the check
+	handler.SetDatabase(failingPinger{})
+	w = httptest.NewRecorder()
+	handler.HealthCheck(w, req)
+
+	response = nil
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if w.Code != http.StatusOK || response["status"] != "degraded" || response["database"] != "down" {
+		t.Errorf("Expected degraded status with database down, got %d %v", w.Code, response)
+	}
+}
+
+// failingPinger is a DatabasePinger whose database is unreachable
+type failingPinger struct{}
+
+func (failingPinger) Ping(ctx context.Context) error {
+	return errors.New("connection refused")
 }
 
 // TestSearchHandler_GetSearchSuggestions tests search suggestions endpoint
 func TestSearchHandler_GetSearchSuggestions(t *testing.T) {
-	server := createMockTMDbServer(t, map[string]interface{}{})
+	responses := map[string]interface{}{
+		"/movie/popular": models.PopularMovies{
+			Page:         1,
+			Results:      []models.Movie{{ID: 603, Title: "The Matrix", OriginalTitle: "The Matrix", Popularity: 80}},
+			TotalPages:   1,
+			TotalResults: 1,
+		},
+		"/trending/person/week": models.SearchResponse[models.Person]{
+			Page:         1,
+			Results:      []models.Person{{ID: 6384, Name: "Keanu Reeves", Popularity: 70}},
+			TotalPages:   1,
+			TotalResults: 1,
+		},
+	}
+
+	server := createMockTMDbServer(t, responses)
 	defer server.Close()
 
 	handler := createTestSearchHandler(server)
+	if err := handler.suggestions.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
 
-	req := httptest.NewRequest("GET", "/api/v1/search/suggestions", nil)
-	w := httptest.NewRecorder()
+	tests := []struct {
+		name           string
+		url            string
+		expectedStatus int
+		expectedIDs    []int
+	}{
+		{
+			name:           "no query",
+			url:            "/api/v1/search/suggestions",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []int{},
+		},
+		{
+			name:           "movie prefix",
+			url:            "/api/v1/search/suggestions?query=the+ma",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []int{603},
+		},
+		{
+			name:           "person word prefix",
+			url:            "/api/v1/search/suggestions?query=reeves&limit=5",
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []int{6384},
+		},
+		{
+			name:           "invalid limit",
+			url:            "/api/v1/search/suggestions?query=the&limit=100",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
 
-	handler.GetSearchSuggestions(w, req)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.url, nil)
+			w := httptest.NewRecorder()
 
-	if w.Code != http.StatusOK {
-		t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
-	}
+			handler.GetSearchSuggestions(w, req)
 
-	var response map[string]interface{}
-	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
-		t.Fatalf("Failed to decode response: %v", err)
-	}
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response models.SearchSuggestionsResponse
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
 
-	if suggestions, ok := response["suggestions"].([]interface{}); !ok || len(suggestions) != 0 {
-		t.Errorf("Expected empty suggestions array, got %v", response["suggestions"])
+			if response.Suggestions == nil || len(response.Suggestions) != len(tt.expectedIDs) {
+				t.Fatalf("Expected %d suggestions, got %v", len(tt.expectedIDs), response.Suggestions)
+			}
+			for i, id := range tt.expectedIDs {
+				if response.Suggestions[i].ID != id {
+					t.Errorf("Expected suggestion %d to be %d, got %+v", i, id, response.Suggestions[i])
+				}
+			}
+			if w.Header().Get("Cache-Control") == "" {
+				t.Error("Expected Cache-Control header on suggestions")
+			}
+		})
 	}
 }
 
@@ -546,6 +1145,81 @@ func TestWriteErrorResponse(t *testing.T) {
 	}
 }
 
+// fakeSearchCatalogue is a SearchCatalogue that matches every query with one title
+// of the searched type
+type fakeSearchCatalogue struct{}
+
+func (fakeSearchCatalogue) SearchTitles(ctx context.Context, opts models.SearchOptions) (*models.MultiSearchResponse, error) {
+	response := &models.MultiSearchResponse{Page: 1, Results: []models.MultiSearchResult{}}
+	if opts.Type != "tv" && opts.Type != "person" {
+		title := "The Matrix"
+		response.Results = append(response.Results, models.MultiSearchResult{ID: 603, MediaType: models.SearchItemTypeMovie, Title: &title})
+	}
+	response.TotalResults = len(response.Results)
+	response.TotalPages = 1
+	return response, nil
+}
+
+// TestSearchHandler_StaleCatalogue tests that searches fall back to the local
+// catalogue when TMDb is unavailable
+func TestSearchHandler_StaleCatalogue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(models.ErrorResponse{StatusCode: 503, StatusMessage: "Service unavailable"})
+	}))
+	defer server.Close()
+
+	handler := createTestSearchHandler(server)
+	recorder := &mockSearchRecorder{}
+	handler.SetRecorder(recorder)
+
+	// Without a catalogue the search fails
+	req := httptest.NewRequest("GET", "/api/v1/search?query=matrix", nil)
+	w := httptest.NewRecorder()
+	handler.Search(w, req)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected status %d without a catalogue, got %d", http.StatusInternalServerError, w.Code)
+	}
+
+	handler.SetCatalogue(fakeSearchCatalogue{})
+	for _, query := range []string{"query=matrix", "query=matrix&limit=5"} {
+		req := httptest.NewRequest("GET", "/api/v1/search?"+query, nil)
+		w := httptest.NewRecorder()
+		handler.Search(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected status %d, got %d", query, http.StatusOK, w.Code)
+		}
+		if source := w.Header().Get(DataSourceHeader); source != DataSourceStaleCache {
+			t.Errorf("%s: expected %s header %q, got %q", query, DataSourceHeader, DataSourceStaleCache, source)
+		}
+		var response models.APISearchResponse
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if !response.Stale || len(response.Results) != 1 || response.Results[0].ID != 603 {
+			t.Errorf("%s: expected stale response with the cached movie, got %+v", query, response)
+		}
+	}
+
+	// Faceted search reports the person facet as unavailable
+	req = httptest.NewRequest("GET", "/api/v1/search?query=matrix&type=all&facets=true", nil)
+	w = httptest.NewRecorder()
+	handler.Search(w, req)
+
+	var faceted models.FacetedSearchResponse
+	if err := json.NewDecoder(w.Body).Decode(&faceted); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if !faceted.Stale || !faceted.Partial || faceted.TotalResults != 1 || len(faceted.Facets) != 3 {
+		t.Errorf("Expected stale partial faceted response with 1 result, got %+v", faceted)
+	}
+
+	if len(recorder.entries) != 0 {
+		t.Errorf("Expected stale searches not to be recorded, got %d entries", len(recorder.entries))
+	}
+}
+
 // Helper functions for pointer types
 func float64Ptr(f float64) *float64 {
 	return &f
@@ -0,0 +1,256 @@
+// Package handlers provides the handler for titles several people have credits on.
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+)
+
+// GetSharedCredits handles GET /api/v1/people/shared_credits requests. ids lists
+// the people; role and job give either one requirement for everyone or one per
+// person, so that "directed by A starring B" is ids=A,B&role=crew,cast&job=Director,
+// where the empty job after the last comma leaves B's job unconstrained. Titles
+// are returned when every person has a matching credit on them.
+func (h *PersonHandler) GetSharedCredits(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+
+	req, err := parseSharedCreditsRequest(r.URL.Query())
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", err.Error())
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", validationMessage(err))
+		return
+	}
+	for _, person := range req.People {
+		if person.Job != "" && person.Role == models.CreditRoleCast {
+			writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", "invalid job parameter: jobs only apply to crew credits")
+			return
+		}
+	}
+
+	credits, personID, err := h.fetchPeopleCredits(r.Context(), req.People)
+	if err != nil {
+		log.Printf("Failed to get credits for person %d: %v", personID, err)
+		if isTMDbNotFound(err) {
+			writeErrorResponse(w, http.StatusNotFound, "person_not_found", fmt.Sprintf("Person with ID %d not found", personID))
+			return
+		}
+		writeUpstreamError(w, err, "Failed to retrieve shared credits")
+		return
+	}
+
+	results := sharedTitles(req.People, credits)
+	writeJSONResponse(w, http.StatusOK, models.SharedCredits{
+		People:       req.People,
+		TotalResults: len(results),
+		Results:      results,
+	})
+}
+
+// parseSharedCreditsRequest reads the ids, role and job query parameters. The
+// number of people and the roles are checked by validating the request afterwards.
+func parseSharedCreditsRequest(query url.Values) (models.SharedCreditsRequest, error) {
+	var req models.SharedCreditsRequest
+
+	ids := query.Get("ids")
+	if ids == "" {
+		return req, fmt.Errorf("ids parameter is required")
+	}
+	for _, value := range strings.Split(ids, ",") {
+		personID, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || personID < 1 {
+			return req, fmt.Errorf("invalid ids parameter: must be a comma-separated list of person IDs")
+		}
+		for _, person := range req.People {
+			if person.PersonID == personID {
+				return req, fmt.Errorf("invalid ids parameter: person %d is listed twice", personID)
+			}
+		}
+		req.People = append(req.People, models.CreditRequirement{PersonID: personID, Role: models.CreditRoleAny})
+	}
+
+	for _, param := range []struct {
+		name string
+		set  func(person *models.CreditRequirement, value string)
+	}{
+		{"role", func(person *models.CreditRequirement, value string) {
+			if value != "" {
+				person.Role = strings.ToLower(value)
+			}
+		}},
+		{"job", func(person *models.CreditRequirement, value string) { person.Job = value }},
+	} {
+		if !query.Has(param.name) {
+			continue
+		}
+		values := strings.Split(query.Get(param.name), ",")
+		if len(values) != 1 && len(values) != len(req.People) {
+			return req, fmt.Errorf("invalid %s parameter: must be a single value or one per person", param.name)
+		}
+		for i := range req.People {
+			param.set(&req.People[i], strings.TrimSpace(values[min(i, len(values)-1)]))
+		}
+	}
+
+	return req, nil
+}
+
+// personCredits holds the movie and TV credits of one person
+type personCredits struct {
+	movie *models.PersonMovieCredits
+	tv    *models.PersonTVCredits
+}
+
+// fetchPeopleCredits fetches the movie and TV credits of each person
+// concurrently. On failure, the first person whose credits could not be fetched
+// is returned with the error.
+func (h *PersonHandler) fetchPeopleCredits(ctx context.Context, people []models.CreditRequirement) (credits []personCredits, failedID int, err error) {
+	credits = make([]personCredits, len(people))
+	movieErrs := make([]error, len(people))
+	tvErrs := make([]error, len(people))
+
+	var wg sync.WaitGroup
+	for i, person := range people {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			credits[i].movie, movieErrs[i] = h.tmdbClient.GetPersonMovieCredits(ctx, person.PersonID)
+		}()
+		go func() {
+			defer wg.Done()
+			credits[i].tv, tvErrs[i] = h.tmdbClient.GetPersonTVCredits(ctx, person.PersonID)
+		}()
+	}
+	wg.Wait()
+
+	for i, person := range people {
+		for _, err := range []error{movieErrs[i], tvErrs[i]} {
+			if err != nil {
+				return nil, person.PersonID, err
+			}
+		}
+	}
+	return credits, 0, nil
+}
+
+// creditIndex collects the credits of one person that meet their requirement,
+// merged per title
+type creditIndex struct {
+	requirement models.CreditRequirement
+	order       []titleRef
+	titles      map[titleRef]*models.SharedCreditTitle
+	credits     map[titleRef]*models.PersonCredit
+}
+
+// add records a cast or crew credit on title if it meets the requirement. Jobs
+// only apply to crew credits, so no cast credit meets a requirement with a job.
+func (c *creditIndex) add(title models.SharedCreditTitle, character, job string, cast bool) {
+	switch {
+	case cast && (c.requirement.Role == models.CreditRoleCrew || c.requirement.Job != ""):
+		return
+	case !cast && c.requirement.Role == models.CreditRoleCast:
+		return
+	case !cast && c.requirement.Job != "" && !strings.EqualFold(job, c.requirement.Job):
+		return
+	}
+
+	ref := titleRef{mediaType: models.SearchItemType(title.MediaType), mediaID: title.ID}
+	credit, ok := c.credits[ref]
+	if !ok {
+		c.order = append(c.order, ref)
+		c.titles[ref] = &title
+		credit = &models.PersonCredit{PersonID: c.requirement.PersonID}
+		c.credits[ref] = credit
+	}
+	if cast && character != "" && !slices.Contains(credit.Characters, character) {
+		credit.Characters = append(credit.Characters, character)
+	}
+	if !cast && job != "" && !slices.Contains(credit.Jobs, job) {
+		credit.Jobs = append(credit.Jobs, job)
+	}
+}
+
+// indexCredits returns the credits of a person that meet their requirement
+func indexCredits(requirement models.CreditRequirement, credits personCredits) *creditIndex {
+	index := &creditIndex{
+		requirement: requirement,
+		titles:      map[titleRef]*models.SharedCreditTitle{},
+		credits:     map[titleRef]*models.PersonCredit{},
+	}
+	movie := func(id int, title string, releaseDate, posterPath *string, voteAverage float64, voteCount int, popularity float64) models.SharedCreditTitle {
+		shared := models.SharedCreditTitle{ID: id, MediaType: string(models.SearchItemTypeMovie), Title: title, PosterPath: posterPath, VoteAverage: voteAverage, VoteCount: voteCount, Popularity: popularity}
+		if releaseDate != nil {
+			shared.ReleaseDate = *releaseDate
+		}
+		return shared
+	}
+	tv := func(id int, name string, firstAirDate, posterPath *string, voteAverage float64, voteCount int, popularity float64) models.SharedCreditTitle {
+		shared := movie(id, name, firstAirDate, posterPath, voteAverage, voteCount, popularity)
+		shared.MediaType = string(models.SearchItemTypeTV)
+		return shared
+	}
+
+	for _, c := range credits.movie.Cast {
+		index.add(movie(c.ID, c.Title, c.ReleaseDate, c.PosterPath, c.VoteAverage, c.VoteCount, c.Popularity), c.Character, "", true)
+	}
+	for _, c := range credits.movie.Crew {
+		index.add(movie(c.ID, c.Title, c.ReleaseDate, c.PosterPath, c.VoteAverage, c.VoteCount, c.Popularity), "", c.Job, false)
+	}
+	for _, c := range credits.tv.Cast {
+		index.add(tv(c.ID, c.Name, c.FirstAirDate, c.PosterPath, c.VoteAverage, c.VoteCount, c.Popularity), c.Character, "", true)
+	}
+	for _, c := range credits.tv.Crew {
+		index.add(tv(c.ID, c.Name, c.FirstAirDate, c.PosterPath, c.VoteAverage, c.VoteCount, c.Popularity), "", c.Job, false)
+	}
+	return index
+}
+
+// sharedTitles returns the titles on which every person has a credit meeting
+// their requirement. Titles without a release date come first, then the latest.
+func sharedTitles(people []models.CreditRequirement, credits []personCredits) []models.SharedCreditTitle {
+	indexes := make([]*creditIndex, len(people))
+	for i, person := range people {
+		indexes[i] = indexCredits(person, credits[i])
+	}
+
+	results := []models.SharedCreditTitle{}
+	for _, ref := range indexes[0].order {
+		title := *indexes[0].titles[ref]
+		for _, index := range indexes {
+			credit, ok := index.credits[ref]
+			if !ok {
+				break
+			}
+			title.Credits = append(title.Credits, *credit)
+		}
+		if len(title.Credits) == len(indexes) {
+			results = append(results, title)
+		}
+	}
+
+	slices.SortStableFunc(results, func(a, b models.SharedCreditTitle) int {
+		switch {
+		case a.ReleaseDate == b.ReleaseDate:
+			return strings.Compare(a.Title, b.Title)
+		case a.ReleaseDate == "":
+			return -1
+		case b.ReleaseDate == "":
+			return 1
+		}
+		return strings.Compare(b.ReleaseDate, a.ReleaseDate)
+	})
+	return results
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+	"github.com/takeshi-arihori/movie-api/internal/services"
+)
+
+// fakePeopleClient serves the credits of several people. Person 500 makes TMDb
+// fail and unknown people are not found.
+type fakePeopleClient struct {
+	MockPersonClient
+	movies map[int]*models.PersonMovieCredits
+	tv     map[int]*models.PersonTVCredits
+}
+
+func (c *fakePeopleClient) GetPersonMovieCredits(ctx context.Context, personID int) (*models.PersonMovieCredits, error) {
+	if personID == 500 {
+		return nil, errors.New("connection refused")
+	}
+	if credits, ok := c.movies[personID]; ok {
+		return credits, nil
+	}
+	return nil, &services.TMDbError{StatusCode: 404, StatusMessage: "Not found"}
+}
+
+func (c *fakePeopleClient) GetPersonTVCredits(ctx context.Context, personID int) (*models.PersonTVCredits, error) {
+	if credits, ok := c.tv[personID]; ok {
+		return credits, nil
+	}
+	return &models.PersonTVCredits{ID: personID}, nil
+}
+
+// newFakePeopleClient returns the credits of a director (7) who casts the same
+// actor (8) in their films, and of an actor (9) who worked with both once
+func newFakePeopleClient() *fakePeopleClient {
+	cast := func(id int, title, date, character string) models.PersonMovieCast {
+		return models.PersonMovieCast{ID: id, Title: title, ReleaseDate: stringPtr(date), Character: character}
+	}
+	crew := func(id int, title, date, job string) models.PersonMovieCrew {
+		return models.PersonMovieCrew{ID: id, Title: title, ReleaseDate: stringPtr(date), Job: job}
+	}
+	undated := models.PersonMovieCast{ID: 3, Title: "Gamma", Character: "Lead"}
+
+	return &fakePeopleClient{
+		movies: map[int]*models.PersonMovieCredits{
+			7: {ID: 7,
+				Cast: []models.PersonMovieCast{cast(2, "Beta", "2015-05-01", "Cameo")},
+				Crew: []models.PersonMovieCrew{
+					crew(1, "Alpha", "2010-05-01", "Director"),
+					crew(1, "Alpha", "2010-05-01", "Writer"),
+					{ID: 3, Title: "Gamma", Job: "Director"},
+				},
+			},
+			8: {ID: 8, Cast: []models.PersonMovieCast{
+				cast(1, "Alpha", "2010-05-01", "Hero"),
+				cast(2, "Beta", "2015-05-01", "Sidekick"),
+				undated,
+			}},
+			9: {ID: 9, Cast: []models.PersonMovieCast{
+				cast(1, "Alpha", "2010-05-01", "Villain"),
+				cast(4, "Delta", "2020-05-01", "Hero"),
+			}},
+		},
+		tv: map[int]*models.PersonTVCredits{
+			7: {ID: 7, Crew: []models.PersonTVCrew{{ID: 1, Name: "Show", FirstAirDate: stringPtr("2012-01-01"), Job: "Executive Producer"}}},
+			8: {ID: 8, Cast: []models.PersonTVCast{{ID: 1, Name: "Show", FirstAirDate: stringPtr("2012-01-01"), Character: "Host"}}},
+		},
+	}
+}
+
+func TestPersonHandler_GetSharedCredits(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedError  string
+		expectedTitles []string
+	}{
+		{"any credit", "?ids=7,8", http.StatusOK, "", []string{"Gamma", "Beta", "Show", "Alpha"}},
+		{"directed by 7 starring 8", "?ids=7,8&role=crew,cast&job=Director,", http.StatusOK, "", []string{"Gamma", "Alpha"}},
+		{"both in the cast", "?ids=7,8&role=cast", http.StatusOK, "", []string{"Beta"}},
+		{"job for everyone", "?ids=7,8&job=director", http.StatusOK, "", []string{}},
+		{"three people", "?ids=7,8,9", http.StatusOK, "", []string{"Alpha"}},
+		{"missing ids", "", http.StatusBadRequest, "invalid_parameter", nil},
+		{"one person", "?ids=7", http.StatusBadRequest, "invalid_parameter", nil},
+		{"repeated person", "?ids=7,7", http.StatusBadRequest, "invalid_parameter", nil},
+		{"invalid ID", "?ids=7,abc", http.StatusBadRequest, "invalid_parameter", nil},
+		{"invalid role", "?ids=7,8&role=writer", http.StatusBadRequest, "invalid_parameter", nil},
+		{"roles not matching people", "?ids=7,8&role=cast,crew,any", http.StatusBadRequest, "invalid_parameter", nil},
+		{"job for a cast role", "?ids=7,8&role=cast&job=Director", http.StatusBadRequest, "invalid_parameter", nil},
+		{"person not found", "?ids=7,999", http.StatusNotFound, "person_not_found", nil},
+		{"TMDb unavailable", "?ids=7,500", http.StatusInternalServerError, "api_error", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewPersonHandler(newFakePeopleClient())
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/people/shared_credits"+tt.query, nil)
+			w := httptest.NewRecorder()
+			handler.GetSharedCredits(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedError != "" {
+				var errorResp ErrorResponse
+				if err := json.NewDecoder(w.Body).Decode(&errorResp); err != nil {
+					t.Fatalf("failed to decode error response: %v", err)
+				}
+				if errorResp.Error != tt.expectedError {
+					t.Errorf("expected error %q, got %q", tt.expectedError, errorResp.Error)
+				}
+				return
+			}
+
+			var shared models.SharedCredits
+			if err := json.NewDecoder(w.Body).Decode(&shared); err != nil {
+				t.Fatalf("failed to decode shared credits response: %v", err)
+			}
+			if shared.TotalResults != len(tt.expectedTitles) || len(shared.Results) != len(tt.expectedTitles) {
+				t.Fatalf("expected titles %v, got %+v", tt.expectedTitles, shared.Results)
+			}
+			for i, title := range shared.Results {
+				if title.Title != tt.expectedTitles[i] {
+					t.Errorf("expected titles %v, got %+v", tt.expectedTitles, shared.Results)
+				}
+				if len(title.Credits) != len(shared.People) {
+					t.Errorf("expected a credit for each person on %s, got %+v", title.Title, title.Credits)
+				}
+			}
+		})
+	}
+}
+
+func TestPersonHandler_GetSharedCredits_Credits(t *testing.T) {
+	handler := NewPersonHandler(newFakePeopleClient())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/people/shared_credits?ids=7,8", nil)
+	w := httptest.NewRecorder()
+	handler.GetSharedCredits(w, req)
+
+	var shared models.SharedCredits
+	if err := json.NewDecoder(w.Body).Decode(&shared); err != nil {
+		t.Fatalf("failed to decode shared credits response: %v", err)
+	}
+	if len(shared.People) != 2 || shared.People[0].Role != models.CreditRoleAny {
+		t.Errorf("expected both people with any role, got %+v", shared.People)
+	}
+
+	alpha := shared.Results[3]
+	if alpha.MediaType != "movie" || alpha.ReleaseDate != "2010-05-01" {
+		t.Errorf("expected the movie Alpha released 2010-05-01, got %+v", alpha)
+	}
+	director, actor := alpha.Credits[0], alpha.Credits[1]
+	if director.PersonID != 7 || len(director.Jobs) != 2 || director.Jobs[0] != "Director" || director.Jobs[1] != "Writer" {
+		t.Errorf("expected person 7 as director and writer, got %+v", director)
+	}
+	if actor.PersonID != 8 || len(actor.Characters) != 1 || actor.Characters[0] != "Hero" {
+		t.Errorf("expected person 8 as Hero, got %+v", actor)
+	}
+
+	if show := shared.Results[2]; show.MediaType != "tv" || show.Credits[1].Characters[0] != "Host" {
+		t.Errorf("expected the TV show with person 8 as Host, got %+v", show)
+	}
+}
@@ -0,0 +1,117 @@
+// Package handlers provides helpers for looking up the titles users save.
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+	"github.com/takeshi-arihori/movie-api/internal/services"
+)
+
+// titleLookupConcurrency bounds the concurrent title lookups made while
+// hydrating a list of saved titles
+const titleLookupConcurrency = 8
+
+// TitleDetailsClient looks up movie and TV show details, from TMDb or the title cache
+type TitleDetailsClient interface {
+	GetMovieDetails(ctx context.Context, movieID int) (*models.MovieDetails, error)
+	GetTVShowDetails(ctx context.Context, tvID int) (*models.TVShowDetails, error)
+}
+
+// titleRef identifies a movie or TV show
+type titleRef struct {
+	mediaType models.SearchItemType
+	mediaID   int
+}
+
+// lookupTitle returns the display fields of a movie or TV show
+func lookupTitle(ctx context.Context, titles TitleDetailsClient, mediaType models.SearchItemType, mediaID int) (models.TitleSummary, error) {
+	if mediaType == models.SearchItemTypeTV {
+		details, err := titles.GetTVShowDetails(ctx, mediaID)
+		if err != nil {
+			return models.TitleSummary{}, err
+		}
+		return models.TitleSummary{
+			Title:       details.Name,
+			PosterPath:  details.PosterPath,
+			ReleaseDate: details.FirstAirDate,
+			VoteAverage: details.VoteAverage,
+			VoteCount:   details.VoteCount,
+		}, nil
+	}
+
+	details, err := titles.GetMovieDetails(ctx, mediaID)
+	if err != nil {
+		return models.TitleSummary{}, err
+	}
+	return models.TitleSummary{
+		Title:       details.Title,
+		PosterPath:  details.PosterPath,
+		ReleaseDate: details.ReleaseDate,
+		VoteAverage: details.VoteAverage,
+		VoteCount:   details.VoteCount,
+	}, nil
+}
+
+// lookupTitles looks up the display fields of each title concurrently. Titles
+// that cannot be loaded are returned marked Unavailable rather than failing the
+// whole list.
+func lookupTitles(ctx context.Context, titles TitleDetailsClient, refs []titleRef) []models.TitleSummary {
+	summaries := make([]models.TitleSummary, len(refs))
+	sem := make(chan struct{}, titleLookupConcurrency)
+
+	var wg sync.WaitGroup
+	for i, ref := range refs {
+		wg.Add(1)
+		go func(i int, ref titleRef) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			summary, err := lookupTitle(ctx, titles, ref.mediaType, ref.mediaID)
+			if err != nil {
+				log.Printf("Failed to look up %s %d: %v", ref.mediaType, ref.mediaID, err)
+				summary.Unavailable = true
+			}
+			summaries[i] = summary
+		}(i, ref)
+	}
+	wg.Wait()
+
+	return summaries
+}
+
+// checkTitle looks up a title a user is saving. Titles TMDb does not know are
+// rejected with a 404 response and ok is false; other lookup failures should not
+// stop a user from saving a title, so the title is returned marked Unavailable.
+func checkTitle(w http.ResponseWriter, r *http.Request, titles TitleDetailsClient, mediaType models.SearchItemType, mediaID int) (summary models.TitleSummary, ok bool) {
+	summary, err := lookupTitle(r.Context(), titles, mediaType, mediaID)
+	if isTMDbNotFound(err) {
+		writeErrorResponse(w, http.StatusNotFound, "title_not_found", fmt.Sprintf("%s with ID %d not found", mediaTypeName(mediaType), mediaID))
+		return summary, false
+	}
+	if err != nil {
+		log.Printf("Failed to look up %s %d: %v", mediaType, mediaID, err)
+		summary.Unavailable = true
+	}
+	return summary, true
+}
+
+// isTMDbNotFound reports whether err is TMDb answering that a resource does not exist
+func isTMDbNotFound(err error) bool {
+	var tmdbErr *services.TMDbError
+	return errors.As(err, &tmdbErr) && tmdbErr.StatusCode == http.StatusNotFound
+}
+
+// mediaTypeName returns a display name for a movie or TV media type
+func mediaTypeName(mediaType models.SearchItemType) string {
+	if mediaType == models.SearchItemTypeTV {
+		return "TV show"
+	}
+	return "Movie"
+}
@@ -0,0 +1,62 @@
+// Package handlers provides HTTP handlers for TV show endpoints.
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/takeshi-arihori/movie-api/internal/credits"
+	"github.com/takeshi-arihori/movie-api/internal/models"
+)
+
+// TVClient defines the interface for TV show TMDb operations
+type TVClient interface {
+	GetTVShowCredits(ctx context.Context, tvID int) (*models.TVCredits, error)
+}
+
+// TVHandler handles TV show HTTP requests
+type TVHandler struct {
+	tmdbClient TVClient
+}
+
+// NewTVHandler creates a new TVHandler instance
+func NewTVHandler(tmdbClient TVClient) *TVHandler {
+	return &TVHandler{
+		tmdbClient: tmdbClient,
+	}
+}
+
+// GetTVCredits handles GET /api/v1/tv/{id}/credits requests. See
+// models.CreditsOptions for the filters and views.
+func (h *TVHandler) GetTVCredits(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+
+	tvID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || tvID <= 0 {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", "TV show ID must be a positive integer")
+		return
+	}
+	opts, ok := parseCreditsOptions(w, r)
+	if !ok {
+		return
+	}
+
+	tvCredits, err := h.tmdbClient.GetTVShowCredits(r.Context(), tvID)
+	if isTMDbNotFound(err) {
+		writeErrorResponse(w, http.StatusNotFound, "tv_not_found", fmt.Sprintf("TV show with ID %d not found", tvID))
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to get TV show credits for ID %d: %v", tvID, err)
+		writeUpstreamError(w, err, "Failed to retrieve TV show credits")
+		return
+	}
+
+	writeCredits(w, tvCredits.ID, tvCredits.Cast, func(member models.TVCastMember) int { return member.Order }, credits.TVCrew(tvCredits.Crew), opts)
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/takeshi-arihori/movie-api/internal/models"
+	"github.com/takeshi-arihori/movie-api/internal/services"
+)
+
+// mockTVClient returns the credits of TV show 1399. Show 500 makes TMDb fail,
+// 502 makes it unreachable and 503 makes it answer that it is down.
+type mockTVClient struct{}
+
+func (m *mockTVClient) GetTVShowCredits(ctx context.Context, tvID int) (*models.TVCredits, error) {
+	switch tvID {
+	case 1399:
+		return &models.TVCredits{
+			ID: 1399,
+			Cast: []models.TVCastMember{
+				{ID: 2, Name: "Actor 2", Order: 1},
+				{ID: 1, Name: "Actor 1", Order: 0},
+			},
+			Crew: []models.TVCrewMember{
+				{ID: 3, Name: "Showrunner", Department: "Production", Job: "Executive Producer", CreditID: "a"},
+				{ID: 3, Name: "Showrunner", Department: "Writing", Job: "Writer", CreditID: "b"},
+				{ID: 4, Name: "Composer", Department: "Sound", Job: "Original Music Composer", CreditID: "c"},
+			},
+		}, nil
+	case 500:
+		return nil, errors.New("connection refused")
+	case 502:
+		return nil, fmt.Errorf("get TV show credits request failed: %w",
+			&url.Error{Op: "Get", URL: "https://api.themoviedb.org/3/tv/502/credits", Err: errors.New("connection refused")})
+	case 503:
+		return nil, &services.TMDbError{StatusCode: 503, StatusMessage: "Service unavailable"}
+	default:
+		return nil, &services.TMDbError{StatusCode: 404, StatusMessage: "Not found"}
+	}
+}
+
+func TestTVHandler_GetTVCredits(t *testing.T) {
+	handler := NewTVHandler(&mockTVClient{})
+
+	tests := []struct {
+		name           string
+		id             string
+		query          string
+		expectedStatus int
+		expectedCast   int
+		expectedCrew   int
+	}{
+		{"full credits", "1399", "", http.StatusOK, 2, 3},
+		{"top-billed cast", "1399", "?limit=1", http.StatusOK, 1, 3},
+		{"job", "1399", "?job=Writer", http.StatusOK, 0, 1},
+		{"merged crew", "1399", "?dedupe_crew=true", http.StatusOK, 2, 2},
+		{"invalid limit", "1399", "?limit=abc", http.StatusBadRequest, 0, 0},
+		{"invalid ID", "0", "", http.StatusBadRequest, 0, 0},
+		{"TV show not found", "999", "", http.StatusNotFound, 0, 0},
+		{"TMDb failure", "500", "", http.StatusInternalServerError, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/tv/"+tt.id+"/credits"+tt.query, nil)
+			req = mux.SetURLVars(req, map[string]string{"id": tt.id})
+			w := httptest.NewRecorder()
+			handler.GetTVCredits(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+			var credits struct {
+				ID   int               `json:"id"`
+				Cast []json.RawMessage `json:"cast"`
+				Crew []json.RawMessage `json:"crew"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&credits); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if credits.ID != 1399 || len(credits.Cast) != tt.expectedCast || len(credits.Crew) != tt.expectedCrew {
+				t.Errorf("Expected %d cast and %d crew, got %+v", tt.expectedCast, tt.expectedCrew, credits)
+			}
+		})
+	}
+}
+
+// TestTVHandler_GetTVCredits_Unavailable tests that credits, which the local
+// catalogue does not hold, fail with 503 while TMDb is unavailable
+func TestTVHandler_GetTVCredits_Unavailable(t *testing.T) {
+	handler := NewTVHandler(&mockTVClient{})
+
+	tests := []struct {
+		name           string
+		id             string
+		expectedStatus int
+		expectedError  string
+		expectedSource string
+	}{
+		{"TMDb unreachable", "502", http.StatusServiceUnavailable, "tmdb_unavailable", DataSourceUnavailable},
+		{"TMDb down", "503", http.StatusServiceUnavailable, "tmdb_unavailable", DataSourceUnavailable},
+		{"TMDb failure", "500", http.StatusInternalServerError, "api_error", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/tv/"+tt.id+"/credits", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": tt.id})
+			w := httptest.NewRecorder()
+			handler.GetTVCredits(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if got := w.Header().Get(DataSourceHeader); got != tt.expectedSource {
+				t.Errorf("Expected %s %q, got %q", DataSourceHeader, tt.expectedSource, got)
+			}
+			var errResp ErrorResponse
+			if err := json.NewDecoder(w.Body).Decode(&errResp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if errResp.Error != tt.expectedError {
+				t.Errorf("Expected error %q, got %q", tt.expectedError, errResp.Error)
+			}
+		})
+	}
+}
+
+func TestTVHandler_GetTVCredits_Summary(t *testing.T) {
+	handler := NewTVHandler(&mockTVClient{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/tv/1399/credits?view=summary", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "1399"})
+	w := httptest.NewRecorder()
+	handler.GetTVCredits(w, req)
+
+	var summary models.CreditsSummary
+	if err := json.NewDecoder(w.Body).Decode(&summary); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(summary.Producers) != 1 || len(summary.Writers) != 1 || len(summary.Composers) != 1 || len(summary.Directors) != 0 {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+}
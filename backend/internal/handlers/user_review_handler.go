@@ -0,0 +1,680 @@
+// Package handlers provides HTTP handlers for reviews written by users.
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/takeshi-arihori/movie-api/internal/auth"
+	"github.com/takeshi-arihori/movie-api/internal/models"
+	"github.com/takeshi-arihori/movie-api/internal/moderation"
+	"github.com/takeshi-arihori/movie-api/internal/ranking"
+	"github.com/takeshi-arihori/movie-api/internal/services"
+	"github.com/takeshi-arihori/movie-api/internal/store"
+)
+
+// UserReviewStore reads and writes user reviews
+type UserReviewStore interface {
+	Create(ctx context.Context, review *models.UserReview) error
+	Get(ctx context.Context, id string) (*models.UserReview, error)
+	Update(ctx context.Context, review *models.UserReview) (*models.UserReview, error)
+	Delete(ctx context.Context, userID, id string) (*models.UserReview, error)
+	List(ctx context.Context, filter models.ReviewFilter) ([]models.UserReview, int, error)
+	ListByTitle(ctx context.Context, mediaType string, mediaID int, sortBy, sortOrder string, offset, limit int) ([]models.UserReview, int, error)
+	ListByUser(ctx context.Context, userID string, offset, limit int) ([]models.UserReview, int, error)
+	StatusCounts(ctx context.Context, userID string) (map[string]int, error)
+	Vote(ctx context.Context, reviewID, userID string, helpful *bool) (*models.ReviewHelpfulnessResponse, error)
+}
+
+// reviewViewUnified is the view query parameter value that interleaves a title's
+// TMDb reviews with its user reviews
+const reviewViewUnified = "unified"
+
+// UserReviewHandler handles user review HTTP requests. CreateReview, UpdateReview,
+// DeleteReview, VoteHelpful, RemoveHelpfulVote and ListMyReviews must be wrapped
+// in AuthHandler.RequireAuth, and ListReviews in AuthHandler.OptionalAuth.
+type UserReviewHandler struct {
+	reviews     UserReviewStore
+	titles      TitleDetailsClient
+	tmdbReviews ReviewClient
+	screener    *moderation.Screener
+}
+
+// NewUserReviewHandler creates a new UserReviewHandler instance. New and edited
+// reviews are pre-screened with screener; tmdbReviews provides the TMDb reviews
+// of unified listings.
+func NewUserReviewHandler(reviews UserReviewStore, titles TitleDetailsClient, tmdbReviews ReviewClient, screener *moderation.Screener) *UserReviewHandler {
+	return &UserReviewHandler{
+		reviews:     reviews,
+		titles:      titles,
+		tmdbReviews: tmdbReviews,
+		screener:    screener,
+	}
+}
+
+// CreateReview handles POST /api/v1/reviews requests. A user can review each
+// title once. The review is published straight away only if its author is trusted
+// and pre-screening found nothing; otherwise it waits for a moderator.
+func (h *UserReviewHandler) CreateReview(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.ReviewRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	if req.MediaID < 1 {
+		writeErrorResponse(w, http.StatusBadRequest, "validation_error", "media_id must satisfy min=1")
+		return
+	}
+	if _, ok := checkTitle(w, r, h.titles, models.SearchItemType(req.MediaType), req.MediaID); !ok {
+		return
+	}
+
+	review := &models.UserReview{
+		UserID:    user.ID,
+		MediaID:   req.MediaID,
+		MediaType: req.MediaType,
+		Title:     req.Title,
+		Content:   req.Content,
+		Rating:    req.Rating,
+		Spoiler:   req.Spoiler,
+	}
+	h.screen(r.Context(), user, review)
+	if err := h.reviews.Create(r.Context(), review); err != nil {
+		h.writeStoreError(w, "create review", err)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, models.ReviewResponse{Review: authorView(*review), Message: statusMessage(review), Success: true})
+}
+
+// GetReview handles GET /api/v1/reviews/{id} requests. Only approved reviews are
+// visible.
+func (h *UserReviewHandler) GetReview(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+
+	review, err := h.reviews.Get(r.Context(), mux.Vars(r)["id"])
+	if err == nil && review.Status != models.ReviewStatusApproved {
+		err = store.ErrNotFound
+	}
+	if err != nil {
+		h.writeStoreError(w, "get review", err)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, publicView(*review))
+}
+
+// UpdateReview handles PATCH /api/v1/reviews/{id} requests. Only the author can
+// edit a review; omitted fields are left unchanged. Edited reviews are screened
+// again as if they were new.
+func (h *UserReviewHandler) UpdateReview(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPatch) {
+		return
+	}
+
+	var req models.ReviewUpdateRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	review, ok := h.ownReview(w, r, user)
+	if !ok {
+		return
+	}
+
+	if req.Title != nil {
+		review.Title = *req.Title
+	}
+	if req.Content != nil {
+		review.Content = *req.Content
+	}
+	if req.Rating != nil {
+		review.Rating = *req.Rating
+	}
+	if req.Spoiler != nil {
+		review.Spoiler = *req.Spoiler
+	}
+	// Catches fields cleared to empty strings, which the update request allows
+	if err := validate.Struct(review); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "validation_error", validationMessage(err))
+		return
+	}
+
+	h.screen(r.Context(), user, review)
+	if _, err := h.reviews.Update(r.Context(), review); err != nil {
+		h.writeStoreError(w, "update review", err)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, models.ReviewResponse{Review: authorView(*review), Message: statusMessage(review), Success: true})
+}
+
+// DeleteReview handles DELETE /api/v1/reviews/{id} requests. Only the author can
+// delete a review.
+func (h *UserReviewHandler) DeleteReview(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodDelete) {
+		return
+	}
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	review, ok := h.ownReview(w, r, user)
+	if !ok {
+		return
+	}
+
+	if _, err := h.reviews.Delete(r.Context(), review.UserID, review.ID); err != nil {
+		h.writeStoreError(w, "delete review", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// VoteHelpful handles POST /api/v1/reviews/{id}/helpfulness requests. Each user
+// has one vote per review: voting the other way switches it and casting the same
+// vote again removes it. Authors cannot vote on their own reviews.
+func (h *UserReviewHandler) VoteHelpful(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	var req models.ReviewHelpfulnessRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	req.ReviewID = mux.Vars(r)["id"]
+	req.UserID = user.ID
+
+	h.vote(w, r, req.ReviewID, req.UserID, &req.Helpful)
+}
+
+// RemoveHelpfulVote handles DELETE /api/v1/reviews/{id}/helpfulness requests.
+// Removing a vote that was never cast succeeds without changing anything.
+func (h *UserReviewHandler) RemoveHelpfulVote(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodDelete) {
+		return
+	}
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	h.vote(w, r, mux.Vars(r)["id"], user.ID, nil)
+}
+
+// ListReviews handles GET /api/v1/reviews requests, listing the user reviews that
+// match the models.ReviewFilter query parameters. Only approved reviews are listed
+// unless the signed-in user is a moderator or lists their own reviews. With
+// view=unified, a title's TMDb reviews are interleaved with its user reviews.
+func (h *UserReviewHandler) ListReviews(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+	page, limit, ok := parsePageRequest(w, r)
+	if !ok {
+		return
+	}
+	filter, err := parseReviewFilter(r.URL.Query())
+	if err == nil {
+		filter.Page, filter.Limit = page, limit
+		err = validate.Struct(filter)
+		if err != nil {
+			err = errors.New(validationMessage(err))
+		}
+	}
+	if err == nil && filter.MinRating != nil && filter.MaxRating != nil && *filter.MinRating > *filter.MaxRating {
+		err = errors.New("min_rating must not be greater than max_rating")
+	}
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", err.Error())
+		return
+	}
+
+	switch view := r.URL.Query().Get("view"); view {
+	case "":
+	case reviewViewUnified:
+		h.listUnified(w, r, filter)
+		return
+	default:
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", "invalid view parameter: must be "+reviewViewUnified)
+		return
+	}
+
+	user, _ := auth.UserFromContext(r.Context())
+	if filter.Status == nil {
+		approved := models.ReviewStatusApproved
+		filter.Status = &approved
+	}
+	if *filter.Status != models.ReviewStatusApproved && !canListUnpublished(user, filter) {
+		writeErrorResponse(w, http.StatusForbidden, "forbidden", "Only moderators can list other users' unpublished reviews")
+		return
+	}
+
+	reviews, total, err := h.reviews.List(r.Context(), filter)
+	if err != nil {
+		h.writeStoreError(w, "list reviews", err)
+		return
+	}
+	for i := range reviews {
+		reviews[i] = viewFor(user, reviews[i])
+	}
+
+	writeJSONResponse(w, http.StatusOK, newReviewListResponse(reviews, filter.Page, filter.Limit, total))
+}
+
+// GetMovieUserReviews handles GET /api/v1/movies/{id}/user_reviews requests
+func (h *UserReviewHandler) GetMovieUserReviews(w http.ResponseWriter, r *http.Request) {
+	h.listTitleReviews(w, r, models.SearchItemTypeMovie)
+}
+
+// GetTVUserReviews handles GET /api/v1/tv/{id}/user_reviews requests
+func (h *UserReviewHandler) GetTVUserReviews(w http.ResponseWriter, r *http.Request) {
+	h.listTitleReviews(w, r, models.SearchItemTypeTV)
+}
+
+// ListMyReviews handles GET /api/v1/me/reviews requests, listing the signed-in
+// user's reviews in every status
+func (h *UserReviewHandler) ListMyReviews(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	page, limit, ok := parsePageRequest(w, r)
+	if !ok {
+		return
+	}
+
+	reviews, total, err := h.reviews.ListByUser(r.Context(), user.ID, (page-1)*limit, limit)
+	if err != nil {
+		h.writeStoreError(w, "list reviews", err)
+		return
+	}
+	for i := range reviews {
+		reviews[i] = authorView(reviews[i])
+	}
+
+	writeJSONResponse(w, http.StatusOK, newReviewListResponse(reviews, page, limit, total))
+}
+
+// listTitleReviews writes a page of a title's approved user reviews, newest first
+// unless the sort_by and sort_order query parameters say otherwise
+func (h *UserReviewHandler) listTitleReviews(w http.ResponseWriter, r *http.Request, mediaType models.SearchItemType) {
+	if !allowGet(w, r) {
+		return
+	}
+
+	mediaID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || mediaID <= 0 {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", fmt.Sprintf("%s ID must be a positive integer", mediaTypeName(mediaType)))
+		return
+	}
+	page, limit, ok := parsePageRequest(w, r)
+	if !ok {
+		return
+	}
+	filter := models.ReviewFilter{
+		SortBy:    r.URL.Query().Get("sort_by"),
+		SortOrder: r.URL.Query().Get("sort_order"),
+		Page:      page,
+		Limit:     limit,
+	}
+	if err := validate.Struct(filter); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", validationMessage(err))
+		return
+	}
+
+	reviews, total, err := h.reviews.ListByTitle(r.Context(), string(mediaType), mediaID, filter.SortBy, filter.SortOrder, (page-1)*limit, limit)
+	if err != nil {
+		h.writeStoreError(w, "list reviews", err)
+		return
+	}
+	for i := range reviews {
+		reviews[i] = publicView(reviews[i])
+	}
+
+	writeJSONResponse(w, http.StatusOK, newReviewListResponse(reviews, page, limit, total))
+}
+
+// listUnified writes a page of a title's TMDb reviews and approved user reviews,
+// interleaved in the order filter asks for. Only the rating range applies to TMDb
+// reviews, which excludes those without a rating.
+func (h *UserReviewHandler) listUnified(w http.ResponseWriter, r *http.Request, filter models.ReviewFilter) {
+	if filter.MediaID == nil || filter.MediaType == nil {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", "media_id and media_type are required with view=unified")
+		return
+	}
+	if filter.UserID != nil || filter.HasSpoiler != nil || filter.Status != nil {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_parameter", "user_id, has_spoiler and status cannot be used with view=unified")
+		return
+	}
+	mediaType := models.SearchItemType(*filter.MediaType)
+
+	tmdbPage, err := services.AllReviews(r.Context(), h.tmdbReviews, mediaType, *filter.MediaID)
+	if isTMDbNotFound(err) {
+		writeErrorResponse(w, http.StatusNotFound, "title_not_found", fmt.Sprintf("%s with ID %d not found", mediaTypeName(mediaType), *filter.MediaID))
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to get TMDb reviews for %s %d: %v", mediaType, *filter.MediaID, err)
+		writeUpstreamError(w, err, "Failed to retrieve TMDb reviews")
+		return
+	}
+	tmdbReviews := tmdbPage.Results
+
+	// Only the user reviews that could land on or before the requested page are
+	// needed, since every TMDb review is at hand
+	approved := models.ReviewStatusApproved
+	userFilter := filter
+	userFilter.Status = &approved
+	userFilter.Page, userFilter.Limit = 1, filter.Page*filter.Limit
+	userReviews, userTotal, err := h.reviews.List(r.Context(), userFilter)
+	if err != nil {
+		h.writeStoreError(w, "list reviews", err)
+		return
+	}
+
+	merged := make([]models.UnifiedReview, 0, len(tmdbReviews)+len(userReviews))
+	for i := range tmdbReviews {
+		if ratingInRange(tmdbReviews[i].AuthorDetails.Rating, filter) {
+			merged = append(merged, models.UnifiedReview{Source: models.ReviewSourceTMDb, TMDbReview: &tmdbReviews[i]})
+		}
+	}
+	total := len(merged) + userTotal
+	for i := range userReviews {
+		review := publicView(userReviews[i])
+		merged = append(merged, models.UnifiedReview{Source: models.ReviewSourceUser, UserReview: &review})
+	}
+	sortUnifiedReviews(merged, filter.SortBy, filter.SortOrder)
+
+	start := min((filter.Page-1)*filter.Limit, len(merged))
+	end := min(start+filter.Limit, len(merged))
+	pages := totalPages(total, filter.Limit)
+	writeJSONResponse(w, http.StatusOK, models.UnifiedReviewListResponse{
+		Reviews:      merged[start:end],
+		Page:         filter.Page,
+		TotalPages:   pages,
+		TotalResults: total,
+		HasNext:      filter.Page < pages,
+		HasPrevious:  filter.Page > 1,
+	})
+}
+
+// vote sets or removes a user's helpfulness vote on the approved review with the
+// given ID and writes the review's new counters
+func (h *UserReviewHandler) vote(w http.ResponseWriter, r *http.Request, reviewID, userID string, helpful *bool) {
+	review, err := h.reviews.Get(r.Context(), reviewID)
+	if err == nil && review.Status != models.ReviewStatusApproved {
+		err = store.ErrNotFound
+	}
+	if err != nil {
+		h.writeStoreError(w, "get review", err)
+		return
+	}
+	if review.UserID == userID {
+		writeErrorResponse(w, http.StatusForbidden, "forbidden", "Authors cannot vote on their own reviews")
+		return
+	}
+
+	resp, err := h.reviews.Vote(r.Context(), reviewID, userID, helpful)
+	if err != nil {
+		h.writeStoreError(w, "vote on review", err)
+		return
+	}
+
+	resp.Message = "Vote recorded"
+	if resp.UserAction == models.ReviewVoteRemoved {
+		resp.Message = "Vote removed"
+	}
+	resp.Success = true
+	writeJSONResponse(w, http.StatusOK, resp)
+}
+
+// screen pre-screens review and sets its moderation status. Reviews by moderators
+// and trusted reviewers are approved unless they were flagged.
+func (h *UserReviewHandler) screen(ctx context.Context, user *models.User, review *models.UserReview) {
+	review.FlagReasons = h.screener.Screen(review.Title, review.Content)
+	review.Flagged = len(review.FlagReasons) > 0
+	review.Status = models.ReviewStatusPending
+	if !review.Flagged && (user.IsModerator || h.isTrusted(ctx, user.ID)) {
+		review.Status = models.ReviewStatusApproved
+	}
+}
+
+// isTrusted reports whether a user has enough approved reviews, and none rejected,
+// to publish without moderation. Errors are logged and the user is not trusted.
+func (h *UserReviewHandler) isTrusted(ctx context.Context, userID string) bool {
+	counts, err := h.reviews.StatusCounts(ctx, userID)
+	if err != nil {
+		log.Printf("Failed to count reviews for user %s: %v", userID, err)
+		return false
+	}
+	return counts[models.ReviewStatusApproved] >= models.TrustedReviewerApprovals && counts[models.ReviewStatusRejected] == 0
+}
+
+// ownReview loads the review named by the id path variable and checks that user
+// wrote it. On failure an error response has already been written and ok is false.
+func (h *UserReviewHandler) ownReview(w http.ResponseWriter, r *http.Request, user *models.User) (*models.UserReview, bool) {
+	review, err := h.reviews.Get(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeStoreError(w, "get review", err)
+		return nil, false
+	}
+	if review.UserID != user.ID {
+		writeErrorResponse(w, http.StatusForbidden, "forbidden", "Only the author can change this review")
+		return nil, false
+	}
+	return review, true
+}
+
+// writeStoreError maps a UserReviewStore error to an error response
+func (h *UserReviewHandler) writeStoreError(w http.ResponseWriter, op string, err error) {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		writeErrorResponse(w, http.StatusNotFound, "review_not_found", "Review not found")
+	case errors.Is(err, store.ErrConflict):
+		writeErrorResponse(w, http.StatusConflict, "review_exists", "You have already reviewed this title")
+	default:
+		log.Printf("Failed to %s: %v", op, err)
+		writeErrorResponse(w, http.StatusInternalServerError, "internal_error", fmt.Sprintf("Failed to %s", op))
+	}
+}
+
+// statusMessage tells the author whether their review was published
+func statusMessage(review *models.UserReview) string {
+	if review.Status == models.ReviewStatusApproved {
+		return "Review published"
+	}
+	return "Review submitted for moderation"
+}
+
+// canListUnpublished reports whether user may list reviews in any status that
+// match filter: moderators can list anyone's, and other users only their own
+func canListUnpublished(user *models.User, filter models.ReviewFilter) bool {
+	if user == nil {
+		return false
+	}
+	return user.IsModerator || (filter.UserID != nil && *filter.UserID == user.ID)
+}
+
+// viewFor returns review as user may see it: moderators see everything, authors
+// everything but pre-screening results, and everyone else the public view
+func viewFor(user *models.User, review models.UserReview) models.UserReview {
+	switch {
+	case user != nil && user.IsModerator:
+		return review
+	case user != nil && user.ID == review.UserID:
+		return authorView(review)
+	default:
+		return publicView(review)
+	}
+}
+
+// authorView hides pre-screening results from a review's author
+func authorView(review models.UserReview) models.UserReview {
+	review.Flagged = false
+	review.FlagReasons = nil
+	return review
+}
+
+// publicView hides moderation details from other users
+func publicView(review models.UserReview) models.UserReview {
+	review = authorView(review)
+	review.ModerationReason = ""
+	return review
+}
+
+// newReviewListResponse builds a page of reviews out of total
+func newReviewListResponse(reviews []models.UserReview, page, limit, total int) models.ReviewListResponse {
+	pages := totalPages(total, limit)
+	return models.ReviewListResponse{
+		Reviews:      reviews,
+		Page:         page,
+		TotalPages:   pages,
+		TotalResults: total,
+		HasNext:      page < pages,
+		HasPrevious:  page > 1,
+	}
+}
+
+// parseReviewFilter reads the models.ReviewFilter query parameters other than page
+// and limit. Values are range-checked by validating the filter afterwards.
+func parseReviewFilter(query url.Values) (models.ReviewFilter, error) {
+	filter := models.ReviewFilter{
+		SortBy:    query.Get("sort_by"),
+		SortOrder: query.Get("sort_order"),
+	}
+
+	if value := query.Get("media_id"); value != "" {
+		mediaID, err := strconv.Atoi(value)
+		if err != nil || mediaID < 1 {
+			return filter, fmt.Errorf("invalid media_id parameter: must be a positive integer")
+		}
+		filter.MediaID = &mediaID
+	}
+	if value := query.Get("media_type"); value != "" {
+		filter.MediaType = &value
+	}
+	if value := query.Get("user_id"); value != "" {
+		if validate.Var(value, "uuid") != nil {
+			return filter, fmt.Errorf("invalid user_id parameter: must be a UUID")
+		}
+		filter.UserID = &value
+	}
+	var err error
+	if filter.MinRating, err = parseRatingParam(query, "min_rating"); err != nil {
+		return filter, err
+	}
+	if filter.MaxRating, err = parseRatingParam(query, "max_rating"); err != nil {
+		return filter, err
+	}
+	if value := query.Get("has_spoiler"); value != "" {
+		hasSpoiler, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, fmt.Errorf("invalid has_spoiler parameter: must be true or false")
+		}
+		filter.HasSpoiler = &hasSpoiler
+	}
+	if value := query.Get("status"); value != "" {
+		filter.Status = &value
+	}
+
+	return filter, nil
+}
+
+// parseRatingParam reads an optional rating query parameter
+func parseRatingParam(query url.Values, name string) (*float64, error) {
+	value := query.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+	rating, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s parameter: must be a number", name)
+	}
+	return &rating, nil
+}
+
+// ratingInRange reports whether a TMDb rating, nil when the review has none, is
+// within the rating range of filter
+func ratingInRange(rating *float64, filter models.ReviewFilter) bool {
+	if filter.MinRating == nil && filter.MaxRating == nil {
+		return true
+	}
+	return rating != nil &&
+		(filter.MinRating == nil || *rating >= *filter.MinRating) &&
+		(filter.MaxRating == nil || *rating <= *filter.MaxRating)
+}
+
+// sortUnifiedReviews orders a unified listing by a models.ReviewFilter sort, newest
+// first by default. TMDb reviews have no helpfulness votes, and reviews without a
+// rating go last when sorting by rating.
+func sortUnifiedReviews(reviews []models.UnifiedReview, sortBy, sortOrder string) {
+	key := func(review models.UnifiedReview) (float64, bool) {
+		if tmdb := review.TMDbReview; tmdb != nil {
+			switch sortBy {
+			case "updated_at":
+				return float64(tmdb.UpdatedAt.UnixNano()), true
+			case "rating":
+				if tmdb.AuthorDetails.Rating == nil {
+					return 0, false
+				}
+				return *tmdb.AuthorDetails.Rating, true
+			case "helpful":
+				return 0, true
+			default:
+				return float64(tmdb.CreatedAt.UnixNano()), true
+			}
+		}
+		user := review.UserReview
+		switch sortBy {
+		case "updated_at":
+			return float64(user.UpdatedAt.UnixNano()), true
+		case "rating":
+			return user.Rating, true
+		case "helpful":
+			return ranking.WilsonLowerBound(user.Helpful, user.NotHelpful, ranking.WilsonZ), true
+		default:
+			return float64(user.CreatedAt.UnixNano()), true
+		}
+	}
+
+	sort.SliceStable(reviews, func(i, j int) bool {
+		a, aOK := key(reviews[i])
+		b, bOK := key(reviews[j])
+		if aOK != bOK {
+			return aOK
+		}
+		if sortOrder == "asc" {
+			return a < b
+		}
+		return a > b
+	})
+}
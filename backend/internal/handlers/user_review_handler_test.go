@@ -0,0 +1,768 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/takeshi-arihori/movie-api/internal/auth"
+	"github.com/takeshi-arihori/movie-api/internal/models"
+	"github.com/takeshi-arihori/movie-api/internal/moderation"
+	"github.com/takeshi-arihori/movie-api/internal/ranking"
+	"github.com/takeshi-arihori/movie-api/internal/services"
+	"github.com/takeshi-arihori/movie-api/internal/store"
+)
+
+// fakeUserReviewStore is an in-memory UserReviewStore and ReviewModerationStore
+type fakeUserReviewStore struct {
+	mu            sync.Mutex
+	reviews       []models.UserReview
+	history       []models.ReviewModerationEntry
+	notifications []models.Notification
+	votes         map[[2]string]bool // Keyed by review and user ID
+	nextID        int
+}
+
+func (s *fakeUserReviewStore) Create(ctx context.Context, review *models.UserReview) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.reviews {
+		if existing.UserID == review.UserID && existing.MediaType == review.MediaType && existing.MediaID == review.MediaID {
+			return store.ErrConflict
+		}
+	}
+	s.nextID++
+	review.ID = fmt.Sprintf("00000000-0000-0000-0000-%012d", s.nextID)
+	review.CreatedAt = time.Date(2024, 1, 1, 0, 0, s.nextID, 0, time.UTC)
+	review.UpdatedAt = review.CreatedAt
+	// Newest first, as the store lists them
+	s.reviews = append([]models.UserReview{*review}, s.reviews...)
+	return nil
+}
+
+func (s *fakeUserReviewStore) Get(ctx context.Context, id string) (*models.UserReview, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, review := range s.reviews {
+		if review.ID == id {
+			return &review, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+func (s *fakeUserReviewStore) Update(ctx context.Context, review *models.UserReview) (*models.UserReview, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, existing := range s.reviews {
+		if existing.ID == review.ID && existing.UserID == review.UserID {
+			s.reviews[i] = *review
+			return &existing, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+func (s *fakeUserReviewStore) Delete(ctx context.Context, userID, id string) (*models.UserReview, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, review := range s.reviews {
+		if review.ID == id && review.UserID == userID {
+			s.reviews = append(s.reviews[:i], s.reviews[i+1:]...)
+			return &review, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+func (s *fakeUserReviewStore) List(ctx context.Context, filter models.ReviewFilter) ([]models.UserReview, int, error) {
+	return s.list((filter.Page-1)*filter.Limit, filter.Limit, func(review models.UserReview) bool {
+		return (filter.MediaID == nil || review.MediaID == *filter.MediaID) &&
+			(filter.MediaType == nil || review.MediaType == *filter.MediaType) &&
+			(filter.UserID == nil || review.UserID == *filter.UserID) &&
+			(filter.MinRating == nil || review.Rating >= *filter.MinRating) &&
+			(filter.MaxRating == nil || review.Rating <= *filter.MaxRating) &&
+			(filter.HasSpoiler == nil || review.Spoiler == *filter.HasSpoiler) &&
+			(filter.Status == nil || review.Status == *filter.Status)
+	}, reviewLess(filter.SortBy, filter.SortOrder))
+}
+
+func (s *fakeUserReviewStore) ListByTitle(ctx context.Context, mediaType string, mediaID int, sortBy, sortOrder string, offset, limit int) ([]models.UserReview, int, error) {
+	return s.list(offset, limit, func(review models.UserReview) bool {
+		return review.MediaType == mediaType && review.MediaID == mediaID && review.Status == models.ReviewStatusApproved
+	}, reviewLess(sortBy, sortOrder))
+}
+
+func (s *fakeUserReviewStore) ListByUser(ctx context.Context, userID string, offset, limit int) ([]models.UserReview, int, error) {
+	return s.list(offset, limit, func(review models.UserReview) bool { return review.UserID == userID }, nil)
+}
+
+func (s *fakeUserReviewStore) StatusCounts(ctx context.Context, userID string) (map[string]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	counts := map[string]int{}
+	for _, review := range s.reviews {
+		if review.UserID == userID {
+			counts[review.Status]++
+		}
+	}
+	return counts, nil
+}
+
+func (s *fakeUserReviewStore) Vote(ctx context.Context, reviewID, userID string, helpful *bool) (*models.ReviewHelpfulnessResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.votes == nil {
+		s.votes = map[[2]string]bool{}
+	}
+	for i, review := range s.reviews {
+		if review.ID != reviewID {
+			continue
+		}
+		key := [2]string{reviewID, userID}
+		if previous, ok := s.votes[key]; ok {
+			delete(s.votes, key)
+			if previous {
+				s.reviews[i].Helpful--
+			} else {
+				s.reviews[i].NotHelpful--
+			}
+			if helpful != nil && *helpful == previous {
+				helpful = nil
+			}
+		}
+		action := models.ReviewVoteRemoved
+		switch {
+		case helpful == nil:
+		case *helpful:
+			s.votes[key] = true
+			s.reviews[i].Helpful++
+			action = models.ReviewVoteHelpful
+		default:
+			s.votes[key] = false
+			s.reviews[i].NotHelpful++
+			action = models.ReviewVoteNotHelpful
+		}
+		return &models.ReviewHelpfulnessResponse{ReviewID: reviewID, Helpful: s.reviews[i].Helpful, NotHelpful: s.reviews[i].NotHelpful, UserAction: action}, nil
+	}
+	return nil, store.ErrNotFound
+}
+
+func (s *fakeUserReviewStore) Queue(ctx context.Context, offset, limit int) ([]models.UserReview, int, error) {
+	// Reviews are stored newest first, so the flagged ones are listed first and
+	// then the rest, both oldest first
+	var flagged, unflagged []models.UserReview
+	s.mu.Lock()
+	for i := len(s.reviews) - 1; i >= 0; i-- {
+		review := s.reviews[i]
+		switch {
+		case review.Status != models.ReviewStatusPending:
+		case review.Flagged:
+			flagged = append(flagged, review)
+		default:
+			unflagged = append(unflagged, review)
+		}
+	}
+	s.mu.Unlock()
+	queue := append(flagged, unflagged...)
+	page := []models.UserReview{}
+	for i := offset; i < len(queue) && i < offset+limit; i++ {
+		page = append(page, queue[i])
+	}
+	return page, len(queue), nil
+}
+
+func (s *fakeUserReviewStore) Moderate(ctx context.Context, entry *models.ReviewModerationEntry, notification *models.Notification) (*models.UserReview, *models.UserReview, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, review := range s.reviews {
+		if review.ID == entry.ReviewID {
+			s.reviews[i].Status = models.ReviewStatusApproved
+			if entry.Action == models.ReviewActionReject {
+				s.reviews[i].Status = models.ReviewStatusRejected
+			}
+			s.reviews[i].ModerationReason = entry.Reason
+			s.history = append(s.history, *entry)
+			notification.UserID = review.UserID
+			s.notifications = append(s.notifications, *notification)
+			moderated := s.reviews[i]
+			return &review, &moderated, nil
+		}
+	}
+	return nil, nil, store.ErrNotFound
+}
+
+func (s *fakeUserReviewStore) History(ctx context.Context, reviewID string) ([]models.ReviewModerationEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries := []models.ReviewModerationEntry{}
+	for _, entry := range s.history {
+		if entry.ReviewID == reviewID {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// list pages through the matching reviews, newest first unless less is given
+func (s *fakeUserReviewStore) list(offset, limit int, match func(models.UserReview) bool, less func(a, b models.UserReview) bool) ([]models.UserReview, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var matched []models.UserReview
+	for _, review := range s.reviews {
+		if match(review) {
+			matched = append(matched, review)
+		}
+	}
+	if less != nil {
+		sort.SliceStable(matched, func(i, j int) bool { return less(matched[i], matched[j]) })
+	}
+	page := []models.UserReview{}
+	for i := offset; i < len(matched) && i < offset+limit; i++ {
+		page = append(page, matched[i])
+	}
+	return page, len(matched), nil
+}
+
+// reviewLess orders reviews like the store's sort_by and sort_order, or returns
+// nil for the default newest-first order reviews are kept in
+func reviewLess(sortBy, sortOrder string) func(a, b models.UserReview) bool {
+	var key func(models.UserReview) float64
+	switch sortBy {
+	case "rating":
+		key = func(review models.UserReview) float64 { return review.Rating }
+	case "helpful":
+		key = func(review models.UserReview) float64 {
+			return ranking.WilsonLowerBound(review.Helpful, review.NotHelpful, ranking.WilsonZ)
+		}
+	default:
+		return nil
+	}
+	return func(a, b models.UserReview) bool {
+		if sortOrder == "asc" {
+			return key(a) < key(b)
+		}
+		return key(a) > key(b)
+	}
+}
+
+func newTestUserReviewHandler() (*UserReviewHandler, *fakeUserReviewStore) {
+	reviews := &fakeUserReviewStore{}
+	return NewUserReviewHandler(reviews, newMockTitleDetailsClient(), &MockReviewClient{movieReviews: &models.MovieReviews{}, tvReviews: &models.TVReviews{}}, moderation.NewScreener(moderation.DefaultBannedWords)), reviews
+}
+
+func TestUserReviewHandler_CreateReview(t *testing.T) {
+	handler, reviews := newTestUserReviewHandler()
+
+	tests := []struct {
+		name           string
+		body           string
+		userID         string
+		expectedStatus int
+	}{
+		{"new review", `{"media_id":550,"media_type":"movie","title":"Mind-bending","content":"Rewatch it.","rating":9}`, "user-1", http.StatusCreated},
+		{"one review per title", `{"media_id":550,"media_type":"movie","title":"Again","content":"Still good.","rating":8}`, "user-1", http.StatusConflict},
+		{"another user", `{"media_id":550,"media_type":"movie","title":"Overrated","content":"Not for me.","rating":4}`, "user-2", http.StatusCreated},
+		{"TMDb unavailable", `{"media_id":500,"media_type":"tv","title":"Unknown","content":"Hmm.","rating":5}`, "user-1", http.StatusCreated},
+		{"unknown title", `{"media_id":999999,"media_type":"movie","title":"Who?","content":"Nothing.","rating":5}`, "user-1", http.StatusNotFound},
+		{"rating out of range", `{"media_id":603,"media_type":"movie","title":"Wow","content":"Wow.","rating":11}`, "user-1", http.StatusBadRequest},
+		{"content too long", `{"media_id":603,"media_type":"movie","title":"Long","content":"` + strings.Repeat("a", 5001) + `","rating":7}`, "user-1", http.StatusBadRequest},
+		{"invalid media type", `{"media_id":287,"media_type":"person","title":"Brad","content":"Great.","rating":7}`, "user-1", http.StatusBadRequest},
+		{"negative media id", `{"media_id":-1,"media_type":"movie","title":"Huh","content":"Huh.","rating":7}`, "user-1", http.StatusBadRequest},
+		{"unauthenticated", `{"media_id":603,"media_type":"movie","title":"Wow","content":"Wow.","rating":7}`, "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/v1/reviews", strings.NewReader(tt.body))
+			if tt.userID != "" {
+				req = withUser(req, tt.userID)
+			}
+			w := httptest.NewRecorder()
+
+			handler.CreateReview(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if w.Code == http.StatusCreated {
+				var resp models.ReviewResponse
+				if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+					t.Fatalf("Failed to decode response: %v", err)
+				}
+				if !resp.Success || resp.Review.ID == "" || resp.Review.UserID != tt.userID {
+					t.Errorf("Expected the created review, got %+v", resp)
+				}
+				if resp.Review.Status != models.ReviewStatusPending {
+					t.Errorf("Expected a new reviewer's review to await moderation, got %q", resp.Review.Status)
+				}
+			}
+		})
+	}
+
+	if len(reviews.reviews) != 3 {
+		t.Errorf("Expected 3 stored reviews, got %d", len(reviews.reviews))
+	}
+}
+
+func TestUserReviewHandler_UpdateAndDelete(t *testing.T) {
+	handler, reviews := newTestUserReviewHandler()
+	review := &models.UserReview{UserID: "user-1", MediaID: 603, MediaType: "movie", Title: "Whoa", Content: "Red pill.", Rating: 8, Status: models.ReviewStatusApproved}
+	reviews.Create(context.Background(), review)
+
+	send := func(method, body, userID string, call func(http.ResponseWriter, *http.Request)) *httptest.ResponseRecorder {
+		req := withUser(httptest.NewRequest(method, "/api/v1/reviews/"+review.ID, strings.NewReader(body)), userID)
+		req = mux.SetURLVars(req, map[string]string{"id": review.ID})
+		w := httptest.NewRecorder()
+		call(w, req)
+		return w
+	}
+
+	updateTests := []struct {
+		name           string
+		body           string
+		userID         string
+		expectedStatus int
+	}{
+		{"not the author", `{"rating":1}`, "user-2", http.StatusForbidden},
+		{"cleared title", `{"title":""}`, "user-1", http.StatusBadRequest},
+		{"rating out of range", `{"rating":0.5}`, "user-1", http.StatusBadRequest},
+		{"unknown field", `{"status":"approved"}`, "user-1", http.StatusBadRequest},
+		{"author", `{"rating":9.5,"spoiler":true}`, "user-1", http.StatusOK},
+	}
+	for _, tt := range updateTests {
+		t.Run("update "+tt.name, func(t *testing.T) {
+			w := send("PATCH", tt.body, tt.userID, handler.UpdateReview)
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	stored, _ := reviews.Get(context.Background(), review.ID)
+	if stored.Rating != 9.5 || !stored.Spoiler || stored.Title != "Whoa" {
+		t.Errorf("Expected only the author's changes to be saved, got %+v", stored)
+	}
+	if stored.Status != models.ReviewStatusPending {
+		t.Errorf("Expected an edited review to be moderated again, got %q", stored.Status)
+	}
+
+	if w := send("DELETE", "", "user-2", handler.DeleteReview); w.Code != http.StatusForbidden {
+		t.Errorf("Expected another user's delete to return 403, got %d", w.Code)
+	}
+	if w := send("DELETE", "", "user-1", handler.DeleteReview); w.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", w.Code)
+	}
+	if w := send("DELETE", "", "user-1", handler.DeleteReview); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 deleting twice, got %d", w.Code)
+	}
+}
+
+func TestUserReviewHandler_ListReviews(t *testing.T) {
+	handler, reviews := newTestUserReviewHandler()
+	for i := 1; i <= 5; i++ {
+		reviews.Create(context.Background(), &models.UserReview{UserID: fmt.Sprintf("user-%d", i), MediaID: 550, MediaType: "movie", Title: "Review", Content: "Review.", Rating: float64(i),
+			Status: models.ReviewStatusApproved, Flagged: i == 5, FlagReasons: []string{moderation.FlagLinkSpam}, ModerationReason: "Links are fine"})
+	}
+	reviews.Create(context.Background(), &models.UserReview{UserID: "user-6", MediaID: 550, MediaType: "movie", Title: "Pending", Content: "Pending.", Rating: 1, Status: models.ReviewStatusPending})
+	reviews.Create(context.Background(), &models.UserReview{UserID: "user-1", MediaID: 1399, MediaType: "tv", Title: "Winter", Content: "Is coming.", Rating: 7, Status: models.ReviewStatusRejected})
+
+	list := func(t *testing.T, query string) models.ReviewListResponse {
+		t.Helper()
+		req := httptest.NewRequest("GET", "/api/v1/movies/550/user_reviews"+query, nil)
+		req = mux.SetURLVars(req, map[string]string{"id": "550"})
+		w := httptest.NewRecorder()
+		handler.GetMovieUserReviews(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp models.ReviewListResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return resp
+	}
+
+	tests := []struct {
+		query       string
+		reviews     int
+		hasNext     bool
+		hasPrevious bool
+	}{
+		{"", 5, false, false},
+		{"?limit=2", 2, true, false},
+		{"?page=2&limit=2", 2, true, true},
+		{"?page=3&limit=2", 1, false, true},
+		{"?page=4&limit=2", 0, false, true},
+	}
+	for _, tt := range tests {
+		t.Run("page "+tt.query, func(t *testing.T) {
+			resp := list(t, tt.query)
+			if len(resp.Reviews) != tt.reviews || resp.HasNext != tt.hasNext || resp.HasPrevious != tt.hasPrevious {
+				t.Errorf("Expected %d reviews, next %v, previous %v; got %d, %v, %v",
+					tt.reviews, tt.hasNext, tt.hasPrevious, len(resp.Reviews), resp.HasNext, resp.HasPrevious)
+			}
+			if resp.TotalResults != 5 {
+				t.Errorf("Expected 5 total results, got %d", resp.TotalResults)
+			}
+		})
+	}
+
+	if resp := list(t, "?limit=2"); resp.TotalPages != 3 || resp.Reviews[0].Rating != 5 {
+		t.Errorf("Expected 3 pages, newest first, got %+v", resp)
+	}
+	if first := list(t, "").Reviews[0]; first.Flagged || first.FlagReasons != nil || first.ModerationReason != "" {
+		t.Errorf("Expected moderation details to be hidden, got %+v", first)
+	}
+
+	for _, query := range []string{"?page=0", "?page=abc", "?limit=101"} {
+		t.Run("invalid "+query, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/movies/550/user_reviews"+query, nil)
+			req = mux.SetURLVars(req, map[string]string{"id": "550"})
+			w := httptest.NewRecorder()
+			handler.GetMovieUserReviews(w, req)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400, got %d", w.Code)
+			}
+		})
+	}
+
+	t.Run("mine", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ListMyReviews(w, withUser(httptest.NewRequest("GET", "/api/v1/me/reviews", nil), "user-1"))
+		var resp models.ReviewListResponse
+		json.NewDecoder(w.Body).Decode(&resp)
+		if w.Code != http.StatusOK || resp.TotalResults != 2 {
+			t.Errorf("Expected user-1's 2 reviews, got %d: %+v", w.Code, resp)
+		}
+		if resp.Reviews[0].Status != models.ReviewStatusRejected || resp.Reviews[1].ModerationReason == "" || resp.Reviews[1].Flagged {
+			t.Errorf("Expected the author to see statuses and reasons but not flags, got %+v", resp.Reviews)
+		}
+	})
+}
+
+func TestUserReviewHandler_AutoApproval(t *testing.T) {
+	handler, reviews := newTestUserReviewHandler()
+	for i, id := range []int{13, 603, 1399} {
+		reviews.Create(context.Background(), &models.UserReview{UserID: "trusted", MediaID: id, MediaType: []string{"movie", "movie", "tv"}[i],
+			Title: "Good", Content: "Good.", Rating: 8, Status: models.ReviewStatusApproved})
+	}
+	reviews.Create(context.Background(), &models.UserReview{UserID: "rejected", MediaID: 550, MediaType: "movie", Title: "Bad", Content: "Bad.", Rating: 1, Status: models.ReviewStatusRejected})
+
+	tests := []struct {
+		name           string
+		user           *models.User
+		body           string
+		expectedStatus string
+	}{
+		{"trusted reviewer", &models.User{ID: "trusted"}, `{"media_id":500,"media_type":"tv","title":"Fine","content":"Fine.","rating":6}`, models.ReviewStatusApproved},
+		{"flagged trusted reviewer", &models.User{ID: "trusted"}, `{"media_id":550,"media_type":"movie","title":"Fine","content":"Visit www.spam.example and www.more.example","rating":6}`, models.ReviewStatusPending},
+		{"previously rejected", &models.User{ID: "rejected"}, `{"media_id":603,"media_type":"movie","title":"Fine","content":"Fine.","rating":6}`, models.ReviewStatusPending},
+		{"moderator", &models.User{ID: "moderator", IsModerator: true}, `{"media_id":603,"media_type":"movie","title":"Fine","content":"Fine.","rating":6}`, models.ReviewStatusApproved},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/v1/reviews", strings.NewReader(tt.body))
+			req = req.WithContext(auth.WithUser(req.Context(), tt.user))
+			w := httptest.NewRecorder()
+
+			handler.CreateReview(w, req)
+
+			var resp models.ReviewResponse
+			json.NewDecoder(w.Body).Decode(&resp)
+			if resp.Review.Status != tt.expectedStatus {
+				t.Errorf("Expected status %q, got %d %+v", tt.expectedStatus, w.Code, resp)
+			}
+			if resp.Review.Flagged || resp.Review.FlagReasons != nil {
+				t.Errorf("Expected flags to be hidden from the author, got %+v", resp.Review)
+			}
+		})
+	}
+}
+
+func TestUserReviewHandler_Helpfulness(t *testing.T) {
+	handler, reviews := newTestUserReviewHandler()
+	review := &models.UserReview{UserID: "user-1", MediaID: 550, MediaType: "movie", Title: "Mind-bending", Content: "Rewatch it.", Rating: 9, Status: models.ReviewStatusApproved}
+	pending := &models.UserReview{UserID: "user-1", MediaID: 603, MediaType: "movie", Title: "Whoa", Content: "Whoa.", Rating: 8, Status: models.ReviewStatusPending}
+	reviews.Create(context.Background(), review)
+	reviews.Create(context.Background(), pending)
+
+	tests := []struct {
+		name           string
+		method         string
+		reviewID       string
+		body           string
+		userID         string
+		expectedStatus int
+		helpful        int
+		notHelpful     int
+		action         string
+	}{
+		{"helpful", "POST", review.ID, `{"helpful":true}`, "user-2", http.StatusOK, 1, 0, models.ReviewVoteHelpful},
+		{"same vote again removes it", "POST", review.ID, `{"helpful":true}`, "user-2", http.StatusOK, 0, 0, models.ReviewVoteRemoved},
+		{"not helpful", "POST", review.ID, `{"helpful":false}`, "user-2", http.StatusOK, 0, 1, models.ReviewVoteNotHelpful},
+		{"switch vote", "POST", review.ID, `{"helpful":true}`, "user-2", http.StatusOK, 1, 0, models.ReviewVoteHelpful},
+		{"another user", "POST", review.ID, `{"helpful":false}`, "user-3", http.StatusOK, 1, 1, models.ReviewVoteNotHelpful},
+		{"remove vote", "DELETE", review.ID, "", "user-2", http.StatusOK, 0, 1, models.ReviewVoteRemoved},
+		{"remove missing vote", "DELETE", review.ID, "", "user-2", http.StatusOK, 0, 1, models.ReviewVoteRemoved},
+		{"own review", "POST", review.ID, `{"helpful":true}`, "user-1", http.StatusForbidden, 0, 0, ""},
+		{"pending review", "POST", pending.ID, `{"helpful":true}`, "user-2", http.StatusNotFound, 0, 0, ""},
+		{"unknown review", "POST", "00000000-0000-0000-0000-000000000099", `{"helpful":true}`, "user-2", http.StatusNotFound, 0, 0, ""},
+		{"invalid body", "POST", review.ID, `{"helpful":"yes"}`, "user-2", http.StatusBadRequest, 0, 0, ""},
+		{"user id in body", "POST", review.ID, `{"helpful":true,"user_id":"user-9"}`, "user-2", http.StatusBadRequest, 0, 0, ""},
+		{"unauthenticated", "POST", review.ID, `{"helpful":true}`, "", http.StatusUnauthorized, 0, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/v1/reviews/"+tt.reviewID+"/helpfulness", strings.NewReader(tt.body))
+			req = mux.SetURLVars(req, map[string]string{"id": tt.reviewID})
+			if tt.userID != "" {
+				req = withUser(req, tt.userID)
+			}
+			w := httptest.NewRecorder()
+
+			if tt.method == "DELETE" {
+				handler.RemoveHelpfulVote(w, req)
+			} else {
+				handler.VoteHelpful(w, req)
+			}
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+			var resp models.ReviewHelpfulnessResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if !resp.Success || resp.Helpful != tt.helpful || resp.NotHelpful != tt.notHelpful || resp.UserAction != tt.action {
+				t.Errorf("Expected %d helpful, %d not helpful and action %q, got %+v", tt.helpful, tt.notHelpful, tt.action, resp)
+			}
+		})
+	}
+}
+
+func TestUserReviewHandler_SortByHelpfulness(t *testing.T) {
+	handler, reviews := newTestUserReviewHandler()
+	for _, review := range []models.UserReview{
+		{UserID: "user-1", Title: "Unrated", Helpful: 0, NotHelpful: 0},
+		{UserID: "user-2", Title: "Few votes", Helpful: 2, NotHelpful: 0},
+		{UserID: "user-3", Title: "Many votes", Helpful: 40, NotHelpful: 10},
+		{UserID: "user-4", Title: "Unhelpful", Helpful: 1, NotHelpful: 9},
+	} {
+		review.MediaID, review.MediaType, review.Content, review.Rating, review.Status = 550, "movie", "Review.", 7, models.ReviewStatusApproved
+		reviews.Create(context.Background(), &review)
+	}
+
+	tests := []struct {
+		query          string
+		expectedStatus int
+		titles         string
+	}{
+		{"?sort_by=helpful", http.StatusOK, "Many votes,Few votes,Unhelpful,Unrated"},
+		{"?sort_by=helpful&sort_order=asc", http.StatusOK, "Unrated,Unhelpful,Few votes,Many votes"},
+		{"", http.StatusOK, "Unhelpful,Many votes,Few votes,Unrated"},
+		{"?sort_by=votes", http.StatusBadRequest, ""},
+		{"?sort_by=helpful&sort_order=up", http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/movies/550/user_reviews"+tt.query, nil)
+			req = mux.SetURLVars(req, map[string]string{"id": "550"})
+			w := httptest.NewRecorder()
+
+			handler.GetMovieUserReviews(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+			var resp models.ReviewListResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			var titles []string
+			for _, review := range resp.Reviews {
+				titles = append(titles, review.Title)
+			}
+			if got := strings.Join(titles, ","); got != tt.titles {
+				t.Errorf("Expected %s, got %s", tt.titles, got)
+			}
+		})
+	}
+}
+
+func TestUserReviewHandler_FilterReviews(t *testing.T) {
+	const author = "00000000-0000-0000-0000-0000000000aa"
+	handler, reviews := newTestUserReviewHandler()
+	for _, review := range []models.UserReview{
+		{UserID: author, MediaID: 550, MediaType: "movie", Title: "Fight Club", Rating: 9, Status: models.ReviewStatusApproved},
+		{UserID: author, MediaID: 603, MediaType: "movie", Title: "The Matrix", Rating: 4, Spoiler: true, Status: models.ReviewStatusApproved},
+		{UserID: author, MediaID: 1399, MediaType: "tv", Title: "Game of Thrones", Rating: 7, Status: models.ReviewStatusPending, Flagged: true},
+		{UserID: "user-2", MediaID: 550, MediaType: "movie", Title: "Overrated", Rating: 3, Status: models.ReviewStatusApproved},
+		{UserID: "user-2", MediaID: 603, MediaType: "movie", Title: "Rejected", Rating: 1, Status: models.ReviewStatusRejected},
+	} {
+		review.Content = "Review."
+		reviews.Create(context.Background(), &review)
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		viewer         func(*http.Request) *http.Request
+		expectedStatus int
+		titles         string
+	}{
+		{name: "approved reviews by default", query: "", expectedStatus: http.StatusOK, titles: "Overrated,The Matrix,Fight Club"},
+		{name: "by title", query: "?media_id=550&media_type=movie", expectedStatus: http.StatusOK, titles: "Overrated,Fight Club"},
+		{name: "by user", query: "?user_id=" + author, expectedStatus: http.StatusOK, titles: "The Matrix,Fight Club"},
+		{name: "by rating range", query: "?min_rating=4&max_rating=9&sort_by=rating", expectedStatus: http.StatusOK, titles: "Fight Club,The Matrix"},
+		{name: "without spoilers", query: "?has_spoiler=false", expectedStatus: http.StatusOK, titles: "Overrated,Fight Club"},
+		{name: "paginated", query: "?limit=1&page=2", expectedStatus: http.StatusOK, titles: "The Matrix"},
+		{name: "own pending reviews", query: "?status=pending&user_id=" + author, viewer: func(r *http.Request) *http.Request { return withUser(r, author) }, expectedStatus: http.StatusOK, titles: "Game of Thrones"},
+		{name: "moderator sees rejected reviews", query: "?status=rejected", viewer: func(r *http.Request) *http.Request { return withModerator(r, "mod-1") }, expectedStatus: http.StatusOK, titles: "Rejected"},
+		{name: "anonymous pending reviews", query: "?status=pending", expectedStatus: http.StatusForbidden},
+		{name: "other user's pending reviews", query: "?status=pending&user_id=" + author, viewer: func(r *http.Request) *http.Request { return withUser(r, "user-2") }, expectedStatus: http.StatusForbidden},
+		{name: "invalid media ID", query: "?media_id=abc", expectedStatus: http.StatusBadRequest},
+		{name: "invalid media type", query: "?media_type=book", expectedStatus: http.StatusBadRequest},
+		{name: "invalid user ID", query: "?user_id=neo", expectedStatus: http.StatusBadRequest},
+		{name: "invalid rating", query: "?min_rating=high", expectedStatus: http.StatusBadRequest},
+		{name: "rating out of range", query: "?max_rating=11", expectedStatus: http.StatusBadRequest},
+		{name: "inverted rating range", query: "?min_rating=8&max_rating=2", expectedStatus: http.StatusBadRequest},
+		{name: "invalid has_spoiler", query: "?has_spoiler=maybe", expectedStatus: http.StatusBadRequest},
+		{name: "invalid status", query: "?status=deleted", expectedStatus: http.StatusBadRequest},
+		{name: "invalid view", query: "?view=all", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/reviews"+tt.query, nil)
+			if tt.viewer != nil {
+				req = tt.viewer(req)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ListReviews(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+			var resp models.ReviewListResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			var titles []string
+			for _, review := range resp.Reviews {
+				titles = append(titles, review.Title)
+				if review.Flagged && tt.viewer == nil {
+					t.Errorf("Expected moderation details to be hidden from %q", review.Title)
+				}
+			}
+			if got := strings.Join(titles, ","); got != tt.titles {
+				t.Errorf("Expected %s, got %s", tt.titles, got)
+			}
+		})
+	}
+}
+
+func TestUserReviewHandler_UnifiedReviews(t *testing.T) {
+	rating := func(r float64) *float64 { return &r }
+	tmdb := &MockReviewClient{movieReviews: &models.MovieReviews{
+		ID: 550, Page: 1, TotalPages: 1, TotalResults: 2,
+		Results: []models.Review{
+			{ID: "tmdb-1", Author: "critic", Content: "Classic.", CreatedAt: time.Date(2024, 1, 1, 0, 0, 1, 500, time.UTC), AuthorDetails: models.AuthorDetails{Rating: rating(8)}},
+			{ID: "tmdb-2", Author: "lurker", Content: "Hmm.", CreatedAt: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)},
+		},
+	}}
+	reviews := &fakeUserReviewStore{}
+	handler := NewUserReviewHandler(reviews, newMockTitleDetailsClient(), tmdb, moderation.NewScreener(moderation.DefaultBannedWords))
+	for _, review := range []models.UserReview{
+		{UserID: "user-1", Title: "Oldest", Rating: 6}, // Created at 00:00:01
+		{UserID: "user-2", Title: "Newest", Rating: 9}, // Created at 00:00:02, after tmdb-1
+		{UserID: "user-3", Title: "Pending", Rating: 10, Status: models.ReviewStatusPending},
+	} {
+		review.MediaID, review.MediaType, review.Content = 550, "movie", "Review."
+		if review.Status == "" {
+			review.Status = models.ReviewStatusApproved
+		}
+		reviews.Create(context.Background(), &review)
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		order          string
+		total          int
+	}{
+		{name: "newest first", query: "?view=unified&media_type=movie&media_id=550", expectedStatus: http.StatusOK, order: "Newest,tmdb-1,Oldest,tmdb-2", total: 4},
+		{name: "paginated", query: "?view=unified&media_type=movie&media_id=550&limit=2&page=2", expectedStatus: http.StatusOK, order: "Oldest,tmdb-2", total: 4},
+		{name: "by rating, unrated last", query: "?view=unified&media_type=movie&media_id=550&sort_by=rating&sort_order=asc", expectedStatus: http.StatusOK, order: "Oldest,tmdb-1,Newest,tmdb-2", total: 4},
+		{name: "rating range excludes unrated", query: "?view=unified&media_type=movie&media_id=550&min_rating=7", expectedStatus: http.StatusOK, order: "Newest,tmdb-1", total: 2},
+		{name: "media required", query: "?view=unified&media_type=movie", expectedStatus: http.StatusBadRequest},
+		{name: "status not allowed", query: "?view=unified&media_type=movie&media_id=550&status=approved", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/reviews"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			handler.ListReviews(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+			var resp models.UnifiedReviewListResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			var order []string
+			for _, review := range resp.Reviews {
+				switch review.Source {
+				case models.ReviewSourceTMDb:
+					order = append(order, review.TMDbReview.ID)
+				case models.ReviewSourceUser:
+					order = append(order, review.UserReview.Title)
+				}
+			}
+			if got := strings.Join(order, ","); got != tt.order {
+				t.Errorf("Expected %s, got %s", tt.order, got)
+			}
+			if resp.TotalResults != tt.total {
+				t.Errorf("Expected %d total results, got %d", tt.total, resp.TotalResults)
+			}
+		})
+	}
+
+	t.Run("title not found", func(t *testing.T) {
+		tmdb.err = &services.TMDbError{StatusCode: http.StatusNotFound}
+		defer func() { tmdb.err = nil }()
+		w := httptest.NewRecorder()
+		handler.ListReviews(w, httptest.NewRequest("GET", "/api/v1/reviews?view=unified&media_type=movie&media_id=550", nil))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+}
@@ -0,0 +1,335 @@
+// Package handlers provides HTTP handlers for users' named watchlists.
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/takeshi-arihori/movie-api/internal/models"
+	"github.com/takeshi-arihori/movie-api/internal/store"
+)
+
+// WatchlistStore reads and writes watchlists and their items
+type WatchlistStore interface {
+	Create(ctx context.Context, list *models.Watchlist) error
+	Get(ctx context.Context, userID, id string) (*models.Watchlist, error)
+	GetPublic(ctx context.Context, slug string) (*models.Watchlist, error)
+	List(ctx context.Context, userID string) ([]models.Watchlist, error)
+	Update(ctx context.Context, list *models.Watchlist) error
+	Delete(ctx context.Context, userID, id string) error
+	Items(ctx context.Context, watchlistID string) ([]models.WatchlistItem, error)
+	AddItem(ctx context.Context, watchlistID string, item *models.WatchlistItem) (bool, error)
+	UpdateItemNote(ctx context.Context, watchlistID, itemID, note string) (*models.WatchlistItem, error)
+	RemoveItem(ctx context.Context, watchlistID, itemID string) error
+	Reorder(ctx context.Context, watchlistID string, itemIDs []string) error
+}
+
+// WatchlistHandler handles watchlist HTTP requests. Apart from GetPublicWatchlist,
+// its routes must be wrapped in AuthHandler.RequireAuth.
+type WatchlistHandler struct {
+	watchlists WatchlistStore
+	titles     TitleDetailsClient
+}
+
+// NewWatchlistHandler creates a new WatchlistHandler instance
+func NewWatchlistHandler(watchlists WatchlistStore, titles TitleDetailsClient) *WatchlistHandler {
+	return &WatchlistHandler{
+		watchlists: watchlists,
+		titles:     titles,
+	}
+}
+
+// ListWatchlists handles GET /api/v1/me/watchlists requests
+func (h *WatchlistHandler) ListWatchlists(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	lists, err := h.watchlists.List(r.Context(), user.ID)
+	if err != nil {
+		log.Printf("Failed to list watchlists for user %s: %v", user.ID, err)
+		writeErrorResponse(w, http.StatusInternalServerError, "internal_error", "Failed to retrieve watchlists")
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, lists)
+}
+
+// CreateWatchlist handles POST /api/v1/me/watchlists requests
+func (h *WatchlistHandler) CreateWatchlist(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.WatchlistRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	list := &models.Watchlist{
+		UserID:      user.ID,
+		Name:        req.Name,
+		Description: req.Description,
+		IsPublic:    req.IsPublic,
+	}
+	if err := h.watchlists.Create(r.Context(), list); err != nil {
+		h.writeStoreError(w, "create watchlist", err)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, list)
+}
+
+// GetWatchlist handles GET /api/v1/me/watchlists/{id} requests
+func (h *WatchlistHandler) GetWatchlist(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+	list, ok := h.ownWatchlist(w, r)
+	if !ok {
+		return
+	}
+
+	h.writeWatchlist(w, r, list)
+}
+
+// GetPublicWatchlist handles GET /api/v1/watchlists/{slug} requests. It needs no
+// authentication.
+func (h *WatchlistHandler) GetPublicWatchlist(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+
+	list, err := h.watchlists.GetPublic(r.Context(), mux.Vars(r)["slug"])
+	if err != nil {
+		h.writeStoreError(w, "get public watchlist", err)
+		return
+	}
+	list.UserID = ""
+
+	h.writeWatchlist(w, r, list)
+}
+
+// UpdateWatchlist handles PATCH /api/v1/me/watchlists/{id} requests
+func (h *WatchlistHandler) UpdateWatchlist(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPatch) {
+		return
+	}
+
+	var req models.WatchlistUpdateRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	list, ok := h.ownWatchlist(w, r)
+	if !ok {
+		return
+	}
+
+	if req.Name != nil {
+		list.Name = *req.Name
+	}
+	if req.Description != nil {
+		list.Description = *req.Description
+	}
+	if req.IsPublic != nil {
+		list.IsPublic = *req.IsPublic
+	}
+	if err := h.watchlists.Update(r.Context(), list); err != nil {
+		h.writeStoreError(w, "update watchlist", err)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, list)
+}
+
+// DeleteWatchlist handles DELETE /api/v1/me/watchlists/{id} requests
+func (h *WatchlistHandler) DeleteWatchlist(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodDelete) {
+		return
+	}
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.watchlists.Delete(r.Context(), user.ID, mux.Vars(r)["id"]); err != nil {
+		h.writeStoreError(w, "delete watchlist", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AddItem handles POST /api/v1/me/watchlists/{id}/items requests. Adding a title
+// that is already in the watchlist returns the existing item with 200 OK.
+func (h *WatchlistHandler) AddItem(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	var req models.WatchlistItemRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	list, ok := h.ownWatchlist(w, r)
+	if !ok {
+		return
+	}
+
+	summary, ok := checkTitle(w, r, h.titles, req.MediaType, req.MediaID)
+	if !ok {
+		return
+	}
+
+	item := &models.WatchlistItem{MediaType: req.MediaType, MediaID: req.MediaID, Note: req.Note}
+	added, err := h.watchlists.AddItem(r.Context(), list.ID, item)
+	if err != nil {
+		h.writeStoreError(w, "add watchlist item", err)
+		return
+	}
+	item.TitleSummary = summary
+
+	status := http.StatusOK
+	if added {
+		status = http.StatusCreated
+	}
+	writeJSONResponse(w, status, item)
+}
+
+// UpdateItem handles PATCH /api/v1/me/watchlists/{id}/items/{item_id} requests,
+// which change an item's note
+func (h *WatchlistHandler) UpdateItem(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPatch) {
+		return
+	}
+
+	var req models.WatchlistItemUpdateRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	list, ok := h.ownWatchlist(w, r)
+	if !ok {
+		return
+	}
+
+	item, err := h.watchlists.UpdateItemNote(r.Context(), list.ID, mux.Vars(r)["item_id"], *req.Note)
+	if err != nil {
+		h.writeStoreError(w, "update watchlist item", err)
+		return
+	}
+
+	item.TitleSummary = lookupTitles(r.Context(), h.titles, []titleRef{{mediaType: item.MediaType, mediaID: item.MediaID}})[0]
+	writeJSONResponse(w, http.StatusOK, item)
+}
+
+// RemoveItem handles DELETE /api/v1/me/watchlists/{id}/items/{item_id} requests
+func (h *WatchlistHandler) RemoveItem(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodDelete) {
+		return
+	}
+	list, ok := h.ownWatchlist(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.watchlists.RemoveItem(r.Context(), list.ID, mux.Vars(r)["item_id"]); err != nil {
+		h.writeStoreError(w, "remove watchlist item", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ReorderItems handles PUT /api/v1/me/watchlists/{id}/order requests and returns
+// the reordered watchlist
+func (h *WatchlistHandler) ReorderItems(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPut) {
+		return
+	}
+
+	var req models.WatchlistOrderRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	list, ok := h.ownWatchlist(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.watchlists.Reorder(r.Context(), list.ID, req.ItemIDs)
+	if errors.Is(err, store.ErrConflict) {
+		writeErrorResponse(w, http.StatusBadRequest, "invalid_order", "item_ids must list every item in the watchlist exactly once")
+		return
+	}
+	if err != nil {
+		h.writeStoreError(w, "reorder watchlist", err)
+		return
+	}
+
+	h.writeWatchlist(w, r, list)
+}
+
+// ownWatchlist loads the signed-in user's watchlist named by the id path
+// variable. On failure an error response has already been written and ok is false.
+func (h *WatchlistHandler) ownWatchlist(w http.ResponseWriter, r *http.Request) (*models.Watchlist, bool) {
+	user, ok := requireUser(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	list, err := h.watchlists.Get(r.Context(), user.ID, mux.Vars(r)["id"])
+	if err != nil {
+		h.writeStoreError(w, "get watchlist", err)
+		return nil, false
+	}
+	return list, true
+}
+
+// writeWatchlist writes a watchlist with its items hydrated with title details
+func (h *WatchlistHandler) writeWatchlist(w http.ResponseWriter, r *http.Request, list *models.Watchlist) {
+	items, err := h.watchlists.Items(r.Context(), list.ID)
+	if err != nil {
+		h.writeStoreError(w, "list watchlist items", err)
+		return
+	}
+
+	refs := make([]titleRef, len(items))
+	for i, item := range items {
+		refs[i] = titleRef{mediaType: item.MediaType, mediaID: item.MediaID}
+	}
+	for i, summary := range lookupTitles(r.Context(), h.titles, refs) {
+		items[i].TitleSummary = summary
+	}
+
+	list.Items = items
+	list.ItemCount = len(items)
+	writeJSONResponse(w, http.StatusOK, list)
+}
+
+// writeStoreError maps a WatchlistStore error to an error response. Watchlists of
+// other users are reported as not found.
+func (h *WatchlistHandler) writeStoreError(w http.ResponseWriter, op string, err error) {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		writeErrorResponse(w, http.StatusNotFound, "not_found", "Watchlist or item not found")
+	case errors.Is(err, store.ErrConflict):
+		writeErrorResponse(w, http.StatusConflict, "watchlist_exists", "You already have a watchlist with this name")
+	case errors.Is(err, store.ErrLimitExceeded):
+		writeErrorResponse(w, http.StatusConflict, "watchlist_full", fmt.Sprintf("A watchlist can hold at most %d titles", models.MaxWatchlistItems))
+	default:
+		log.Printf("Failed to %s: %v", op, err)
+		writeErrorResponse(w, http.StatusInternalServerError, "internal_error", fmt.Sprintf("Failed to %s", op))
+	}
+}
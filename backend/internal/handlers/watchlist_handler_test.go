@@ -0,0 +1,366 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/takeshi-arihori/movie-api/internal/models"
+	"github.com/takeshi-arihori/movie-api/internal/store"
+)
+
+// fakeWatchlistStore is an in-memory WatchlistStore
+type fakeWatchlistStore struct {
+	mu     sync.Mutex
+	lists  []*models.Watchlist
+	items  map[string][]models.WatchlistItem
+	nextID int
+}
+
+func newFakeWatchlistStore() *fakeWatchlistStore {
+	return &fakeWatchlistStore{items: make(map[string][]models.WatchlistItem)}
+}
+
+func (s *fakeWatchlistStore) id() string {
+	s.nextID++
+	return fmt.Sprintf("00000000-0000-0000-0000-%012d", s.nextID)
+}
+
+func (s *fakeWatchlistStore) find(match func(*models.Watchlist) bool) (*models.Watchlist, error) {
+	for _, list := range s.lists {
+		if match(list) {
+			copied := *list
+			copied.ItemCount = len(s.items[list.ID])
+			return &copied, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+func (s *fakeWatchlistStore) Create(ctx context.Context, list *models.Watchlist) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.find(func(l *models.Watchlist) bool { return l.UserID == list.UserID && l.Name == list.Name }); err == nil {
+		return store.ErrConflict
+	}
+	list.ID = s.id()
+	if list.IsPublic {
+		slug := "list-" + list.ID[len(list.ID)-4:]
+		list.Slug = &slug
+	}
+	stored := *list
+	s.lists = append(s.lists, &stored)
+	return nil
+}
+
+func (s *fakeWatchlistStore) Get(ctx context.Context, userID, id string) (*models.Watchlist, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.find(func(l *models.Watchlist) bool { return l.ID == id && l.UserID == userID })
+}
+
+func (s *fakeWatchlistStore) GetPublic(ctx context.Context, slug string) (*models.Watchlist, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.find(func(l *models.Watchlist) bool { return l.IsPublic && l.Slug != nil && *l.Slug == slug })
+}
+
+func (s *fakeWatchlistStore) List(ctx context.Context, userID string) ([]models.Watchlist, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lists := []models.Watchlist{}
+	for _, list := range s.lists {
+		if list.UserID == userID {
+			lists = append(lists, *list)
+		}
+	}
+	return lists, nil
+}
+
+func (s *fakeWatchlistStore) Update(ctx context.Context, list *models.Watchlist) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, stored := range s.lists {
+		if stored.ID == list.ID && stored.UserID == list.UserID {
+			if list.IsPublic && stored.Slug == nil {
+				slug := "list-" + list.ID[len(list.ID)-4:]
+				stored.Slug = &slug
+			}
+			stored.Name, stored.Description, stored.IsPublic = list.Name, list.Description, list.IsPublic
+			list.Slug = stored.Slug
+			return nil
+		}
+	}
+	return store.ErrNotFound
+}
+
+func (s *fakeWatchlistStore) Delete(ctx context.Context, userID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, list := range s.lists {
+		if list.ID == id && list.UserID == userID {
+			s.lists = append(s.lists[:i], s.lists[i+1:]...)
+			delete(s.items, id)
+			return nil
+		}
+	}
+	return store.ErrNotFound
+}
+
+func (s *fakeWatchlistStore) Items(ctx context.Context, watchlistID string) ([]models.WatchlistItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]models.WatchlistItem{}, s.items[watchlistID]...), nil
+}
+
+func (s *fakeWatchlistStore) AddItem(ctx context.Context, watchlistID string, item *models.WatchlistItem) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.items[watchlistID] {
+		if existing.MediaType == item.MediaType && existing.MediaID == item.MediaID {
+			*item = existing
+			return false, nil
+		}
+	}
+	if len(s.items[watchlistID]) >= models.MaxWatchlistItems {
+		return false, store.ErrLimitExceeded
+	}
+	item.ID = s.id()
+	item.Position = len(s.items[watchlistID]) + 1
+	s.items[watchlistID] = append(s.items[watchlistID], *item)
+	return true, nil
+}
+
+func (s *fakeWatchlistStore) UpdateItemNote(ctx context.Context, watchlistID, itemID, note string) (*models.WatchlistItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, item := range s.items[watchlistID] {
+		if item.ID == itemID {
+			s.items[watchlistID][i].Note = note
+			updated := s.items[watchlistID][i]
+			return &updated, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+func (s *fakeWatchlistStore) RemoveItem(ctx context.Context, watchlistID, itemID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := s.items[watchlistID]
+	for i, item := range items {
+		if item.ID == itemID {
+			items = append(items[:i], items[i+1:]...)
+			for j := range items {
+				items[j].Position = j + 1
+			}
+			s.items[watchlistID] = items
+			return nil
+		}
+	}
+	return store.ErrNotFound
+}
+
+func (s *fakeWatchlistStore) Reorder(ctx context.Context, watchlistID string, itemIDs []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	byID := map[string]models.WatchlistItem{}
+	for _, item := range s.items[watchlistID] {
+		byID[item.ID] = item
+	}
+	if len(itemIDs) != len(byID) {
+		return store.ErrConflict
+	}
+	reordered := make([]models.WatchlistItem, 0, len(itemIDs))
+	for i, id := range itemIDs {
+		item, ok := byID[id]
+		if !ok {
+			return store.ErrConflict
+		}
+		delete(byID, id)
+		item.Position = i + 1
+		reordered = append(reordered, item)
+	}
+	s.items[watchlistID] = reordered
+	return nil
+}
+
+// watchlistRequest builds a request from user with the given route variables
+func watchlistRequest(method, target, body, userID string, vars map[string]string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if userID != "" {
+		req = withUser(req, userID)
+	}
+	return mux.SetURLVars(req, vars)
+}
+
+func TestWatchlistHandler_AddItemFull(t *testing.T) {
+	watchlists := newFakeWatchlistStore()
+	handler := NewWatchlistHandler(watchlists, newMockTitleDetailsClient())
+	list := &models.Watchlist{UserID: "user-1", Name: "Everything"}
+	watchlists.Create(context.Background(), list)
+	for i := 1; i < models.MaxWatchlistItems; i++ {
+		watchlists.AddItem(context.Background(), list.ID, &models.WatchlistItem{MediaType: models.SearchItemTypeTV, MediaID: i})
+	}
+	watchlists.AddItem(context.Background(), list.ID, &models.WatchlistItem{MediaType: models.SearchItemTypeMovie, MediaID: 550})
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{"new title", `{"media_type":"movie","media_id":603}`, http.StatusConflict},
+		{"title already in the list", `{"media_type":"movie","media_id":550}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.AddItem(w, watchlistRequest("POST", "/api/v1/me/watchlists/"+list.ID+"/items", tt.body, "user-1", map[string]string{"id": list.ID}))
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedStatus == http.StatusConflict && !strings.Contains(w.Body.String(), "watchlist_full") {
+				t.Errorf("Expected a watchlist_full error, got %s", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestWatchlistHandler_Lifecycle(t *testing.T) {
+	watchlists := newFakeWatchlistStore()
+	handler := NewWatchlistHandler(watchlists, newMockTitleDetailsClient())
+
+	// Create
+	w := httptest.NewRecorder()
+	handler.CreateWatchlist(w, watchlistRequest("POST", "/api/v1/me/watchlists", `{"name":"Ghibli marathon"}`, "user-1", nil))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var list models.Watchlist
+	json.NewDecoder(w.Body).Decode(&list)
+	if list.IsPublic || list.Slug != nil {
+		t.Errorf("Expected a private watchlist without a slug, got %+v", list)
+	}
+
+	w = httptest.NewRecorder()
+	handler.CreateWatchlist(w, watchlistRequest("POST", "/api/v1/me/watchlists", `{"name":"Ghibli marathon"}`, "user-1", nil))
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 for a duplicate name, got %d", w.Code)
+	}
+
+	// Items
+	vars := map[string]string{"id": list.ID}
+	var itemIDs []string
+	for _, body := range []string{
+		`{"media_type":"movie","media_id":603,"note":"Not Ghibli, but still"}`,
+		`{"media_type":"tv","media_id":1399}`,
+		`{"media_type":"movie","media_id":550}`,
+	} {
+		w = httptest.NewRecorder()
+		handler.AddItem(w, watchlistRequest("POST", "/api/v1/me/watchlists/"+list.ID+"/items", body, "user-1", vars))
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+		}
+		var item models.WatchlistItem
+		json.NewDecoder(w.Body).Decode(&item)
+		if item.Title == "" {
+			t.Errorf("Expected a hydrated item, got %+v", item)
+		}
+		itemIDs = append(itemIDs, item.ID)
+	}
+
+	addTests := []struct {
+		name           string
+		body           string
+		userID         string
+		expectedStatus int
+	}{
+		{"duplicate is idempotent", `{"media_type":"movie","media_id":603}`, "user-1", http.StatusOK},
+		{"unknown title", `{"media_type":"movie","media_id":999999}`, "user-1", http.StatusNotFound},
+		{"note too long", `{"media_type":"movie","media_id":550,"note":"` + strings.Repeat("x", 1001) + `"}`, "user-1", http.StatusBadRequest},
+		{"another user's watchlist", `{"media_type":"movie","media_id":550}`, "user-2", http.StatusNotFound},
+	}
+	for _, tt := range addTests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.AddItem(w, watchlistRequest("POST", "/api/v1/me/watchlists/"+list.ID+"/items", tt.body, tt.userID, vars))
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	// Reorder
+	w = httptest.NewRecorder()
+	order := fmt.Sprintf(`{"item_ids":["%s","%s","%s"]}`, itemIDs[2], itemIDs[0], itemIDs[1])
+	handler.ReorderItems(w, watchlistRequest("PUT", "/api/v1/me/watchlists/"+list.ID+"/order", order, "user-1", vars))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	json.NewDecoder(w.Body).Decode(&list)
+	if len(list.Items) != 3 || list.Items[0].MediaID != 550 || list.Items[0].Position != 1 || list.Items[2].MediaID != 1399 {
+		t.Errorf("Unexpected order: %+v", list.Items)
+	}
+
+	w = httptest.NewRecorder()
+	order = fmt.Sprintf(`{"item_ids":["%s","%s"]}`, itemIDs[2], itemIDs[0])
+	handler.ReorderItems(w, watchlistRequest("PUT", "/api/v1/me/watchlists/"+list.ID+"/order", order, "user-1", vars))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an incomplete order, got %d", w.Code)
+	}
+
+	// Notes
+	w = httptest.NewRecorder()
+	itemVars := map[string]string{"id": list.ID, "item_id": itemIDs[1]}
+	handler.UpdateItem(w, watchlistRequest("PATCH", "/api/v1/me/watchlists/"+list.ID+"/items/"+itemIDs[1], `{"note":"Season 1 only"}`, "user-1", itemVars))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Season 1 only") {
+		t.Errorf("Expected the updated note, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Sharing
+	w = httptest.NewRecorder()
+	handler.UpdateWatchlist(w, watchlistRequest("PATCH", "/api/v1/me/watchlists/"+list.ID, `{"is_public":true}`, "user-1", vars))
+	json.NewDecoder(w.Body).Decode(&list)
+	if w.Code != http.StatusOK || !list.IsPublic || list.Slug == nil {
+		t.Fatalf("Expected a public watchlist with a slug, got %d: %+v", w.Code, list)
+	}
+
+	w = httptest.NewRecorder()
+	handler.GetPublicWatchlist(w, watchlistRequest("GET", "/api/v1/watchlists/"+*list.Slug, "", "", map[string]string{"slug": *list.Slug}))
+	var shared models.Watchlist
+	json.NewDecoder(w.Body).Decode(&shared)
+	if w.Code != http.StatusOK || shared.UserID != "" || len(shared.Items) != 3 {
+		t.Errorf("Expected the shared watchlist without its owner, got %d: %+v", w.Code, shared)
+	}
+
+	w = httptest.NewRecorder()
+	handler.UpdateWatchlist(w, watchlistRequest("PATCH", "/api/v1/me/watchlists/"+list.ID, `{"is_public":false}`, "user-1", vars))
+	w = httptest.NewRecorder()
+	handler.GetPublicWatchlist(w, watchlistRequest("GET", "/api/v1/watchlists/"+*list.Slug, "", "", map[string]string{"slug": *list.Slug}))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for a private watchlist, got %d", w.Code)
+	}
+
+	// Removal
+	w = httptest.NewRecorder()
+	handler.RemoveItem(w, watchlistRequest("DELETE", "/api/v1/me/watchlists/"+list.ID+"/items/"+itemIDs[1], "", "user-1", itemVars))
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	handler.DeleteWatchlist(w, watchlistRequest("DELETE", "/api/v1/me/watchlists/"+list.ID, "", "user-2", vars))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 deleting another user's watchlist, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	handler.DeleteWatchlist(w, watchlistRequest("DELETE", "/api/v1/me/watchlists/"+list.ID, "", "user-1", vars))
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", w.Code)
+	}
+}
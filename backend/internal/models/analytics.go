@@ -0,0 +1,45 @@
+// Package models provides search history and analytics data structures.
+package models
+
+import "time"
+
+// SearchHistoryEntry represents a single recorded search
+type SearchHistoryEntry struct {
+	UserID       *string   `json:"user_id,omitempty"`
+	Query        string    `json:"query"`
+	SearchType   string    `json:"search_type"`
+	ResultsCount int       `json:"results_count"`
+	IPAddress    string    `json:"ip_address,omitempty"`
+	UserAgent    string    `json:"user_agent,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// HistoryQuery is a past search query and how often it was searched, as used for
+// search suggestions
+type HistoryQuery struct {
+	Query string `json:"query"`
+	Count int    `json:"count"`
+}
+
+// QueryStat represents how often a query was searched in an analytics window
+type QueryStat struct {
+	Query          string    `json:"query"`
+	SearchCount    int       `json:"search_count"`
+	AverageResults float64   `json:"average_results"`
+	LastSearched   time.Time `json:"last_searched"`
+}
+
+// VolumeBucket represents search volume for one time interval
+type VolumeBucket struct {
+	Bucket        time.Time `json:"bucket"`
+	Searches      int       `json:"searches"`
+	ZeroResults   int       `json:"zero_results"`
+	UniqueQueries int       `json:"unique_queries"`
+}
+
+// AnalyticsResponse represents the API response for a search analytics report
+type AnalyticsResponse[T any] struct {
+	Since    time.Time `json:"since"`
+	Interval string    `json:"interval,omitempty"` // Bucket size for volume reports
+	Results  []T       `json:"results"`
+}
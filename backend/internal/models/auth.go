@@ -0,0 +1,43 @@
+// Package models provides authentication request and response structures.
+package models
+
+import "time"
+
+// RegisterRequest represents a new account registration
+type RegisterRequest struct {
+	Email    string `json:"email" validate:"required,email,max=255"`
+	Username string `json:"username" validate:"required,min=3,max=50,alphanum"`
+	Password string `json:"password" validate:"required,min=8,maxbytes=72"` // bcrypt rejects passwords over 72 bytes
+}
+
+// LoginRequest represents a login with an email address or username
+type LoginRequest struct {
+	Login    string `json:"login" validate:"required,max=255"` // Email address or username
+	Password string `json:"password" validate:"required,maxbytes=72"`
+}
+
+// RefreshRequest carries a refresh token, for refreshing a session or logging out
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// AuthResponse represents a newly issued session
+type AuthResponse struct {
+	AccessToken      string    `json:"access_token"`
+	TokenType        string    `json:"token_type"` // Always "Bearer"
+	ExpiresIn        int       `json:"expires_in"` // Access token lifetime in seconds
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+	User             *User     `json:"user"`
+}
+
+// RefreshToken represents a stored refresh token. Only a hash of the token is kept.
+type RefreshToken struct {
+	ID        string
+	UserID    string
+	FamilyID  string // Shared by the tokens issued by rotating one login's token
+	TokenHash string
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
@@ -64,6 +64,16 @@ type SearchResponse[T any] struct {
 	TotalResults int `json:"total_results" validate:"min=0"`
 }
 
+// CursorPage represents a cursor-paginated API response stitched from one or more TMDb pages
+type CursorPage[T any] struct {
+	Results      []T    `json:"results"`
+	TotalResults int    `json:"total_results"`
+	Limit        int    `json:"limit"`
+	NextCursor   string `json:"next_cursor,omitempty"` // Empty when there are no more results
+	Sort         string `json:"sort,omitempty"`        // Ranking applied to Results, if any
+	Stale        bool   `json:"stale,omitempty"`       // Served from the local catalogue while TMDb is unavailable
+}
+
 // ErrorResponse represents an error response from TMDb API
 type ErrorResponse struct {
 	StatusCode    int    `json:"status_code" validate:"required"`
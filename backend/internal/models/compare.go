@@ -0,0 +1,67 @@
+// Package models provides title comparison data structures.
+package models
+
+// ComparedTitle identifies a movie or TV show in a comparison
+type ComparedTitle struct {
+	ID         int     `json:"id"`
+	MediaType  string  `json:"media_type"` // "movie" or "tv"
+	Title      string  `json:"title"`
+	PosterPath *string `json:"poster_path"`
+}
+
+// NumericComparison compares a number across titles. Highest and Lowest are only
+// set when the known values differ.
+type NumericComparison struct {
+	Values  []*float64 `json:"values"` // Aligned with the titles; null when unknown
+	Differs bool       `json:"differs"`
+	Highest []int      `json:"highest,omitempty"` // Indexes of the titles with the highest value
+	Lowest  []int      `json:"lowest,omitempty"`  // Indexes of the titles with the lowest value
+}
+
+// DateComparison compares a date across titles. Earliest and Latest are only set
+// when the known dates differ.
+type DateComparison struct {
+	Values   []*string `json:"values"` // Aligned with the titles; format: YYYY-MM-DD, null when unknown
+	Differs  bool      `json:"differs"`
+	Earliest []int     `json:"earliest,omitempty"` // Indexes of the titles with the earliest date
+	Latest   []int     `json:"latest,omitempty"`   // Indexes of the titles with the latest date
+}
+
+// SetComparison compares a set of names, such as genres, across titles
+type SetComparison struct {
+	Values  [][]string `json:"values"` // Aligned with the titles
+	Differs bool       `json:"differs"`
+	Common  []string   `json:"common"` // Names every title has
+	Unique  [][]string `json:"unique"` // Aligned with the titles; the names not every title has
+}
+
+// ComparisonFields compares the fields of titles
+type ComparisonFields struct {
+	Runtime             NumericComparison `json:"runtime"` // In minutes; the usual episode runtime for TV shows
+	ReleaseDate         DateComparison    `json:"release_date"`
+	Budget              NumericComparison `json:"budget"`  // In US dollars; unknown for TV shows
+	Revenue             NumericComparison `json:"revenue"` // In US dollars; unknown for TV shows
+	ROI                 NumericComparison `json:"roi"`     // (revenue - budget) / budget
+	VoteAverage         NumericComparison `json:"vote_average"`
+	VoteCount           NumericComparison `json:"vote_count"`
+	Popularity          NumericComparison `json:"popularity"`
+	Genres              SetComparison     `json:"genres"`
+	ProductionCountries SetComparison     `json:"production_countries"`
+}
+
+// SharedPerson is someone credited on more than one compared title
+type SharedPerson struct {
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	ProfilePath *string    `json:"profile_path"`
+	Count       int        `json:"count"` // Number of titles they are credited on
+	Roles       [][]string `json:"roles"` // Aligned with the titles; their characters or jobs, empty when not credited
+}
+
+// Comparison represents movies and TV shows compared side by side
+type Comparison struct {
+	Titles     []ComparedTitle  `json:"titles"`
+	Fields     ComparisonFields `json:"fields"`
+	SharedCast []SharedPerson   `json:"shared_cast"`
+	SharedCrew []SharedPerson   `json:"shared_crew"`
+}
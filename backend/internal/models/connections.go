@@ -0,0 +1,82 @@
+// Package models provides person collaborator and connection data structures.
+package models
+
+// Reasons a PersonConnection was not found
+const (
+	// ConnectionUnreachable means every person within the fan-out limits was explored
+	ConnectionUnreachable = "unreachable"
+	// ConnectionDepthLimit means the people were not connected within the maximum depth
+	ConnectionDepthLimit = "depth_limit"
+	// ConnectionTimeBudget means the search ran out of time
+	ConnectionTimeBudget = "time_budget"
+)
+
+// SharedTitle is a movie a person made with a collaborator
+type SharedTitle struct {
+	ID          int      `json:"id"`
+	Title       string   `json:"title"`
+	ReleaseDate string   `json:"release_date,omitempty"` // Format: YYYY-MM-DD
+	PosterPath  *string  `json:"poster_path"`
+	Characters  []string `json:"characters,omitempty"` // The collaborator's characters in the movie
+	Jobs        []string `json:"jobs,omitempty"`       // The collaborator's jobs on the movie
+}
+
+// Collaborator is someone a person has worked with
+type Collaborator struct {
+	ID                 int           `json:"id"`
+	Name               string        `json:"name"`
+	ProfilePath        *string       `json:"profile_path"`
+	KnownForDepartment string        `json:"known_for_department,omitempty"`
+	SharedCount        int           `json:"shared_count"`
+	SharedTitles       []SharedTitle `json:"shared_titles"` // Latest first
+}
+
+// Collaborators represents the people a person has worked with most
+type Collaborators struct {
+	PersonID      int            `json:"person_id"`
+	Results       []Collaborator `json:"results"`
+	TitlesScanned int            `json:"titles_scanned"`
+	Truncated     bool           `json:"truncated"` // Only the person's most popular movies were scanned
+}
+
+// ConnectionRequest represents a search for the chain of movies linking two actors
+type ConnectionRequest struct {
+	From     int `json:"from" validate:"required,min=1"`
+	To       int `json:"to" validate:"required,min=1"`
+	MaxDepth int `json:"max_depth" validate:"min=1,max=6"` // Maximum number of movies in the chain
+	FanOut   int `json:"fan_out" validate:"min=1,max=50"`  // Movies followed per person, and cast members per movie
+}
+
+// ConnectionPerson is an actor in a chain of movies
+type ConnectionPerson struct {
+	ID          int     `json:"id"`
+	Name        string  `json:"name"`
+	ProfilePath *string `json:"profile_path"`
+	Character   string  `json:"character,omitempty"` // Their character in the linking movie
+}
+
+// ConnectionMovie is a movie linking two actors
+type ConnectionMovie struct {
+	ID          int     `json:"id"`
+	Title       string  `json:"title"`
+	ReleaseDate string  `json:"release_date,omitempty"` // Format: YYYY-MM-DD
+	PosterPath  *string `json:"poster_path"`
+}
+
+// ConnectionLink is two actors appearing in the same movie
+type ConnectionLink struct {
+	From  ConnectionPerson `json:"from"`
+	Movie ConnectionMovie  `json:"movie"`
+	To    ConnectionPerson `json:"to"`
+}
+
+// PersonConnection represents the shortest chain of movies found between two actors
+type PersonConnection struct {
+	From           int              `json:"from"`
+	To             int              `json:"to"`
+	Found          bool             `json:"found"`
+	Degrees        int              `json:"degrees"` // Number of movies in the chain
+	Links          []ConnectionLink `json:"links"`
+	Reason         string           `json:"reason,omitempty"` // Why no chain was found
+	PeopleExplored int              `json:"people_explored"`
+}
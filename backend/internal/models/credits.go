@@ -0,0 +1,58 @@
+// Package models provides title credits filtering and summary data structures.
+package models
+
+// Views of a title's credits
+const (
+	CreditsViewFull    = "full"
+	CreditsViewSummary = "summary"
+)
+
+// CreditsOptions represents the filters and view of a title's credits
+type CreditsOptions struct {
+	Department string `json:"department" validate:"max=50"` // Matched case-insensitively; cast members are in "Acting"
+	Job        string `json:"job" validate:"max=100"`       // Crew job, matched case-insensitively
+	Limit      int    `json:"limit" validate:"min=0"`       // Top-billed cast members returned; all when 0
+	MergeCrew  bool   `json:"dedupe_crew"`                  // List crew members holding several jobs once
+	View       string `json:"view" validate:"omitempty,oneof=full summary"`
+}
+
+// FilteredCredits represents a title's credits after CreditsOptions are applied
+type FilteredCredits[C any, W any] struct {
+	ID        int `json:"id"`
+	Cast      []C `json:"cast"`
+	Crew      []W `json:"crew"`
+	TotalCast int `json:"total_cast"` // Before filtering
+	TotalCrew int `json:"total_crew"` // Before filtering
+}
+
+// MergedCrewMember is a crew member with all their jobs on a title
+type MergedCrewMember struct {
+	ID                 int      `json:"id"`
+	Name               string   `json:"name"`
+	OriginalName       string   `json:"original_name"`
+	Gender             *int     `json:"gender"`
+	KnownForDepartment string   `json:"known_for_department"`
+	Popularity         float64  `json:"popularity"`
+	ProfilePath        *string  `json:"profile_path"`
+	Departments        []string `json:"departments"`
+	Jobs               []string `json:"jobs"`
+	CreditIDs          []string `json:"credit_ids"`
+}
+
+// KeyCrewMember is someone holding a key job on a title
+type KeyCrewMember struct {
+	ID          int      `json:"id"`
+	Name        string   `json:"name"`
+	ProfilePath *string  `json:"profile_path"`
+	Jobs        []string `json:"jobs"` // Their jobs in this role, such as Screenplay and Story for writers
+}
+
+// CreditsSummary represents the key crew of a title
+type CreditsSummary struct {
+	ID               int             `json:"id"`
+	Directors        []KeyCrewMember `json:"directors"`
+	Writers          []KeyCrewMember `json:"writers"`
+	Composers        []KeyCrewMember `json:"composers"`
+	Cinematographers []KeyCrewMember `json:"cinematographers"`
+	Producers        []KeyCrewMember `json:"producers"`
+}
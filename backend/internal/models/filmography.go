@@ -0,0 +1,46 @@
+// Package models provides person filmography data structures.
+package models
+
+// FilmographyEntry is one title a person worked on, merging every cast and crew
+// credit they have on it
+type FilmographyEntry struct {
+	ID            int      `json:"id"`
+	MediaType     string   `json:"media_type"` // "movie" or "tv"
+	Title         string   `json:"title"`
+	OriginalTitle string   `json:"original_title,omitempty"`
+	ReleaseDate   string   `json:"release_date,omitempty"` // First air date for TV shows; format: YYYY-MM-DD
+	Upcoming      bool     `json:"upcoming"`               // Not released yet, or without a release date
+	PosterPath    *string  `json:"poster_path"`
+	VoteAverage   float64  `json:"vote_average"`
+	VoteCount     int      `json:"vote_count"`
+	Characters    []string `json:"characters,omitempty"`
+	Jobs          []string `json:"jobs,omitempty"`
+	Departments   []string `json:"departments"` // Cast credits count as "Acting"
+	EpisodeCount  int      `json:"episode_count,omitempty"`
+}
+
+// FilmographyDepartment lists a year's entries in one department
+type FilmographyDepartment struct {
+	Department string             `json:"department"`
+	Entries    []FilmographyEntry `json:"entries"`
+}
+
+// FilmographyYear groups the entries released in one year by department
+type FilmographyYear struct {
+	Year        int                     `json:"year"` // 0 for titles without a release date
+	Departments []FilmographyDepartment `json:"departments"`
+}
+
+// Filmography represents a person's credits as a timeline, latest first
+type Filmography struct {
+	PersonID     int               `json:"person_id"`
+	TotalEntries int               `json:"total_entries"`
+	Years        []FilmographyYear `json:"years"`
+}
+
+// FilmographyFilter represents the filters of a filmography
+type FilmographyFilter struct {
+	MediaType    string `json:"media_type" validate:"omitempty,oneof=movie tv"`
+	Department   string `json:"department" validate:"omitempty,max=50"` // Matched case-insensitively
+	MinVoteCount int    `json:"min_vote_count" validate:"min=0"`
+}
@@ -0,0 +1,52 @@
+// Package models provides review moderation and notification data structures.
+package models
+
+import "time"
+
+// TrustedReviewerApprovals is the number of approved reviews, with none rejected,
+// after which a user's reviews are published without moderation
+const TrustedReviewerApprovals = 3
+
+// Review moderation actions, as in ReviewModerationRequest.Action
+const (
+	ReviewActionApprove = "approve"
+	ReviewActionReject  = "reject"
+)
+
+// ReviewModerationEntry is an audit record of a moderator's decision on a review
+type ReviewModerationEntry struct {
+	ID          string    `json:"id"`
+	ReviewID    string    `json:"review_id"`
+	ModeratorID string    `json:"moderator_id"`
+	Action      string    `json:"action"`
+	Reason      string    `json:"reason"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Notification types
+const (
+	NotificationReviewApproved = "review_approved"
+	NotificationReviewRejected = "review_rejected"
+)
+
+// Notification is a message to a user, such as a moderation decision on their review
+type Notification struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"-"`
+	Type      string     `json:"type"`
+	Message   string     `json:"message"`
+	ReviewID  *string    `json:"review_id,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	ReadAt    *time.Time `json:"read_at"`
+}
+
+// NotificationListResponse is a page of a user's notifications, newest first
+type NotificationListResponse struct {
+	Notifications []Notification `json:"notifications"`
+	Page          int            `json:"page"`
+	TotalPages    int            `json:"total_pages"`
+	TotalResults  int            `json:"total_results"`
+	Unread        int            `json:"unread"`
+	HasNext       bool           `json:"has_next"`
+	HasPrevious   bool           `json:"has_previous"`
+}
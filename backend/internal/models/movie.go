@@ -46,6 +46,7 @@ type MovieDetails struct {
 	Video               bool                 `json:"video"`
 	VoteAverage         float64              `json:"vote_average"`
 	VoteCount           int                  `json:"vote_count"`
+	Stale               bool                 `json:"stale,omitempty"` // Served from the local catalogue while TMDb is unavailable
 }
 
 // MovieSearchResponse represents a search response for movies
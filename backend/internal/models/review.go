@@ -12,6 +12,22 @@ type Review struct {
 	ID            string      `json:"id" validate:"required"`
 	UpdatedAt     time.Time   `json:"updated_at" validate:"required"`
 	URL           string      `json:"url" validate:"required"`
+	Analysis      *ReviewAnalysis `json:"analysis,omitempty"` // Only when analysis was asked for
+}
+
+// Review sentiment labels
+const (
+	SentimentPositive = "positive"
+	SentimentNegative = "negative"
+	SentimentNeutral  = "neutral"
+)
+
+// ReviewAnalysis holds the results of analysing a review's text offline
+type ReviewAnalysis struct {
+	Language          string  `json:"language,omitempty"` // ISO 639-1 code, empty when undetected
+	Sentiment         float64 `json:"sentiment"`          // -1 (negative) to 1 (positive)
+	SentimentLabel    string  `json:"sentiment_label"`    // Sentiment* values
+	SpoilerLikelihood float64 `json:"spoiler_likelihood"` // 0 to 1
 }
 
 // AuthorDetails represents author details in a review
@@ -40,7 +56,39 @@ type TVReviews struct {
 	TotalResults int      `json:"total_results" validate:"required,min=0"`
 }
 
-// UserReview represents a user-generated review (for future implementation)
+// RenderedReview is a TMDb review with its content rendered for display
+type RenderedReview struct {
+	Review
+	ContentHTML string `json:"content_html"` // Sanitized HTML of the Markdown content
+	ContentText string `json:"content_text"`
+	Excerpt     string `json:"excerpt"`
+	Length      int    `json:"length"` // Characters of plain text content
+}
+
+// RenderedReviewList represents every TMDb review of a movie or TV show, rendered
+// for display
+type RenderedReviewList struct {
+	ID           int              `json:"id"`
+	Results      []RenderedReview `json:"results"`
+	TotalResults int              `json:"total_results"`
+	Truncated    bool             `json:"truncated"` // TMDb has more reviews than were fetched
+}
+
+// RenderedReviewSort represents the order of a RenderedReviewList. Without
+// SortBy, reviews keep TMDb's order.
+type RenderedReviewSort struct {
+	SortBy    string `json:"sort_by" validate:"omitempty,oneof=created_at updated_at rating length"`
+	SortOrder string `json:"sort_order" validate:"omitempty,oneof=asc desc"`
+}
+
+// User review moderation statuses
+const (
+	ReviewStatusPending  = "pending"
+	ReviewStatusApproved = "approved"
+	ReviewStatusRejected = "rejected"
+)
+
+// UserReview represents a user-generated review
 type UserReview struct {
 	ID        string    `json:"id" validate:"required"`
 	UserID    string    `json:"user_id" validate:"required"`
@@ -56,6 +104,10 @@ type UserReview struct {
 	Helpful   int    `json:"helpful"`
 	NotHelpful int   `json:"not_helpful"`
 	Status    string `json:"status" validate:"required,oneof=pending approved rejected"`
+	// Moderation details, hidden from public views
+	Flagged          bool     `json:"flagged,omitempty"`           // Pre-screening found something for moderators to check
+	FlagReasons      []string `json:"flag_reasons,omitempty"`      // moderation.Flag* values
+	ModerationReason string   `json:"moderation_reason,omitempty"` // Moderator's reason for the current status
 }
 
 // ReviewStats represents aggregated review statistics
@@ -122,6 +174,29 @@ type ReviewListResponse struct {
 	HasPrevious  bool         `json:"has_previous"`
 }
 
+// Review sources in a unified review listing
+const (
+	ReviewSourceTMDb = "tmdb"
+	ReviewSourceUser = "user"
+)
+
+// UnifiedReview is a TMDb review or a user review in a listing that combines both
+type UnifiedReview struct {
+	Source     string      `json:"source"` // ReviewSourceTMDb or ReviewSourceUser
+	TMDbReview *Review     `json:"tmdb_review,omitempty"`
+	UserReview *UserReview `json:"user_review,omitempty"`
+}
+
+// UnifiedReviewListResponse represents a paginated list of TMDb and user reviews
+type UnifiedReviewListResponse struct {
+	Reviews      []UnifiedReview `json:"reviews"`
+	Page         int             `json:"page"`
+	TotalPages   int             `json:"total_pages"`
+	TotalResults int             `json:"total_results"`
+	HasNext      bool            `json:"has_next"`
+	HasPrevious  bool            `json:"has_previous"`
+}
+
 // ReviewFilter represents filters for querying reviews
 type ReviewFilter struct {
 	MediaID     *int     `json:"media_id,omitempty"`
@@ -139,18 +214,25 @@ type ReviewFilter struct {
 
 // ReviewModerationRequest represents a request to moderate a review
 type ReviewModerationRequest struct {
-	ReviewID string `json:"review_id" validate:"required"`
+	ReviewID string `json:"review_id" validate:"required,uuid"`
 	Action   string `json:"action" validate:"required,oneof=approve reject"`
 	Reason   string `json:"reason,omitempty" validate:"omitempty,max=500"`
 }
 
 // ReviewHelpfulnessRequest represents a request to mark a review as helpful/not helpful
 type ReviewHelpfulnessRequest struct {
-	ReviewID string `json:"review_id" validate:"required"`
-	UserID   string `json:"user_id" validate:"required"`
+	ReviewID string `json:"-"` // Taken from the URL
+	UserID   string `json:"-"` // Taken from the authenticated user
 	Helpful  bool   `json:"helpful"`
 }
 
+// Helpfulness vote outcomes reported in ReviewHelpfulnessResponse.UserAction
+const (
+	ReviewVoteHelpful    = "helpful"
+	ReviewVoteNotHelpful = "not_helpful"
+	ReviewVoteRemoved    = "removed"
+)
+
 // ReviewHelpfulnessResponse represents the response to a helpfulness action
 type ReviewHelpfulnessResponse struct {
 	ReviewID    string `json:"review_id"`
@@ -1,6 +1,8 @@
 // Package models provides search-related data structures for multi-search functionality.
 package models
 
+import "fmt"
+
 // SearchItemType represents the type of search result item
 type SearchItemType string
 
@@ -56,22 +58,47 @@ type MultiSearchResponse struct {
 
 // SearchRequest represents the parameters for a search request
 type SearchRequest struct {
-	Query    string `json:"query" validate:"required,min=1"`
-	Type     string `json:"type,omitempty"`     // movie, tv, person, all
-	Page     int    `json:"page,omitempty"`     // Default: 1
-	Language string `json:"language,omitempty"` // Default: ja-JP
-	Year     int    `json:"year,omitempty"`     // For movies only
+	Query              string `json:"query" validate:"required,min=1"`
+	Type               string `json:"type,omitempty"`                 // movie, tv, person, all
+	Page               int    `json:"page,omitempty"`                 // Default: 1
+	Language           string `json:"language,omitempty"`             // Default: ja-JP
+	Year               int    `json:"year,omitempty"`                 // For movies only
+	PrimaryReleaseYear int    `json:"primary_release_year,omitempty"` // For movies only
+	FirstAirDateYear   int    `json:"first_air_date_year,omitempty"`  // For TV shows only
+	IncludeAdult       bool   `json:"include_adult,omitempty"`
+	Region             string `json:"region,omitempty"` // ISO 3166-1 alpha-2 code
+	Facets             bool   `json:"facets,omitempty"`   // Faceted search, type=all only
+	PerType            int    `json:"per_type,omitempty"` // Results per facet, default: 5
+	Limit              int    `json:"limit,omitempty"`    // Cursor pagination window size
+	Cursor             string `json:"cursor,omitempty"`   // Opaque cursor from a previous response
+	Sort               string `json:"sort,omitempty"`     // Ranking applied to the fetched results
+}
+
+// UsesCursor reports whether the request asks for cursor-based pagination
+func (sr *SearchRequest) UsesCursor() bool {
+	return sr.Limit > 0 || sr.Cursor != ""
 }
 
-// APISearchResponse represents the API response for search requests
+// MaxFacetResults is the maximum number of results returned per facet
+const MaxFacetResults = 20
+
+// APISearchResponse represents the API response for search requests.
+// Page and TotalPages are set for page-based requests; Limit and NextCursor for
+// cursor-based requests.
 type APISearchResponse struct {
 	Query        string               `json:"query"`
 	Type         string               `json:"type"`
-	Page         int                  `json:"page"`
-	TotalPages   int                  `json:"total_pages"`
+	Page         int                  `json:"page,omitempty"`
+	TotalPages   int                  `json:"total_pages,omitempty"`
 	TotalResults int                  `json:"total_results"`
 	Results      []MultiSearchResult  `json:"results"`
 	Language     string               `json:"language"`
+	Limit        int                  `json:"limit,omitempty"`
+	NextCursor   string               `json:"next_cursor,omitempty"`
+	Sort         string               `json:"sort"`                    // Ranking that was applied to Results
+	QueryVariant string               `json:"query_variant"`           // Query form that produced Results, e.g. original or romaji
+	MatchedQuery string               `json:"matched_query,omitempty"` // Set when a variant other than the original matched
+	Stale        bool                 `json:"stale,omitempty"`         // Served from the local catalogue while TMDb is unavailable
 }
 
 // Validate validates the search request parameters
@@ -92,7 +119,44 @@ func (sr *SearchRequest) Validate() error {
 		return &ValidationError{Field: "year", Message: "Year must be a positive number"}
 	}
 	
-	return nil
+	if sr.Facets && sr.Type != "" && sr.Type != "all" {
+		return &ValidationError{Field: "facets", Message: "Faceted search is only supported for type=all"}
+	}
+	
+	if sr.UsesCursor() && sr.Page > 0 {
+		return &ValidationError{Field: "page", Message: "page cannot be combined with limit or cursor"}
+	}
+	
+	if sr.UsesCursor() && sr.Facets {
+		return &ValidationError{Field: "facets", Message: "Faceted search does not support limit or cursor"}
+	}
+	
+	if sr.Facets && sr.Sort != "" && sr.Sort != "tmdb" {
+		return &ValidationError{Field: "sort", Message: "Faceted search does not support sort"}
+	}
+	
+	if sr.PerType < 0 || sr.PerType > MaxFacetResults {
+		return &ValidationError{Field: "per_type", Message: fmt.Sprintf("per_type must be between 1 and %d", MaxFacetResults)}
+	}
+	
+	return sr.ToSearchOptions().Filter.validateFor(sr.Type)
+}
+
+// ToSearchOptions converts the request into the options understood by the TMDb client
+func (sr *SearchRequest) ToSearchOptions() SearchOptions {
+	return SearchOptions{
+		Query:    sr.Query,
+		Type:     sr.Type,
+		Page:     sr.Page,
+		Language: sr.Language,
+		Filter: SearchFilter{
+			IncludeAdult:       sr.IncludeAdult,
+			Year:               sr.Year,
+			PrimaryReleaseYear: sr.PrimaryReleaseYear,
+			FirstAirDateYear:   sr.FirstAirDateYear,
+			Region:             sr.Region,
+		},
+	}
 }
 
 // SetDefaults sets default values for optional fields
@@ -106,6 +170,12 @@ func (sr *SearchRequest) SetDefaults() {
 	if sr.Language == "" {
 		sr.Language = "ja-JP"
 	}
+	if sr.Facets && sr.PerType == 0 {
+		sr.PerType = 5
+	}
+	if sr.Sort == "" {
+		sr.Sort = "tmdb"
+	}
 }
 
 // ValidationError represents a validation error
@@ -243,10 +313,44 @@ func getStringValue(s *string) string {
 
 // SearchFilter represents filtering options for search
 type SearchFilter struct {
-	IncludeAdult bool   `json:"include_adult,omitempty"`
-	Year         int    `json:"year,omitempty"`
-	PrimaryReleaseYear int `json:"primary_release_year,omitempty"`
-	Region       string `json:"region,omitempty"`
+	IncludeAdult       bool   `json:"include_adult,omitempty"`
+	Year               int    `json:"year,omitempty"`                 // Movies only
+	PrimaryReleaseYear int    `json:"primary_release_year,omitempty"` // Movies only
+	FirstAirDateYear   int    `json:"first_air_date_year,omitempty"`  // TV shows only
+	Region             string `json:"region,omitempty"`
+}
+
+// validateFor rejects filters that TMDb does not support for the given search type.
+// An empty type is treated as "all" (multi-search).
+func (f SearchFilter) validateFor(searchType string) error {
+	if searchType != "movie" {
+		if f.Year != 0 {
+			return &ValidationError{Field: "year", Message: "year filter is only supported for type=movie"}
+		}
+		if f.PrimaryReleaseYear != 0 {
+			return &ValidationError{Field: "primary_release_year", Message: "primary_release_year filter is only supported for type=movie"}
+		}
+	}
+	if searchType != "tv" && f.FirstAirDateYear != 0 {
+		return &ValidationError{Field: "first_air_date_year", Message: "first_air_date_year filter is only supported for type=tv"}
+	}
+	if f.Region != "" && !isRegionCode(f.Region) {
+		return &ValidationError{Field: "region", Message: "region must be an ISO 3166-1 alpha-2 code (e.g. JP)"}
+	}
+	return nil
+}
+
+// isRegionCode reports whether s looks like an upper-case ISO 3166-1 alpha-2 code
+func isRegionCode(s string) bool {
+	if len(s) != 2 {
+		return false
+	}
+	for _, r := range s {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
 }
 
 // SearchOptions represents all search configuration options
@@ -256,4 +360,56 @@ type SearchOptions struct {
 	Page     int          `json:"page,omitempty"`
 	Language string       `json:"language,omitempty"`
 	Filter   SearchFilter `json:"filter,omitempty"`
-}
\ No newline at end of file
+}
+
+// Validate checks that the options contain a query and only filters applicable to the search type
+func (so SearchOptions) Validate() error {
+	if so.Query == "" {
+		return &ValidationError{Field: "query", Message: "Query parameter is required"}
+	}
+	return so.Filter.validateFor(so.Type)
+}
+
+// SearchFacet represents the results for a single media type in a faceted search
+type SearchFacet struct {
+	Type         SearchItemType      `json:"type"`
+	TotalResults int                 `json:"total_results"`
+	TotalPages   int                 `json:"total_pages"`
+	Results      []MultiSearchResult `json:"results"`
+	Error        string              `json:"error,omitempty"` // Set when this type's search failed
+}
+
+// FacetedSearchResponse represents the API response for a faceted "all" search.
+// Each media type is searched independently so a failure in one facet does not
+// prevent the others from being returned; Partial is set when any facet failed.
+type FacetedSearchResponse struct {
+	Query        string        `json:"query"`
+	Type         string        `json:"type"`
+	Language     string        `json:"language"`
+	PerType      int           `json:"per_type"`
+	TotalResults int           `json:"total_results"`
+	Facets       []SearchFacet `json:"facets"`
+	Partial      bool          `json:"partial"`
+	QueryVariant string        `json:"query_variant"`
+	MatchedQuery string        `json:"matched_query,omitempty"`
+	Stale        bool          `json:"stale,omitempty"` // Served from the local catalogue while TMDb is unavailable
+}
+
+// SuggestionTypeQuery marks a suggestion taken from past search queries rather than a title or person
+const SuggestionTypeQuery SearchItemType = "query"
+
+// SearchSuggestion represents a single autocomplete suggestion
+type SearchSuggestion struct {
+	Text   string         `json:"text"`
+	Type   SearchItemType `json:"type"`         // movie, tv, person or query
+	ID     int            `json:"id,omitempty"` // TMDb ID; unset for query suggestions
+	Score  float64        `json:"score"`
+	Source string         `json:"source"` // index or history
+}
+
+// SearchSuggestionsResponse represents the API response for search suggestions
+type SearchSuggestionsResponse struct {
+	Query       string             `json:"query"`
+	Suggestions []SearchSuggestion `json:"suggestions"`
+	Partial     bool               `json:"partial,omitempty"` // Set when a source missed the latency budget
+}
@@ -0,0 +1,48 @@
+// Package models provides shared credits search data structures.
+package models
+
+// Roles a person can be required to have on a shared title
+const (
+	CreditRoleCast = "cast"
+	CreditRoleCrew = "crew"
+	CreditRoleAny  = "any"
+)
+
+// CreditRequirement is the credit a person must have on a title for it to count as shared
+type CreditRequirement struct {
+	PersonID int    `json:"person_id" validate:"min=1"`
+	Role     string `json:"role" validate:"oneof=cast crew any"`
+	Job      string `json:"job,omitempty" validate:"max=100"` // Crew job, matched case-insensitively; any job when empty
+}
+
+// SharedCreditsRequest represents a search for the titles several people have credits on
+type SharedCreditsRequest struct {
+	People []CreditRequirement `json:"people" validate:"min=2,max=10,dive"`
+}
+
+// PersonCredit is what one person did on a title
+type PersonCredit struct {
+	PersonID   int      `json:"person_id"`
+	Characters []string `json:"characters,omitempty"`
+	Jobs       []string `json:"jobs,omitempty"`
+}
+
+// SharedCreditTitle is a movie or TV show every person searched for has a matching credit on
+type SharedCreditTitle struct {
+	ID          int            `json:"id"`
+	MediaType   string         `json:"media_type"` // "movie" or "tv"
+	Title       string         `json:"title"`
+	ReleaseDate string         `json:"release_date,omitempty"` // First air date for TV shows; format: YYYY-MM-DD
+	PosterPath  *string        `json:"poster_path"`
+	VoteAverage float64        `json:"vote_average"`
+	VoteCount   int            `json:"vote_count"`
+	Popularity  float64        `json:"popularity"`
+	Credits     []PersonCredit `json:"credits"` // The matching credits of each person, in the order they were asked for
+}
+
+// SharedCredits represents the titles several people have credits on, latest first
+type SharedCredits struct {
+	People       []CreditRequirement `json:"people"`
+	TotalResults int                 `json:"total_results"`
+	Results      []SharedCreditTitle `json:"results"`
+}
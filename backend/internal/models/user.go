@@ -0,0 +1,60 @@
+// Package models provides user account and favorites data structures.
+package models
+
+import "time"
+
+// User represents a registered user account
+type User struct {
+	ID           string    `json:"id"`
+	Email        string    `json:"email" validate:"required,email,max=255"`
+	Username     string    `json:"username" validate:"required,min=3,max=50"`
+	PasswordHash string    `json:"-"`
+	IsActive     bool      `json:"is_active"`
+	IsModerator  bool      `json:"is_moderator"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Favorite represents a movie or TV show a user has marked as a favorite
+type Favorite struct {
+	ID        string         `json:"id"`
+	UserID    string         `json:"user_id"`
+	MediaType SearchItemType `json:"media_type" validate:"required,oneof=movie tv"`
+	MediaID   int            `json:"media_id" validate:"required,min=1"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// FavoriteRequest is the request body for adding a favorite
+type FavoriteRequest struct {
+	MediaType SearchItemType `json:"media_type" validate:"required,oneof=movie tv"`
+	MediaID   int            `json:"media_id" validate:"required,min=1"`
+}
+
+// FavoriteSort orders a user's favorites
+type FavoriteSort string
+
+const (
+	// FavoriteSortDateAdded lists the most recently added favorites first
+	FavoriteSortDateAdded FavoriteSort = "date_added"
+	// FavoriteSortRating lists the highest rated favorites first
+	FavoriteSortRating FavoriteSort = "rating"
+)
+
+// TitleSummary carries the display fields of a movie or TV show
+type TitleSummary struct {
+	Title       string  `json:"title,omitempty"` // Name for TV shows
+	PosterPath  *string `json:"poster_path"`
+	ReleaseDate *string `json:"release_date,omitempty"` // First air date for TV shows
+	VoteAverage float64 `json:"vote_average"`
+	VoteCount   int     `json:"vote_count"`
+	Unavailable bool    `json:"unavailable,omitempty"` // Title details could not be loaded
+}
+
+// FavoriteItem is a favorite hydrated with the title's display fields
+type FavoriteItem struct {
+	ID        string         `json:"id"`
+	MediaType SearchItemType `json:"media_type"`
+	MediaID   int            `json:"media_id"`
+	TitleSummary
+	AddedAt time.Time `json:"added_at"`
+}
@@ -0,0 +1,66 @@
+// Package models provides watchlist data structures.
+package models
+
+import "time"
+
+// MaxWatchlistItems bounds the number of titles in a single watchlist
+const MaxWatchlistItems = 200
+
+// Watchlist is a named, ordered list of movies and TV shows. Public watchlists
+// can be read by anyone through their slug.
+type Watchlist struct {
+	ID          string          `json:"id"`
+	UserID      string          `json:"user_id,omitempty"` // Omitted from public views
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	IsPublic    bool            `json:"is_public"`
+	Slug        *string         `json:"slug,omitempty"` // Assigned the first time the list is made public
+	ItemCount   int             `json:"item_count"`
+	Items       []WatchlistItem `json:"items,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// WatchlistItem is a title in a watchlist. Positions start at 1.
+type WatchlistItem struct {
+	ID        string         `json:"id"`
+	MediaType SearchItemType `json:"media_type"`
+	MediaID   int            `json:"media_id"`
+	Position  int            `json:"position"`
+	Note      string         `json:"note"`
+	TitleSummary
+	AddedAt time.Time `json:"added_at"`
+}
+
+// WatchlistRequest is the request body for creating a watchlist
+type WatchlistRequest struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Description string `json:"description" validate:"max=1000"`
+	IsPublic    bool   `json:"is_public"`
+}
+
+// WatchlistUpdateRequest is the request body for updating a watchlist. Omitted
+// fields are left unchanged.
+type WatchlistUpdateRequest struct {
+	Name        *string `json:"name" validate:"omitempty,min=1,max=100"`
+	Description *string `json:"description" validate:"omitempty,max=1000"`
+	IsPublic    *bool   `json:"is_public"`
+}
+
+// WatchlistItemRequest is the request body for adding a title to a watchlist
+type WatchlistItemRequest struct {
+	MediaType SearchItemType `json:"media_type" validate:"required,oneof=movie tv"`
+	MediaID   int            `json:"media_id" validate:"required,min=1"`
+	Note      string         `json:"note" validate:"max=1000"`
+}
+
+// WatchlistItemUpdateRequest is the request body for changing an item's note
+type WatchlistItemUpdateRequest struct {
+	Note *string `json:"note" validate:"required,max=1000"`
+}
+
+// WatchlistOrderRequest is the request body for reordering a watchlist. It must
+// list every item in the watchlist exactly once, in the new order.
+type WatchlistOrderRequest struct {
+	ItemIDs []string `json:"item_ids" validate:"required,min=1,max=200,dive,uuid"`
+}
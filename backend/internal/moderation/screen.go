@@ -0,0 +1,106 @@
+// Package moderation pre-screens user reviews so that likely abuse and spam reach
+// the front of the moderation queue. Screening never rejects a review on its own;
+// it only flags it for a moderator.
+package moderation
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/takeshi-arihori/movie-api/internal/textnorm"
+)
+
+// Reasons a review is flagged
+const (
+	// FlagBannedWords marks text containing a word from the banned-word list
+	FlagBannedWords = "banned_words"
+	// FlagLinkSpam marks text containing more links than a review needs
+	FlagLinkSpam = "link_spam"
+)
+
+// MaxLinks is the number of links a review may contain before it is flagged as
+// link spam
+const MaxLinks = 1
+
+// DefaultBannedWords are slurs, threats and spam phrases in English and Japanese.
+// Words are matched after textnorm.Normalize; Latin words and phrases must match
+// whole words, other scripts match anywhere.
+var DefaultBannedWords = []string{
+	// Abuse
+	"fuck", "fucking", "cunt", "faggot", "nigger", "retard", "kill yourself", "kys",
+	"死ね", "殺す", "ガイジ", "キチガイ",
+	// Spam
+	"viagra", "cialis", "casino", "free money", "work from home", "crypto giveaway",
+	"出会い系", "副業", "稼げる",
+}
+
+// linkPattern matches URLs, www. addresses and bare domains on common spam TLDs
+var linkPattern = regexp.MustCompile(`(?i)\bhttps?://\S+|\bwww\.\S+|\b[a-z0-9-]+\.(?:com|net|org|info|biz|io|xyz|top|ru|cn|jp)\b`)
+
+// Screener flags review text for moderators
+type Screener struct {
+	words   []string // Latin words and phrases, padded with spaces for whole-word matching
+	phrases []string // Words in other scripts, matched as substrings
+}
+
+// NewScreener creates a Screener that flags the given banned words
+func NewScreener(bannedWords []string) *Screener {
+	s := &Screener{}
+	for _, word := range bannedWords {
+		normalized := textnorm.Normalize(word)
+		switch {
+		case normalized == "":
+		case isLatin(normalized):
+			s.words = append(s.words, " "+normalized+" ")
+		default:
+			s.phrases = append(s.phrases, normalized)
+		}
+	}
+	return s
+}
+
+// Screen returns the reasons to flag a review made of texts, such as its title and
+// content, or nil if nothing was found
+func (s *Screener) Screen(texts ...string) []string {
+	var flags []string
+	if s.hasBannedWord(texts) {
+		flags = append(flags, FlagBannedWords)
+	}
+
+	links := 0
+	for _, text := range texts {
+		links += len(linkPattern.FindAllStringIndex(text, -1))
+	}
+	if links > MaxLinks {
+		flags = append(flags, FlagLinkSpam)
+	}
+	return flags
+}
+
+// hasBannedWord reports whether any of texts contains a banned word
+func (s *Screener) hasBannedWord(texts []string) bool {
+	for _, text := range texts {
+		normalized := " " + textnorm.Normalize(text) + " "
+		for _, word := range s.words {
+			if strings.Contains(normalized, word) {
+				return true
+			}
+		}
+		for _, phrase := range s.phrases {
+			if strings.Contains(normalized, phrase) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isLatin reports whether s is written only in ASCII
+func isLatin(s string) bool {
+	for _, r := range s {
+		if r > 0x7f {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,36 @@
+package moderation
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestScreen tests banned-word and link-spam flagging
+func TestScreen(t *testing.T) {
+	screener := NewScreener(DefaultBannedWords)
+
+	tests := []struct {
+		name     string
+		texts    []string
+		expected []string
+	}{
+		{"clean", []string{"A masterpiece", "Kubrick at his best."}, nil},
+		{"banned word", []string{"Awful", "What a FUCKING waste of time."}, []string{FlagBannedWords}},
+		{"full-width", []string{"Ｃａｓｉｎｏ night", "Fun."}, []string{FlagBannedWords}},
+		{"phrase", []string{"Great", "Kill   yourself if you disagree"}, []string{FlagBannedWords}},
+		{"whole words only", []string{"Scunthorpe", "The casinos of Las Vegas look great; a shitake dinner."}, nil},
+		{"japanese", []string{"最悪", "監督は死ねばいい"}, []string{FlagBannedWords}},
+		{"one link", []string{"Source", "More at https://example.com/review"}, nil},
+		{"link spam", []string{"Watch free", "Stream at www.spam.example and cheap-pills.xyz now"}, []string{FlagLinkSpam}},
+		{"links across fields", []string{"http://a.example", "http://b.example"}, []string{FlagLinkSpam}},
+		{"both", []string{"viagra", "https://a.example https://b.example"}, []string{FlagBannedWords, FlagLinkSpam}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := screener.Screen(tt.texts...); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Screen(%q) = %v, expected %v", tt.texts, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,257 @@
+// Package pagination provides opaque cursor-based pagination on top of TMDb's fixed-size pages.
+//
+// A cursor records an offset into the upstream result sequence. Fetch translates an
+// offset and limit into the TMDb pages that cover them, fetches those pages
+// concurrently and stitches the results into a single de-duplicated window.
+// FetchAll instead collects every page of a list, up to a cap.
+package pagination
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+const (
+	// PageSize is the number of results TMDb returns per page
+	PageSize = 20
+	// MaxPage is the highest page number TMDb will serve
+	MaxPage = 500
+	// DefaultLimit is the number of results returned when no limit is given
+	DefaultLimit = 20
+	// MaxLimit is the largest window a single request may ask for
+	MaxLimit = 100
+)
+
+// ErrInvalidCursor is returned when a cursor cannot be decoded or belongs to a different query
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is the decoded form of an opaque pagination cursor
+type Cursor struct {
+	Offset int      `json:"o"`
+	Scope  string   `json:"s"`
+	Seen   []string `json:"k,omitempty"` // Keys returned at the end of the previous window
+	Query  string   `json:"q,omitempty"` // Upstream query, when it differs from the request's
+}
+
+// Encode returns the opaque string form of the cursor
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Parse decodes an opaque cursor and checks that it was issued for the same scope,
+// typically the endpoint and its query parameters. An empty token yields a cursor at
+// the start of the result set.
+func Parse(token string, scope ...string) (Cursor, error) {
+	fingerprint := scopeOf(scope)
+	if token == "" {
+		return Cursor{Scope: fingerprint}, nil
+	}
+
+	var c Cursor
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, ErrInvalidCursor
+	}
+	if c.Offset < 0 || c.Offset >= PageSize*MaxPage || c.Scope != fingerprint {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// scopeOf returns a short fingerprint of the parameters a cursor is bound to, so
+// that a cursor from one query cannot be replayed against another
+func scopeOf(parts []string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// Page is a single upstream page of results
+type Page[T any] struct {
+	Results      []T
+	TotalPages   int
+	TotalResults int
+}
+
+// PageFunc fetches a single upstream page (1-based)
+type PageFunc[T any] func(ctx context.Context, page int) (*Page[T], error)
+
+// Window is a stitched range of results
+type Window[T any] struct {
+	Results      []T
+	TotalResults int
+	NextCursor   string
+}
+
+// Fetch returns up to limit results starting at the cursor's offset. The first
+// covering page is fetched on its own to learn the total page count; the remaining
+// pages are then fetched concurrently. Results whose key has already been seen in
+// this window, or at the end of the previous one, are dropped.
+func Fetch[T any](ctx context.Context, cursor Cursor, limit int, fetch PageFunc[T], key func(T) string) (*Window[T], error) {
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+
+	firstPage := cursor.Offset/PageSize + 1
+	lastPage := (cursor.Offset+limit-1)/PageSize + 1
+
+	first, err := fetch(ctx, firstPage)
+	if err != nil {
+		return nil, fmt.Errorf("fetch page %d: %w", firstPage, err)
+	}
+
+	totalPages := first.TotalPages
+	if totalPages > MaxPage {
+		totalPages = MaxPage
+	}
+	if lastPage > totalPages {
+		lastPage = totalPages
+	}
+
+	pages := make([]*Page[T], 0, lastPage-firstPage+1)
+	pages = append(pages, first)
+	if lastPage > firstPage {
+		rest := make([]*Page[T], lastPage-firstPage)
+		errs := make([]error, len(rest))
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		var wg sync.WaitGroup
+		for i := range rest {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				page, err := fetch(ctx, firstPage+1+i)
+				if err != nil {
+					errs[i] = fmt.Errorf("fetch page %d: %w", firstPage+1+i, err)
+					cancel()
+					return
+				}
+				rest[i] = page
+			}(i)
+		}
+		wg.Wait()
+
+		if err := errors.Join(errs...); err != nil {
+			return nil, err
+		}
+		pages = append(pages, rest...)
+	}
+
+	// Flatten the covering pages and cut out the requested range
+	var all []T
+	for _, page := range pages {
+		all = append(all, page.Results...)
+	}
+	start := cursor.Offset - (firstPage-1)*PageSize
+	if start > len(all) {
+		start = len(all)
+	}
+	end := start + limit
+	if end > len(all) {
+		end = len(all)
+	}
+
+	seen := make(map[string]bool, limit+len(cursor.Seen))
+	for _, k := range cursor.Seen {
+		seen[k] = true
+	}
+	results := make([]T, 0, end-start)
+	var tail []string
+	for _, item := range all[start:end] {
+		k := key(item)
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		results = append(results, item)
+		tail = append(tail, k)
+	}
+
+	window := &Window[T]{
+		Results:      results,
+		TotalResults: first.TotalResults,
+	}
+
+	nextOffset := cursor.Offset + (end - start)
+	if end > start && nextOffset < first.TotalResults && nextOffset < totalPages*PageSize {
+		if len(tail) > PageSize {
+			tail = tail[len(tail)-PageSize:]
+		}
+		window.NextCursor = Cursor{Offset: nextOffset, Scope: cursor.Scope, Seen: tail, Query: cursor.Query}.Encode()
+	}
+
+	return window, nil
+}
+
+// FetchAll returns the results of the first maxPages pages of a list, in order,
+// along with the list's upstream totals. The first page is fetched on its own to
+// learn the page count; the rest are fetched concurrently, at most concurrency at
+// a time. Callers can tell the list was cut short by TotalPages exceeding maxPages.
+func FetchAll[T any](ctx context.Context, fetch PageFunc[T], maxPages, concurrency int) (*Page[T], error) {
+	first, err := fetch(ctx, 1)
+	if err != nil {
+		return nil, fmt.Errorf("fetch page 1: %w", err)
+	}
+
+	lastPage := min(first.TotalPages, maxPages, MaxPage)
+	all := &Page[T]{
+		Results:      first.Results,
+		TotalPages:   first.TotalPages,
+		TotalResults: first.TotalResults,
+	}
+	if lastPage <= 1 {
+		return all, nil
+	}
+
+	rest := make([]*Page[T], lastPage-1)
+	errs := make([]error, len(rest))
+	sem := make(chan struct{}, max(concurrency, 1))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i := range rest {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			page, err := fetch(ctx, i+2)
+			if err != nil {
+				errs[i] = fmt.Errorf("fetch page %d: %w", i+2, err)
+				cancel()
+				return
+			}
+			rest[i] = page
+		}(i)
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	for _, page := range rest {
+		all.Results = append(all.Results, page.Results...)
+	}
+	return all, nil
+}
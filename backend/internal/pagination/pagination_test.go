@@ -0,0 +1,194 @@
+package pagination
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+)
+
+// pagedSource simulates a TMDb list of total items with ids 1..total
+func pagedSource(total int, calls *int32) PageFunc[int] {
+	totalPages := (total + PageSize - 1) / PageSize
+	return func(ctx context.Context, page int) (*Page[int], error) {
+		atomic.AddInt32(calls, 1)
+		var results []int
+		for i := (page-1)*PageSize + 1; i <= page*PageSize && i <= total; i++ {
+			results = append(results, i)
+		}
+		return &Page[int]{Results: results, TotalPages: totalPages, TotalResults: total}, nil
+	}
+}
+
+func intKey(i int) string {
+	return fmt.Sprint(i)
+}
+
+// TestFetchStitchesPages tests that a window spanning several pages is stitched in order
+func TestFetchStitchesPages(t *testing.T) {
+	var calls int32
+	cursor, _ := Parse("", "test")
+
+	window, err := Fetch(context.Background(), cursor, 50, pagedSource(95, &calls), intKey)
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	if len(window.Results) != 50 {
+		t.Fatalf("Expected 50 results, got %d", len(window.Results))
+	}
+	for i, v := range window.Results {
+		if v != i+1 {
+			t.Fatalf("Expected result %d to be %d, got %d", i, i+1, v)
+		}
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 page fetches, got %d", calls)
+	}
+	if window.TotalResults != 95 {
+		t.Errorf("Expected total 95, got %d", window.TotalResults)
+	}
+	if window.NextCursor == "" {
+		t.Fatal("Expected next cursor")
+	}
+
+	// Follow the cursor to the end of the list
+	next, err := Parse(window.NextCursor, "test")
+	if err != nil {
+		t.Fatalf("Failed to parse next cursor: %v", err)
+	}
+	if next.Offset != 50 {
+		t.Errorf("Expected next offset 50, got %d", next.Offset)
+	}
+
+	window, err = Fetch(context.Background(), next, 50, pagedSource(95, &calls), intKey)
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if len(window.Results) != 45 || window.Results[0] != 51 {
+		t.Errorf("Expected 45 results starting at 51, got %d starting at %v", len(window.Results), window.Results)
+	}
+	if window.NextCursor != "" {
+		t.Errorf("Expected no next cursor at end of list, got %q", window.NextCursor)
+	}
+}
+
+// TestFetchDeduplicates tests that IDs repeated across page boundaries are dropped
+func TestFetchDeduplicates(t *testing.T) {
+	fetch := func(ctx context.Context, page int) (*Page[int], error) {
+		// Page 2 repeats the last item of page 1, as happens when rankings shift
+		results := make([]int, PageSize)
+		for i := range results {
+			results[i] = (page-1)*PageSize + i
+		}
+		if page == 2 {
+			results[0] = PageSize - 1
+		}
+		return &Page[int]{Results: results, TotalPages: 3, TotalResults: 3 * PageSize}, nil
+	}
+
+	cursor, _ := Parse("", "test")
+	window, err := Fetch(context.Background(), cursor, 40, fetch, intKey)
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	seen := map[int]bool{}
+	for _, v := range window.Results {
+		if seen[v] {
+			t.Fatalf("Duplicate result %d", v)
+		}
+		seen[v] = true
+	}
+	if len(window.Results) != 39 {
+		t.Errorf("Expected 39 unique results, got %d", len(window.Results))
+	}
+
+	// Keys at the end of the previous window are skipped in the next one
+	next, _ := Parse(window.NextCursor, "test")
+	next.Seen = append(next.Seen, intKey(2*PageSize))
+	window, err = Fetch(context.Background(), next, 5, fetch, intKey)
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if len(window.Results) != 4 || window.Results[0] != 2*PageSize+1 {
+		t.Errorf("Expected previously seen key to be skipped, got %v", window.Results)
+	}
+}
+
+// TestFetchError tests that a failing page fails the whole window
+func TestFetchError(t *testing.T) {
+	fetch := func(ctx context.Context, page int) (*Page[int], error) {
+		if page == 2 {
+			return nil, errors.New("upstream failure")
+		}
+		return &Page[int]{Results: make([]int, PageSize), TotalPages: 5, TotalResults: 100}, nil
+	}
+
+	cursor, _ := Parse("", "test")
+	if _, err := Fetch(context.Background(), cursor, 40, fetch, intKey); err == nil {
+		t.Error("Expected error when a page fails, got nil")
+	}
+}
+
+// TestFetchAll tests that every page up to the cap is collected in order
+func TestFetchAll(t *testing.T) {
+	var calls int32
+	all, err := FetchAll(context.Background(), pagedSource(95, &calls), 10, 2)
+	if err != nil {
+		t.Fatalf("FetchAll failed: %v", err)
+	}
+	if len(all.Results) != 95 || calls != 5 {
+		t.Fatalf("Expected 95 results from 5 pages, got %d from %d", len(all.Results), calls)
+	}
+	for i, v := range all.Results {
+		if v != i+1 {
+			t.Fatalf("Expected result %d to be %d, got %d", i, i+1, v)
+		}
+	}
+
+	calls = 0
+	all, err = FetchAll(context.Background(), pagedSource(95, &calls), 3, 2)
+	if err != nil {
+		t.Fatalf("FetchAll failed: %v", err)
+	}
+	if len(all.Results) != 3*PageSize || calls != 3 || all.TotalPages != 5 || all.TotalResults != 95 {
+		t.Errorf("Expected 3 capped pages of 5, got %d results from %d pages (%+v)", len(all.Results), calls, all.TotalPages)
+	}
+
+	failing := func(ctx context.Context, page int) (*Page[int], error) {
+		if page == 3 {
+			return nil, errors.New("upstream failure")
+		}
+		return &Page[int]{Results: make([]int, PageSize), TotalPages: 4, TotalResults: 80}, nil
+	}
+	if _, err := FetchAll(context.Background(), failing, 10, 2); err == nil {
+		t.Error("Expected error when a page fails, got nil")
+	}
+}
+
+// TestParse tests cursor decoding and scope checks
+func TestParse(t *testing.T) {
+	token := Cursor{Offset: 40, Scope: scopeOf([]string{"search", "matrix"})}.Encode()
+
+	cursor, err := Parse(token, "search", "matrix")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if cursor.Offset != 40 {
+		t.Errorf("Expected offset 40, got %d", cursor.Offset)
+	}
+
+	if _, err := Parse(token, "search", "inception"); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("Expected ErrInvalidCursor for scope mismatch, got %v", err)
+	}
+	if _, err := Parse("not-a-cursor!", "search"); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("Expected ErrInvalidCursor for malformed cursor, got %v", err)
+	}
+
+	negative := Cursor{Offset: -1, Scope: scopeOf([]string{"search"})}.Encode()
+	if _, err := Parse(negative, "search"); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("Expected ErrInvalidCursor for negative offset, got %v", err)
+	}
+}
@@ -0,0 +1,177 @@
+package ranking
+
+import (
+	"math"
+	"testing"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+)
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func ids(movies []models.Movie) []int {
+	out := make([]int, len(movies))
+	for i, m := range movies {
+		out[i] = m.ID
+	}
+	return out
+}
+
+func equalIDs(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// TestParseMode tests sort parameter parsing
+func TestParseMode(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Mode
+		wantErr bool
+	}{
+		{"", TMDb, false},
+		{"relevance", Relevance, false},
+		{"Weighted_Rating", WeightedRating, false},
+		{"release_date", ReleaseDate, false},
+		{"vote_average", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseMode(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseMode(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("ParseMode(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+// TestWeightedRating tests that a single perfect vote does not outrank a well-known film
+func TestWeightedRating(t *testing.T) {
+	movies := []models.Movie{
+		{ID: 1, Title: "Obscure", VoteAverage: 10.0, VoteCount: 1},
+		{ID: 2, Title: "Classic", VoteAverage: 8.5, VoteCount: 20000},
+		{ID: 3, Title: "Average", VoteAverage: 6.0, VoteCount: 5000},
+	}
+
+	Sort(movies, WeightedRating, "", MovieFields)
+
+	if got := ids(movies); !equalIDs(got, []int{2, 1, 3}) {
+		t.Errorf("Expected order [2 1 3], got %v", got)
+	}
+}
+
+// TestBayesianAverage tests the weighted rating formula
+func TestBayesianAverage(t *testing.T) {
+	if got := BayesianAverage(10, 0, 7, 100); got != 7 {
+		t.Errorf("Expected prior for zero votes, got %f", got)
+	}
+	if got := BayesianAverage(9, 100, 7, 100); math.Abs(got-8) > 1e-9 {
+		t.Errorf("Expected 8 when votes equal minVotes, got %f", got)
+	}
+	if got := BayesianAverage(5, 0, 0, 0); got != 0 {
+		t.Errorf("Expected 0 with no votes and no prior, got %f", got)
+	}
+}
+
+// TestWilsonLowerBound tests the helpfulness score of vote counts
+func TestWilsonLowerBound(t *testing.T) {
+	tests := []struct {
+		positive, negative int
+		want               float64
+	}{
+		{0, 0, 0},
+		{2, 0, 0.3424},
+		{40, 10, 0.6696},
+		{0, 5, 0},
+	}
+
+	for _, tt := range tests {
+		if got := WilsonLowerBound(tt.positive, tt.negative, WilsonZ); math.Abs(got-tt.want) > 1e-4 {
+			t.Errorf("WilsonLowerBound(%d, %d) = %f, want %f", tt.positive, tt.negative, got, tt.want)
+		}
+	}
+	if WilsonLowerBound(40, 10, WilsonZ) <= WilsonLowerBound(2, 0, WilsonZ) {
+		t.Error("Expected 40 of 50 helpful votes to outrank 2 of 2")
+	}
+}
+
+// TestRelevance tests ordering by title similarity
+func TestRelevance(t *testing.T) {
+	movies := []models.Movie{
+		{ID: 1, Title: "The Matrix Revisited", Popularity: 5},
+		{ID: 2, Title: "Matrix of Leadership", Popularity: 50},
+		{ID: 3, Title: "The Matrix", Popularity: 80},
+		{ID: 4, Title: "Unrelated Movie", Popularity: 100},
+		{ID: 5, Title: "マトリックス", OriginalTitle: "The Matrix", Popularity: 10},
+	}
+
+	Sort(movies, Relevance, "the matrix", MovieFields)
+
+	got := ids(movies)
+	if got[0] != 3 {
+		t.Errorf("Expected exact title match first, got %v", got)
+	}
+	if got[1] != 5 {
+		t.Errorf("Expected original title match second, got %v", got)
+	}
+	if got[len(got)-1] != 4 {
+		t.Errorf("Expected unrelated title last, got %v", got)
+	}
+}
+
+// TestReleaseDateAndPopularity tests date and popularity ordering
+func TestReleaseDateAndPopularity(t *testing.T) {
+	movies := []models.Movie{
+		{ID: 1, ReleaseDate: strPtr("1999-03-31"), Popularity: 30},
+		{ID: 2, Popularity: 90},
+		{ID: 3, ReleaseDate: strPtr("2021-12-22"), Popularity: 10},
+		{ID: 4, ReleaseDate: strPtr(""), Popularity: 20},
+	}
+
+	Sort(movies, ReleaseDate, "", MovieFields)
+	if got := ids(movies); !equalIDs(got, []int{3, 1, 2, 4}) {
+		t.Errorf("Expected order [3 1 2 4], got %v", got)
+	}
+
+	Sort(movies, Popularity, "", MovieFields)
+	if got := ids(movies); !equalIDs(got, []int{2, 1, 4, 3}) {
+		t.Errorf("Expected order [2 1 4 3], got %v", got)
+	}
+
+	// TMDb order leaves items untouched
+	Sort(movies, TMDb, "", MovieFields)
+	if got := ids(movies); !equalIDs(got, []int{2, 1, 4, 3}) {
+		t.Errorf("Expected unchanged order, got %v", got)
+	}
+}
+
+// TestSearchResultFields tests field extraction across media types
+func TestSearchResultFields(t *testing.T) {
+	tv := models.MultiSearchResult{
+		MediaType:    models.SearchItemTypeTV,
+		Name:         strPtr("Breaking Bad"),
+		OriginalName: strPtr("Breaking Bad"),
+		FirstAirDate: strPtr("2008-01-20"),
+	}
+	f := SearchResultFields(tv)
+	if len(f.Titles) != 2 || f.Date != "2008-01-20" {
+		t.Errorf("Unexpected fields for TV result: %+v", f)
+	}
+
+	person := models.MultiSearchResult{MediaType: models.SearchItemTypePerson, Name: strPtr("Edward Norton")}
+	f = SearchResultFields(person)
+	if len(f.Titles) != 1 || f.Date != "" || f.VoteCount != 0 {
+		t.Errorf("Unexpected fields for person result: %+v", f)
+	}
+}
@@ -0,0 +1,108 @@
+// Package reviewanalysis analyses review text offline: it detects the language,
+// scores sentiment against English and Japanese lexicons and estimates how likely
+// a review is to spoil the title.
+//
+// Everything is heuristic and runs without external services, so results are
+// meant for sorting and filtering rather than as ground truth. Text is matched
+// after textnorm.Normalize; Latin words and phrases must match whole words, while
+// Japanese, which has no spaces between words, is matched anywhere.
+package reviewanalysis
+
+import (
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+	"github.com/takeshi-arihori/movie-api/internal/textnorm"
+)
+
+const (
+	// SpoilerThreshold is the spoiler likelihood from which a review is treated as
+	// a spoiler, such as when spoilers are hidden
+	SpoilerThreshold = 0.5
+
+	// maxCharacters is the number of top-billed characters looked for in reviews;
+	// further down the cast, characters are bit parts such as "Bartender"
+	maxCharacters = 15
+)
+
+// Analyzer analyses the reviews of one title
+type Analyzer struct {
+	characters [][]string // Normalized names of each character, see characterNames
+}
+
+// NewAnalyzer creates an Analyzer for a title whose cast plays characters, as
+// listed in its credits. Naming these characters makes a review more likely to be
+// a spoiler.
+func NewAnalyzer(characters []string) *Analyzer {
+	return &Analyzer{characters: characterNames(characters)}
+}
+
+// MovieCharacters returns the characters of a movie's top-billed cast
+func MovieCharacters(credits *models.MovieCredits) []string {
+	cast := slices.Clone(credits.Cast)
+	slices.SortStableFunc(cast, func(a, b models.CastMember) int { return a.Order - b.Order })
+	var characters []string
+	for _, member := range cast[:min(len(cast), maxCharacters)] {
+		characters = append(characters, member.Character)
+	}
+	return characters
+}
+
+// TVCharacters returns the characters of a TV show's top-billed cast
+func TVCharacters(credits *models.TVCredits) []string {
+	cast := slices.Clone(credits.Cast)
+	slices.SortStableFunc(cast, func(a, b models.TVCastMember) int { return a.Order - b.Order })
+	var characters []string
+	for _, member := range cast[:min(len(cast), maxCharacters)] {
+		characters = append(characters, member.Character)
+	}
+	return characters
+}
+
+// Analyze returns the language, sentiment and spoiler likelihood of text
+func (a *Analyzer) Analyze(text string) models.ReviewAnalysis {
+	language := DetectLanguage(text)
+	// Typographic apostrophes would otherwise split words such as don’t
+	normalized := " " + textnorm.Normalize(strings.ReplaceAll(text, "’", "'")) + " "
+
+	sentiment := 0.0
+	switch language {
+	case "ja":
+		sentiment = japaneseSentiment(normalized)
+	case "en", "":
+		sentiment = englishSentiment(normalized)
+	}
+
+	return models.ReviewAnalysis{
+		Language:          language,
+		Sentiment:         round2(sentiment),
+		SentimentLabel:    sentimentLabel(sentiment),
+		SpoilerLikelihood: round2(a.spoilerLikelihood(normalized)),
+	}
+}
+
+// containsWord reports whether normalized text, padded with spaces, contains
+// word: Latin words and phrases must match whole words, others match anywhere
+func containsWord(normalized, word string) bool {
+	if isLatin(word) {
+		return strings.Contains(normalized, " "+word+" ")
+	}
+	return strings.Contains(normalized, word)
+}
+
+// isLatin reports whether s is written only in ASCII
+func isLatin(s string) bool {
+	for _, r := range s {
+		if r > 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+// round2 rounds x to two decimal places
+func round2(x float64) float64 {
+	return math.Round(x*100) / 100
+}
@@ -0,0 +1,113 @@
+package reviewanalysis
+
+import (
+	"testing"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"This was one of the best films I have seen in years, and the cast is great.", "en"},
+		{"La película es muy buena, pero el final no me gustó.", "es"},
+		{"Le film est très beau mais la fin est pas terrible pour moi.", "fr"},
+		{"Der Film ist sehr gut und die Musik auch, aber das Ende nicht.", "de"},
+		{"最後まで目が離せない、本当に面白い映画でした。", "ja"},
+		{"정말 재미있는 영화였어요", "ko"},
+		{"这部电影非常好看", "zh"},
+		{"Отличный фильм, всем советую", "ru"},
+		{"12345 !!!", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			if got := DetectLanguage(tt.text); got != tt.expected {
+				t.Errorf("Expected %q for %q, got %q", tt.expected, tt.text, got)
+			}
+		})
+	}
+}
+
+func TestSentiment(t *testing.T) {
+	analyzer := NewAnalyzer(nil)
+	tests := []struct {
+		text  string
+		label string
+	}{
+		{"An absolutely brilliant film. I loved every minute of it.", models.SentimentPositive},
+		{"Boring, predictable and a complete waste of time.", models.SentimentNegative},
+		{"It wasn’t good at all.", models.SentimentNegative},
+		{"Not bad, actually.", models.SentimentPositive},
+		{"The film runs for two hours.", models.SentimentNeutral},
+		{"最高の映画でした。感動して泣けました。", models.SentimentPositive},
+		{"つまらなかった。時間の無駄。", models.SentimentNegative},
+		{"期待していたほど面白くなかった。", models.SentimentNegative},
+		{"退屈じゃない映画だった。", models.SentimentPositive},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got := analyzer.Analyze(tt.text)
+			if got.SentimentLabel != tt.label {
+				t.Errorf("Expected %s, got %s (%v)", tt.label, got.SentimentLabel, got.Sentiment)
+			}
+			if got.Sentiment < -1 || got.Sentiment > 1 {
+				t.Errorf("Expected sentiment within -1 to 1, got %v", got.Sentiment)
+			}
+		})
+	}
+}
+
+func TestSpoilerLikelihood(t *testing.T) {
+	credits := &models.MovieCredits{Cast: []models.CastMember{
+		{Character: "Marla Singer", Order: 2},
+		{Character: "The Narrator", Order: 0},
+		{Character: "Tyler Durden", Order: 1},
+		{Character: "Bartender #2", Order: 3},
+	}}
+	analyzer := NewAnalyzer(MovieCharacters(credits))
+
+	tests := []struct {
+		name    string
+		text    string
+		spoiler bool
+	}{
+		{"no signals", "Great performances and a memorable soundtrack.", false},
+		{"disclaimer", "No spoilers here, but the cast is wonderful.", false},
+		{"character alone", "Tyler is one of the great screen characters.", false},
+		{"keyword and character", "Spoiler alert: it turns out Tyler Durden was never real.", true},
+		{"ending revealed", "The twist ending reveals the killer is his own brother.", true},
+		{"japanese", "ネタバレ注意。ラストで犯人の正体が分かる。", true},
+		{"japanese disclaimer", "ネタバレなしで感想を書きます。映像が美しい。", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := analyzer.Analyze(tt.text).SpoilerLikelihood
+			if (got >= SpoilerThreshold) != tt.spoiler {
+				t.Errorf("Expected spoiler %v for %q, got likelihood %v", tt.spoiler, tt.text, got)
+			}
+		})
+	}
+}
+
+func TestCharacterNames(t *testing.T) {
+	names := characterNames([]string{"Tyler Durden", "Himself", "Anna (voice)", "Will Turner / Young Will", "Cop #2"})
+	expected := [][]string{{"tyler durden", "tyler", "durden"}, {"anna"}, {"will turner", "turner", "young will"}}
+	if len(names) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, names)
+	}
+	for i := range expected {
+		if len(names[i]) != len(expected[i]) {
+			t.Fatalf("Expected %v, got %v", expected, names)
+		}
+		for j := range expected[i] {
+			if names[i][j] != expected[i][j] {
+				t.Errorf("Expected %v, got %v", expected, names)
+			}
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package reviewanalysis
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/takeshi-arihori/movie-api/internal/textnorm"
+)
+
+// scriptShare is the share of letters a script needs for text to be detected as
+// written in it
+const scriptShare = 0.3
+
+// stopwords are frequent words that tell apart the languages written in Latin
+// script. Words shared between languages count towards each of them.
+var stopwords = map[string][]string{
+	"en": {"the", "and", "is", "was", "this", "that", "with", "it", "of", "but", "not", "for", "you", "are", "have", "i", "to", "in"},
+	"es": {"el", "la", "los", "las", "que", "es", "una", "pero", "muy", "con", "por", "del", "esta", "y", "en", "película"},
+	"fr": {"le", "la", "les", "est", "une", "et", "pas", "que", "des", "du", "très", "mais", "pour", "avec", "ce", "je"},
+	"de": {"der", "die", "das", "und", "ist", "nicht", "ein", "eine", "sehr", "mit", "auch", "aber", "ich", "es", "zu"},
+	"pt": {"o", "os", "que", "é", "um", "uma", "não", "muito", "com", "mas", "para", "do", "da", "e", "filme"},
+	"it": {"il", "che", "è", "un", "una", "non", "molto", "con", "ma", "per", "della", "del", "sono", "e", "di"},
+}
+
+// latinLanguages lists the keys of stopwords in the order ties are broken
+var latinLanguages = []string{"en", "es", "fr", "de", "pt", "it"}
+
+// DetectLanguage returns the ISO 639-1 code of the language text is written in,
+// or "" when it cannot tell. Japanese, Korean, Chinese and Russian are told apart
+// by script; English, Spanish, French, German, Portuguese and Italian by their
+// most frequent words.
+func DetectLanguage(text string) string {
+	var letters, kana, han, hangul, cyrillic, latin int
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		switch {
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			kana++
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.Is(unicode.Hangul, r):
+			hangul++
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		}
+	}
+	if letters == 0 {
+		return ""
+	}
+
+	share := func(n int) float64 { return float64(n) / float64(letters) }
+	switch {
+	case kana > 0 && share(kana+han) >= scriptShare:
+		return "ja"
+	case share(hangul) >= scriptShare:
+		return "ko"
+	case share(han) >= scriptShare:
+		return "zh"
+	case share(cyrillic) >= scriptShare:
+		return "ru"
+	case share(latin) >= scriptShare:
+		return latinLanguage(text)
+	}
+	return ""
+}
+
+// latinLanguage returns the language whose stopwords are most frequent in text,
+// or "" when none occur
+func latinLanguage(text string) string {
+	counts := map[string]int{}
+	for _, word := range strings.Fields(textnorm.Normalize(text)) {
+		for language, words := range stopwords {
+			for _, stopword := range words {
+				if word == stopword {
+					counts[language]++
+				}
+			}
+		}
+	}
+
+	best := ""
+	for _, language := range latinLanguages {
+		if counts[language] > counts[best] {
+			best = language
+		}
+	}
+	return best
+}
@@ -0,0 +1,171 @@
+package reviewanalysis
+
+import (
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+)
+
+const (
+	// neutralBand is the distance from 0 within which sentiment is neutral
+	neutralBand = 0.05
+
+	// normalization scales summed word scores into -1 to 1; higher values need
+	// more sentiment words to approach the ends
+	normalization = 15
+
+	// negationScope is the number of words after a negator whose score is flipped
+	negationScope = 3
+
+	// intensifierBoost scales the score of the word after an intensifier
+	intensifierBoost = 1.5
+)
+
+// englishLexicon scores English words from -3 (very negative) to 3 (very positive)
+var englishLexicon = map[string]float64{
+	// Positive
+	"masterpiece": 3, "brilliant": 3, "outstanding": 3, "superb": 3, "phenomenal": 3, "flawless": 3,
+	"excellent": 3, "amazing": 3, "incredible": 3, "perfect": 3, "stunning": 3, "wonderful": 3,
+	"great": 2, "love": 2, "loved": 2, "beautiful": 2, "fantastic": 2, "enjoyed": 2, "enjoyable": 2,
+	"fun": 2, "gripping": 2, "moving": 2, "powerful": 2, "recommend": 2, "impressive": 2,
+	"compelling": 2, "hilarious": 2, "delightful": 2, "favorite": 2, "favourite": 2, "best": 2,
+	"good": 1, "nice": 1, "solid": 1, "decent": 1, "like": 1, "liked": 1, "interesting": 1,
+	"funny": 1, "entertaining": 1, "worth": 1, "charming": 1, "clever": 1,
+	// Negative
+	"worst": -3, "terrible": -3, "awful": -3, "horrible": -3, "garbage": -3, "trash": -3,
+	"unwatchable": -3, "disaster": -3, "atrocious": -3,
+	"bad": -2, "boring": -2, "waste": -2, "disappointing": -2, "disappointed": -2, "dull": -2,
+	"hate": -2, "hated": -2, "stupid": -2, "mess": -2, "poor": -2, "weak": -2, "annoying": -2,
+	"pointless": -2, "predictable": -2, "overrated": -2, "tedious": -2, "cringe": -2,
+	"meh": -1, "mediocre": -1, "slow": -1, "forgettable": -1, "bland": -1, "flawed": -1,
+	"confusing": -1, "lacking": -1,
+}
+
+// englishNegators flip the score of the following words
+var englishNegators = map[string]bool{
+	"not": true, "no": true, "never": true, "nothing": true, "hardly": true, "without": true,
+	"isn't": true, "wasn't": true, "don't": true, "didn't": true, "doesn't": true, "can't": true,
+	"couldn't": true, "won't": true, "wouldn't": true, "aren't": true, "weren't": true,
+}
+
+// englishIntensifiers strengthen the score of the following word
+var englishIntensifiers = map[string]bool{
+	"very": true, "really": true, "extremely": true, "so": true, "incredibly": true,
+	"absolutely": true, "truly": true, "super": true, "totally": true, "utterly": true,
+}
+
+// japaneseLexicon scores Japanese stems from -3 to 3. Adjectives are listed
+// without their endings so that negated forms such as 面白くない can be caught.
+var japaneseLexicon = map[string]float64{
+	// Positive
+	"最高": 3, "傑作": 3, "名作": 3, "素晴らし": 3, "圧巻": 3, "神作": 3,
+	"面白": 2, "おもしろ": 2, "感動": 2, "泣け": 2, "好き": 2, "良かっ": 2, "よかっ": 2,
+	"楽し": 2, "美し": 2, "見事": 2, "おすすめ": 2, "オススメ": 2, "満足": 2, "素敵": 2,
+	"良い": 1, "まあまあ": 1, "笑え": 1,
+	// Negative
+	"最悪": -3, "駄作": -3, "ひどすぎ": -3, "金返せ": -3,
+	"つまらな": -2, "退屈": -2, "がっかり": -2, "残念": -2, "ひど": -2, "酷い": -2,
+	"嫌い": -2, "不満": -2, "無駄": -2, "期待外れ": -2, "期待はずれ": -2, "失望": -2, "苦痛": -2,
+	"微妙": -1, "眠くな": -1,
+}
+
+// japaneseNegations follow a stem to negate it, as in 面白くない
+var japaneseNegations = []string{"ない", "なかっ", "ません", "なく", "じゃな"}
+
+// japaneseNegationReach is how many characters after a stem a negation may start
+const japaneseNegationReach = 3
+
+// englishSentiment scores normalized English text from -1 to 1
+func englishSentiment(normalized string) float64 {
+	total := 0.0
+	negated, boost := 0, 1.0
+	for _, word := range strings.Fields(normalized) {
+		switch {
+		case englishNegators[word] || strings.HasSuffix(word, "n't"):
+			negated = negationScope
+			continue
+		case englishIntensifiers[word]:
+			boost = intensifierBoost
+			continue
+		}
+
+		if score, ok := englishLexicon[word]; ok {
+			score *= boost
+			if negated > 0 {
+				// "not good" is less negative than "bad" is
+				score *= -0.5
+			}
+			total += score
+		}
+		boost = 1
+		if negated > 0 {
+			negated--
+		}
+	}
+	return squash(total)
+}
+
+// japaneseSentiment scores normalized Japanese text from -1 to 1, taking the
+// longest stem at each position
+func japaneseSentiment(normalized string) float64 {
+	total := 0.0
+	for i := 0; i < len(normalized); {
+		stem, score := longestStem(normalized[i:])
+		if stem == "" {
+			_, size := utf8.DecodeRuneInString(normalized[i:])
+			i += size
+			continue
+		}
+
+		i += len(stem)
+		if negatedAt(normalized[i:]) {
+			score *= -0.5
+		}
+		total += score
+	}
+	return squash(total)
+}
+
+// longestStem returns the longest japaneseLexicon stem s starts with
+func longestStem(s string) (stem string, score float64) {
+	for candidate, candidateScore := range japaneseLexicon {
+		if len(candidate) > len(stem) && strings.HasPrefix(s, candidate) {
+			stem, score = candidate, candidateScore
+		}
+	}
+	return stem, score
+}
+
+// negatedAt reports whether a Japanese negation starts within the first few
+// characters of s
+func negatedAt(s string) bool {
+	for n, i := 0, 0; n <= japaneseNegationReach && i < len(s); n++ {
+		for _, negation := range japaneseNegations {
+			if strings.HasPrefix(s[i:], negation) {
+				return true
+			}
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+	}
+	return false
+}
+
+// squash maps a summed score into -1 to 1
+func squash(total float64) float64 {
+	return total / math.Sqrt(total*total+normalization)
+}
+
+// sentimentLabel names a sentiment score
+func sentimentLabel(sentiment float64) string {
+	switch {
+	case sentiment > neutralBand:
+		return models.SentimentPositive
+	case sentiment < -neutralBand:
+		return models.SentimentNegative
+	default:
+		return models.SentimentNeutral
+	}
+}
@@ -0,0 +1,126 @@
+package reviewanalysis
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/takeshi-arihori/movie-api/internal/textnorm"
+)
+
+const (
+	// characterEvidence is the spoiler evidence of each character named, by any
+	// of their names
+	characterEvidence = 0.3
+	// maxCharacterEvidence caps the evidence from naming characters
+	maxCharacterEvidence = 0.9
+	// plotEvidence is added when a review both names characters and uses spoiler
+	// keywords, as in "Tyler dies"
+	plotEvidence = 0.5
+	// minNameLength is the length, in characters, of the shortest name matched
+	minNameLength = 4
+)
+
+// spoilerKeywords weigh words and phrases that suggest a review gives away the plot
+var spoilerKeywords = map[string]float64{
+	// English
+	"spoiler": 1, "spoilers": 1, "spoiler alert": 1, "spoilers ahead": 1, "plot twist": 1,
+	"twist ending": 1, "the ending": 0.6, "the end": 0.4, "turns out": 0.8, "final scene": 0.8,
+	"the killer is": 1.5, "is revealed": 0.8, "revealed to be": 1.2, "dies": 0.8, "died": 0.6,
+	"is killed": 1, "gets killed": 1, "was dead": 1.2, "is dead": 0.8, "twist": 0.4, "ending": 0.3,
+	// Japanese
+	"ネタバレ": 1.5, "結末": 0.8, "ラスト": 0.6, "犯人": 0.8, "黒幕": 1.2, "正体": 0.8,
+	"どんでん返し": 1, "死ぬ": 0.6, "死んで": 0.6, "殺され": 0.8, "最後に": 0.4,
+}
+
+// spoilerDisclaimers announce that a review gives nothing away. They are removed
+// before keywords are matched, so that "no spoilers" does not count as "spoilers".
+var spoilerDisclaimers = []string{
+	"no spoilers", "no spoiler", "spoiler free", "spoiler-free", "without spoilers", "without spoiling",
+	"ネタバレなし", "ネタバレ無し", "ネタバレしない",
+}
+
+// ignoredNames are character names and name parts that are ordinary words or
+// roles rather than characters a review could give away
+var ignoredNames = map[string]bool{
+	"himself": true, "herself": true, "themselves": true, "self": true, "narrator": true,
+	"voice": true, "host": true, "guest": true, "additional": true, "voices": true,
+	"young": true, "older": true, "little": true, "mother": true, "father": true,
+	"doctor": true, "detective": true, "officer": true, "agent": true, "captain": true,
+	"king": true, "queen": true, "will": true, "mark": true, "grace": true, "hope": true,
+	"faith": true, "the": true, "man": true, "woman": true, "girl": true, "boy": true,
+}
+
+// parenthetical matches notes such as "(voice)" or "(uncredited)" in character names
+var parenthetical = regexp.MustCompile(`\([^)]*\)`)
+
+// characterNames returns, for each character, the normalized names worth looking
+// for in reviews: the full name, and its parts long enough not to be ordinary
+// words. Characters played by several people are separated by slashes.
+func characterNames(characters []string) [][]string {
+	seen := map[string]bool{}
+	var names [][]string
+	for _, character := range characters {
+		var aliases []string
+		add := func(name string) {
+			if utf8.RuneCountInString(name) >= minNameLength && !ignoredNames[name] && !seen[name] {
+				seen[name] = true
+				aliases = append(aliases, name)
+			}
+		}
+
+		for _, name := range strings.Split(parenthetical.ReplaceAllString(character, ""), "/") {
+			name = textnorm.Normalize(name)
+			if name == "" || strings.ContainsAny(name, "0123456789#") {
+				continue
+			}
+			add(name)
+			if parts := strings.Fields(name); len(parts) > 1 {
+				for _, part := range parts {
+					add(part)
+				}
+			}
+		}
+		if len(aliases) > 0 {
+			names = append(names, aliases)
+		}
+	}
+	return names
+}
+
+// spoilerLikelihood estimates from 0 to 1 how likely normalized text is to spoil
+// the title. Each keyword and named character adds evidence; the likelihood
+// approaches 1 as evidence accumulates.
+func (a *Analyzer) spoilerLikelihood(normalized string) float64 {
+	for _, disclaimer := range spoilerDisclaimers {
+		if isLatin(disclaimer) {
+			disclaimer = " " + disclaimer + " "
+		}
+		normalized = strings.ReplaceAll(normalized, disclaimer, " ")
+	}
+
+	keywords := 0.0
+	for keyword, weight := range spoilerKeywords {
+		if containsWord(normalized, keyword) {
+			keywords += weight
+		}
+	}
+
+	names := 0.0
+	for _, aliases := range a.characters {
+		for _, name := range aliases {
+			if containsWord(normalized, name) {
+				names += characterEvidence
+				break
+			}
+		}
+	}
+	names = math.Min(names, maxCharacterEvidence)
+
+	evidence := keywords + names
+	if keywords > 0 && names > 0 {
+		evidence += plotEvidence
+	}
+	return 1 - math.Exp(-evidence)
+}
@@ -0,0 +1,227 @@
+// Package reviewstats computes review statistics that combine TMDb reviews with
+// reviews written by users of this service.
+//
+// TMDb reviews are slow to aggregate, since every page has to be fetched, so each
+// title's statistics are cached. User reviews change far more often; rather than
+// dropping the cache entry, each change is applied to the cached user tally.
+package reviewstats
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/takeshi-arihori/movie-api/internal/cache"
+	"github.com/takeshi-arihori/movie-api/internal/models"
+	"github.com/takeshi-arihori/movie-api/internal/pagination"
+	"github.com/takeshi-arihori/movie-api/internal/services"
+)
+
+const (
+	// CacheTTL is how long a title's statistics are reused before TMDb is asked again
+	CacheTTL = time.Hour
+
+	// cacheSize bounds the number of titles whose statistics are cached
+	cacheSize = 5000
+
+	// pageConcurrency bounds the TMDb review pages fetched at once
+	pageConcurrency = 4
+)
+
+// Source defines the TMDb review lists statistics are built from
+type Source = services.ReviewSource
+
+// RatingSource provides the ratings of user reviews, such as the user_reviews table
+type RatingSource interface {
+	// ApprovedRatings returns the ratings of a title's approved reviews
+	ApprovedRatings(ctx context.Context, mediaType string, mediaID int) ([]float64, error)
+}
+
+// Service computes and caches review statistics
+type Service struct {
+	source  Source
+	ratings RatingSource
+	cache   *cache.Cache[string, *entry]
+
+	mu      sync.Mutex    // Guards the tallies of cached entries and caching new ones
+	changes atomic.Uint64 // Number of user review changes seen so far; only added to under mu
+}
+
+// entry holds a title's tallies
+type entry struct {
+	tmdb  tally
+	users tally
+}
+
+// tally counts reviews and their ratings. Reviews without a rating count towards
+// the number of reviews but not towards the average or distribution.
+type tally struct {
+	reviews   int
+	rated     int
+	sum       float64
+	histogram [10]int // Rated reviews by rating rounded to 1-10
+}
+
+// NewService creates a Service that reads TMDb reviews from source. Only TMDb
+// reviews are counted until SetRatings is called.
+func NewService(source Source) *Service {
+	return &Service{
+		source: source,
+		cache:  cache.New[string, *entry](CacheTTL, cacheSize),
+	}
+}
+
+// SetRatings counts user reviews from ratings. It must be called before the
+// service starts serving requests.
+func (s *Service) SetRatings(ratings RatingSource) {
+	s.ratings = ratings
+}
+
+// Stats returns the review statistics of a movie or TV show. mediaType is "movie"
+// or "tv". Errors from TMDb, such as a title that does not exist, are returned
+// unchanged.
+func (s *Service) Stats(ctx context.Context, mediaType string, mediaID int) (*models.ReviewStats, error) {
+	key := cacheKey(mediaType, mediaID)
+	if e, ok := s.cache.Get(key); ok {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return e.stats(mediaType, mediaID), nil
+	}
+
+	// A user review that changes while the tallies are computed may or may not be
+	// counted, so the result is only cached if nothing changed. Changes are made
+	// under s.mu, so none can slip in between the check and caching the result.
+	changes := s.changes.Load()
+	e := &entry{}
+	if err := s.tallyTMDb(ctx, &e.tmdb, mediaType, mediaID); err != nil {
+		return nil, err
+	}
+	if s.ratings != nil {
+		ratings, err := s.ratings.ApprovedRatings(ctx, mediaType, mediaID)
+		if err != nil {
+			return nil, fmt.Errorf("get user ratings: %w", err)
+		}
+		for _, rating := range ratings {
+			e.users.add(&rating, 1)
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.changes.Load() == changes {
+		s.cache.Set(key, e)
+	}
+	return e.stats(mediaType, mediaID), nil
+}
+
+// ReviewChanged updates cached statistics after a user review was created,
+// edited, moderated or deleted. before is the review as it was, or nil if it was
+// created; after is the review as it now is, or nil if it was deleted. Only
+// approved reviews are counted.
+func (s *Service) ReviewChanged(before, after *models.UserReview) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.changes.Add(1)
+	s.count(before, -1)
+	s.count(after, 1)
+}
+
+// count adds review to or, when sign is -1, removes it from its title's cached
+// user tally. The caller must hold s.mu.
+func (s *Service) count(review *models.UserReview, sign int) {
+	if review == nil || review.Status != models.ReviewStatusApproved {
+		return
+	}
+	if e, ok := s.cache.Get(cacheKey(review.MediaType, review.MediaID)); ok {
+		e.users.add(&review.Rating, sign)
+	}
+}
+
+// forget drops a title's cached statistics so that they are recomputed
+func (s *Service) forget(mediaType string, mediaID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.changes.Add(1)
+	s.cache.Delete(cacheKey(mediaType, mediaID))
+}
+
+// tallyTMDb counts all of a title's TMDb reviews into t. Unlike
+// services.AllReviews, which stops at services.MaxReviewPages for display, every
+// page TMDb serves is fetched so that the statistics are complete; the result is
+// cached for CacheTTL.
+func (s *Service) tallyTMDb(ctx context.Context, t *tally, mediaType string, mediaID int) error {
+	pages := services.ReviewPages(s.source, models.SearchItemType(mediaType), mediaID)
+	reviews, err := pagination.FetchAll(ctx, pages, pagination.MaxPage, pageConcurrency)
+	if err != nil {
+		return err
+	}
+	for _, review := range reviews.Results {
+		t.add(review.AuthorDetails.Rating, 1)
+	}
+	return nil
+}
+
+// add counts a review with rating, which is nil if the review has none, when sign
+// is 1 and uncounts it when sign is -1
+func (t *tally) add(rating *float64, sign int) {
+	t.reviews += sign
+	if rating == nil {
+		return
+	}
+	t.rated += sign
+	t.sum += float64(sign) * *rating
+	t.histogram[bucket(*rating)] += sign
+}
+
+// merge adds the counts of other to t
+func (t *tally) merge(other tally) {
+	t.reviews += other.reviews
+	t.rated += other.rated
+	t.sum += other.sum
+	for i, n := range other.histogram {
+		t.histogram[i] += n
+	}
+}
+
+// average returns the mean rating to one decimal place, or 0 with no ratings
+func (t *tally) average() float64 {
+	if t.rated <= 0 {
+		return 0
+	}
+	return math.Round(t.sum/float64(t.rated)*10) / 10
+}
+
+// stats builds the statistics of the title e belongs to
+func (e *entry) stats(mediaType string, mediaID int) *models.ReviewStats {
+	all := e.tmdb
+	all.merge(e.users)
+	h := all.histogram
+
+	return &models.ReviewStats{
+		MediaID:       mediaID,
+		MediaType:     mediaType,
+		TotalReviews:  all.reviews,
+		AverageRating: all.average(),
+		RatingDistribution: models.RatingDistribution{
+			Rating1: h[0], Rating2: h[1], Rating3: h[2], Rating4: h[3], Rating5: h[4],
+			Rating6: h[5], Rating7: h[6], Rating8: h[7], Rating9: h[8], Rating10: h[9],
+		},
+		TMDbReviews: e.tmdb.reviews,
+		TMDbRating:  e.tmdb.average(),
+		UserReviews: e.users.reviews,
+		UserRating:  e.users.average(),
+	}
+}
+
+// bucket returns the histogram index of a rating. TMDb ratings run from 0 to 10,
+// so ratings are rounded and then clamped to 1-10.
+func bucket(rating float64) int {
+	return min(max(int(math.Round(rating)), 1), 10) - 1
+}
+
+// cacheKey identifies a title in the cache
+func cacheKey(mediaType string, mediaID int) string {
+	return fmt.Sprintf("%s:%d", mediaType, mediaID)
+}
@@ -0,0 +1,303 @@
+package reviewstats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+	"github.com/takeshi-arihori/movie-api/internal/services"
+	"github.com/takeshi-arihori/movie-api/internal/store"
+)
+
+func ratingPtr(r float64) *float64 {
+	return &r
+}
+
+// fakeSource serves pages of TMDb reviews and counts the pages fetched
+type fakeSource struct {
+	mu    sync.Mutex
+	pages [][]models.Review
+	calls int
+	err   error
+}
+
+func (s *fakeSource) page(page int) ([]models.Review, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if s.err != nil {
+		return nil, 0, s.err
+	}
+	if page > len(s.pages) {
+		return nil, len(s.pages), nil
+	}
+	return s.pages[page-1], len(s.pages), nil
+}
+
+func (s *fakeSource) GetMovieReviews(ctx context.Context, movieID int, page int) (*models.MovieReviews, error) {
+	results, pages, err := s.page(page)
+	if err != nil {
+		return nil, err
+	}
+	return &models.MovieReviews{ID: movieID, Page: page, Results: results, TotalPages: pages}, nil
+}
+
+func (s *fakeSource) GetTVShowReviews(ctx context.Context, tvID int, page int) (*models.TVReviews, error) {
+	results, pages, err := s.page(page)
+	if err != nil {
+		return nil, err
+	}
+	return &models.TVReviews{ID: tvID, Page: page, Results: results, TotalPages: pages}, nil
+}
+
+// fakeRatings serves fixed user review ratings
+type fakeRatings []float64
+
+func (r fakeRatings) ApprovedRatings(ctx context.Context, mediaType string, mediaID int) ([]float64, error) {
+	return r, nil
+}
+
+func newTestService() (*Service, *fakeSource) {
+	source := &fakeSource{pages: [][]models.Review{
+		{
+			{ID: "a", AuthorDetails: models.AuthorDetails{Rating: ratingPtr(8)}},
+			{ID: "b", AuthorDetails: models.AuthorDetails{Rating: ratingPtr(0)}},
+			{ID: "c"},
+		},
+		{
+			{ID: "d", AuthorDetails: models.AuthorDetails{Rating: ratingPtr(10)}},
+		},
+	}}
+	s := NewService(source)
+	s.SetRatings(fakeRatings{6, 7.5})
+	return s, source
+}
+
+func TestStats(t *testing.T) {
+	s, source := newTestService()
+
+	stats, err := s.Stats(context.Background(), "movie", 550)
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+
+	if stats.MediaID != 550 || stats.MediaType != "movie" {
+		t.Errorf("Expected movie 550, got %s %d", stats.MediaType, stats.MediaID)
+	}
+	if stats.TMDbReviews != 4 || stats.TMDbRating != 6 {
+		t.Errorf("Expected 4 TMDb reviews averaging 6 over the rated ones, got %d averaging %v", stats.TMDbReviews, stats.TMDbRating)
+	}
+	if stats.UserReviews != 2 || stats.UserRating != 6.8 {
+		t.Errorf("Expected 2 user reviews averaging 6.8, got %d averaging %v", stats.UserReviews, stats.UserRating)
+	}
+	if stats.TotalReviews != 6 || stats.AverageRating != 6.3 {
+		t.Errorf("Expected 6 reviews averaging 6.3, got %d averaging %v", stats.TotalReviews, stats.AverageRating)
+	}
+	want := models.RatingDistribution{Rating1: 1, Rating6: 1, Rating8: 2, Rating10: 1}
+	if stats.RatingDistribution != want {
+		t.Errorf("Expected distribution %+v, got %+v", want, stats.RatingDistribution)
+	}
+
+	if source.calls != 2 {
+		t.Errorf("Expected both TMDb pages to be fetched, got %d calls", source.calls)
+	}
+	if _, err := s.Stats(context.Background(), "movie", 550); err != nil || source.calls != 2 {
+		t.Errorf("Expected cached stats, got %d calls, %v", source.calls, err)
+	}
+	if _, err := s.Stats(context.Background(), "tv", 550); err != nil || source.calls != 4 {
+		t.Errorf("Expected TV show stats to be cached separately, got %d calls, %v", source.calls, err)
+	}
+}
+
+func TestStats_AllPages(t *testing.T) {
+	source := &fakeSource{}
+	for i := 0; i < services.MaxReviewPages+5; i++ {
+		source.pages = append(source.pages, []models.Review{{ID: fmt.Sprint(i), AuthorDetails: models.AuthorDetails{Rating: ratingPtr(5)}}})
+	}
+	s := NewService(source)
+
+	stats, err := s.Stats(context.Background(), "movie", 550)
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	if stats.TMDbReviews != len(source.pages) {
+		t.Errorf("Expected all %d TMDb reviews to be counted, got %d", len(source.pages), stats.TMDbReviews)
+	}
+}
+
+func TestStats_TMDbError(t *testing.T) {
+	s, source := newTestService()
+	source.err = errors.New("connection refused")
+
+	if _, err := s.Stats(context.Background(), "movie", 550); err == nil {
+		t.Fatal("Expected the TMDb error")
+	}
+	source.err = nil
+	if _, err := s.Stats(context.Background(), "movie", 550); err != nil {
+		t.Errorf("Expected failures not to be cached, got %v", err)
+	}
+}
+
+func TestReviewChanged(t *testing.T) {
+	s, source := newTestService()
+	ctx := context.Background()
+	s.Stats(ctx, "movie", 550)
+
+	review := &models.UserReview{ID: "r1", MediaType: "movie", MediaID: 550, Rating: 9, Status: models.ReviewStatusPending}
+	steps := []struct {
+		name          string
+		before, after *models.UserReview
+		userReviews   int
+		userRating    float64
+	}{
+		{"pending review", nil, review, 2, 6.8},
+		{"approved", review, &models.UserReview{MediaType: "movie", MediaID: 550, Rating: 9, Status: models.ReviewStatusApproved}, 3, 7.5},
+		{"edited", &models.UserReview{MediaType: "movie", MediaID: 550, Rating: 9, Status: models.ReviewStatusApproved}, &models.UserReview{MediaType: "movie", MediaID: 550, Rating: 3, Status: models.ReviewStatusApproved}, 3, 5.5},
+		{"deleted", &models.UserReview{MediaType: "movie", MediaID: 550, Rating: 3, Status: models.ReviewStatusApproved}, nil, 2, 6.8},
+		{"another title", nil, &models.UserReview{MediaType: "movie", MediaID: 603, Rating: 1, Status: models.ReviewStatusApproved}, 2, 6.8},
+	}
+
+	for _, step := range steps {
+		s.ReviewChanged(step.before, step.after)
+		stats, err := s.Stats(ctx, "movie", 550)
+		if err != nil {
+			t.Fatalf("%s: Stats failed: %v", step.name, err)
+		}
+		if stats.UserReviews != step.userReviews || stats.UserRating != step.userRating {
+			t.Errorf("%s: expected %d user reviews averaging %v, got %d averaging %v",
+				step.name, step.userReviews, step.userRating, stats.UserReviews, stats.UserRating)
+		}
+	}
+	if source.calls != 2 {
+		t.Errorf("Expected changes to be applied without refetching TMDb, got %d calls", source.calls)
+	}
+}
+
+// fakeReviews is a store.UserReviewRepository holding a single review. Get
+// serves stale instead when set, like a read racing a concurrent change.
+type fakeReviews struct {
+	store.UserReviewRepository
+	review *models.UserReview
+	stale  *models.UserReview
+}
+
+func (f *fakeReviews) Get(ctx context.Context, id string) (*models.UserReview, error) {
+	if f.stale != nil {
+		review := *f.stale
+		return &review, nil
+	}
+	if f.review == nil || f.review.ID != id {
+		return nil, store.ErrNotFound
+	}
+	review := *f.review
+	return &review, nil
+}
+
+func (f *fakeReviews) Create(ctx context.Context, review *models.UserReview) error {
+	stored := *review
+	f.review = &stored
+	return nil
+}
+
+func (f *fakeReviews) Update(ctx context.Context, review *models.UserReview) (*models.UserReview, error) {
+	if f.review == nil || f.review.ID != review.ID {
+		return nil, store.ErrNotFound
+	}
+	previous := f.review
+	stored := *review
+	f.review = &stored
+	return previous, nil
+}
+
+func (f *fakeReviews) Delete(ctx context.Context, userID, id string) (*models.UserReview, error) {
+	if f.review == nil || f.review.ID != id {
+		return nil, store.ErrNotFound
+	}
+	deleted := f.review
+	f.review = nil
+	return deleted, nil
+}
+
+func (f *fakeReviews) Moderate(ctx context.Context, entry *models.ReviewModerationEntry, notification *models.Notification) (*models.UserReview, *models.UserReview, error) {
+	if f.review == nil || f.review.ID != entry.ReviewID {
+		return nil, nil, store.ErrNotFound
+	}
+	previous := *f.review
+	f.review.Status = models.ReviewStatusRejected
+	review := *f.review
+	return &previous, &review, nil
+}
+
+func TestTrack(t *testing.T) {
+	s, source := newTestService()
+	ctx := context.Background()
+	reviews := s.Track(&fakeReviews{})
+	s.Stats(ctx, "movie", 550)
+
+	userReviews := func() int {
+		stats, err := s.Stats(ctx, "movie", 550)
+		if err != nil {
+			t.Fatalf("Stats failed: %v", err)
+		}
+		return stats.UserReviews
+	}
+
+	review := &models.UserReview{ID: "r1", MediaType: "movie", MediaID: 550, Rating: 9, Status: models.ReviewStatusApproved}
+	if err := reviews.Create(ctx, review); err != nil || userReviews() != 3 {
+		t.Errorf("Expected a created review to be counted, got %d, %v", userReviews(), err)
+	}
+	review.Rating = 4
+	if _, err := reviews.Update(ctx, review); err != nil || userReviews() != 3 {
+		t.Errorf("Expected an edited review to be counted once, got %d, %v", userReviews(), err)
+	}
+	if _, _, err := reviews.Moderate(ctx, &models.ReviewModerationEntry{ReviewID: "r1", Action: models.ReviewActionReject}, &models.Notification{}); err != nil || userReviews() != 2 {
+		t.Errorf("Expected a rejected review to be uncounted, got %d, %v", userReviews(), err)
+	}
+	if _, err := reviews.Delete(ctx, "", "missing"); !errors.Is(err, store.ErrNotFound) || userReviews() != 2 {
+		t.Errorf("Expected a failed delete to change nothing, got %d, %v", userReviews(), err)
+	}
+	if source.calls != 2 {
+		t.Errorf("Expected changes to be applied without refetching TMDb, got %d calls", source.calls)
+	}
+}
+
+// TestTrack_ConcurrentChanges tests that changes are counted against the review
+// the store replaced, not one read beforehand that a concurrent change outdated
+func TestTrack_ConcurrentChanges(t *testing.T) {
+	s, _ := newTestService()
+	ctx := context.Background()
+	fake := &fakeReviews{}
+	reviews := s.Track(fake)
+	s.Stats(ctx, "movie", 550)
+
+	userReviews := func() int {
+		stats, err := s.Stats(ctx, "movie", 550)
+		if err != nil {
+			t.Fatalf("Stats failed: %v", err)
+		}
+		return stats.UserReviews
+	}
+
+	approved := &models.UserReview{ID: "r1", MediaType: "movie", MediaID: 550, Rating: 9, Status: models.ReviewStatusApproved}
+	if err := reviews.Create(ctx, approved); err != nil || userReviews() != 3 {
+		t.Fatalf("Expected a created review to be counted, got %d, %v", userReviews(), err)
+	}
+
+	// A moderator rejects the review while its author's edit is in flight, so a
+	// read before the edit would still see it approved
+	stale := *approved
+	fake.stale = &stale
+	if _, _, err := reviews.Moderate(ctx, &models.ReviewModerationEntry{ReviewID: "r1", Action: models.ReviewActionReject}, &models.Notification{}); err != nil || userReviews() != 2 {
+		t.Fatalf("Expected a rejected review to be uncounted, got %d, %v", userReviews(), err)
+	}
+	edited := &models.UserReview{ID: "r1", MediaType: "movie", MediaID: 550, Rating: 4, Status: models.ReviewStatusRejected}
+	if _, err := reviews.Update(ctx, edited); err != nil || userReviews() != 2 {
+		t.Errorf("Expected an edit of a rejected review to stay uncounted, got %d, %v", userReviews(), err)
+	}
+	if _, err := reviews.Delete(ctx, "", "r1"); err != nil || userReviews() != 2 {
+		t.Errorf("Expected deleting a rejected review to change nothing, got %d, %v", userReviews(), err)
+	}
+}
@@ -0,0 +1,62 @@
+package reviewstats
+
+import (
+	"context"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+	"github.com/takeshi-arihori/movie-api/internal/store"
+)
+
+// trackingRepository is a store.UserReviewRepository that reports every
+// successful change to a Service. The store returns each review as it was
+// before a change, read in the same transaction, so concurrent changes to a
+// review cannot make the cached tally drift.
+type trackingRepository struct {
+	store.UserReviewRepository
+	stats *Service
+}
+
+// Track wraps reviews so that the service's cached statistics follow the reviews
+// created, edited, moderated and deleted through it
+func (s *Service) Track(reviews store.UserReviewRepository) store.UserReviewRepository {
+	return &trackingRepository{UserReviewRepository: reviews, stats: s}
+}
+
+// Create inserts a review and counts it
+func (r *trackingRepository) Create(ctx context.Context, review *models.UserReview) error {
+	if err := r.UserReviewRepository.Create(ctx, review); err != nil {
+		return err
+	}
+	r.stats.ReviewChanged(nil, review)
+	return nil
+}
+
+// Update saves a review and recounts it
+func (r *trackingRepository) Update(ctx context.Context, review *models.UserReview) (*models.UserReview, error) {
+	previous, err := r.UserReviewRepository.Update(ctx, review)
+	if err != nil {
+		return nil, err
+	}
+	r.stats.ReviewChanged(previous, review)
+	return previous, nil
+}
+
+// Delete deletes a review and uncounts it
+func (r *trackingRepository) Delete(ctx context.Context, userID, id string) (*models.UserReview, error) {
+	deleted, err := r.UserReviewRepository.Delete(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	r.stats.ReviewChanged(deleted, nil)
+	return deleted, nil
+}
+
+// Moderate applies a moderator's decision and recounts the review
+func (r *trackingRepository) Moderate(ctx context.Context, entry *models.ReviewModerationEntry, notification *models.Notification) (*models.UserReview, *models.UserReview, error) {
+	previous, moderated, err := r.UserReviewRepository.Moderate(ctx, entry, notification)
+	if err != nil {
+		return nil, nil, err
+	}
+	r.stats.ReviewChanged(previous, moderated)
+	return previous, moderated, nil
+}
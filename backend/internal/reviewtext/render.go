@@ -0,0 +1,608 @@
+// Package reviewtext renders the content of TMDb reviews for display.
+//
+// Review content is written in Markdown, often mixed with stray HTML such as
+// <em> or <br /> tags and HTML entities. Render parses both into a small document
+// tree and writes it out again as HTML and as plain text. Only the markup the tree
+// knows about survives, every piece of text is escaped, and links are kept only
+// when they point at http or https URLs, so the HTML is safe to embed as is.
+package reviewtext
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// ExcerptLength is the length, in characters, of review excerpts
+	ExcerptLength = 200
+	// MaxContentLength is the length, in bytes, of review content rendered; the
+	// rest is left out
+	MaxContentLength = 64 << 10
+	// maxInlineDepth bounds how deeply inline elements nest; deeper content is
+	// kept as plain text
+	maxInlineDepth = 16
+)
+
+// Rendered is review content rendered for display
+type Rendered struct {
+	HTML string
+	Text string
+}
+
+// Render renders Markdown and HTML review content as safe HTML and as plain text
+func Render(content string) Rendered {
+	if len(content) > MaxContentLength {
+		cut := MaxContentLength
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+		content = content[:cut]
+	}
+	blocks := parseBlocks(strings.Split(normalize(content), "\n"))
+
+	var h, t strings.Builder
+	for i, b := range blocks {
+		b.writeHTML(&h)
+		if i > 0 {
+			t.WriteString("\n\n")
+		}
+		b.writeText(&t)
+	}
+	return Rendered{HTML: h.String(), Text: t.String()}
+}
+
+// Excerpt shortens plain text to at most length characters, collapsing whitespace
+// and cutting at a word boundary where there is one. Shortened text ends in an
+// ellipsis, which counts towards the length.
+func Excerpt(text string, length int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= length {
+		return text
+	}
+
+	runes := []rune(text)[:max(length-1, 0)]
+	cut := string(runes)
+	if i := strings.LastIndexByte(cut, ' '); i > len(cut)/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimRightFunc(cut, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	}) + "…"
+}
+
+var (
+	// scriptPattern matches elements whose content is never text
+	scriptPattern = regexp.MustCompile(`(?is)<(script|style)\b.*?</(script|style)\s*>`)
+	// blockTagPattern matches HTML tags that separate paragraphs
+	blockTagPattern = regexp.MustCompile(`(?i)</?(p|div|blockquote|ul|ol|h[1-6])\b[^>]*>`)
+	// breakTagPattern matches line breaks
+	breakTagPattern = regexp.MustCompile(`(?i)<br\s*/?>`)
+	// itemTagPattern matches HTML list items, which become Markdown ones
+	itemTagPattern = regexp.MustCompile(`(?i)<li\b[^>]*>`)
+	// endItemTagPattern matches the end of HTML list items
+	endItemTagPattern = regexp.MustCompile(`(?i)</li\s*>`)
+)
+
+// normalize unifies line endings and turns block-level HTML into the line
+// structure Markdown would use, leaving inline tags for the inline parser
+func normalize(content string) string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = strings.ReplaceAll(content, "\r", "\n")
+	content = scriptPattern.ReplaceAllString(content, "")
+	content = blockTagPattern.ReplaceAllString(content, "\n\n")
+	content = breakTagPattern.ReplaceAllString(content, "\n")
+	content = itemTagPattern.ReplaceAllString(content, "\n- ")
+	content = endItemTagPattern.ReplaceAllString(content, "\n")
+	return content
+}
+
+// blockKind is the kind of a block of content
+type blockKind int
+
+const (
+	paragraph blockKind = iota
+	heading
+	quote
+	bulletList
+	numberedList
+	rule
+)
+
+// block is a paragraph-level element. Paragraphs and headings hold lines, lists
+// hold one line per item and quotes hold nested blocks.
+type block struct {
+	kind   blockKind
+	level  int // Heading level, 1-6
+	lines  [][]inline
+	blocks []block
+}
+
+var (
+	headingPattern = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	bulletPattern  = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	numberPattern  = regexp.MustCompile(`^\s*\d{1,9}[.)]\s+(.*)$`)
+	rulePattern    = regexp.MustCompile(`^\s*([-*_])(\s*[-*_]){2,}\s*$`)
+	quotePattern   = regexp.MustCompile(`^\s*(>|&gt;)\s?(.*)$`)
+)
+
+// parseBlocks splits lines into blocks. Blank lines end paragraphs and lists;
+// consecutive quoted lines form one quote.
+func parseBlocks(lines []string) []block {
+	var blocks []block
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		switch {
+		case strings.TrimSpace(line) == "":
+			i++
+
+		case rulePattern.MatchString(line):
+			blocks = append(blocks, block{kind: rule})
+			i++
+
+		case headingPattern.MatchString(line):
+			m := headingPattern.FindStringSubmatch(line)
+			blocks = append(blocks, block{kind: heading, level: len(m[1]), lines: [][]inline{parseInline(m[2], 0)}})
+			i++
+
+		case quotePattern.MatchString(line):
+			var quoted []string
+			for ; i < len(lines) && quotePattern.MatchString(lines[i]); i++ {
+				quoted = append(quoted, quotePattern.FindStringSubmatch(lines[i])[2])
+			}
+			blocks = append(blocks, block{kind: quote, blocks: parseBlocks(quoted)})
+
+		case bulletPattern.MatchString(line), numberPattern.MatchString(line):
+			pattern, kind := bulletPattern, bulletList
+			if !bulletPattern.MatchString(line) {
+				pattern, kind = numberPattern, numberedList
+			}
+			b := block{kind: kind}
+			for ; i < len(lines) && pattern.MatchString(lines[i]); i++ {
+				if item := strings.TrimSpace(pattern.FindStringSubmatch(lines[i])[1]); item != "" {
+					b.lines = append(b.lines, parseInline(item, 0))
+				}
+			}
+			if len(b.lines) > 0 {
+				blocks = append(blocks, b)
+			}
+
+		default:
+			b := block{kind: paragraph}
+			for ; i < len(lines) && strings.TrimSpace(lines[i]) != "" && !startsBlock(lines[i]); i++ {
+				b.lines = append(b.lines, parseInline(strings.TrimSpace(lines[i]), 0))
+			}
+			blocks = append(blocks, b)
+		}
+	}
+	return blocks
+}
+
+// startsBlock reports whether line ends the paragraph before it
+func startsBlock(line string) bool {
+	return headingPattern.MatchString(line) || quotePattern.MatchString(line) ||
+		bulletPattern.MatchString(line) || numberPattern.MatchString(line) ||
+		rulePattern.MatchString(line)
+}
+
+// writeHTML writes the block as HTML
+func (b block) writeHTML(w *strings.Builder) {
+	switch b.kind {
+	case heading:
+		// Review headings sit below the page's own, so they start at h3
+		tag := "h" + strconv.Itoa(min(b.level+2, 6))
+		w.WriteString("<" + tag + ">")
+		writeInlineHTML(w, b.lines[0])
+		w.WriteString("</" + tag + ">")
+	case quote:
+		w.WriteString("<blockquote>")
+		for _, nested := range b.blocks {
+			nested.writeHTML(w)
+		}
+		w.WriteString("</blockquote>")
+	case bulletList, numberedList:
+		tag := "ul"
+		if b.kind == numberedList {
+			tag = "ol"
+		}
+		w.WriteString("<" + tag + ">")
+		for _, item := range b.lines {
+			w.WriteString("<li>")
+			writeInlineHTML(w, item)
+			w.WriteString("</li>")
+		}
+		w.WriteString("</" + tag + ">")
+	case rule:
+		w.WriteString("<hr>")
+	default:
+		w.WriteString("<p>")
+		for i, line := range b.lines {
+			if i > 0 {
+				w.WriteString("<br>")
+			}
+			writeInlineHTML(w, line)
+		}
+		w.WriteString("</p>")
+	}
+}
+
+// writeText writes the block as plain text
+func (b block) writeText(w *strings.Builder) {
+	switch b.kind {
+	case quote:
+		for i, nested := range b.blocks {
+			if i > 0 {
+				w.WriteString("\n\n")
+			}
+			nested.writeText(w)
+		}
+	case bulletList, numberedList:
+		for i, item := range b.lines {
+			if i > 0 {
+				w.WriteString("\n")
+			}
+			if b.kind == numberedList {
+				w.WriteString(strconv.Itoa(i+1) + ". ")
+			} else {
+				w.WriteString("- ")
+			}
+			writeInlineText(w, item)
+		}
+	case rule:
+		w.WriteString("---")
+	default:
+		for i, line := range b.lines {
+			if i > 0 {
+				w.WriteString("\n")
+			}
+			writeInlineText(w, line)
+		}
+	}
+}
+
+// inlineKind is the kind of a piece of inline content
+type inlineKind int
+
+const (
+	text inlineKind = iota
+	span            // Inline content without markup of its own
+	emphasis
+	strong
+	code
+	link
+)
+
+// inline is a run of text or an element holding other inline content
+type inline struct {
+	kind     inlineKind
+	text     string // Unescaped text of text and code runs
+	href     string // Target of links
+	children []inline
+}
+
+var (
+	// htmlTagPattern matches an inline HTML tag, capturing whether it closes, its
+	// name and its attributes
+	htmlTagPattern = regexp.MustCompile(`^<(/?)([a-zA-Z][a-zA-Z0-9]*)\b([^<>]*)>`)
+	// anyTagPattern matches any HTML tag
+	anyTagPattern = regexp.MustCompile(`<[^<>]*>`)
+	// hrefPattern captures the href attribute of an anchor tag
+	hrefPattern = regexp.MustCompile(`(?i)\bhref\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+	// inlineTags maps the HTML tags kept in inline content to their kind
+	inlineTags = map[string]inlineKind{
+		"em": emphasis, "i": emphasis,
+		"strong": strong, "b": strong,
+		"code": code,
+		"a":    link,
+	}
+)
+
+// parseInline parses Markdown emphasis, code spans and links, and the HTML tags
+// in inlineTags, in a single line. Other HTML tags are dropped, and delimiters or
+// tags without a match are kept as text. depth is the number of elements s is
+// nested in.
+func parseInline(s string, depth int) []inline {
+	if depth >= maxInlineDepth {
+		return []inline{{kind: text, text: html.UnescapeString(stripTags(s))}}
+	}
+	closing := matchTags(s)
+	// unclosed holds the delimiters no closer was found for; later ones would
+	// scan the rest of the line again for nothing
+	unclosed := map[string]bool{}
+	var nodes []inline
+	var plain strings.Builder
+	flush := func() {
+		if plain.Len() > 0 {
+			nodes = append(nodes, inline{kind: text, text: html.UnescapeString(plain.String())})
+			plain.Reset()
+		}
+	}
+
+	for i := 0; i < len(s); {
+		rest := s[i:]
+		switch {
+		case rest[0] == '\\' && len(rest) > 1 && isASCIIPunct(rest[1]):
+			plain.WriteByte(rest[1])
+			i += 2
+			continue
+
+		case rest[0] == '`':
+			if end := strings.IndexByte(rest[1:], '`'); end > 0 {
+				flush()
+				nodes = append(nodes, inline{kind: code, text: html.UnescapeString(rest[1 : end+1])})
+				i += end + 2
+				continue
+			}
+
+		case rest[0] == '*' || rest[0] == '_':
+			delim := rest[:1]
+			kind := emphasis
+			if strings.HasPrefix(rest, delim+delim) {
+				delim, kind = delim+delim, strong
+			}
+			if !unclosed[delim] && canOpen(s, i, delim) {
+				if end := findCloser(s, i+len(delim), delim); end >= 0 {
+					flush()
+					nodes = append(nodes, inline{kind: kind, children: parseInline(s[i+len(delim):end], depth+1)})
+					i = end + len(delim)
+					continue
+				}
+				unclosed[delim] = true
+			}
+			plain.WriteString(delim)
+			i += len(delim)
+			continue
+
+		case rest[0] == '[':
+			if label, target, n, ok := markdownLink(rest); ok {
+				flush()
+				nodes = append(nodes, newLink(target, parseInline(label, depth+1)))
+				i += n
+				continue
+			}
+
+		case rest[0] == '<':
+			if m := htmlTagPattern.FindStringSubmatch(rest); m != nil {
+				name := strings.ToLower(m[2])
+				kind, kept := inlineTags[name]
+				if kept && m[1] == "" {
+					if end, ok := closing[i]; ok {
+						flush()
+						inner := s[i+len(m[0]) : end.start]
+						switch kind {
+						case link:
+							nodes = append(nodes, newLink(hrefOf(m[3]), parseInline(inner, depth+1)))
+						case code:
+							nodes = append(nodes, inline{kind: code, text: html.UnescapeString(stripTags(inner))})
+						default:
+							nodes = append(nodes, inline{kind: kind, children: parseInline(inner, depth+1)})
+						}
+						i = end.end
+						continue
+					}
+				}
+				// Unknown or unmatched tags are dropped
+				i += len(m[0])
+				continue
+			}
+		}
+
+		_, size := utf8.DecodeRuneInString(rest)
+		plain.WriteString(rest[:size])
+		i += size
+	}
+	flush()
+	return nodes
+}
+
+// canOpen reports whether the delimiter at s[i] can open emphasis: it must be
+// followed by a non-space, and underscores must not sit inside a word
+func canOpen(s string, i int, delim string) bool {
+	next, _ := utf8.DecodeRuneInString(s[i+len(delim):])
+	if i+len(delim) >= len(s) || unicode.IsSpace(next) {
+		return false
+	}
+	if delim[0] == '_' && i > 0 {
+		prev, _ := utf8.DecodeLastRuneInString(s[:i])
+		return !isWordRune(prev)
+	}
+	return true
+}
+
+// findCloser returns the index of the delimiter closing emphasis opened before
+// s[from], or -1. A closer follows a non-space, and underscores must not be
+// followed by a word character.
+func findCloser(s string, from int, delim string) int {
+	for i := from; i < len(s); {
+		j := strings.Index(s[i:], delim)
+		if j < 0 {
+			return -1
+		}
+		j += i
+		prev, _ := utf8.DecodeLastRuneInString(s[:j])
+		next, _ := utf8.DecodeRuneInString(s[j+len(delim):])
+		closes := j > from && !unicode.IsSpace(prev)
+		if delim[0] == '_' && j+len(delim) < len(s) && isWordRune(next) {
+			closes = false
+		}
+		// A double delimiter followed by a third closes on the last two, so that
+		// ***text*** nests emphasis inside strong; a single delimiter must not
+		// match half of a double one
+		if len(delim) == 2 && j+2 < len(s) && s[j+2] == delim[0] {
+			j++
+		}
+		if len(delim) == 1 && j+1 < len(s) && s[j+1] == delim[0] {
+			closes = false
+			j++
+		}
+		if closes {
+			return j
+		}
+		i = j + 1
+	}
+	return -1
+}
+
+// tagSpan is the position of an HTML tag in a string
+type tagSpan struct {
+	start, end int
+}
+
+// matchTags finds the tags in inlineTags in a single pass and returns the tag
+// closing each element, by the index of the tag opening it. Nested elements of
+// the same name are skipped, so a closing tag closes the latest one still open.
+func matchTags(s string) map[int]tagSpan {
+	closing := map[int]tagSpan{}
+	open := map[string][]int{}
+	for i := 0; i < len(s); {
+		j := strings.IndexByte(s[i:], '<')
+		if j < 0 {
+			break
+		}
+		j += i
+		m := htmlTagPattern.FindStringSubmatch(s[j:])
+		if m == nil {
+			i = j + 1
+			continue
+		}
+		i = j + len(m[0])
+
+		name := strings.ToLower(m[2])
+		if _, kept := inlineTags[name]; !kept {
+			continue
+		}
+		if m[1] == "" {
+			open[name] = append(open[name], j)
+		} else if n := len(open[name]); n > 0 {
+			closing[open[name][n-1]] = tagSpan{start: j, end: i}
+			open[name] = open[name][:n-1]
+		}
+	}
+	return closing
+}
+
+// markdownLink parses a [label](target) link at the start of s, returning its
+// length in bytes
+func markdownLink(s string) (label, target string, n int, ok bool) {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth > 0 {
+				continue
+			}
+			if !strings.HasPrefix(s[i+1:], "(") {
+				return "", "", 0, false
+			}
+			end := closingParen(s[i+2:])
+			if end < 0 {
+				return "", "", 0, false
+			}
+			target = strings.TrimSpace(s[i+2 : i+2+end])
+			// Drop an optional "title" after the URL
+			if fields := strings.Fields(target); len(fields) > 0 {
+				target = fields[0]
+			}
+			return s[1:i], strings.Trim(target, "<>"), i + 3 + end, true
+		}
+	}
+	return "", "", 0, false
+}
+
+// closingParen returns the index of the parenthesis closing a link target that
+// s starts with, allowing for balanced parentheses inside it, or -1
+func closingParen(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+	return -1
+}
+
+// newLink returns a link to target, or just its label when target is not an
+// http or https URL
+func newLink(target string, label []inline) inline {
+	u, err := url.Parse(html.UnescapeString(strings.TrimSpace(target)))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return inline{kind: span, children: label}
+	}
+	return inline{kind: link, href: u.String(), children: label}
+}
+
+// hrefOf returns the href attribute of an anchor tag's attributes
+func hrefOf(attrs string) string {
+	m := hrefPattern.FindStringSubmatch(attrs)
+	if m == nil {
+		return ""
+	}
+	return m[1] + m[2] + m[3]
+}
+
+// stripTags removes every HTML tag from s
+func stripTags(s string) string {
+	return anyTagPattern.ReplaceAllString(s, "")
+}
+
+// writeInlineHTML writes inline content as HTML
+func writeInlineHTML(w *strings.Builder, nodes []inline) {
+	for _, n := range nodes {
+		switch n.kind {
+		case span:
+			writeInlineHTML(w, n.children)
+		case emphasis:
+			w.WriteString("<em>")
+			writeInlineHTML(w, n.children)
+			w.WriteString("</em>")
+		case strong:
+			w.WriteString("<strong>")
+			writeInlineHTML(w, n.children)
+			w.WriteString("</strong>")
+		case code:
+			w.WriteString("<code>" + html.EscapeString(n.text) + "</code>")
+		case link:
+			w.WriteString(`<a href="` + html.EscapeString(n.href) + `" rel="nofollow noopener noreferrer">`)
+			writeInlineHTML(w, n.children)
+			w.WriteString("</a>")
+		default:
+			w.WriteString(html.EscapeString(n.text))
+		}
+	}
+}
+
+// writeInlineText writes inline content as plain text
+func writeInlineText(w *strings.Builder, nodes []inline) {
+	for _, n := range nodes {
+		if n.kind == text || n.kind == code {
+			w.WriteString(n.text)
+			continue
+		}
+		writeInlineText(w, n.children)
+	}
+}
+
+// isASCIIPunct reports whether c can be backslash-escaped in Markdown
+func isASCIIPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+// isWordRune reports whether r is part of a word
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
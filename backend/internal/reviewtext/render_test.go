@@ -0,0 +1,133 @@
+package reviewtext
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		html    string
+		text    string
+	}{
+		{
+			name:    "paragraphs and line breaks",
+			content: "First line\r\nsecond line\r\n\r\nNext paragraph",
+			html:    "<p>First line<br>second line</p><p>Next paragraph</p>",
+			text:    "First line\nsecond line\n\nNext paragraph",
+		},
+		{
+			name:    "emphasis",
+			content: "A **great** film, _truly_ *moving*, ***unmissable***",
+			html:    "<p>A <strong>great</strong> film, <em>truly</em> <em>moving</em>, <strong><em>unmissable</em></strong></p>",
+			text:    "A great film, truly moving, unmissable",
+		},
+		{
+			name:    "unmatched and intraword delimiters stay text",
+			content: `5 * 3 = 15, snake_case_name and \*escaped\*`,
+			html:    "<p>5 * 3 = 15, snake_case_name and *escaped*</p>",
+			text:    "5 * 3 = 15, snake_case_name and *escaped*",
+		},
+		{
+			name:    "headings, lists, quotes and rules",
+			content: "## Verdict\n- Plot\n- Cast\n\n1. First\n2. Second\n\n> Quoted\n> text\n\n---",
+			html:    "<h4>Verdict</h4><ul><li>Plot</li><li>Cast</li></ul><ol><li>First</li><li>Second</li></ol><blockquote><p>Quoted<br>text</p></blockquote><hr>",
+			text:    "Verdict\n\n- Plot\n- Cast\n\n1. First\n2. Second\n\nQuoted\ntext\n\n---",
+		},
+		{
+			name:    "links and code",
+			content: "See [my blog](https://example.com/a?b=1&c=2 \"title\") and `x < y`",
+			html:    `<p>See <a href="https://example.com/a?b=1&amp;c=2" rel="nofollow noopener noreferrer">my blog</a> and <code>x &lt; y</code></p>`,
+			text:    "See my blog and x < y",
+		},
+		{
+			name:    "stray HTML",
+			content: "<p>It&#39;s <em>brilliant</em>.<br />Really <b>bold</b> <span>and</span> <a href='http://example.com'>linked</a>.</p>",
+			html:    `<p>It&#39;s <em>brilliant</em>.<br>Really <strong>bold</strong> and <a href="http://example.com" rel="nofollow noopener noreferrer">linked</a>.</p>`,
+			text:    "It's brilliant.\nReally bold and linked.",
+		},
+		{
+			name:    "unsafe content",
+			content: `<script>alert(1)</script><img src=x onerror=alert(1)>[click](javascript:alert(1)) <a href="javascript:alert(1)">me</a> &lt;script&gt; "quoted" I <3 it`,
+			html:    "<p>click me &lt;script&gt; &#34;quoted&#34; I &lt;3 it</p>",
+			text:    `click me <script> "quoted" I <3 it`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Render(tt.content)
+			if got.HTML != tt.html {
+				t.Errorf("Expected HTML %q, got %q", tt.html, got.HTML)
+			}
+			if got.Text != tt.text {
+				t.Errorf("Expected text %q, got %q", tt.text, got.Text)
+			}
+		})
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	if got := Excerpt("Short  and\n\nsweet", 200); got != "Short and sweet" {
+		t.Errorf("Expected whitespace to be collapsed, got %q", got)
+	}
+
+	long := strings.Repeat("word ", 100)
+	got := Excerpt(long, ExcerptLength)
+	if n := utf8.RuneCountInString(got); n > ExcerptLength {
+		t.Errorf("Expected at most %d characters, got %d", ExcerptLength, n)
+	}
+	if !strings.HasSuffix(got, "word…") {
+		t.Errorf("Expected a cut at a word boundary with an ellipsis, got %q", got)
+	}
+
+	japanese := strings.Repeat("素晴らしい映画でした。", 30)
+	if got := Excerpt(japanese, 20); utf8.RuneCountInString(got) != 20 || !strings.HasSuffix(got, "…") {
+		t.Errorf("Expected a 20 character excerpt of unspaced text, got %q", got)
+	}
+}
+
+// pathologicalContent returns review content that once took quadratic time to
+// render: unclosed and deeply nested tags and emphasis
+func pathologicalContent() map[string]string {
+	return map[string]string{
+		"unclosed tags":   strings.Repeat("<em>", 5000),
+		"nested tags":     strings.Repeat("<em>", 2500) + "x" + strings.Repeat("</em>", 2500),
+		"mixed case tags": strings.Repeat("<EM>a", 4000),
+		"emphasis":        strings.Repeat("*a ", 7000),
+		"oversized":       strings.Repeat("<b>word</b> ", 100000),
+	}
+}
+
+func TestRender_PathologicalInput(t *testing.T) {
+	for name, content := range pathologicalContent() {
+		t.Run(name, func(t *testing.T) {
+			start := time.Now()
+			Render(content)
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("Expected rendering within a second, took %v", elapsed)
+			}
+		})
+	}
+}
+
+func TestRender_MaxContentLength(t *testing.T) {
+	got := Render(strings.Repeat("映", MaxContentLength))
+	if n := len(got.Text); n > MaxContentLength || !utf8.ValidString(got.Text) {
+		t.Errorf("Expected at most %d bytes of valid text, got %d", MaxContentLength, n)
+	}
+}
+
+func BenchmarkRender_PathologicalInput(b *testing.B) {
+	for name, content := range pathologicalContent() {
+		b.Run(name, func(b *testing.B) {
+			for b.Loop() {
+				Render(content)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+// Package services provides a TMDb client that caches fetched titles in the database.
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+	"github.com/takeshi-arihori/movie-api/internal/store"
+)
+
+// titleWriteTimeout bounds each background write to or refresh of the title cache
+const titleWriteTimeout = 10 * time.Second
+
+// TitleStore persists titles fetched from TMDb
+type TitleStore interface {
+	UpsertMovies(ctx context.Context, movies []models.Movie) error
+	UpsertTVShows(ctx context.Context, shows []models.TVShow) error
+	UpsertMovieDetails(ctx context.Context, details *models.MovieDetails) error
+	GetMovieDetails(ctx context.Context, id int) (*store.CachedMovieDetails, error)
+	UpsertTVShowDetails(ctx context.Context, details *models.TVShowDetails) error
+	GetTVShowDetails(ctx context.Context, id int) (*store.CachedTVShowDetails, error)
+}
+
+// CachingClient is a TMDbClient that writes fetched movies and TV shows through
+// to the database and serves details from it. Search results, lists and person
+// credits are written through too, but always fetched from TMDb. Details older than the staleness
+// window are still served, but refreshed from TMDb in the background.
+type CachingClient struct {
+	*TMDbClient
+	titles     TitleStore
+	staleAfter time.Duration
+	now        func() time.Time
+
+	mu         sync.Mutex
+	refreshing map[string]bool // Keys of details being refreshed
+	pending    sync.WaitGroup
+}
+
+// NewCachingClient wraps client so fetched titles are stored in titles. It
+// registers itself as client's TitleObserver, so titles client fetches in
+// search results, lists and person credits are stored even when callers use
+// client directly.
+func NewCachingClient(client *TMDbClient, titles TitleStore, staleAfter time.Duration) *CachingClient {
+	c := &CachingClient{
+		TMDbClient: client,
+		titles:     titles,
+		staleAfter: staleAfter,
+		now:        time.Now,
+		refreshing: make(map[string]bool),
+	}
+	client.SetTitleObserver(c)
+	return c
+}
+
+// GetMovieDetails returns a movie's details from the database, falling back to TMDb
+func (c *CachingClient) GetMovieDetails(ctx context.Context, movieID int) (*models.MovieDetails, error) {
+	cached, err := c.titles.GetMovieDetails(ctx, movieID)
+	if err == nil {
+		if c.isStale(cached.CachedAt) {
+			c.refresh("movie:"+strconv.Itoa(movieID), func(ctx context.Context) error {
+				details, err := c.TMDbClient.GetMovieDetails(ctx, movieID)
+				if err != nil {
+					return err
+				}
+				return c.titles.UpsertMovieDetails(ctx, details)
+			})
+		}
+		return &cached.Details, nil
+	}
+	if !errors.Is(err, store.ErrNotFound) {
+		log.Printf("Title cache read failed for movie %d: %v", movieID, err)
+	}
+
+	details, err := c.TMDbClient.GetMovieDetails(ctx, movieID)
+	if err != nil {
+		return nil, err
+	}
+	c.write("movie details", func(ctx context.Context) error {
+		return c.titles.UpsertMovieDetails(ctx, details)
+	})
+	return details, nil
+}
+
+// GetTVShowDetails returns a TV show's details from the database, falling back to TMDb
+func (c *CachingClient) GetTVShowDetails(ctx context.Context, tvID int) (*models.TVShowDetails, error) {
+	cached, err := c.titles.GetTVShowDetails(ctx, tvID)
+	if err == nil {
+		if c.isStale(cached.CachedAt) {
+			c.refresh("tv:"+strconv.Itoa(tvID), func(ctx context.Context) error {
+				details, err := c.TMDbClient.GetTVShowDetails(ctx, tvID)
+				if err != nil {
+					return err
+				}
+				return c.titles.UpsertTVShowDetails(ctx, details)
+			})
+		}
+		return &cached.Details, nil
+	}
+	if !errors.Is(err, store.ErrNotFound) {
+		log.Printf("Title cache read failed for TV show %d: %v", tvID, err)
+	}
+
+	details, err := c.TMDbClient.GetTVShowDetails(ctx, tvID)
+	if err != nil {
+		return nil, err
+	}
+	c.write("TV show details", func(ctx context.Context) error {
+		return c.titles.UpsertTVShowDetails(ctx, details)
+	})
+	return details, nil
+}
+
+// ObserveMovies stores movies the wrapped client fetched in search results,
+// lists or person credits
+func (c *CachingClient) ObserveMovies(movies []models.Movie) {
+	c.write("movies", func(ctx context.Context) error {
+		return c.titles.UpsertMovies(ctx, movies)
+	})
+}
+
+// ObserveTVShows stores TV shows the wrapped client fetched in search results,
+// lists or person credits
+func (c *CachingClient) ObserveTVShows(shows []models.TVShow) {
+	c.write("TV shows", func(ctx context.Context) error {
+		return c.titles.UpsertTVShows(ctx, shows)
+	})
+}
+
+// Wait blocks until background writes and refreshes have finished
+func (c *CachingClient) Wait() {
+	c.pending.Wait()
+}
+
+// isStale reports whether details cached at cachedAt should be refreshed
+func (c *CachingClient) isStale(cachedAt time.Time) bool {
+	return c.now().Sub(cachedAt) >= c.staleAfter
+}
+
+// write runs fn in the background, detached from the request so that storing
+// titles never delays or fails a response
+func (c *CachingClient) write(what string, fn func(ctx context.Context) error) {
+	c.pending.Add(1)
+	go func() {
+		defer c.pending.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), titleWriteTimeout)
+		defer cancel()
+		if err := fn(ctx); err != nil {
+			log.Printf("Title cache write failed for %s: %v", what, err)
+		}
+	}()
+}
+
+// refresh runs fn in the background unless a refresh for key is already running
+func (c *CachingClient) refresh(key string, fn func(ctx context.Context) error) {
+	c.mu.Lock()
+	if c.refreshing[key] {
+		c.mu.Unlock()
+		return
+	}
+	c.refreshing[key] = true
+	c.mu.Unlock()
+
+	c.write(key, func(ctx context.Context) error {
+		defer func() {
+			c.mu.Lock()
+			delete(c.refreshing, key)
+			c.mu.Unlock()
+		}()
+		return fn(ctx)
+	})
+}
@@ -0,0 +1,225 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+	"github.com/takeshi-arihori/movie-api/internal/store"
+)
+
+// fakeTitleStore is an in-memory TitleStore
+type fakeTitleStore struct {
+	mu           sync.Mutex
+	movies       map[int]models.Movie
+	movieDetails map[int]store.CachedMovieDetails
+	tvShows      map[int]models.TVShow
+}
+
+func newFakeTitleStore() *fakeTitleStore {
+	return &fakeTitleStore{
+		movies:       make(map[int]models.Movie),
+		movieDetails: make(map[int]store.CachedMovieDetails),
+		tvShows:      make(map[int]models.TVShow),
+	}
+}
+
+func (s *fakeTitleStore) UpsertMovies(ctx context.Context, movies []models.Movie) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, m := range movies {
+		s.movies[m.ID] = m
+	}
+	return nil
+}
+
+func (s *fakeTitleStore) UpsertTVShows(ctx context.Context, shows []models.TVShow) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, show := range shows {
+		s.tvShows[show.ID] = show
+	}
+	return nil
+}
+
+func (s *fakeTitleStore) UpsertMovieDetails(ctx context.Context, details *models.MovieDetails) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.movieDetails[details.ID] = store.CachedMovieDetails{Details: *details, CachedAt: time.Now()}
+	return nil
+}
+
+func (s *fakeTitleStore) GetMovieDetails(ctx context.Context, id int) (*store.CachedMovieDetails, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cached, ok := s.movieDetails[id]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return &cached, nil
+}
+
+func (s *fakeTitleStore) UpsertTVShowDetails(ctx context.Context, details *models.TVShowDetails) error {
+	return nil
+}
+
+func (s *fakeTitleStore) GetTVShowDetails(ctx context.Context, id int) (*store.CachedTVShowDetails, error) {
+	return nil, store.ErrNotFound
+}
+
+// TestCachingClientMovieDetails tests that details are served from the store
+// once fetched, and refreshed in the background when stale
+func TestCachingClientMovieDetails(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(models.MovieDetails{ID: 550, Title: "Fight Club"})
+	}))
+	defer server.Close()
+
+	titles := newFakeTitleStore()
+	client := NewCachingClient(createTestClient(server.URL), titles, time.Hour)
+
+	// Miss: fetched from TMDb and stored
+	details, err := client.GetMovieDetails(context.Background(), 550)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if details.Title != "Fight Club" {
+		t.Errorf("Expected title 'Fight Club', got '%s'", details.Title)
+	}
+	client.Wait()
+	if _, err := titles.GetMovieDetails(context.Background(), 550); err != nil {
+		t.Fatalf("Expected details to be stored, got %v", err)
+	}
+
+	// Fresh hit: served without TMDb
+	if _, err := client.GetMovieDetails(context.Background(), 550); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	client.Wait()
+	if got := requests.Load(); got != 1 {
+		t.Errorf("Expected 1 TMDb request after a fresh hit, got %d", got)
+	}
+
+	// Stale hit: served from the store and refreshed once in the background
+	client.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	details, err = client.GetMovieDetails(context.Background(), 550)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if details.Title != "Fight Club" {
+		t.Errorf("Expected stale title 'Fight Club', got '%s'", details.Title)
+	}
+	client.Wait()
+	if got := requests.Load(); got != 2 {
+		t.Errorf("Expected 2 TMDb requests after a stale hit, got %d", got)
+	}
+}
+
+// TestCachingClientLists tests that list results are written through to the store
+func TestCachingClientLists(t *testing.T) {
+	server := createMockServer(t, map[string]interface{}{
+		"/movie/popular": models.PopularMovies{
+			Page:    1,
+			Results: []models.Movie{{ID: 550, Title: "Fight Club"}, {ID: 680, Title: "Pulp Fiction"}},
+		},
+		"/trending/tv/week": models.SearchResponse[models.TVShow]{
+			Page:    1,
+			Results: []models.TVShow{{ID: 1399, Name: "Game of Thrones"}},
+		},
+	})
+	defer server.Close()
+
+	titles := newFakeTitleStore()
+	client := NewCachingClient(createTestClient(server.URL), titles, time.Hour)
+
+	if _, err := client.GetPopularMovies(context.Background(), 1); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := client.GetTrendingTVShows(context.Background(), "week", 1); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	client.Wait()
+
+	if len(titles.movies) != 2 {
+		t.Errorf("Expected 2 stored movies, got %d", len(titles.movies))
+	}
+	if _, ok := titles.tvShows[1399]; !ok {
+		t.Error("Expected trending TV show to be stored")
+	}
+}
+
+// TestCachingClientSearchAndCredits tests that titles in search results and
+// person credits are stored, even when fetched through the wrapped client
+func TestCachingClientSearchAndCredits(t *testing.T) {
+	movie, tv := models.SearchItemTypeMovie, models.SearchItemTypeTV
+	title, name := "Inception", "Breaking Bad"
+	mediaMovie := "movie"
+	server := createMockServer(t, map[string]interface{}{
+		"/search/movie": models.MovieSearchResponse{
+			Page:    1,
+			Results: []models.Movie{{ID: 550, Title: "Fight Club"}},
+		},
+		"/search/multi": models.MultiSearchResponse{
+			Page: 1,
+			Results: []models.MultiSearchResult{
+				{ID: 27205, MediaType: movie, Title: &title},
+				{ID: 1396, MediaType: tv, Name: &name},
+				{ID: 287, MediaType: models.SearchItemTypePerson},
+			},
+		},
+		"/person/287/combined_credits": models.PersonCombinedCredits{
+			ID:   287,
+			Cast: []models.PersonCombinedCast{{ID: 297, MediaType: mediaMovie, Title: &title}},
+		},
+		"/person/287/tv_credits": models.PersonTVCredits{
+			ID:   287,
+			Crew: []models.PersonTVCrew{{ID: 1399, Name: "Game of Thrones"}},
+		},
+	})
+	defer server.Close()
+
+	tmdb := createTestClient(server.URL)
+	titles := newFakeTitleStore()
+	client := NewCachingClient(tmdb, titles, time.Hour)
+
+	ctx := context.Background()
+	if _, err := tmdb.SearchMovies(ctx, models.SearchOptions{Query: "fight"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := tmdb.MultiSearch(ctx, models.SearchOptions{Query: "inception"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := tmdb.GetPersonCombinedCredits(ctx, 287); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := tmdb.GetPersonTVCredits(ctx, 287); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	client.Wait()
+
+	for _, id := range []int{550, 27205, 297} {
+		if _, ok := titles.movies[id]; !ok {
+			t.Errorf("Expected movie %d to be stored", id)
+		}
+	}
+	for _, id := range []int{1396, 1399} {
+		if _, ok := titles.tvShows[id]; !ok {
+			t.Errorf("Expected TV show %d to be stored", id)
+		}
+	}
+	if len(titles.movies) != 3 || len(titles.tvShows) != 2 {
+		t.Errorf("Expected 3 movies and 2 TV shows stored, got %d and %d", len(titles.movies), len(titles.tvShows))
+	}
+	if got := titles.movies[297].Title; got != title {
+		t.Errorf("Expected combined credit title %q, got %q", title, got)
+	}
+}
@@ -0,0 +1,91 @@
+// Package services provides faceted search across every TMDb result type.
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+)
+
+// FacetedSearchTimeout is the shared deadline for all per-type searches in a faceted search
+const FacetedSearchTimeout = 5 * time.Second
+
+// facetTypes lists the media types searched by FacetedSearch, in response order
+var facetTypes = []models.SearchItemType{
+	models.SearchItemTypeMovie,
+	models.SearchItemTypeTV,
+	models.SearchItemTypePerson,
+}
+
+// FacetedSearch runs the movie, TV and person searches concurrently under a shared
+// deadline and returns per-type totals with the top perType results of each.
+// A failing type is reported in its facet's Error field with a generic message, the
+// cause being logged; an error is returned only when every type fails.
+func (c *TMDbClient) FacetedSearch(ctx context.Context, opts models.SearchOptions, perType int) (*models.FacetedSearchResponse, error) {
+	opts.Type = "all"
+	if err := opts.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid search options: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, FacetedSearchTimeout)
+	defer cancel()
+
+	facets := make([]models.SearchFacet, len(facetTypes))
+	errs := make([]error, len(facetTypes))
+	var wg sync.WaitGroup
+	for i, itemType := range facetTypes {
+		wg.Add(1)
+		go func(i int, itemType models.SearchItemType) {
+			defer wg.Done()
+
+			typeOpts := opts
+			typeOpts.Type = string(itemType)
+			facet := models.SearchFacet{Type: itemType, Results: []models.MultiSearchResult{}}
+
+			result, err := c.SearchByType(ctx, typeOpts)
+			if err != nil {
+				log.Printf("Faceted search: %s search failed: %v", itemType, err)
+				errs[i] = err
+				facet.Error = fmt.Sprintf("%s search failed", itemType)
+				facets[i] = facet
+				return
+			}
+
+			facet.TotalResults = result.TotalResults
+			facet.TotalPages = result.TotalPages
+			facet.Results = result.Results
+			if perType > 0 && len(facet.Results) > perType {
+				facet.Results = facet.Results[:perType]
+			}
+			facets[i] = facet
+		}(i, itemType)
+	}
+	wg.Wait()
+
+	response := &models.FacetedSearchResponse{
+		Query:    opts.Query,
+		Type:     opts.Type,
+		Language: opts.Language,
+		PerType:  perType,
+		Facets:   facets,
+	}
+
+	failed := 0
+	for _, facet := range facets {
+		if facet.Error != "" {
+			failed++
+			response.Partial = true
+			continue
+		}
+		response.TotalResults += facet.TotalResults
+	}
+	if failed == len(facets) {
+		return nil, fmt.Errorf("faceted search failed for all types: %w", errs[0])
+	}
+
+	return response, nil
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+)
+
+// TestFacetedSearch tests concurrent per-type search with a failing facet
+func TestFacetedSearch(t *testing.T) {
+	movies := make([]models.Movie, 8)
+	for i := range movies {
+		movies[i] = models.Movie{ID: i + 1, Title: "Movie", OriginalTitle: "Movie", OriginalLanguage: "en"}
+	}
+
+	responses := map[string]interface{}{
+		"/search/movie": models.MovieSearchResponse{
+			Page:         1,
+			Results:      movies,
+			TotalPages:   3,
+			TotalResults: 48,
+		},
+		"/search/tv": models.TVSearchResponse{
+			Page:         1,
+			Results:      []models.TVShow{{ID: 1396, Name: "Breaking Bad", OriginalName: "Breaking Bad", OriginalLanguage: "en"}},
+			TotalPages:   1,
+			TotalResults: 1,
+		},
+		// "/search/person" is missing so the mock server returns 404
+	}
+
+	server := createMockServer(t, responses)
+	defer server.Close()
+
+	client := createTestClient(server.URL)
+
+	result, err := client.FacetedSearch(context.Background(), models.SearchOptions{Query: "test"}, 5)
+	if err != nil {
+		t.Fatalf("FacetedSearch failed: %v", err)
+	}
+
+	if len(result.Facets) != 3 {
+		t.Fatalf("Expected 3 facets, got %d", len(result.Facets))
+	}
+	if !result.Partial {
+		t.Error("Expected partial result when one facet fails")
+	}
+	if result.TotalResults != 49 {
+		t.Errorf("Expected total results 49, got %d", result.TotalResults)
+	}
+
+	movieFacet := result.Facets[0]
+	if movieFacet.Type != models.SearchItemTypeMovie {
+		t.Errorf("Expected first facet to be movie, got %s", movieFacet.Type)
+	}
+	if movieFacet.TotalResults != 48 {
+		t.Errorf("Expected movie total 48, got %d", movieFacet.TotalResults)
+	}
+	if len(movieFacet.Results) != 5 {
+		t.Errorf("Expected 5 movie results, got %d", len(movieFacet.Results))
+	}
+
+	if len(result.Facets[1].Results) != 1 {
+		t.Errorf("Expected 1 TV result, got %d", len(result.Facets[1].Results))
+	}
+
+	personFacet := result.Facets[2]
+	if personFacet.Error == "" {
+		t.Error("Expected person facet to report an error")
+	}
+	if personFacet.Results == nil {
+		t.Error("Expected empty (non-nil) results for failed facet")
+	}
+
+	// Type-specific filters are not valid for a faceted search
+	_, err = client.FacetedSearch(context.Background(), models.SearchOptions{
+		Query:  "test",
+		Filter: models.SearchFilter{Year: 1999},
+	}, 5)
+	if err == nil {
+		t.Error("Expected error for year filter in faceted search, got nil")
+	}
+}
+
+// TestFacetedSearchAllFail tests that an error is returned when every facet fails
+func TestFacetedSearchAllFail(t *testing.T) {
+	server := createMockServer(t, map[string]interface{}{})
+	defer server.Close()
+
+	client := createTestClient(server.URL)
+
+	if _, err := client.FacetedSearch(context.Background(), models.SearchOptions{Query: "test"}, 5); err == nil {
+		t.Error("Expected error when all facets fail, got nil")
+	}
+}
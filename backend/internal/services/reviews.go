@@ -0,0 +1,49 @@
+// Package services provides helpers for fetching every page of a title's TMDb reviews.
+package services
+
+import (
+	"context"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+	"github.com/takeshi-arihori/movie-api/internal/pagination"
+)
+
+const (
+	// MaxReviewPages caps the TMDb review pages AllReviews fetches for one title
+	MaxReviewPages = 25
+
+	// reviewPageConcurrency bounds the review pages fetched at once
+	reviewPageConcurrency = 4
+)
+
+// ReviewSource lists the TMDb reviews of movies and TV shows, such as TMDbClient
+type ReviewSource interface {
+	GetMovieReviews(ctx context.Context, movieID int, page int) (*models.MovieReviews, error)
+	GetTVShowReviews(ctx context.Context, tvID int, page int) (*models.TVReviews, error)
+}
+
+// AllReviews returns the TMDb reviews of a movie or TV show from up to
+// MaxReviewPages pages, fetched concurrently. The returned page's totals are
+// TMDb's, so TotalPages exceeds MaxReviewPages when reviews were left out.
+func AllReviews(ctx context.Context, source ReviewSource, mediaType models.SearchItemType, mediaID int) (*pagination.Page[models.Review], error) {
+	return pagination.FetchAll(ctx, ReviewPages(source, mediaType, mediaID), MaxReviewPages, reviewPageConcurrency)
+}
+
+// ReviewPages returns a pagination.PageFunc over a title's TMDb reviews
+func ReviewPages(source ReviewSource, mediaType models.SearchItemType, mediaID int) pagination.PageFunc[models.Review] {
+	return func(ctx context.Context, page int) (*pagination.Page[models.Review], error) {
+		if mediaType == models.SearchItemTypeTV {
+			reviews, err := source.GetTVShowReviews(ctx, mediaID, page)
+			if err != nil {
+				return nil, err
+			}
+			return &pagination.Page[models.Review]{Results: reviews.Results, TotalPages: reviews.TotalPages, TotalResults: reviews.TotalResults}, nil
+		}
+
+		reviews, err := source.GetMovieReviews(ctx, mediaID, page)
+		if err != nil {
+			return nil, err
+		}
+		return &pagination.Page[models.Review]{Results: reviews.Results, TotalPages: reviews.TotalPages, TotalResults: reviews.TotalResults}, nil
+	}
+}
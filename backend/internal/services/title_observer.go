@@ -0,0 +1,197 @@
+// Package services provides hooks for observing the titles a TMDb client fetches.
+package services
+
+import (
+	"github.com/takeshi-arihori/movie-api/internal/models"
+)
+
+// TitleObserver is told about the movies and TV shows that a TMDbClient fetches
+// in search results, lists and person credits
+type TitleObserver interface {
+	ObserveMovies(movies []models.Movie)
+	ObserveTVShows(shows []models.TVShow)
+}
+
+// SetTitleObserver registers observer to be told about fetched titles. It must
+// be called before the client is used.
+func (c *TMDbClient) SetTitleObserver(observer TitleObserver) {
+	c.observer = observer
+}
+
+// observeMovies passes movies to the observer, if any
+func (c *TMDbClient) observeMovies(movies []models.Movie) {
+	if c.observer != nil && len(movies) > 0 {
+		c.observer.ObserveMovies(movies)
+	}
+}
+
+// observeTVShows passes shows to the observer, if any
+func (c *TMDbClient) observeTVShows(shows []models.TVShow) {
+	if c.observer != nil && len(shows) > 0 {
+		c.observer.ObserveTVShows(shows)
+	}
+}
+
+// observeSearchResults passes the movies and TV shows among results to the observer
+func (c *TMDbClient) observeSearchResults(results []models.MultiSearchResult) {
+	if c.observer == nil {
+		return
+	}
+	var movies []models.Movie
+	var shows []models.TVShow
+	for i := range results {
+		if movie := results[i].ToMovie(); movie != nil {
+			movies = append(movies, *movie)
+		} else if show := results[i].ToTVShow(); show != nil {
+			shows = append(shows, *show)
+		}
+	}
+	c.observeMovies(movies)
+	c.observeTVShows(shows)
+}
+
+// observeMovieCredits passes the movies of a person's movie credits to the observer
+func (c *TMDbClient) observeMovieCredits(credits *models.PersonMovieCredits) {
+	if c.observer == nil {
+		return
+	}
+	movies := make([]models.Movie, 0, len(credits.Cast)+len(credits.Crew))
+	for _, cast := range credits.Cast {
+		movies = append(movies, models.Movie{
+			Adult:            cast.Adult,
+			BackdropPath:     cast.BackdropPath,
+			GenreIDs:         cast.GenreIDs,
+			ID:               cast.ID,
+			OriginalLanguage: cast.OriginalLanguage,
+			OriginalTitle:    cast.OriginalTitle,
+			Overview:         cast.Overview,
+			Popularity:       cast.Popularity,
+			PosterPath:       cast.PosterPath,
+			ReleaseDate:      cast.ReleaseDate,
+			Title:            cast.Title,
+			Video:            cast.Video,
+			VoteAverage:      cast.VoteAverage,
+			VoteCount:        cast.VoteCount,
+		})
+	}
+	for _, crew := range credits.Crew {
+		movies = append(movies, models.Movie{
+			Adult:            crew.Adult,
+			BackdropPath:     crew.BackdropPath,
+			GenreIDs:         crew.GenreIDs,
+			ID:               crew.ID,
+			OriginalLanguage: crew.OriginalLanguage,
+			OriginalTitle:    crew.OriginalTitle,
+			Overview:         crew.Overview,
+			Popularity:       crew.Popularity,
+			PosterPath:       crew.PosterPath,
+			ReleaseDate:      crew.ReleaseDate,
+			Title:            crew.Title,
+			Video:            crew.Video,
+			VoteAverage:      crew.VoteAverage,
+			VoteCount:        crew.VoteCount,
+		})
+	}
+	c.observeMovies(movies)
+}
+
+// observeTVCredits passes the TV shows of a person's TV credits to the observer
+func (c *TMDbClient) observeTVCredits(credits *models.PersonTVCredits) {
+	if c.observer == nil {
+		return
+	}
+	shows := make([]models.TVShow, 0, len(credits.Cast)+len(credits.Crew))
+	for _, cast := range credits.Cast {
+		shows = append(shows, models.TVShow{
+			Adult:            cast.Adult,
+			BackdropPath:     cast.BackdropPath,
+			GenreIDs:         cast.GenreIDs,
+			ID:               cast.ID,
+			OriginCountry:    cast.OriginCountry,
+			OriginalLanguage: cast.OriginalLanguage,
+			OriginalName:     cast.OriginalName,
+			Overview:         cast.Overview,
+			Popularity:       cast.Popularity,
+			PosterPath:       cast.PosterPath,
+			FirstAirDate:     cast.FirstAirDate,
+			Name:             cast.Name,
+			VoteAverage:      cast.VoteAverage,
+			VoteCount:        cast.VoteCount,
+		})
+	}
+	for _, crew := range credits.Crew {
+		shows = append(shows, models.TVShow{
+			Adult:            crew.Adult,
+			BackdropPath:     crew.BackdropPath,
+			GenreIDs:         crew.GenreIDs,
+			ID:               crew.ID,
+			OriginCountry:    crew.OriginCountry,
+			OriginalLanguage: crew.OriginalLanguage,
+			OriginalName:     crew.OriginalName,
+			Overview:         crew.Overview,
+			Popularity:       crew.Popularity,
+			PosterPath:       crew.PosterPath,
+			FirstAirDate:     crew.FirstAirDate,
+			Name:             crew.Name,
+			VoteAverage:      crew.VoteAverage,
+			VoteCount:        crew.VoteCount,
+		})
+	}
+	c.observeTVShows(shows)
+}
+
+// observeCombinedCredits passes the movies and TV shows of a person's combined
+// credits to the observer
+func (c *TMDbClient) observeCombinedCredits(credits *models.PersonCombinedCredits) {
+	if c.observer == nil {
+		return
+	}
+	results := make([]models.MultiSearchResult, 0, len(credits.Cast)+len(credits.Crew))
+	for _, cast := range credits.Cast {
+		results = append(results, models.MultiSearchResult{
+			ID:               cast.ID,
+			MediaType:        models.SearchItemType(cast.MediaType),
+			Popularity:       cast.Popularity,
+			Adult:            cast.Adult,
+			BackdropPath:     cast.BackdropPath,
+			GenreIDs:         cast.GenreIDs,
+			OriginalLanguage: &cast.OriginalLanguage,
+			Overview:         &cast.Overview,
+			PosterPath:       cast.PosterPath,
+			VoteAverage:      &cast.VoteAverage,
+			VoteCount:        &cast.VoteCount,
+			OriginalTitle:    cast.OriginalTitle,
+			ReleaseDate:      cast.ReleaseDate,
+			Title:            cast.Title,
+			Video:            cast.Video,
+			FirstAirDate:     cast.FirstAirDate,
+			Name:             cast.Name,
+			OriginalName:     cast.OriginalName,
+			OriginCountry:    cast.OriginCountry,
+		})
+	}
+	for _, crew := range credits.Crew {
+		results = append(results, models.MultiSearchResult{
+			ID:               crew.ID,
+			MediaType:        models.SearchItemType(crew.MediaType),
+			Popularity:       crew.Popularity,
+			Adult:            crew.Adult,
+			BackdropPath:     crew.BackdropPath,
+			GenreIDs:         crew.GenreIDs,
+			OriginalLanguage: &crew.OriginalLanguage,
+			Overview:         &crew.Overview,
+			PosterPath:       crew.PosterPath,
+			VoteAverage:      &crew.VoteAverage,
+			VoteCount:        &crew.VoteCount,
+			OriginalTitle:    crew.OriginalTitle,
+			ReleaseDate:      crew.ReleaseDate,
+			Title:            crew.Title,
+			Video:            crew.Video,
+			FirstAirDate:     crew.FirstAirDate,
+			Name:             crew.Name,
+			OriginalName:     crew.OriginalName,
+			OriginCountry:    crew.OriginCountry,
+		})
+	}
+	c.observeSearchResults(results)
+}
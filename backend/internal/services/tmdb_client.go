@@ -20,6 +20,7 @@ type TMDbClient struct {
 	apiKey     string
 	baseURL    string
 	httpClient *http.Client
+	observer   TitleObserver // Told about fetched titles, if set
 }
 
 // TMDbError represents an error response from TMDb API
@@ -78,6 +79,10 @@ func (c *TMDbClient) makeRequest(ctx context.Context, endpoint string, params ur
 	// Execute request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
+		// Transport errors quote the request URL, which carries the API key
+		if urlErr, ok := err.(*url.Error); ok {
+			urlErr.URL = c.baseURL + endpoint
+		}
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
 
@@ -110,21 +115,15 @@ func (c *TMDbClient) handleResponse(resp *http.Response, target interface{}) err
 	return nil
 }
 
-// SearchMovies searches for movies by query string
-func (c *TMDbClient) SearchMovies(ctx context.Context, query string, page int) (*models.MovieSearchResponse, error) {
-	if query == "" {
-		return nil, fmt.Errorf("search query cannot be empty")
+// SearchMovies searches for movies with the query, page, language and filters
+// of opts. opts.Type is ignored.
+func (c *TMDbClient) SearchMovies(ctx context.Context, opts models.SearchOptions) (*models.MovieSearchResponse, error) {
+	opts.Type = "movie"
+	if err := opts.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid search options: %w", err)
 	}
 
-	params := url.Values{
-		"query": {query},
-	}
-
-	if page > 0 {
-		params.Set("page", strconv.Itoa(page))
-	}
-
-	resp, err := c.makeRequest(ctx, "/search/movie", params)
+	resp, err := c.makeRequest(ctx, "/search/movie", searchParams(opts))
 	if err != nil {
 		return nil, fmt.Errorf("search movies request failed: %w", err)
 	}
@@ -133,25 +132,20 @@ func (c *TMDbClient) SearchMovies(ctx context.Context, query string, page int) (
 	if err := c.handleResponse(resp, &result); err != nil {
 		return nil, fmt.Errorf("search movies response handling failed: %w", err)
 	}
+	c.observeMovies(result.Results)
 
 	return &result, nil
 }
 
-// SearchTVShows searches for TV shows by query string
-func (c *TMDbClient) SearchTVShows(ctx context.Context, query string, page int) (*models.TVSearchResponse, error) {
-	if query == "" {
-		return nil, fmt.Errorf("search query cannot be empty")
+// SearchTVShows searches for TV shows with the query, page, language and filters
+// of opts. opts.Type is ignored.
+func (c *TMDbClient) SearchTVShows(ctx context.Context, opts models.SearchOptions) (*models.TVSearchResponse, error) {
+	opts.Type = "tv"
+	if err := opts.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid search options: %w", err)
 	}
 
-	params := url.Values{
-		"query": {query},
-	}
-
-	if page > 0 {
-		params.Set("page", strconv.Itoa(page))
-	}
-
-	resp, err := c.makeRequest(ctx, "/search/tv", params)
+	resp, err := c.makeRequest(ctx, "/search/tv", searchParams(opts))
 	if err != nil {
 		return nil, fmt.Errorf("search TV shows request failed: %w", err)
 	}
@@ -160,6 +154,7 @@ func (c *TMDbClient) SearchTVShows(ctx context.Context, query string, page int)
 	if err := c.handleResponse(resp, &result); err != nil {
 		return nil, fmt.Errorf("search TV shows response handling failed: %w", err)
 	}
+	c.observeTVShows(result.Results)
 
 	return &result, nil
 }
@@ -330,6 +325,7 @@ func (c *TMDbClient) GetPersonMovieCredits(ctx context.Context, personID int) (*
 	if err := c.handleResponse(resp, &result); err != nil {
 		return nil, fmt.Errorf("get person movie credits response handling failed: %w", err)
 	}
+	c.observeMovieCredits(&result)
 
 	return &result, nil
 }
@@ -350,6 +346,7 @@ func (c *TMDbClient) GetPersonTVCredits(ctx context.Context, personID int) (*mod
 	if err := c.handleResponse(resp, &result); err != nil {
 		return nil, fmt.Errorf("get person TV credits response handling failed: %w", err)
 	}
+	c.observeTVCredits(&result)
 
 	return &result, nil
 }
@@ -370,6 +367,7 @@ func (c *TMDbClient) GetPersonCombinedCredits(ctx context.Context, personID int)
 	if err := c.handleResponse(resp, &result); err != nil {
 		return nil, fmt.Errorf("get person combined credits response handling failed: %w", err)
 	}
+	c.observeCombinedCredits(&result)
 
 	return &result, nil
 }
@@ -390,6 +388,7 @@ func (c *TMDbClient) GetPopularMovies(ctx context.Context, page int) (*models.Po
 	if err := c.handleResponse(resp, &result); err != nil {
 		return nil, fmt.Errorf("get popular movies response handling failed: %w", err)
 	}
+	c.observeMovies(result.Results)
 
 	return &result, nil
 }
@@ -410,6 +409,7 @@ func (c *TMDbClient) GetTopRatedMovies(ctx context.Context, page int) (*models.T
 	if err := c.handleResponse(resp, &result); err != nil {
 		return nil, fmt.Errorf("get top rated movies response handling failed: %w", err)
 	}
+	c.observeMovies(result.Results)
 
 	return &result, nil
 }
@@ -435,6 +435,7 @@ func (c *TMDbClient) GetTrendingMovies(ctx context.Context, timeWindow string, p
 	if err := c.handleResponse(resp, &result); err != nil {
 		return nil, fmt.Errorf("get trending movies response handling failed: %w", err)
 	}
+	c.observeMovies(result.Results)
 
 	return &result, nil
 }
@@ -460,93 +461,84 @@ func (c *TMDbClient) GetTrendingTVShows(ctx context.Context, timeWindow string,
 	if err := c.handleResponse(resp, &result); err != nil {
 		return nil, fmt.Errorf("get trending TV shows response handling failed: %w", err)
 	}
+	c.observeTVShows(result.Results)
 
 	return &result, nil
 }
 
-// MultiSearch performs a multi-search across movies, TV shows, and people
-func (c *TMDbClient) MultiSearch(ctx context.Context, query string, page int, language string) (*models.MultiSearchResponse, error) {
-	if query == "" {
-		return nil, fmt.Errorf("search query cannot be empty")
-	}
-
-	params := url.Values{
-		"query": {query},
+// GetTrendingPeople retrieves trending people
+func (c *TMDbClient) GetTrendingPeople(ctx context.Context, timeWindow string, page int) (*models.SearchResponse[models.Person], error) {
+	if timeWindow != "day" && timeWindow != "week" {
+		timeWindow = "week" // Default to week
 	}
 
+	params := url.Values{}
 	if page > 0 {
 		params.Set("page", strconv.Itoa(page))
 	}
 
-	if language != "" {
-		params.Set("language", language)
-	}
-
-	resp, err := c.makeRequest(ctx, "/search/multi", params)
+	endpoint := fmt.Sprintf("/trending/person/%s", timeWindow)
+	resp, err := c.makeRequest(ctx, endpoint, params)
 	if err != nil {
-		return nil, fmt.Errorf("multi search request failed: %w", err)
+		return nil, fmt.Errorf("get trending people request failed: %w", err)
 	}
 
-	var result models.MultiSearchResponse
+	var result models.SearchResponse[models.Person]
 	if err := c.handleResponse(resp, &result); err != nil {
-		return nil, fmt.Errorf("multi search response handling failed: %w", err)
+		return nil, fmt.Errorf("get trending people response handling failed: %w", err)
 	}
 
 	return &result, nil
 }
 
-// SearchByType performs a search filtered by media type (movie, tv, or person)
-func (c *TMDbClient) SearchByType(ctx context.Context, searchType, query string, page int, language string) (*models.MultiSearchResponse, error) {
-	if query == "" {
-		return nil, fmt.Errorf("search query cannot be empty")
-	}
-
-	var endpoint string
-	switch searchType {
-	case "movie":
-		endpoint = "/search/movie"
-	case "tv":
-		endpoint = "/search/tv"
-	case "person":
-		endpoint = "/search/person"
-	default:
-		return c.MultiSearch(ctx, query, page, language)
-	}
-
-	params := url.Values{
-		"query": {query},
+// MultiSearch performs a multi-search across movies, TV shows, and people
+func (c *TMDbClient) MultiSearch(ctx context.Context, opts models.SearchOptions) (*models.MultiSearchResponse, error) {
+	opts.Type = "all"
+	if err := opts.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid search options: %w", err)
 	}
 
-	if page > 0 {
-		params.Set("page", strconv.Itoa(page))
+	resp, err := c.makeRequest(ctx, "/search/multi", searchParams(opts))
+	if err != nil {
+		return nil, fmt.Errorf("multi search request failed: %w", err)
 	}
 
-	if language != "" {
-		params.Set("language", language)
+	var result models.MultiSearchResponse
+	if err := c.handleResponse(resp, &result); err != nil {
+		return nil, fmt.Errorf("multi search response handling failed: %w", err)
 	}
+	c.observeSearchResults(result.Results)
 
-	resp, err := c.makeRequest(ctx, endpoint, params)
-	if err != nil {
-		return nil, fmt.Errorf("search by type request failed: %w", err)
-	}
+	return &result, nil
+}
 
-	// For type-specific searches, we need to convert the response to MultiSearchResponse
-	switch searchType {
+// SearchByType performs a search filtered by media type (movie, tv, or person).
+// Any other type falls back to MultiSearch.
+func (c *TMDbClient) SearchByType(ctx context.Context, opts models.SearchOptions) (*models.MultiSearchResponse, error) {
+	switch opts.Type {
 	case "movie":
-		var movieResult models.MovieSearchResponse
-		if err := c.handleResponse(resp, &movieResult); err != nil {
-			return nil, fmt.Errorf("movie search response handling failed: %w", err)
+		movieResult, err := c.SearchMovies(ctx, opts)
+		if err != nil {
+			return nil, err
 		}
-		return c.convertMovieSearchToMultiSearch(&movieResult), nil
+		return c.convertMovieSearchToMultiSearch(movieResult), nil
 
 	case "tv":
-		var tvResult models.TVSearchResponse
-		if err := c.handleResponse(resp, &tvResult); err != nil {
-			return nil, fmt.Errorf("TV search response handling failed: %w", err)
+		tvResult, err := c.SearchTVShows(ctx, opts)
+		if err != nil {
+			return nil, err
 		}
-		return c.convertTVSearchToMultiSearch(&tvResult), nil
+		return c.convertTVSearchToMultiSearch(tvResult), nil
 
 	case "person":
+		if err := opts.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid search options: %w", err)
+		}
+		resp, err := c.makeRequest(ctx, "/search/person", searchParams(opts))
+		if err != nil {
+			return nil, fmt.Errorf("search people request failed: %w", err)
+		}
+
 		var personResult models.PersonSearchResponse
 		if err := c.handleResponse(resp, &personResult); err != nil {
 			return nil, fmt.Errorf("person search response handling failed: %w", err)
@@ -554,10 +546,45 @@ func (c *TMDbClient) SearchByType(ctx context.Context, searchType, query string,
 		return c.convertPersonSearchToMultiSearch(&personResult), nil
 
 	default:
-		return nil, fmt.Errorf("unsupported search type: %s", searchType)
+		return c.MultiSearch(ctx, opts)
 	}
 }
 
+// searchParams builds the TMDb query parameters for a search. Filters are
+// expected to have been validated against opts.Type beforehand.
+func searchParams(opts models.SearchOptions) url.Values {
+	params := url.Values{
+		"query": {opts.Query},
+	}
+
+	if opts.Page > 0 {
+		params.Set("page", strconv.Itoa(opts.Page))
+	}
+
+	if opts.Language != "" {
+		params.Set("language", opts.Language)
+	}
+
+	filter := opts.Filter
+	if filter.IncludeAdult {
+		params.Set("include_adult", "true")
+	}
+	if filter.Region != "" {
+		params.Set("region", filter.Region)
+	}
+	if filter.Year > 0 {
+		params.Set("year", strconv.Itoa(filter.Year))
+	}
+	if filter.PrimaryReleaseYear > 0 {
+		params.Set("primary_release_year", strconv.Itoa(filter.PrimaryReleaseYear))
+	}
+	if filter.FirstAirDateYear > 0 {
+		params.Set("first_air_date_year", strconv.Itoa(filter.FirstAirDateYear))
+	}
+
+	return params
+}
+
 // convertMovieSearchToMultiSearch converts MovieSearchResponse to MultiSearchResponse
 func (c *TMDbClient) convertMovieSearchToMultiSearch(movieResult *models.MovieSearchResponse) *models.MultiSearchResponse {
 	results := make([]models.MultiSearchResult, len(movieResult.Results))
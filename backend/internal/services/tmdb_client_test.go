@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"github.com/takeshi-arihori/movie-api/internal/config"
@@ -111,7 +112,7 @@ func TestSearchMovies(t *testing.T) {
 	ctx := context.Background()
 
 	// Test successful search
-	result, err := client.SearchMovies(ctx, "Fight Club", 1)
+	result, err := client.SearchMovies(ctx, models.SearchOptions{Query: "Fight Club", Page: 1})
 	if err != nil {
 		t.Fatalf("SearchMovies failed: %v", err)
 	}
@@ -129,7 +130,7 @@ func TestSearchMovies(t *testing.T) {
 	}
 
 	// Test empty query
-	_, err = client.SearchMovies(ctx, "", 1)
+	_, err = client.SearchMovies(ctx, models.SearchOptions{Page: 1})
 	if err == nil {
 		t.Error("Expected error for empty query, got nil")
 	}
@@ -166,7 +167,7 @@ func TestSearchTVShows(t *testing.T) {
 	ctx := context.Background()
 
 	// Test successful search
-	result, err := client.SearchTVShows(ctx, "Breaking Bad", 1)
+	result, err := client.SearchTVShows(ctx, models.SearchOptions{Query: "Breaking Bad", Page: 1})
 	if err != nil {
 		t.Fatalf("SearchTVShows failed: %v", err)
 	}
@@ -184,7 +185,7 @@ func TestSearchTVShows(t *testing.T) {
 	}
 
 	// Test empty query
-	_, err = client.SearchTVShows(ctx, "", 1)
+	_, err = client.SearchTVShows(ctx, models.SearchOptions{Page: 1})
 	if err == nil {
 		t.Error("Expected error for empty query, got nil")
 	}
@@ -362,7 +363,7 @@ func TestUnauthorizedAccess(t *testing.T) {
 	ctx := context.Background()
 
 	// Test unauthorized access
-	_, err := client.SearchMovies(ctx, "test", 1)
+	_, err := client.SearchMovies(ctx, models.SearchOptions{Query: "test", Page: 1})
 	if err == nil {
 		t.Error("Expected error for unauthorized access, got nil")
 	}
@@ -391,7 +392,7 @@ func TestMakeRequestWithContext(t *testing.T) {
 	cancel() // Cancel immediately
 
 	// Test context cancellation
-	_, err := client.SearchMovies(ctx, "test", 1)
+	_, err := client.SearchMovies(ctx, models.SearchOptions{Query: "test", Page: 1})
 	if err == nil {
 		t.Error("Expected error for cancelled context, got nil")
 	}
@@ -479,6 +480,32 @@ func TestGetTrendingMovies(t *testing.T) {
 	}
 }
 
+// TestGetTrendingPeople tests trending people retrieval
+func TestGetTrendingPeople(t *testing.T) {
+	responses := map[string]interface{}{
+		"/trending/person/day": models.SearchResponse[models.Person]{
+			Page:         1,
+			Results:      []models.Person{{ID: 819, Name: "Edward Norton", Popularity: 25.0}},
+			TotalPages:   1,
+			TotalResults: 1,
+		},
+	}
+
+	server := createMockServer(t, responses)
+	defer server.Close()
+
+	client := createTestClient(server.URL)
+
+	result, err := client.GetTrendingPeople(context.Background(), "day", 1)
+	if err != nil {
+		t.Fatalf("GetTrendingPeople failed: %v", err)
+	}
+
+	if len(result.Results) != 1 || result.Results[0].Name != "Edward Norton" {
+		t.Errorf("Expected Edward Norton, got %+v", result.Results)
+	}
+}
+
 // TestMultiSearch tests multi-search functionality
 func TestMultiSearch(t *testing.T) {
 	mockResponse := models.MultiSearchResponse{
@@ -530,7 +557,7 @@ func TestMultiSearch(t *testing.T) {
 	ctx := context.Background()
 
 	// Test successful multi-search
-	result, err := client.MultiSearch(ctx, "test query", 1, "en-US")
+	result, err := client.MultiSearch(ctx, models.SearchOptions{Query: "test query", Page: 1, Language: "en-US"})
 	if err != nil {
 		t.Fatalf("MultiSearch failed: %v", err)
 	}
@@ -567,7 +594,7 @@ func TestMultiSearch(t *testing.T) {
 	}
 
 	// Test empty query
-	_, err = client.MultiSearch(ctx, "", 1, "en-US")
+	_, err = client.MultiSearch(ctx, models.SearchOptions{Query: "", Page: 1, Language: "en-US"})
 	if err == nil {
 		t.Error("Expected error for empty query, got nil")
 	}
@@ -640,7 +667,7 @@ func TestSearchByType(t *testing.T) {
 	ctx := context.Background()
 
 	// Test movie search
-	movieResult, err := client.SearchByType(ctx, "movie", "Fight Club", 1, "en-US")
+	movieResult, err := client.SearchByType(ctx, models.SearchOptions{Type: "movie", Query: "Fight Club", Page: 1, Language: "en-US"})
 	if err != nil {
 		t.Fatalf("SearchByType (movie) failed: %v", err)
 	}
@@ -652,7 +679,7 @@ func TestSearchByType(t *testing.T) {
 	}
 
 	// Test TV search
-	tvResult, err := client.SearchByType(ctx, "tv", "Breaking Bad", 1, "en-US")
+	tvResult, err := client.SearchByType(ctx, models.SearchOptions{Type: "tv", Query: "Breaking Bad", Page: 1, Language: "en-US"})
 	if err != nil {
 		t.Fatalf("SearchByType (tv) failed: %v", err)
 	}
@@ -664,7 +691,7 @@ func TestSearchByType(t *testing.T) {
 	}
 
 	// Test person search
-	personResult, err := client.SearchByType(ctx, "person", "Edward Norton", 1, "en-US")
+	personResult, err := client.SearchByType(ctx, models.SearchOptions{Type: "person", Query: "Edward Norton", Page: 1, Language: "en-US"})
 	if err != nil {
 		t.Fatalf("SearchByType (person) failed: %v", err)
 	}
@@ -684,7 +711,7 @@ func TestSearchByType(t *testing.T) {
 		TotalResults: 0,
 	}
 	
-	multiResult, err := client.SearchByType(ctx, "invalid", "test", 1, "en-US")
+	multiResult, err := client.SearchByType(ctx, models.SearchOptions{Type: "invalid", Query: "test", Page: 1, Language: "en-US"})
 	if err != nil {
 		t.Fatalf("SearchByType (invalid type) failed: %v", err)
 	}
@@ -694,6 +721,107 @@ func TestSearchByType(t *testing.T) {
 	}
 }
 
+// TestSearchByTypeFilters tests that search filters are forwarded to TMDb
+func TestSearchByTypeFilters(t *testing.T) {
+	var received url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(models.MovieSearchResponse{Page: 1, Results: []models.Movie{}})
+	}))
+	defer server.Close()
+
+	client := createTestClient(server.URL)
+	ctx := context.Background()
+
+	testCases := []struct {
+		name     string
+		opts     models.SearchOptions
+		expected map[string]string
+	}{
+		{
+			name: "movie filters",
+			opts: models.SearchOptions{
+				Type:  "movie",
+				Query: "Matrix",
+				Filter: models.SearchFilter{
+					Year:               1999,
+					PrimaryReleaseYear: 1999,
+					IncludeAdult:       true,
+					Region:             "JP",
+				},
+			},
+			expected: map[string]string{
+				"year":                 "1999",
+				"primary_release_year": "1999",
+				"include_adult":        "true",
+				"region":               "JP",
+			},
+		},
+		{
+			name: "tv filters",
+			opts: models.SearchOptions{
+				Type:   "tv",
+				Query:  "Breaking Bad",
+				Filter: models.SearchFilter{FirstAirDateYear: 2008},
+			},
+			expected: map[string]string{
+				"first_air_date_year": "2008",
+				"year":                "",
+				"include_adult":       "",
+			},
+		},
+		{
+			name: "person filters",
+			opts: models.SearchOptions{
+				Type:   "person",
+				Query:  "Edward Norton",
+				Filter: models.SearchFilter{IncludeAdult: true, Region: "US"},
+			},
+			expected: map[string]string{
+				"include_adult": "true",
+				"region":        "US",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			received = nil
+			if _, err := client.SearchByType(ctx, tc.opts); err != nil {
+				t.Fatalf("SearchByType failed: %v", err)
+			}
+			for key, want := range tc.expected {
+				if got := received.Get(key); got != want {
+					t.Errorf("Expected %s=%q, got %q", key, want, got)
+				}
+			}
+		})
+	}
+
+	// Filters that do not apply to the search type are rejected before any request is made
+	received = nil
+	_, err := client.SearchByType(ctx, models.SearchOptions{
+		Type:   "tv",
+		Query:  "Breaking Bad",
+		Filter: models.SearchFilter{Year: 2008},
+	})
+	if err == nil {
+		t.Error("Expected error for year filter on TV search, got nil")
+	}
+	if received != nil {
+		t.Error("Expected no request to be sent for invalid filters")
+	}
+
+	_, err = client.MultiSearch(ctx, models.SearchOptions{
+		Query:  "Matrix",
+		Filter: models.SearchFilter{FirstAirDateYear: 1999},
+	})
+	if err == nil {
+		t.Error("Expected error for first_air_date_year filter on multi-search, got nil")
+	}
+}
+
 // TestConvertMovieSearchToMultiSearch tests movie search conversion
 func TestConvertMovieSearchToMultiSearch(t *testing.T) {
 	client := createTestClient("http://test.com")
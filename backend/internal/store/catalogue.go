@@ -0,0 +1,241 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+)
+
+// CataloguePageSize matches TMDb's page size, so catalogue pages can stand in for
+// TMDb pages when TMDb is unavailable
+const CataloguePageSize = 20
+
+// TitleOrder orders titles listed from the local catalogue
+type TitleOrder string
+
+const (
+	// OrderPopularity lists the most popular titles first
+	OrderPopularity TitleOrder = "popularity"
+	// OrderRating lists the highest rated titles first
+	OrderRating TitleOrder = "rating"
+)
+
+// orderBy returns the ORDER BY clause for o
+func (o TitleOrder) orderBy() string {
+	if o == OrderRating {
+		return "vote_average DESC NULLS LAST, vote_count DESC NULLS LAST, id"
+	}
+	return "popularity DESC NULLS LAST, id"
+}
+
+// ListMovies returns one page of cached movies
+func (r *titleRepository) ListMovies(ctx context.Context, order TitleOrder, page int) (*models.SearchResponse[models.Movie], error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+movieColumns+`, count(*) OVER ()
+		FROM movies ORDER BY `+order.orderBy()+`
+		LIMIT $1 OFFSET $2`, CataloguePageSize, pageOffset(page))
+	if err != nil {
+		return nil, fmt.Errorf("list movies: %w", err)
+	}
+	defer rows.Close()
+
+	response := &models.SearchResponse[models.Movie]{Page: page, Results: []models.Movie{}}
+	for rows.Next() {
+		c, err := scanMovie(withTotal(rows, &response.TotalResults))
+		if err != nil {
+			return nil, fmt.Errorf("list movies: %w", err)
+		}
+		response.Results = append(response.Results, c.Movie)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list movies: %w", err)
+	}
+	response.TotalPages = totalPages(response.TotalResults)
+	return response, nil
+}
+
+// ListTVShows returns one page of cached TV shows
+func (r *titleRepository) ListTVShows(ctx context.Context, order TitleOrder, page int) (*models.SearchResponse[models.TVShow], error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+tvShowColumns+`, count(*) OVER ()
+		FROM tv_shows ORDER BY `+order.orderBy()+`
+		LIMIT $1 OFFSET $2`, CataloguePageSize, pageOffset(page))
+	if err != nil {
+		return nil, fmt.Errorf("list TV shows: %w", err)
+	}
+	defer rows.Close()
+
+	response := &models.SearchResponse[models.TVShow]{Page: page, Results: []models.TVShow{}}
+	for rows.Next() {
+		c, err := scanTVShow(withTotal(rows, &response.TotalResults))
+		if err != nil {
+			return nil, fmt.Errorf("list TV shows: %w", err)
+		}
+		response.Results = append(response.Results, c.TVShow)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list TV shows: %w", err)
+	}
+	response.TotalPages = totalPages(response.TotalResults)
+	return response, nil
+}
+
+// SearchTitles searches cached movies and TV shows by title using the full-text
+// indexes, plus a substring match for scripts the english parser cannot split
+// (e.g. Japanese). People are not cached, so person searches return no results.
+func (r *titleRepository) SearchTitles(ctx context.Context, opts models.SearchOptions) (*models.MultiSearchResponse, error) {
+	page := opts.Page
+	if page < 1 {
+		page = 1
+	}
+	response := &models.MultiSearchResponse{Page: page, Results: []models.MultiSearchResult{}}
+
+	args := []any{opts.Query, "%" + escapeLike(opts.Query) + "%"}
+	var parts []string
+	if opts.Type == "all" || opts.Type == "movie" || opts.Type == "" {
+		conditions := []string{`(to_tsvector('english', title) @@ plainto_tsquery('english', $1)
+			OR title ILIKE $2 OR original_title ILIKE $2)`}
+		if !opts.Filter.IncludeAdult {
+			conditions = append(conditions, "adult IS NOT TRUE")
+		}
+		for _, year := range []int{opts.Filter.Year, opts.Filter.PrimaryReleaseYear} {
+			if year != 0 {
+				args = append(args, year)
+				conditions = append(conditions, fmt.Sprintf("EXTRACT(YEAR FROM release_date) = $%d", len(args)))
+			}
+		}
+		parts = append(parts, `
+			SELECT 'movie' AS media_type, id, title, original_title, overview,
+				to_char(release_date, 'YYYY-MM-DD') AS date, poster_path, backdrop_path, vote_average, vote_count,
+				popularity, adult, genre_ids, original_language, NULL::varchar[] AS origin_country,
+				ts_rank(to_tsvector('english', title), plainto_tsquery('english', $1)) AS rank
+			FROM movies WHERE `+strings.Join(conditions, " AND "))
+	}
+	if opts.Type == "all" || opts.Type == "tv" || opts.Type == "" {
+		conditions := []string{`(to_tsvector('english', name) @@ plainto_tsquery('english', $1)
+			OR name ILIKE $2 OR original_name ILIKE $2)`}
+		if opts.Filter.FirstAirDateYear != 0 {
+			args = append(args, opts.Filter.FirstAirDateYear)
+			conditions = append(conditions, fmt.Sprintf("EXTRACT(YEAR FROM first_air_date) = $%d", len(args)))
+		}
+		parts = append(parts, `
+			SELECT 'tv' AS media_type, id, name AS title, original_name AS original_title, overview,
+				to_char(first_air_date, 'YYYY-MM-DD') AS date, poster_path, backdrop_path, vote_average, vote_count,
+				popularity, NULL::boolean AS adult, genre_ids, original_language, origin_country,
+				ts_rank(to_tsvector('english', name), plainto_tsquery('english', $1)) AS rank
+			FROM tv_shows WHERE `+strings.Join(conditions, " AND "))
+	}
+	if len(parts) == 0 {
+		return response, nil
+	}
+
+	args = append(args, CataloguePageSize, pageOffset(page))
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT media_type, id, title, original_title, overview, date, poster_path, backdrop_path,
+			vote_average, vote_count, popularity, adult, genre_ids, original_language, origin_country,
+			count(*) OVER ()
+		FROM (`+strings.Join(parts, " UNION ALL ")+`) titles
+		ORDER BY rank DESC, popularity DESC NULLS LAST, id
+		LIMIT $`+fmt.Sprint(len(args)-1)+` OFFSET $`+fmt.Sprint(len(args)), args...)
+	if err != nil {
+		return nil, fmt.Errorf("search titles: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		result, err := scanSearchResult(rows, &response.TotalResults)
+		if err != nil {
+			return nil, fmt.Errorf("search titles: %w", err)
+		}
+		response.Results = append(response.Results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("search titles: %w", err)
+	}
+	response.TotalPages = totalPages(response.TotalResults)
+	return response, nil
+}
+
+// scanSearchResult reads a SearchTitles row into the shape TMDb's search returns
+func scanSearchResult(rows *sql.Rows, total *int) (models.MultiSearchResult, error) {
+	var result models.MultiSearchResult
+	var mediaType, title string
+	var originalTitle, overview, originalLanguage, date sql.NullString
+	var voteAverage, popularity sql.NullFloat64
+	var voteCount sql.NullInt64
+	var adult sql.NullBool
+	var genreIDs []int64
+	var originCountry []sql.NullString
+
+	err := rows.Scan(&mediaType, &result.ID, &title, &originalTitle, &overview, &date, &result.PosterPath,
+		&result.BackdropPath, &voteAverage, &voteCount, &popularity, &adult, pq.Array(&genreIDs),
+		&originalLanguage, pq.Array(&originCountry), total)
+	if err != nil {
+		return result, err
+	}
+
+	result.MediaType = models.SearchItemType(mediaType)
+	result.Popularity = popularity.Float64
+	result.GenreIDs = toInts(genreIDs)
+	result.Overview = &overview.String
+	result.OriginalLanguage = &originalLanguage.String
+	voteAverageValue, voteCountValue := voteAverage.Float64, int(voteCount.Int64)
+	result.VoteAverage = &voteAverageValue
+	result.VoteCount = &voteCountValue
+
+	switch result.MediaType {
+	case models.SearchItemTypeMovie:
+		result.Title = &title
+		result.OriginalTitle = &originalTitle.String
+		result.Adult = &adult.Bool
+		if date.Valid {
+			result.ReleaseDate = &date.String
+		}
+	case models.SearchItemTypeTV:
+		result.Name = &title
+		result.OriginalName = &originalTitle.String
+		if date.Valid {
+			result.FirstAirDate = &date.String
+		}
+		for _, country := range originCountry {
+			if country.Valid {
+				result.OriginCountry = append(result.OriginCountry, country.String)
+			}
+		}
+	}
+	return result, nil
+}
+
+// totalScanner reads a trailing count(*) OVER () column after the title columns
+type totalScanner struct {
+	rows  *sql.Rows
+	total *int
+}
+
+// withTotal adapts rows so scanMovie and scanTVShow also read the total count
+func withTotal(rows *sql.Rows, total *int) rowScanner {
+	return totalScanner{rows: rows, total: total}
+}
+
+// Scan scans dest followed by the total count
+func (s totalScanner) Scan(dest ...any) error {
+	return s.rows.Scan(append(dest, s.total)...)
+}
+
+// pageOffset returns the row offset of a 1-based catalogue page
+func pageOffset(page int) int {
+	if page < 1 {
+		page = 1
+	}
+	return (page - 1) * CataloguePageSize
+}
+
+// totalPages returns the number of catalogue pages holding total rows
+func totalPages(total int) int {
+	return (total + CataloguePageSize - 1) / CataloguePageSize
+}
@@ -0,0 +1,150 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+)
+
+// FavoriteRepository reads and writes the user_favorites table
+type FavoriteRepository interface {
+	// Add inserts favorite, filling in its ID and creation time. It returns
+	// ErrConflict if the user already has this title as a favorite.
+	Add(ctx context.Context, favorite *models.Favorite) error
+	// Remove deletes a user's favorite, or returns ErrNotFound
+	Remove(ctx context.Context, userID string, mediaType models.SearchItemType, mediaID int) error
+	// Get returns a user's favorite for a title, or ErrNotFound
+	Get(ctx context.Context, userID string, mediaType models.SearchItemType, mediaID int) (*models.Favorite, error)
+	// List returns a user's favorites, newest first
+	List(ctx context.Context, userID string) ([]models.Favorite, error)
+	// ListByRating returns a user's favorites, best rated first by the ratings in
+	// the titles cache. Titles that are not cached come last, newest first.
+	ListByRating(ctx context.Context, userID string) ([]models.Favorite, error)
+	// Exists reports whether the user has the title as a favorite
+	Exists(ctx context.Context, userID string, mediaType models.SearchItemType, mediaID int) (bool, error)
+}
+
+// favoriteRepository is the PostgreSQL FavoriteRepository
+type favoriteRepository struct {
+	db *sql.DB
+}
+
+// NewFavoriteRepository creates a FavoriteRepository on db
+func NewFavoriteRepository(db *sql.DB) FavoriteRepository {
+	return &favoriteRepository{db: db}
+}
+
+// Add inserts a favorite
+func (r *favoriteRepository) Add(ctx context.Context, favorite *models.Favorite) error {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO user_favorites (user_id, media_type, media_id)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at`,
+		favorite.UserID, favorite.MediaType, favorite.MediaID,
+	).Scan(&favorite.ID, &favorite.CreatedAt)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("add favorite: %w", ErrConflict)
+	}
+	if err != nil {
+		return fmt.Errorf("add favorite: %w", err)
+	}
+	return nil
+}
+
+// Remove deletes a favorite
+func (r *favoriteRepository) Remove(ctx context.Context, userID string, mediaType models.SearchItemType, mediaID int) error {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM user_favorites
+		WHERE user_id = $1 AND media_type = $2 AND media_id = $3`,
+		userID, mediaType, mediaID)
+	if err != nil {
+		return fmt.Errorf("remove favorite: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Get returns a single favorite
+func (r *favoriteRepository) Get(ctx context.Context, userID string, mediaType models.SearchItemType, mediaID int) (*models.Favorite, error) {
+	var f models.Favorite
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, user_id, media_type, media_id, created_at
+		FROM user_favorites
+		WHERE user_id = $1 AND media_type = $2 AND media_id = $3`,
+		userID, mediaType, mediaID,
+	).Scan(&f.ID, &f.UserID, &f.MediaType, &f.MediaID, &f.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get favorite: %w", err)
+	}
+	return &f, nil
+}
+
+// List returns a user's favorites
+func (r *favoriteRepository) List(ctx context.Context, userID string) ([]models.Favorite, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, user_id, media_type, media_id, created_at
+		FROM user_favorites
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list favorites: %w", err)
+	}
+	return scanFavorites(rows)
+}
+
+// ListByRating returns a user's favorites ordered by their cached ratings
+func (r *favoriteRepository) ListByRating(ctx context.Context, userID string) ([]models.Favorite, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT f.id, f.user_id, f.media_type, f.media_id, f.created_at
+		FROM user_favorites f
+		LEFT JOIN movies m ON f.media_type = 'movie' AND m.id = f.media_id
+		LEFT JOIN tv_shows t ON f.media_type = 'tv' AND t.id = f.media_id
+		WHERE f.user_id = $1
+		ORDER BY COALESCE(m.vote_average, t.vote_average) DESC NULLS LAST,
+			COALESCE(m.vote_count, t.vote_count) DESC NULLS LAST,
+			f.created_at DESC, f.id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list favorites by rating: %w", err)
+	}
+	return scanFavorites(rows)
+}
+
+// scanFavorites reads and closes rows of favorites
+func scanFavorites(rows *sql.Rows) ([]models.Favorite, error) {
+	defer rows.Close()
+
+	favorites := []models.Favorite{}
+	for rows.Next() {
+		var f models.Favorite
+		if err := rows.Scan(&f.ID, &f.UserID, &f.MediaType, &f.MediaID, &f.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan favorite: %w", err)
+		}
+		favorites = append(favorites, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list favorites: %w", err)
+	}
+	return favorites, nil
+}
+
+// Exists reports whether a favorite exists
+func (r *favoriteRepository) Exists(ctx context.Context, userID string, mediaType models.SearchItemType, mediaID int) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM user_favorites
+			WHERE user_id = $1 AND media_type = $2 AND media_id = $3
+		)`, userID, mediaType, mediaID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("check favorite: %w", err)
+	}
+	return exists, nil
+}
@@ -0,0 +1,57 @@
+package store
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestMigrations tests that the embedded migrations are complete and ordered
+func TestMigrations(t *testing.T) {
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatalf("Migrations failed: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("Expected at least one migration")
+	}
+
+	for i, m := range migrations {
+		if m.Version != int64(i+1) {
+			t.Errorf("Expected migration %d to have version %d, got %d", i, i+1, m.Version)
+		}
+		if strings.TrimSpace(m.Up) == "" || strings.TrimSpace(m.Down) == "" {
+			t.Errorf("Migration %d_%s has an empty script", m.Version, m.Name)
+		}
+	}
+
+	initial := migrations[0]
+	if initial.Name != "initial_schema" {
+		t.Errorf("Expected first migration to be initial_schema, got %s", initial.Name)
+	}
+	for _, table := range []string{"users", "movies", "tv_shows", "user_favorites", "search_history"} {
+		if !strings.Contains(initial.Up, "CREATE TABLE IF NOT EXISTS "+table+" (") {
+			t.Errorf("Expected initial schema to create %s idempotently", table)
+		}
+	}
+}
+
+// TestCutDirection tests migration file name parsing
+func TestCutDirection(t *testing.T) {
+	tests := []struct {
+		file      string
+		stem      string
+		direction string
+		ok        bool
+	}{
+		{"0001_initial_schema.up.sql", "0001_initial_schema", "up", true},
+		{"0002_add_index.down.sql", "0002_add_index", "down", true},
+		{"0003_notes.sql", "", "", false},
+	}
+
+	for _, tt := range tests {
+		stem, direction, ok := cutDirection(tt.file)
+		if stem != tt.stem || direction != tt.direction || ok != tt.ok {
+			t.Errorf("cutDirection(%q) = %q, %q, %v", tt.file, stem, direction, ok)
+		}
+	}
+}
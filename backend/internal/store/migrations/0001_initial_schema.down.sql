@@ -0,0 +1,12 @@
+SET LOCAL search_path TO movieapi, public;
+
+DROP VIEW IF EXISTS recent_searches;
+DROP VIEW IF EXISTS popular_movies;
+
+DROP TABLE IF EXISTS search_history;
+DROP TABLE IF EXISTS user_favorites;
+DROP TABLE IF EXISTS tv_shows;
+DROP TABLE IF EXISTS movies;
+DROP TABLE IF EXISTS users;
+
+DROP FUNCTION IF EXISTS update_updated_at_column();
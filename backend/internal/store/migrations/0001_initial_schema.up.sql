@@ -0,0 +1,138 @@
+-- Initial schema, matching scripts/init-db.sql. Every statement is idempotent so
+-- databases created by the init script can adopt migrations without changes.
+
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+CREATE EXTENSION IF NOT EXISTS "citext";
+
+CREATE SCHEMA IF NOT EXISTS movieapi;
+SET LOCAL search_path TO movieapi, public;
+
+CREATE TABLE IF NOT EXISTS users (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    email CITEXT UNIQUE NOT NULL,
+    username VARCHAR(50) UNIQUE NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS movies (
+    id INTEGER PRIMARY KEY, -- TMDb movie ID
+    title VARCHAR(255) NOT NULL,
+    original_title VARCHAR(255),
+    overview TEXT,
+    release_date DATE,
+    poster_path VARCHAR(255),
+    backdrop_path VARCHAR(255),
+    vote_average DECIMAL(3,1),
+    vote_count INTEGER,
+    popularity DECIMAL(8,3),
+    adult BOOLEAN DEFAULT false,
+    genre_ids INTEGER[],
+    original_language VARCHAR(10),
+    video BOOLEAN DEFAULT false,
+    cached_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS tv_shows (
+    id INTEGER PRIMARY KEY, -- TMDb TV show ID
+    name VARCHAR(255) NOT NULL,
+    original_name VARCHAR(255),
+    overview TEXT,
+    first_air_date DATE,
+    last_air_date DATE,
+    poster_path VARCHAR(255),
+    backdrop_path VARCHAR(255),
+    vote_average DECIMAL(3,1),
+    vote_count INTEGER,
+    popularity DECIMAL(8,3),
+    genre_ids INTEGER[],
+    original_language VARCHAR(10),
+    origin_country VARCHAR(10)[],
+    cached_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS user_favorites (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    media_type VARCHAR(10) NOT NULL CHECK (media_type IN ('movie', 'tv')),
+    media_id INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(user_id, media_type, media_id)
+);
+
+CREATE TABLE IF NOT EXISTS search_history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    query VARCHAR(255) NOT NULL,
+    search_type VARCHAR(20),
+    results_count INTEGER DEFAULT 0,
+    ip_address INET,
+    user_agent TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE search_history ADD COLUMN IF NOT EXISTS search_type VARCHAR(20);
+
+CREATE INDEX IF NOT EXISTS idx_movies_title ON movies USING GIN (to_tsvector('english', title));
+CREATE INDEX IF NOT EXISTS idx_movies_release_date ON movies(release_date);
+CREATE INDEX IF NOT EXISTS idx_movies_popularity ON movies(popularity DESC);
+CREATE INDEX IF NOT EXISTS idx_movies_vote_average ON movies(vote_average DESC);
+CREATE INDEX IF NOT EXISTS idx_movies_cached_at ON movies(cached_at);
+
+CREATE INDEX IF NOT EXISTS idx_tv_shows_name ON tv_shows USING GIN (to_tsvector('english', name));
+CREATE INDEX IF NOT EXISTS idx_tv_shows_first_air_date ON tv_shows(first_air_date);
+CREATE INDEX IF NOT EXISTS idx_tv_shows_popularity ON tv_shows(popularity DESC);
+CREATE INDEX IF NOT EXISTS idx_tv_shows_vote_average ON tv_shows(vote_average DESC);
+CREATE INDEX IF NOT EXISTS idx_tv_shows_cached_at ON tv_shows(cached_at);
+
+CREATE INDEX IF NOT EXISTS idx_user_favorites_user_id ON user_favorites(user_id);
+CREATE INDEX IF NOT EXISTS idx_user_favorites_media ON user_favorites(media_type, media_id);
+
+CREATE INDEX IF NOT EXISTS idx_search_history_user_id ON search_history(user_id);
+CREATE INDEX IF NOT EXISTS idx_search_history_created_at ON search_history(created_at);
+CREATE INDEX IF NOT EXISTS idx_search_history_query ON search_history(lower(query) text_pattern_ops, created_at);
+
+CREATE OR REPLACE FUNCTION update_updated_at_column()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = CURRENT_TIMESTAMP;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE OR REPLACE TRIGGER update_users_updated_at BEFORE UPDATE ON users
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE OR REPLACE TRIGGER update_movies_updated_at BEFORE UPDATE ON movies
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE OR REPLACE TRIGGER update_tv_shows_updated_at BEFORE UPDATE ON tv_shows
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE OR REPLACE VIEW popular_movies AS
+SELECT
+    id,
+    title,
+    overview,
+    release_date,
+    poster_path,
+    vote_average,
+    vote_count,
+    popularity
+FROM movies
+WHERE cached_at > CURRENT_TIMESTAMP - INTERVAL '7 days'
+ORDER BY popularity DESC;
+
+CREATE OR REPLACE VIEW recent_searches AS
+SELECT
+    query,
+    COUNT(*) as search_count,
+    MAX(created_at) as last_searched
+FROM search_history
+WHERE created_at > CURRENT_TIMESTAMP - INTERVAL '30 days'
+GROUP BY query
+ORDER BY search_count DESC, last_searched DESC;
@@ -0,0 +1,7 @@
+SET LOCAL search_path TO movieapi, public;
+
+ALTER TABLE tv_shows DROP COLUMN IF EXISTS details_cached_at;
+ALTER TABLE tv_shows DROP COLUMN IF EXISTS details;
+
+ALTER TABLE movies DROP COLUMN IF EXISTS details_cached_at;
+ALTER TABLE movies DROP COLUMN IF EXISTS details;
@@ -0,0 +1,11 @@
+-- Full TMDb details for cached titles, so detail pages can be served from the
+-- database. details_cached_at tracks their freshness separately from cached_at,
+-- which list and search results refresh without fetching details.
+
+SET LOCAL search_path TO movieapi, public;
+
+ALTER TABLE movies ADD COLUMN IF NOT EXISTS details JSONB;
+ALTER TABLE movies ADD COLUMN IF NOT EXISTS details_cached_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE tv_shows ADD COLUMN IF NOT EXISTS details JSONB;
+ALTER TABLE tv_shows ADD COLUMN IF NOT EXISTS details_cached_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,4 @@
+SET LOCAL search_path TO movieapi, public;
+
+DROP TABLE IF EXISTS watchlist_items;
+DROP TABLE IF EXISTS watchlists;
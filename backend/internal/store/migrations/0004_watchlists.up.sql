@@ -0,0 +1,35 @@
+-- Named watchlists of movies and TV shows. Items keep a 1-based position for
+-- manual ordering; positions stay contiguous as items are added and removed.
+-- Public lists get a slug the first time they are shared, which is kept if the
+-- list is made private again so that old links work when it is re-shared.
+
+SET LOCAL search_path TO movieapi, public;
+
+CREATE TABLE IF NOT EXISTS watchlists (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    is_public BOOLEAN NOT NULL DEFAULT false,
+    slug VARCHAR(64) UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(user_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS watchlist_items (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    watchlist_id UUID NOT NULL REFERENCES watchlists(id) ON DELETE CASCADE,
+    media_type VARCHAR(10) NOT NULL CHECK (media_type IN ('movie', 'tv')),
+    media_id INTEGER NOT NULL,
+    position INTEGER NOT NULL CHECK (position > 0),
+    note TEXT NOT NULL DEFAULT '',
+    added_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(watchlist_id, media_type, media_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_watchlists_user_id ON watchlists(user_id);
+CREATE INDEX IF NOT EXISTS idx_watchlist_items_position ON watchlist_items(watchlist_id, position);
+
+CREATE OR REPLACE TRIGGER update_watchlists_updated_at BEFORE UPDATE ON watchlists
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
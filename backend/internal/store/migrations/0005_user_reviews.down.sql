@@ -0,0 +1,3 @@
+SET LOCAL search_path TO movieapi, public;
+
+DROP TABLE IF EXISTS user_reviews;
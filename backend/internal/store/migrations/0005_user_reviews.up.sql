@@ -0,0 +1,27 @@
+-- Reviews written by users of this service, one per user per title. Ratings use
+-- TMDb's 1-10 scale. Every review is approved until moderation is introduced.
+
+SET LOCAL search_path TO movieapi, public;
+
+CREATE TABLE IF NOT EXISTS user_reviews (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    media_type VARCHAR(10) NOT NULL CHECK (media_type IN ('movie', 'tv')),
+    media_id INTEGER NOT NULL,
+    title VARCHAR(200) NOT NULL,
+    content TEXT NOT NULL,
+    rating DECIMAL(3,1) NOT NULL CHECK (rating BETWEEN 1 AND 10),
+    spoiler BOOLEAN NOT NULL DEFAULT false,
+    helpful INTEGER NOT NULL DEFAULT 0,
+    not_helpful INTEGER NOT NULL DEFAULT 0,
+    status VARCHAR(10) NOT NULL DEFAULT 'approved' CHECK (status IN ('pending', 'approved', 'rejected')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(user_id, media_type, media_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_reviews_media ON user_reviews(media_type, media_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_user_reviews_user_id ON user_reviews(user_id, created_at DESC);
+
+CREATE OR REPLACE TRIGGER update_user_reviews_updated_at BEFORE UPDATE ON user_reviews
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,12 @@
+SET LOCAL search_path TO movieapi, public;
+
+DROP TABLE IF EXISTS user_notifications;
+DROP TABLE IF EXISTS review_moderation_log;
+DROP INDEX IF EXISTS idx_user_reviews_queue;
+
+ALTER TABLE user_reviews DROP COLUMN IF EXISTS moderation_reason;
+ALTER TABLE user_reviews DROP COLUMN IF EXISTS flag_reasons;
+ALTER TABLE user_reviews DROP COLUMN IF EXISTS flagged;
+ALTER TABLE user_reviews ALTER COLUMN status SET DEFAULT 'approved';
+
+ALTER TABLE users DROP COLUMN IF EXISTS is_moderator;
@@ -0,0 +1,42 @@
+-- Review moderation. New reviews wait in a queue as pending unless their author is
+-- trusted; pre-screening flags suspicious ones so they are handled first. Every
+-- moderator decision is kept in an audit log and sent to the author as a
+-- notification. Moderators are users with is_moderator set, which is granted
+-- directly in the database.
+
+SET LOCAL search_path TO movieapi, public;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_moderator BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE user_reviews ALTER COLUMN status SET DEFAULT 'pending';
+ALTER TABLE user_reviews ADD COLUMN IF NOT EXISTS flagged BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE user_reviews ADD COLUMN IF NOT EXISTS flag_reasons TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE user_reviews ADD COLUMN IF NOT EXISTS moderation_reason VARCHAR(500) NOT NULL DEFAULT '';
+
+-- Flagged reviews first, then oldest first
+CREATE INDEX IF NOT EXISTS idx_user_reviews_queue ON user_reviews(flagged DESC, created_at)
+    WHERE status = 'pending';
+
+-- Entries outlive the reviews and moderators they refer to
+CREATE TABLE IF NOT EXISTS review_moderation_log (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    review_id UUID NOT NULL,
+    moderator_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    action VARCHAR(10) NOT NULL CHECK (action IN ('approve', 'reject')),
+    reason VARCHAR(500) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_review_moderation_log_review_id ON review_moderation_log(review_id, created_at);
+
+CREATE TABLE IF NOT EXISTS user_notifications (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    message TEXT NOT NULL,
+    review_id UUID,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    read_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_notifications_user_id ON user_notifications(user_id, created_at DESC);
@@ -0,0 +1,6 @@
+SET LOCAL search_path TO movieapi, public;
+
+CREATE OR REPLACE TRIGGER update_user_reviews_updated_at BEFORE UPDATE ON user_reviews
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+DROP TABLE IF EXISTS review_votes;
@@ -0,0 +1,23 @@
+-- Helpfulness votes on user reviews, one per user per review. The helpful and
+-- not_helpful counters on user_reviews are kept in step with this table by the
+-- application, inside the transaction that records the vote.
+
+SET LOCAL search_path TO movieapi, public;
+
+CREATE TABLE IF NOT EXISTS review_votes (
+    review_id UUID NOT NULL REFERENCES user_reviews(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    helpful BOOLEAN NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (review_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_review_votes_user_id ON review_votes(user_id);
+
+CREATE OR REPLACE TRIGGER update_review_votes_updated_at BEFORE UPDATE ON review_votes
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- updated_at tracks the author's edits, so votes and moderation leave it alone
+CREATE OR REPLACE TRIGGER update_user_reviews_updated_at BEFORE UPDATE OF title, content, rating, spoiler ON user_reviews
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,88 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+)
+
+// NotificationRepository reads the user_notifications table. Notifications are
+// written by the operations that cause them, such as UserReviewRepository.Moderate.
+type NotificationRepository interface {
+	// List returns a page of a user's notifications, newest first, the number of
+	// notifications in total and the number still unread
+	List(ctx context.Context, userID string, offset, limit int) (notifications []models.Notification, total, unread int, err error)
+	// MarkRead marks a user's notification as read, or returns ErrNotFound
+	MarkRead(ctx context.Context, userID, id string) error
+}
+
+// notificationRepository is the PostgreSQL NotificationRepository
+type notificationRepository struct {
+	db *sql.DB
+}
+
+// NewNotificationRepository creates a NotificationRepository on db
+func NewNotificationRepository(db *sql.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+// List returns a user's notifications
+func (r *notificationRepository) List(ctx context.Context, userID string, offset, limit int) ([]models.Notification, int, int, error) {
+	var total, unread int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT count(*), count(*) FILTER (WHERE read_at IS NULL)
+		FROM user_notifications WHERE user_id = $1`, userID).Scan(&total, &unread)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("list notifications: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, user_id, type, message, review_id, created_at, read_at
+		FROM user_notifications WHERE user_id = $1
+		ORDER BY created_at DESC, id
+		OFFSET $2 LIMIT $3`, userID, offset, limit)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("list notifications: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := []models.Notification{}
+	for rows.Next() {
+		var n models.Notification
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Type, &n.Message, &n.ReviewID, &n.CreatedAt, &n.ReadAt); err != nil {
+			return nil, 0, 0, fmt.Errorf("scan notification: %w", err)
+		}
+		notifications = append(notifications, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, 0, fmt.Errorf("list notifications: %w", err)
+	}
+	return notifications, total, unread, nil
+}
+
+// MarkRead marks a notification as read. Marking it again keeps the first read time.
+func (r *notificationRepository) MarkRead(ctx context.Context, userID, id string) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE user_notifications SET read_at = COALESCE(read_at, CURRENT_TIMESTAMP)
+		WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return fmt.Errorf("mark notification read: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// insertNotification sends notification to its user within tx, filling in its ID
+// and creation time
+func insertNotification(ctx context.Context, tx *sql.Tx, notification *models.Notification) error {
+	return tx.QueryRowContext(ctx, `
+		INSERT INTO user_notifications (user_id, type, message, review_id)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`,
+		notification.UserID, notification.Type, notification.Message, notification.ReviewID,
+	).Scan(&notification.ID, &notification.CreatedAt)
+}
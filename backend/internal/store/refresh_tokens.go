@@ -0,0 +1,91 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+)
+
+// RefreshTokenRepository reads and writes the refresh_tokens table
+type RefreshTokenRepository interface {
+	// Create inserts token, filling in its ID and CreatedAt. A token without a
+	// FamilyID starts a new family.
+	Create(ctx context.Context, token *models.RefreshToken) error
+	// GetByHash returns the token with the given hash, or ErrNotFound
+	GetByHash(ctx context.Context, hash string) (*models.RefreshToken, error)
+	// Revoke revokes a token. It returns false if the token was already revoked,
+	// so that only one of several concurrent uses of a token succeeds.
+	Revoke(ctx context.Context, id string) (bool, error)
+	// RevokeFamily revokes every token in a family
+	RevokeFamily(ctx context.Context, familyID string) error
+}
+
+// refreshTokenRepository is the PostgreSQL RefreshTokenRepository
+type refreshTokenRepository struct {
+	db *sql.DB
+}
+
+// NewRefreshTokenRepository creates a RefreshTokenRepository on db
+func NewRefreshTokenRepository(db *sql.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{db: db}
+}
+
+// Create inserts a new refresh token
+func (r *refreshTokenRepository) Create(ctx context.Context, token *models.RefreshToken) error {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at)
+		VALUES ($1, COALESCE(NULLIF($2, '')::uuid, uuid_generate_v4()), $3, $4)
+		RETURNING id, family_id, created_at`,
+		token.UserID, token.FamilyID, token.TokenHash, token.ExpiresAt,
+	).Scan(&token.ID, &token.FamilyID, &token.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("create refresh token: %w", err)
+	}
+	return nil
+}
+
+// GetByHash returns a refresh token by its hash
+func (r *refreshTokenRepository) GetByHash(ctx context.Context, hash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, user_id, family_id, token_hash, expires_at, revoked_at, created_at
+		FROM refresh_tokens WHERE token_hash = $1`, hash,
+	).Scan(&token.ID, &token.UserID, &token.FamilyID, &token.TokenHash, &token.ExpiresAt,
+		&token.RevokedAt, &token.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get refresh token: %w", err)
+	}
+	return &token, nil
+}
+
+// Revoke revokes a refresh token if it is not already revoked
+func (r *refreshTokenRepository) Revoke(ctx context.Context, id string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND revoked_at IS NULL`, id)
+	if err != nil {
+		return false, fmt.Errorf("revoke refresh token: %w", err)
+	}
+	revoked, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("revoke refresh token: %w", err)
+	}
+	return revoked == 1, nil
+}
+
+// RevokeFamily revokes all unrevoked tokens in a family
+func (r *refreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP
+		WHERE family_id = $1 AND revoked_at IS NULL`, familyID)
+	if err != nil {
+		return fmt.Errorf("revoke refresh token family: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+)
+
+// Volume intervals accepted by SearchHistoryRepository.Volume
+const (
+	IntervalHour = "hour"
+	IntervalDay  = "day"
+	IntervalWeek = "week"
+)
+
+// PopularQueryMinClients is the number of distinct users or, for anonymous
+// searches, IP addresses that must have searched a query before PopularQueries
+// returns it, so that one client's searches are never suggested to others
+const PopularQueryMinClients = 3
+
+// SearchHistoryRepository reads and writes the search_history table
+type SearchHistoryRepository interface {
+	// RecordSearch inserts a search into the history
+	RecordSearch(ctx context.Context, entry models.SearchHistoryEntry) error
+	// TopQueries returns the most searched queries since the given time
+	TopQueries(ctx context.Context, since time.Time, limit int) ([]models.QueryStat, error)
+	// ZeroResultQueries returns the most searched queries since the given time that found nothing
+	ZeroResultQueries(ctx context.Context, since time.Time, limit int) ([]models.QueryStat, error)
+	// Volume returns search counts since the given time, bucketed by interval
+	Volume(ctx context.Context, since time.Time, interval string) ([]models.VolumeBucket, error)
+	// PopularQueries returns recent successful queries starting with prefix that
+	// at least PopularQueryMinClients clients searched
+	PopularQueries(ctx context.Context, prefix string, limit int) ([]models.HistoryQuery, error)
+}
+
+// searchHistoryRepository is the PostgreSQL SearchHistoryRepository
+type searchHistoryRepository struct {
+	db *sql.DB
+}
+
+// NewSearchHistoryRepository creates a SearchHistoryRepository on db
+func NewSearchHistoryRepository(db *sql.DB) SearchHistoryRepository {
+	return &searchHistoryRepository{db: db}
+}
+
+// RecordSearch inserts a search into the history
+func (r *searchHistoryRepository) RecordSearch(ctx context.Context, entry models.SearchHistoryEntry) error {
+	createdAt := entry.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO search_history (user_id, query, search_type, results_count, ip_address, user_agent, created_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, '')::inet, NULLIF($6, ''), $7)`,
+		entry.UserID, truncate(entry.Query, 255), entry.SearchType, entry.ResultsCount,
+		entry.IPAddress, entry.UserAgent, createdAt)
+	if err != nil {
+		return fmt.Errorf("record search: %w", err)
+	}
+	return nil
+}
+
+// TopQueries returns the most searched queries since the given time. Queries are
+// grouped case-insensitively.
+func (r *searchHistoryRepository) TopQueries(ctx context.Context, since time.Time, limit int) ([]models.QueryStat, error) {
+	return r.queryStats(ctx, `
+		SELECT lower(query), COUNT(*), AVG(results_count), MAX(created_at)
+		FROM search_history
+		WHERE created_at >= $1
+		GROUP BY lower(query)
+		ORDER BY COUNT(*) DESC, MAX(created_at) DESC
+		LIMIT $2`, since, limit)
+}
+
+// ZeroResultQueries returns the most searched queries since the given time that
+// returned no results
+func (r *searchHistoryRepository) ZeroResultQueries(ctx context.Context, since time.Time, limit int) ([]models.QueryStat, error) {
+	return r.queryStats(ctx, `
+		SELECT lower(query), COUNT(*), 0, MAX(created_at)
+		FROM search_history
+		WHERE created_at >= $1 AND results_count = 0
+		GROUP BY lower(query)
+		ORDER BY COUNT(*) DESC, MAX(created_at) DESC
+		LIMIT $2`, since, limit)
+}
+
+// Volume returns search counts since the given time, bucketed by interval
+// (IntervalHour, IntervalDay or IntervalWeek)
+func (r *searchHistoryRepository) Volume(ctx context.Context, since time.Time, interval string) ([]models.VolumeBucket, error) {
+	switch interval {
+	case IntervalHour, IntervalDay, IntervalWeek:
+	default:
+		return nil, fmt.Errorf("invalid volume interval %q", interval)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT date_trunc($2, created_at) AS bucket,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE results_count = 0),
+			COUNT(DISTINCT lower(query))
+		FROM search_history
+		WHERE created_at >= $1
+		GROUP BY bucket
+		ORDER BY bucket`, since, interval)
+	if err != nil {
+		return nil, fmt.Errorf("query search volume: %w", err)
+	}
+	defer rows.Close()
+
+	buckets := []models.VolumeBucket{}
+	for rows.Next() {
+		var b models.VolumeBucket
+		if err := rows.Scan(&b.Bucket, &b.Searches, &b.ZeroResults, &b.UniqueQueries); err != nil {
+			return nil, fmt.Errorf("scan search volume: %w", err)
+		}
+		buckets = append(buckets, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query search volume: %w", err)
+	}
+	return buckets, nil
+}
+
+// PopularQueries returns the most searched queries of the last 30 days that start
+// with prefix, found results and were searched by at least PopularQueryMinClients
+// users or IP addresses, for search suggestions. Searches by neither a known user
+// nor a known address count towards no client.
+func (r *searchHistoryRepository) PopularQueries(ctx context.Context, prefix string, limit int) ([]models.HistoryQuery, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT lower(query), COUNT(*)
+		FROM search_history
+		WHERE created_at > CURRENT_TIMESTAMP - INTERVAL '30 days'
+			AND lower(query) LIKE $1 || '%' ESCAPE '\'
+			AND results_count > 0
+		GROUP BY lower(query)
+		HAVING COUNT(DISTINCT COALESCE(user_id::text, host(ip_address))) >= $3
+		ORDER BY COUNT(*) DESC
+		LIMIT $2`, escapeLike(strings.ToLower(prefix)), limit, PopularQueryMinClients)
+	if err != nil {
+		return nil, fmt.Errorf("query popular searches: %w", err)
+	}
+	defer rows.Close()
+
+	var queries []models.HistoryQuery
+	for rows.Next() {
+		var q models.HistoryQuery
+		if err := rows.Scan(&q.Query, &q.Count); err != nil {
+			return nil, fmt.Errorf("scan popular searches: %w", err)
+		}
+		queries = append(queries, q)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query popular searches: %w", err)
+	}
+	return queries, nil
+}
+
+// queryStats runs a query returning (query, count, average results, last searched) rows
+func (r *searchHistoryRepository) queryStats(ctx context.Context, query string, args ...interface{}) ([]models.QueryStat, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query search stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []models.QueryStat{}
+	for rows.Next() {
+		var s models.QueryStat
+		if err := rows.Scan(&s.Query, &s.SearchCount, &s.AverageResults, &s.LastSearched); err != nil {
+			return nil, fmt.Errorf("scan search stats: %w", err)
+		}
+		stats = append(stats, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query search stats: %w", err)
+	}
+	return stats, nil
+}
+
+// escapeLike escapes LIKE wildcards so s matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// truncate shortens s to at most n runes
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
@@ -0,0 +1,26 @@
+package store
+
+import "testing"
+
+func TestEscapeLike(t *testing.T) {
+	tests := map[string]string{
+		"matrix":    "matrix",
+		"100%":      `100\%`,
+		"snake_eye": `snake\_eye`,
+		`back\`:     `back\\`,
+	}
+	for input, expected := range tests {
+		if got := escapeLike(input); got != expected {
+			t.Errorf("escapeLike(%q) = %q, expected %q", input, got, expected)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := truncate("千と千尋の神隠し", 3); got != "千と千" {
+		t.Errorf("Expected truncation by runes, got %q", got)
+	}
+	if got := truncate("matrix", 255); got != "matrix" {
+		t.Errorf("Expected short string unchanged, got %q", got)
+	}
+}
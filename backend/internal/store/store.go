@@ -0,0 +1,150 @@
+// Package store provides PostgreSQL persistence for the Movie API service.
+//
+// Open creates a pooled connection from config.DatabaseConfig, MigrateUp brings
+// the schema up to date from the embedded migrations, and Store groups the
+// repositories for each table behind interfaces so callers can substitute fakes.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/takeshi-arihori/movie-api/internal/config"
+)
+
+const (
+	// connectTimeout bounds the initial connectivity check in Open
+	connectTimeout = 5 * time.Second
+	// pingTimeout bounds a health check ping
+	pingTimeout = 2 * time.Second
+)
+
+var (
+	// ErrNotFound is returned when a requested row does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is returned when a write violates a uniqueness constraint
+	ErrConflict = errors.New("already exists")
+	// ErrLimitExceeded is returned when a write would take a collection past its
+	// size limit
+	ErrLimitExceeded = errors.New("limit exceeded")
+)
+
+// Store groups the repositories backed by a single database pool
+type Store struct {
+	db *sql.DB
+
+	Users         UserRepository
+	RefreshTokens RefreshTokenRepository
+	Favorites     FavoriteRepository
+	Watchlists    WatchlistRepository
+	UserReviews   UserReviewRepository
+	Notifications NotificationRepository
+	Titles        TitleRepository
+	SearchHistory SearchHistoryRepository
+}
+
+// Open connects to the database described by cfg, applies its pool settings and
+// verifies the connection
+func Open(cfg config.DatabaseConfig) (*sql.DB, error) {
+	db, err := sql.Open("postgres", cfg.DSN())
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetime) * time.Second)
+	db.SetConnMaxIdleTime(time.Duration(cfg.ConnMaxIdleTime) * time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), connectTimeout)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("connect to database %s:%d: %w", cfg.Host, cfg.Port, err)
+	}
+
+	return db, nil
+}
+
+// New creates a Store with PostgreSQL repositories on db
+func New(db *sql.DB) *Store {
+	return &Store{
+		db:            db,
+		Users:         NewUserRepository(db),
+		RefreshTokens: NewRefreshTokenRepository(db),
+		Favorites:     NewFavoriteRepository(db),
+		Watchlists:    NewWatchlistRepository(db),
+		UserReviews:   NewUserReviewRepository(db),
+		Notifications: NewNotificationRepository(db),
+		Titles:        NewTitleRepository(db),
+		SearchHistory: NewSearchHistoryRepository(db),
+	}
+}
+
+// Connect opens the database, applies pending migrations when cfg.AutoMigrate is
+// set, and returns a Store on it
+func Connect(ctx context.Context, cfg config.DatabaseConfig) (*Store, error) {
+	db, err := Open(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.AutoMigrate {
+		if _, err := MigrateUp(ctx, db); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("migrate database: %w", err)
+		}
+	}
+
+	return New(db), nil
+}
+
+// DB returns the underlying connection pool
+func (s *Store) DB() *sql.DB {
+	return s.db
+}
+
+// Ping checks that the database is reachable, for health checks
+func (s *Store) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+	return s.db.PingContext(ctx)
+}
+
+// Stats returns connection pool statistics
+func (s *Store) Stats() sql.DBStats {
+	return s.db.Stats()
+}
+
+// Close closes the connection pool
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// isUniqueViolation reports whether err is a PostgreSQL unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// inTx runs fn in a transaction, committing if it succeeds
+func inTx(ctx context.Context, db *sql.DB, op string, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
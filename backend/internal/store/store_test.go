@@ -0,0 +1,351 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+)
+
+// openTestDB connects to the database in TEST_DATABASE_DSN and migrates it, or
+// skips the test when no test database is configured
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN not set; skipping database test")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if _, err := MigrateUp(context.Background(), db); err != nil {
+		t.Fatalf("MigrateUp failed: %v", err)
+	}
+	return db
+}
+
+// TestRepositories exercises the PostgreSQL repositories against a real database
+func TestRepositories(t *testing.T) {
+	db := openTestDB(t)
+	s := New(db)
+	ctx := context.Background()
+
+	if err := s.Ping(ctx); err != nil {
+		t.Fatalf("Ping failed: %v", err)
+	}
+
+	// Users
+	suffix := time.Now().Format("150405.000000")
+	user := &models.User{Email: "user" + suffix + "@example.com", Username: "user" + suffix, PasswordHash: "hash", IsActive: true}
+	if err := s.Users.Create(ctx, user); err != nil {
+		t.Fatalf("Create user failed: %v", err)
+	}
+	t.Cleanup(func() { db.Exec(`DELETE FROM users WHERE id = $1`, user.ID) })
+
+	if err := s.Users.Create(ctx, &models.User{Email: user.Email, Username: "other" + suffix, PasswordHash: "hash"}); !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected ErrConflict for duplicate email, got %v", err)
+	}
+	found, err := s.Users.GetByEmail(ctx, "USER"+suffix+"@EXAMPLE.COM")
+	if err != nil || found.ID != user.ID {
+		t.Errorf("Expected case-insensitive email lookup, got %v %v", found, err)
+	}
+	if _, err := s.Users.GetByUsername(ctx, "missing"+suffix); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	// Refresh tokens
+	token := &models.RefreshToken{UserID: user.ID, TokenHash: fmt.Sprintf("%064s", suffix), ExpiresAt: time.Now().Add(time.Hour)}
+	if err := s.RefreshTokens.Create(ctx, token); err != nil {
+		t.Fatalf("Create refresh token failed: %v", err)
+	}
+	if token.FamilyID == "" {
+		t.Error("Expected a new token family")
+	}
+	if revoked, err := s.RefreshTokens.Revoke(ctx, token.ID); err != nil || !revoked {
+		t.Errorf("Expected the token to be revoked, got %v %v", revoked, err)
+	}
+	if revoked, err := s.RefreshTokens.Revoke(ctx, token.ID); err != nil || revoked {
+		t.Errorf("Expected a second revoke to be a no-op, got %v %v", revoked, err)
+	}
+	stored, err := s.RefreshTokens.GetByHash(ctx, token.TokenHash)
+	if err != nil || stored.RevokedAt == nil {
+		t.Errorf("Expected a revoked token, got %v %v", stored, err)
+	}
+
+	// Favorites
+	favorite := &models.Favorite{UserID: user.ID, MediaType: models.SearchItemTypeMovie, MediaID: 603}
+	if err := s.Favorites.Add(ctx, favorite); err != nil {
+		t.Fatalf("Add favorite failed: %v", err)
+	}
+	if err := s.Favorites.Add(ctx, &models.Favorite{UserID: user.ID, MediaType: models.SearchItemTypeMovie, MediaID: 603}); !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected ErrConflict for duplicate favorite, got %v", err)
+	}
+	if existing, err := s.Favorites.Get(ctx, user.ID, models.SearchItemTypeMovie, 603); err != nil || existing.ID != favorite.ID {
+		t.Errorf("Expected the added favorite, got %v %v", existing, err)
+	}
+	favorites, err := s.Favorites.List(ctx, user.ID)
+	if err != nil || len(favorites) != 1 {
+		t.Errorf("Expected 1 favorite, got %v %v", favorites, err)
+	}
+	if err := s.Favorites.Remove(ctx, user.ID, models.SearchItemTypeMovie, 603); err != nil {
+		t.Errorf("Remove favorite failed: %v", err)
+	}
+	if err := s.Favorites.Remove(ctx, user.ID, models.SearchItemTypeMovie, 603); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound removing twice, got %v", err)
+	}
+
+	// Watchlists
+	watchlist := &models.Watchlist{UserID: user.ID, Name: "Ghibli marathon", IsPublic: true}
+	if err := s.Watchlists.Create(ctx, watchlist); err != nil {
+		t.Fatalf("Create watchlist failed: %v", err)
+	}
+	if watchlist.Slug == nil {
+		t.Fatal("Expected a public watchlist to get a slug")
+	}
+	if err := s.Watchlists.Create(ctx, &models.Watchlist{UserID: user.ID, Name: "Ghibli marathon"}); !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected ErrConflict for a duplicate watchlist name, got %v", err)
+	}
+	var itemIDs []string
+	for _, id := range []int{129, 8392, 4935} {
+		item := &models.WatchlistItem{MediaType: models.SearchItemTypeMovie, MediaID: id}
+		if added, err := s.Watchlists.AddItem(ctx, watchlist.ID, item); err != nil || !added {
+			t.Fatalf("AddItem failed: %v %v", added, err)
+		}
+		itemIDs = append(itemIDs, item.ID)
+	}
+	if added, err := s.Watchlists.AddItem(ctx, watchlist.ID, &models.WatchlistItem{MediaType: models.SearchItemTypeMovie, MediaID: 129}); err != nil || added {
+		t.Errorf("Expected a duplicate item to be reported as existing, got %v %v", added, err)
+	}
+	if err := s.Watchlists.Reorder(ctx, watchlist.ID, []string{itemIDs[2], itemIDs[0], itemIDs[1]}); err != nil {
+		t.Fatalf("Reorder failed: %v", err)
+	}
+	if err := s.Watchlists.Reorder(ctx, watchlist.ID, []string{itemIDs[0], itemIDs[0], itemIDs[1]}); !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected ErrConflict for a duplicate ID in the order, got %v", err)
+	}
+	if err := s.Watchlists.RemoveItem(ctx, watchlist.ID, itemIDs[2]); err != nil {
+		t.Fatalf("RemoveItem failed: %v", err)
+	}
+	items, err := s.Watchlists.Items(ctx, watchlist.ID)
+	if err != nil || len(items) != 2 || items[0].ID != itemIDs[0] || items[0].Position != 1 || items[1].Position != 2 {
+		t.Errorf("Expected contiguous positions after reorder and removal, got %+v %v", items, err)
+	}
+	shared, err := s.Watchlists.GetPublic(ctx, *watchlist.Slug)
+	if err != nil || shared.ItemCount != 2 {
+		t.Errorf("Expected the public watchlist with 2 items, got %+v %v", shared, err)
+	}
+	watchlist.IsPublic = false
+	if err := s.Watchlists.Update(ctx, watchlist); err != nil {
+		t.Fatalf("Update watchlist failed: %v", err)
+	}
+	if _, err := s.Watchlists.GetPublic(ctx, *watchlist.Slug); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected a private watchlist to be hidden, got %v", err)
+	}
+
+	// User reviews
+	review := &models.UserReview{UserID: user.ID, MediaType: "movie", MediaID: 129, Title: "Spirited", Content: "Still magical.", Rating: 9.5,
+		Status: models.ReviewStatusPending, Flagged: true, FlagReasons: []string{"link_spam"}}
+	if err := s.UserReviews.Create(ctx, review); err != nil {
+		t.Fatalf("Create review failed: %v", err)
+	}
+	if err := s.UserReviews.Create(ctx, &models.UserReview{UserID: user.ID, MediaType: "movie", MediaID: 129, Title: "Again", Content: "Again.", Rating: 8, Status: models.ReviewStatusPending}); !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected ErrConflict for a second review of a title, got %v", err)
+	}
+	review.Rating = 10
+	previous, err := s.UserReviews.Update(ctx, review)
+	if err != nil || previous.Rating != 9.5 {
+		t.Fatalf("Update review failed: %+v %v", previous, err)
+	}
+	if _, err := s.UserReviews.Update(ctx, &models.UserReview{ID: review.ID, UserID: "00000000-0000-0000-0000-000000000000", Title: "x", Content: "x", Rating: 1}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound updating another user's review, got %v", err)
+	}
+	reviews, total, err := s.UserReviews.ListByUser(ctx, user.ID, 0, 10)
+	if err != nil || total != 1 || len(reviews) != 1 || reviews[0].Rating != 10 {
+		t.Errorf("Expected the updated review, got %+v %d %v", reviews, total, err)
+	}
+	if queue, total, err := s.UserReviews.Queue(ctx, 0, 100); err != nil || total < 1 || len(queue) < 1 || !queue[0].Flagged {
+		t.Errorf("Expected a flagged review at the front of the queue, got %d of %d, %v", len(queue), total, err)
+	}
+	entry := &models.ReviewModerationEntry{ReviewID: review.ID, ModeratorID: user.ID, Action: models.ReviewActionApprove, Reason: "Fine"}
+	notification := &models.Notification{Type: models.NotificationReviewApproved, Message: "Approved", ReviewID: &review.ID}
+	previous, moderated, err := s.UserReviews.Moderate(ctx, entry, notification)
+	if err != nil || previous.Status != models.ReviewStatusPending || moderated.Status != models.ReviewStatusApproved || entry.ID == "" || notification.ID == "" {
+		t.Fatalf("Moderate failed: %+v %+v %v", previous, moderated, err)
+	}
+	if history, err := s.UserReviews.History(ctx, review.ID); err != nil || len(history) != 1 || history[0].Action != models.ReviewActionApprove {
+		t.Errorf("Expected the approval in the audit log, got %+v %v", history, err)
+	}
+	if counts, err := s.UserReviews.StatusCounts(ctx, user.ID); err != nil || counts[models.ReviewStatusApproved] != 1 {
+		t.Errorf("Expected 1 approved review, got %v %v", counts, err)
+	}
+	notifications, total, unread, err := s.Notifications.List(ctx, user.ID, 0, 10)
+	if err != nil || total != 1 || unread != 1 || notifications[0].ID != notification.ID {
+		t.Fatalf("Expected the moderation notification, got %+v %d %d %v", notifications, total, unread, err)
+	}
+	if err := s.Notifications.MarkRead(ctx, user.ID, notification.ID); err != nil {
+		t.Errorf("MarkRead failed: %v", err)
+	}
+	if _, _, unread, err := s.Notifications.List(ctx, user.ID, 0, 10); err != nil || unread != 0 {
+		t.Errorf("Expected no unread notifications, got %d %v", unread, err)
+	}
+	voter := &models.User{Email: "voter" + suffix + "@example.com", Username: "voter" + suffix, PasswordHash: "hash", IsActive: true}
+	if err := s.Users.Create(ctx, voter); err != nil {
+		t.Fatalf("Create voter failed: %v", err)
+	}
+	t.Cleanup(func() { db.Exec(`DELETE FROM users WHERE id = $1`, voter.ID) })
+	helpful, notHelpful := true, false
+	if vote, err := s.UserReviews.Vote(ctx, review.ID, voter.ID, &helpful); err != nil || vote.Helpful != 1 || vote.UserAction != models.ReviewVoteHelpful {
+		t.Errorf("Expected a helpful vote, got %+v %v", vote, err)
+	}
+	if vote, err := s.UserReviews.Vote(ctx, review.ID, voter.ID, &notHelpful); err != nil || vote.Helpful != 0 || vote.NotHelpful != 1 {
+		t.Errorf("Expected the vote to switch to not helpful, got %+v %v", vote, err)
+	}
+	if vote, err := s.UserReviews.Vote(ctx, review.ID, voter.ID, nil); err != nil || vote.NotHelpful != 0 || vote.UserAction != models.ReviewVoteRemoved {
+		t.Errorf("Expected the vote to be removed, got %+v %v", vote, err)
+	}
+	if _, err := s.UserReviews.Vote(ctx, "00000000-0000-0000-0000-000000000000", voter.ID, &helpful); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound voting on a missing review, got %v", err)
+	}
+	// An even number of concurrent toggles must leave no vote and zero counters
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.UserReviews.Vote(ctx, review.ID, voter.ID, &helpful); err != nil {
+				t.Errorf("Concurrent vote failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if voted, err := s.UserReviews.Get(ctx, review.ID); err != nil || voted.Helpful != 0 || voted.NotHelpful != 0 {
+		t.Errorf("Expected the counters to match the toggles, got %+v %v", voted, err)
+	}
+	if reviews, total, err := s.UserReviews.ListByTitle(ctx, "movie", 129, "helpful", "desc", 0, 100); err != nil || total < 1 || len(reviews) < 1 {
+		t.Errorf("Expected the title's reviews sorted by helpfulness, got %d of %d, %v", len(reviews), total, err)
+	}
+	mediaID, minRating, approved := 129, 9.0, models.ReviewStatusApproved
+	filter := models.ReviewFilter{MediaID: &mediaID, UserID: &user.ID, MinRating: &minRating, Status: &approved, SortBy: "rating", Page: 1, Limit: 10}
+	if reviews, total, err := s.UserReviews.List(ctx, filter); err != nil || total != 1 || len(reviews) != 1 || reviews[0].ID != review.ID {
+		t.Errorf("Expected the filter to match the review, got %d of %d, %v", len(reviews), total, err)
+	}
+	minRating = 10.5
+	if reviews, total, err := s.UserReviews.List(ctx, filter); err != nil || total != 0 || len(reviews) != 0 {
+		t.Errorf("Expected no reviews rated above 10, got %d of %d, %v", len(reviews), total, err)
+	}
+	if ratings, err := s.UserReviews.ApprovedRatings(ctx, "movie", 129); err != nil || len(ratings) < 1 {
+		t.Errorf("Expected the approved review's rating, got %v %v", ratings, err)
+	}
+	if reviews, total, err := s.UserReviews.ListByTitle(ctx, "movie", 129, "", "", 0, 100); err != nil || total < 1 || len(reviews) < 1 {
+		t.Errorf("Expected the title's reviews to include the approved review, got %d of %d, %v", len(reviews), total, err)
+	}
+	if deleted, err := s.UserReviews.Delete(ctx, user.ID, review.ID); err != nil || deleted.Status != models.ReviewStatusApproved {
+		t.Errorf("Delete review failed: %+v %v", deleted, err)
+	}
+	if _, err := s.UserReviews.Get(ctx, review.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after delete, got %v", err)
+	}
+
+	// Titles
+	releaseDate := "1999-03-31"
+	movie := models.Movie{ID: 603, Title: "The Matrix", OriginalTitle: "The Matrix", ReleaseDate: &releaseDate,
+		GenreIDs: []int{28, 878}, OriginalLanguage: "en", VoteAverage: 8.2, VoteCount: 25000, Popularity: 80.5}
+	if err := s.Titles.UpsertMovies(ctx, []models.Movie{movie}); err != nil {
+		t.Fatalf("UpsertMovies failed: %v", err)
+	}
+	cached, err := s.Titles.GetMovie(ctx, 603)
+	if err != nil {
+		t.Fatalf("GetMovie failed: %v", err)
+	}
+	if cached.Movie.Title != "The Matrix" || cached.Movie.ReleaseDate == nil || *cached.Movie.ReleaseDate != releaseDate || len(cached.Movie.GenreIDs) != 2 {
+		t.Errorf("Unexpected cached movie: %+v", cached.Movie)
+	}
+	if _, err := s.Titles.GetTVShow(ctx, -1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for missing show, got %v", err)
+	}
+	if _, err := s.Titles.GetMovieDetails(ctx, 603); err != nil && !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetMovieDetails failed: %v", err)
+	}
+	tagline := "Welcome to the Real World."
+	details := models.MovieDetails{ID: 603, Title: "The Matrix", ReleaseDate: &releaseDate, Tagline: &tagline,
+		Genres: []models.Genre{{ID: 28, Name: "Action"}}}
+	if err := s.Titles.UpsertMovieDetails(ctx, &details); err != nil {
+		t.Fatalf("UpsertMovieDetails failed: %v", err)
+	}
+	cachedDetails, err := s.Titles.GetMovieDetails(ctx, 603)
+	if err != nil {
+		t.Fatalf("GetMovieDetails failed: %v", err)
+	}
+	if cachedDetails.Details.Tagline == nil || *cachedDetails.Details.Tagline != tagline {
+		t.Errorf("Unexpected cached details: %+v", cachedDetails.Details)
+	}
+	matches, err := s.Titles.SearchTitles(ctx, models.SearchOptions{Query: "matrix", Type: "all"})
+	if err != nil {
+		t.Fatalf("SearchTitles failed: %v", err)
+	}
+	if matches.TotalResults == 0 {
+		t.Error("Expected the cached movie to match a catalogue search")
+	}
+	listed, err := s.Titles.ListMovies(ctx, OrderPopularity, 1)
+	if err != nil {
+		t.Fatalf("ListMovies failed: %v", err)
+	}
+	if listed.TotalResults == 0 || len(listed.Results) == 0 {
+		t.Error("Expected the cached movie to be listed")
+	}
+
+	// Search history
+	query := "matrix " + suffix
+	if err := s.SearchHistory.RecordSearch(ctx, models.SearchHistoryEntry{Query: query, SearchType: "movie", ResultsCount: 0, IPAddress: "203.0.113.7"}); err != nil {
+		t.Fatalf("RecordSearch failed: %v", err)
+	}
+	t.Cleanup(func() { db.Exec(`DELETE FROM search_history WHERE query = $1`, query) })
+
+	zero, err := s.SearchHistory.ZeroResultQueries(ctx, time.Now().Add(-time.Hour), 100)
+	if err != nil {
+		t.Fatalf("ZeroResultQueries failed: %v", err)
+	}
+	foundQuery := false
+	for _, stat := range zero {
+		foundQuery = foundQuery || stat.Query == query
+	}
+	if !foundQuery {
+		t.Errorf("Expected %q among zero-result queries", query)
+	}
+
+	// A query is only suggested once enough clients have searched it
+	popular := "inception " + suffix
+	t.Cleanup(func() { db.Exec(`DELETE FROM search_history WHERE query = $1`, popular) })
+	suggested := func() bool {
+		t.Helper()
+		queries, err := s.SearchHistory.PopularQueries(ctx, popular, 10)
+		if err != nil {
+			t.Fatalf("PopularQueries failed: %v", err)
+		}
+		return len(queries) == 1 && queries[0].Query == popular
+	}
+	// The first client searches twice, which counts once
+	for _, ip := range []string{"203.0.113.1", "203.0.113.1", "203.0.113.2"} {
+		if err := s.SearchHistory.RecordSearch(ctx, models.SearchHistoryEntry{Query: popular, SearchType: "movie", ResultsCount: 1, IPAddress: ip}); err != nil {
+			t.Fatalf("RecordSearch failed: %v", err)
+		}
+	}
+	if suggested() {
+		t.Errorf("Expected %q not to be suggested before %d clients searched it", popular, PopularQueryMinClients)
+	}
+	if err := s.SearchHistory.RecordSearch(ctx, models.SearchHistoryEntry{Query: popular, SearchType: "movie", ResultsCount: 1, IPAddress: "203.0.113.3"}); err != nil {
+		t.Fatalf("RecordSearch failed: %v", err)
+	}
+	if !suggested() {
+		t.Errorf("Expected %q to be suggested once %d clients searched it", popular, PopularQueryMinClients)
+	}
+}
@@ -0,0 +1,392 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+)
+
+// CachedMovie is a movie from the movies cache table
+type CachedMovie struct {
+	Movie    models.Movie
+	CachedAt time.Time
+}
+
+// CachedTVShow is a TV show from the tv_shows cache table
+type CachedTVShow struct {
+	TVShow   models.TVShow
+	CachedAt time.Time
+}
+
+// CachedMovieDetails is a movie's full details from the movies cache table
+type CachedMovieDetails struct {
+	Details  models.MovieDetails
+	CachedAt time.Time
+}
+
+// CachedTVShowDetails is a TV show's full details from the tv_shows cache table
+type CachedTVShowDetails struct {
+	Details  models.TVShowDetails
+	CachedAt time.Time
+}
+
+// TitleRepository reads and writes the movies and tv_shows cache tables
+type TitleRepository interface {
+	// UpsertMovies inserts or refreshes movies, resetting their cached_at
+	UpsertMovies(ctx context.Context, movies []models.Movie) error
+	// GetMovie returns a cached movie, or ErrNotFound
+	GetMovie(ctx context.Context, id int) (*CachedMovie, error)
+	// UpsertTVShows inserts or refreshes TV shows, resetting their cached_at
+	UpsertTVShows(ctx context.Context, shows []models.TVShow) error
+	// GetTVShow returns a cached TV show, or ErrNotFound
+	GetTVShow(ctx context.Context, id int) (*CachedTVShow, error)
+	// UpsertMovieDetails inserts or refreshes a movie with its full details,
+	// resetting both cached_at and details_cached_at
+	UpsertMovieDetails(ctx context.Context, details *models.MovieDetails) error
+	// GetMovieDetails returns a movie's cached details, or ErrNotFound if none
+	// have been stored
+	GetMovieDetails(ctx context.Context, id int) (*CachedMovieDetails, error)
+	// UpsertTVShowDetails inserts or refreshes a TV show with its full details,
+	// resetting both cached_at and details_cached_at
+	UpsertTVShowDetails(ctx context.Context, details *models.TVShowDetails) error
+	// GetTVShowDetails returns a TV show's cached details, or ErrNotFound if none
+	// have been stored
+	GetTVShowDetails(ctx context.Context, id int) (*CachedTVShowDetails, error)
+	// ListMovies returns a page of cached movies, for use when TMDb is unavailable
+	ListMovies(ctx context.Context, order TitleOrder, page int) (*models.SearchResponse[models.Movie], error)
+	// ListTVShows returns a page of cached TV shows, for use when TMDb is unavailable
+	ListTVShows(ctx context.Context, order TitleOrder, page int) (*models.SearchResponse[models.TVShow], error)
+	// SearchTitles searches cached movies and TV shows, for use when TMDb is unavailable
+	SearchTitles(ctx context.Context, opts models.SearchOptions) (*models.MultiSearchResponse, error)
+}
+
+// titleRepository is the PostgreSQL TitleRepository
+type titleRepository struct {
+	db *sql.DB
+}
+
+// NewTitleRepository creates a TitleRepository on db
+func NewTitleRepository(db *sql.DB) TitleRepository {
+	return &titleRepository{db: db}
+}
+
+// UpsertMovies inserts or updates movies in a single transaction
+func (r *titleRepository) UpsertMovies(ctx context.Context, movies []models.Movie) error {
+	return inTx(ctx, r.db, "upsert movies", func(tx *sql.Tx) error {
+		for _, m := range movies {
+			_, err := tx.ExecContext(ctx, `
+				INSERT INTO movies (id, title, original_title, overview, release_date, poster_path, backdrop_path,
+					vote_average, vote_count, popularity, adult, genre_ids, original_language, video, cached_at)
+				VALUES ($1, $2, $3, $4, NULLIF($5, '')::date, $6, $7, $8, $9, $10, $11, $12, $13, $14, CURRENT_TIMESTAMP)
+				ON CONFLICT (id) DO UPDATE SET
+					title = EXCLUDED.title,
+					original_title = EXCLUDED.original_title,
+					overview = EXCLUDED.overview,
+					release_date = EXCLUDED.release_date,
+					poster_path = EXCLUDED.poster_path,
+					backdrop_path = EXCLUDED.backdrop_path,
+					vote_average = EXCLUDED.vote_average,
+					vote_count = EXCLUDED.vote_count,
+					popularity = EXCLUDED.popularity,
+					adult = EXCLUDED.adult,
+					genre_ids = EXCLUDED.genre_ids,
+					original_language = EXCLUDED.original_language,
+					video = EXCLUDED.video,
+					cached_at = EXCLUDED.cached_at`,
+				m.ID, truncate(m.Title, 255), truncate(m.OriginalTitle, 255), m.Overview, m.ReleaseDate,
+				m.PosterPath, m.BackdropPath, m.VoteAverage, m.VoteCount, m.Popularity, m.Adult,
+				pq.Array(toInt64s(m.GenreIDs)), m.OriginalLanguage, m.Video)
+			if err != nil {
+				return fmt.Errorf("movie %d: %w", m.ID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetMovie returns a cached movie
+func (r *titleRepository) GetMovie(ctx context.Context, id int) (*CachedMovie, error) {
+	c, err := scanMovie(r.db.QueryRowContext(ctx, `SELECT `+movieColumns+` FROM movies WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get movie %d: %w", id, err)
+	}
+	return c, nil
+}
+
+// UpsertTVShows inserts or updates TV shows in a single transaction
+func (r *titleRepository) UpsertTVShows(ctx context.Context, shows []models.TVShow) error {
+	return inTx(ctx, r.db, "upsert TV shows", func(tx *sql.Tx) error {
+		for _, s := range shows {
+			_, err := tx.ExecContext(ctx, `
+				INSERT INTO tv_shows (id, name, original_name, overview, first_air_date, poster_path, backdrop_path,
+					vote_average, vote_count, popularity, genre_ids, original_language, origin_country, cached_at)
+				VALUES ($1, $2, $3, $4, NULLIF($5, '')::date, $6, $7, $8, $9, $10, $11, $12, $13, CURRENT_TIMESTAMP)
+				ON CONFLICT (id) DO UPDATE SET
+					name = EXCLUDED.name,
+					original_name = EXCLUDED.original_name,
+					overview = EXCLUDED.overview,
+					first_air_date = EXCLUDED.first_air_date,
+					poster_path = EXCLUDED.poster_path,
+					backdrop_path = EXCLUDED.backdrop_path,
+					vote_average = EXCLUDED.vote_average,
+					vote_count = EXCLUDED.vote_count,
+					popularity = EXCLUDED.popularity,
+					genre_ids = EXCLUDED.genre_ids,
+					original_language = EXCLUDED.original_language,
+					origin_country = EXCLUDED.origin_country,
+					cached_at = EXCLUDED.cached_at`,
+				s.ID, truncate(s.Name, 255), truncate(s.OriginalName, 255), s.Overview, s.FirstAirDate,
+				s.PosterPath, s.BackdropPath, s.VoteAverage, s.VoteCount, s.Popularity,
+				pq.Array(toInt64s(s.GenreIDs)), s.OriginalLanguage, pq.Array(s.OriginCountry))
+			if err != nil {
+				return fmt.Errorf("TV show %d: %w", s.ID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetTVShow returns a cached TV show
+func (r *titleRepository) GetTVShow(ctx context.Context, id int) (*CachedTVShow, error) {
+	c, err := scanTVShow(r.db.QueryRowContext(ctx, `SELECT `+tvShowColumns+` FROM tv_shows WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get TV show %d: %w", id, err)
+	}
+	return c, nil
+}
+
+// UpsertMovieDetails inserts or updates a movie's row and stored details
+func (r *titleRepository) UpsertMovieDetails(ctx context.Context, d *models.MovieDetails) error {
+	encoded, err := json.Marshal(d)
+	if err != nil {
+		return fmt.Errorf("upsert movie details %d: %w", d.ID, err)
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO movies (id, title, original_title, overview, release_date, poster_path, backdrop_path,
+			vote_average, vote_count, popularity, adult, genre_ids, original_language, video, cached_at,
+			details, details_cached_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, '')::date, $6, $7, $8, $9, $10, $11, $12, $13, $14, CURRENT_TIMESTAMP,
+			$15::jsonb, CURRENT_TIMESTAMP)
+		ON CONFLICT (id) DO UPDATE SET
+			title = EXCLUDED.title,
+			original_title = EXCLUDED.original_title,
+			overview = EXCLUDED.overview,
+			release_date = EXCLUDED.release_date,
+			poster_path = EXCLUDED.poster_path,
+			backdrop_path = EXCLUDED.backdrop_path,
+			vote_average = EXCLUDED.vote_average,
+			vote_count = EXCLUDED.vote_count,
+			popularity = EXCLUDED.popularity,
+			adult = EXCLUDED.adult,
+			genre_ids = EXCLUDED.genre_ids,
+			original_language = EXCLUDED.original_language,
+			video = EXCLUDED.video,
+			cached_at = EXCLUDED.cached_at,
+			details = EXCLUDED.details,
+			details_cached_at = EXCLUDED.details_cached_at`,
+		d.ID, truncate(d.Title, 255), truncate(d.OriginalTitle, 255), d.Overview, d.ReleaseDate,
+		d.PosterPath, d.BackdropPath, d.VoteAverage, d.VoteCount, d.Popularity, d.Adult,
+		pq.Array(genreIDs(d.Genres)), d.OriginalLanguage, d.Video, string(encoded))
+	if err != nil {
+		return fmt.Errorf("upsert movie details %d: %w", d.ID, err)
+	}
+	return nil
+}
+
+// GetMovieDetails returns a movie's stored details
+func (r *titleRepository) GetMovieDetails(ctx context.Context, id int) (*CachedMovieDetails, error) {
+	var c CachedMovieDetails
+	var encoded []byte
+	err := r.db.QueryRowContext(ctx, `
+		SELECT details, details_cached_at FROM movies
+		WHERE id = $1 AND details IS NOT NULL`, id,
+	).Scan(&encoded, &c.CachedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get movie details %d: %w", id, err)
+	}
+	if err := json.Unmarshal(encoded, &c.Details); err != nil {
+		return nil, fmt.Errorf("decode movie details %d: %w", id, err)
+	}
+	return &c, nil
+}
+
+// UpsertTVShowDetails inserts or updates a TV show's row and stored details
+func (r *titleRepository) UpsertTVShowDetails(ctx context.Context, d *models.TVShowDetails) error {
+	encoded, err := json.Marshal(d)
+	if err != nil {
+		return fmt.Errorf("upsert TV show details %d: %w", d.ID, err)
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO tv_shows (id, name, original_name, overview, first_air_date, last_air_date, poster_path,
+			backdrop_path, vote_average, vote_count, popularity, genre_ids, original_language, origin_country,
+			cached_at, details, details_cached_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, '')::date, NULLIF($6, '')::date, $7, $8, $9, $10, $11, $12, $13, $14,
+			CURRENT_TIMESTAMP, $15::jsonb, CURRENT_TIMESTAMP)
+		ON CONFLICT (id) DO UPDATE SET
+			name = EXCLUDED.name,
+			original_name = EXCLUDED.original_name,
+			overview = EXCLUDED.overview,
+			first_air_date = EXCLUDED.first_air_date,
+			last_air_date = EXCLUDED.last_air_date,
+			poster_path = EXCLUDED.poster_path,
+			backdrop_path = EXCLUDED.backdrop_path,
+			vote_average = EXCLUDED.vote_average,
+			vote_count = EXCLUDED.vote_count,
+			popularity = EXCLUDED.popularity,
+			genre_ids = EXCLUDED.genre_ids,
+			original_language = EXCLUDED.original_language,
+			origin_country = EXCLUDED.origin_country,
+			cached_at = EXCLUDED.cached_at,
+			details = EXCLUDED.details,
+			details_cached_at = EXCLUDED.details_cached_at`,
+		d.ID, truncate(d.Name, 255), truncate(d.OriginalName, 255), d.Overview, d.FirstAirDate, d.LastAirDate,
+		d.PosterPath, d.BackdropPath, d.VoteAverage, d.VoteCount, d.Popularity,
+		pq.Array(genreIDs(d.Genres)), d.OriginalLanguage, pq.Array(d.OriginCountry), string(encoded))
+	if err != nil {
+		return fmt.Errorf("upsert TV show details %d: %w", d.ID, err)
+	}
+	return nil
+}
+
+// GetTVShowDetails returns a TV show's stored details
+func (r *titleRepository) GetTVShowDetails(ctx context.Context, id int) (*CachedTVShowDetails, error) {
+	var c CachedTVShowDetails
+	var encoded []byte
+	err := r.db.QueryRowContext(ctx, `
+		SELECT details, details_cached_at FROM tv_shows
+		WHERE id = $1 AND details IS NOT NULL`, id,
+	).Scan(&encoded, &c.CachedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get TV show details %d: %w", id, err)
+	}
+	if err := json.Unmarshal(encoded, &c.Details); err != nil {
+		return nil, fmt.Errorf("decode TV show details %d: %w", id, err)
+	}
+	return &c, nil
+}
+
+// movieColumns are the movies columns read by scanMovie
+const movieColumns = `id, title, original_title, overview, to_char(release_date, 'YYYY-MM-DD'), poster_path,
+	backdrop_path, vote_average, vote_count, popularity, adult, genre_ids, original_language, video, cached_at`
+
+// tvShowColumns are the tv_shows columns read by scanTVShow
+const tvShowColumns = `id, name, original_name, overview, to_char(first_air_date, 'YYYY-MM-DD'), poster_path,
+	backdrop_path, vote_average, vote_count, popularity, genre_ids, original_language, origin_country, cached_at`
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanMovie reads a movie selected with movieColumns
+func scanMovie(row rowScanner) (*CachedMovie, error) {
+	var c CachedMovie
+	var originalTitle, overview, originalLanguage sql.NullString
+	var voteAverage, popularity sql.NullFloat64
+	var voteCount sql.NullInt64
+	var adult, video sql.NullBool
+	var genreIDs []int64
+
+	err := row.Scan(&c.Movie.ID, &c.Movie.Title, &originalTitle, &overview, &c.Movie.ReleaseDate, &c.Movie.PosterPath,
+		&c.Movie.BackdropPath, &voteAverage, &voteCount, &popularity, &adult, pq.Array(&genreIDs),
+		&originalLanguage, &video, &c.CachedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Movie.OriginalTitle = originalTitle.String
+	c.Movie.Overview = overview.String
+	c.Movie.VoteAverage = voteAverage.Float64
+	c.Movie.VoteCount = int(voteCount.Int64)
+	c.Movie.Popularity = popularity.Float64
+	c.Movie.Adult = adult.Bool
+	c.Movie.GenreIDs = toInts(genreIDs)
+	c.Movie.OriginalLanguage = originalLanguage.String
+	c.Movie.Video = video.Bool
+	return &c, nil
+}
+
+// scanTVShow reads a TV show selected with tvShowColumns
+func scanTVShow(row rowScanner) (*CachedTVShow, error) {
+	var c CachedTVShow
+	var originalName, overview, originalLanguage sql.NullString
+	var voteAverage, popularity sql.NullFloat64
+	var voteCount sql.NullInt64
+	var genreIDs []int64
+	var originCountry []sql.NullString
+
+	err := row.Scan(&c.TVShow.ID, &c.TVShow.Name, &originalName, &overview, &c.TVShow.FirstAirDate, &c.TVShow.PosterPath,
+		&c.TVShow.BackdropPath, &voteAverage, &voteCount, &popularity, pq.Array(&genreIDs),
+		&originalLanguage, pq.Array(&originCountry), &c.CachedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	c.TVShow.OriginalName = originalName.String
+	c.TVShow.Overview = overview.String
+	c.TVShow.VoteAverage = voteAverage.Float64
+	c.TVShow.VoteCount = int(voteCount.Int64)
+	c.TVShow.Popularity = popularity.Float64
+	c.TVShow.GenreIDs = toInts(genreIDs)
+	c.TVShow.OriginalLanguage = originalLanguage.String
+	for _, country := range originCountry {
+		if country.Valid {
+			c.TVShow.OriginCountry = append(c.TVShow.OriginCountry, country.String)
+		}
+	}
+	return &c, nil
+}
+
+// toInt64s converts ints for pq.Array, which has no []int support
+func toInt64s(values []int) []int64 {
+	if values == nil {
+		return nil
+	}
+	out := make([]int64, len(values))
+	for i, v := range values {
+		out[i] = int64(v)
+	}
+	return out
+}
+
+// genreIDs extracts the IDs of genres for the genre_ids column
+func genreIDs(genres []models.Genre) []int64 {
+	out := make([]int64, len(genres))
+	for i, g := range genres {
+		out[i] = int64(g.ID)
+	}
+	return out
+}
+
+// toInts converts int64s scanned by pq.Array back to ints
+func toInts(values []int64) []int {
+	if values == nil {
+		return nil
+	}
+	out := make([]int, len(values))
+	for i, v := range values {
+		out[i] = int(v)
+	}
+	return out
+}
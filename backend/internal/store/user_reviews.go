@@ -0,0 +1,526 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+	"github.com/takeshi-arihori/movie-api/internal/ranking"
+)
+
+// UserReviewRepository reads and writes the user_reviews table
+type UserReviewRepository interface {
+	// Create inserts review with its status and flags, filling in its ID, counters
+	// and timestamps. It returns ErrConflict if the user has already reviewed the title.
+	Create(ctx context.Context, review *models.UserReview) error
+	// Get returns a review by ID, or ErrNotFound
+	Get(ctx context.Context, id string) (*models.UserReview, error)
+	// Update saves review's title, content, rating, spoiler flag, status and flags,
+	// clearing any moderation reason and refreshing its update time. It returns
+	// the review as it was before, or ErrNotFound unless review.UserID wrote it.
+	Update(ctx context.Context, review *models.UserReview) (*models.UserReview, error)
+	// Delete deletes a user's review and returns it as it was, or ErrNotFound
+	Delete(ctx context.Context, userID, id string) (*models.UserReview, error)
+	// ListByTitle returns a page of a title's approved reviews, ordered by sortBy
+	// and sortOrder as in models.ReviewFilter, and the number of approved reviews
+	// in total. Reviews are sorted by helpfulness on the Wilson lower bound of
+	// their votes.
+	ListByTitle(ctx context.Context, mediaType string, mediaID int, sortBy, sortOrder string, offset, limit int) ([]models.UserReview, int, error)
+	// List returns the page of reviews matching filter that filter.Page and
+	// filter.Limit select, sorted as filter asks, and the number of matching
+	// reviews. Nil filter fields match every review, including every status.
+	List(ctx context.Context, filter models.ReviewFilter) ([]models.UserReview, int, error)
+	// ApprovedRatings returns the ratings of a title's approved reviews
+	ApprovedRatings(ctx context.Context, mediaType string, mediaID int) ([]float64, error)
+	// ListByUser returns a page of a user's reviews in any status, newest first,
+	// and the number of reviews in total
+	ListByUser(ctx context.Context, userID string, offset, limit int) ([]models.UserReview, int, error)
+	// StatusCounts returns the number of a user's reviews in each status
+	StatusCounts(ctx context.Context, userID string) (map[string]int, error)
+	// Vote records a user's helpfulness vote on a review and updates the review's
+	// counters. Casting the same vote again removes it, as does a nil helpful. It
+	// returns the new counters and what became of the vote, or ErrNotFound.
+	Vote(ctx context.Context, reviewID, userID string, helpful *bool) (*models.ReviewHelpfulnessResponse, error)
+
+	// Queue returns a page of pending reviews, flagged reviews first and then
+	// oldest first, and the number of pending reviews in total
+	Queue(ctx context.Context, offset, limit int) ([]models.UserReview, int, error)
+	// Moderate applies a moderator's decision to a review, records it in the audit
+	// log and sends notification to the author, filling in the IDs and times of
+	// entry and notification. It returns the review as it was before and as
+	// moderated, or ErrNotFound.
+	Moderate(ctx context.Context, entry *models.ReviewModerationEntry, notification *models.Notification) (previous, moderated *models.UserReview, err error)
+	// History returns the moderation decisions on a review, oldest first
+	History(ctx context.Context, reviewID string) ([]models.ReviewModerationEntry, error)
+}
+
+// userReviewRepository is the PostgreSQL UserReviewRepository
+type userReviewRepository struct {
+	db *sql.DB
+}
+
+// NewUserReviewRepository creates a UserReviewRepository on db
+func NewUserReviewRepository(db *sql.DB) UserReviewRepository {
+	return &userReviewRepository{db: db}
+}
+
+const userReviewColumns = `id, user_id, media_id, media_type, title, content, rating, spoiler,
+	helpful, not_helpful, status, flagged, flag_reasons, moderation_reason, created_at, updated_at`
+
+// Create inserts a review
+func (r *userReviewRepository) Create(ctx context.Context, review *models.UserReview) error {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO user_reviews (user_id, media_type, media_id, title, content, rating, spoiler, status, flagged, flag_reasons)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, helpful, not_helpful, created_at, updated_at`,
+		review.UserID, review.MediaType, review.MediaID, review.Title, review.Content, review.Rating, review.Spoiler,
+		review.Status, review.Flagged, pq.Array(nonNil(review.FlagReasons)),
+	).Scan(&review.ID, &review.Helpful, &review.NotHelpful, &review.CreatedAt, &review.UpdatedAt)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("create review: %w", ErrConflict)
+	}
+	if err != nil {
+		return fmt.Errorf("create review: %w", err)
+	}
+	return nil
+}
+
+// Get returns a review
+func (r *userReviewRepository) Get(ctx context.Context, id string) (*models.UserReview, error) {
+	var review models.UserReview
+	err := scanUserReview(r.db.QueryRowContext(ctx, `
+		SELECT `+userReviewColumns+`
+		FROM user_reviews WHERE id = $1`, id), &review)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get review: %w", err)
+	}
+	return &review, nil
+}
+
+// Update saves a review's editable fields and returns the review as it was
+func (r *userReviewRepository) Update(ctx context.Context, review *models.UserReview) (*models.UserReview, error) {
+	var previous models.UserReview
+	err := inTx(ctx, r.db, "update review", func(tx *sql.Tx) error {
+		// Locking the review makes previous the version this update replaces, even
+		// when it is moderated concurrently
+		err := scanUserReview(tx.QueryRowContext(ctx, `
+			SELECT `+userReviewColumns+`
+			FROM user_reviews WHERE id = $1 AND user_id = $2
+			FOR UPDATE`, review.ID, review.UserID), &previous)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		return tx.QueryRowContext(ctx, `
+			UPDATE user_reviews
+			SET title = $2, content = $3, rating = $4, spoiler = $5,
+				status = $6, flagged = $7, flag_reasons = $8, moderation_reason = ''
+			WHERE id = $1
+			RETURNING updated_at`,
+			review.ID, review.Title, review.Content, review.Rating, review.Spoiler,
+			review.Status, review.Flagged, pq.Array(nonNil(review.FlagReasons)),
+		).Scan(&review.UpdatedAt)
+	})
+	if err != nil {
+		return nil, err
+	}
+	review.ModerationReason = ""
+	return &previous, nil
+}
+
+// Delete deletes a review and returns it as it was
+func (r *userReviewRepository) Delete(ctx context.Context, userID, id string) (*models.UserReview, error) {
+	var deleted models.UserReview
+	err := scanUserReview(r.db.QueryRowContext(ctx, `
+		DELETE FROM user_reviews WHERE id = $1 AND user_id = $2
+		RETURNING `+userReviewColumns, id, userID), &deleted)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("delete review: %w", err)
+	}
+	return &deleted, nil
+}
+
+// ListByTitle returns a title's approved reviews
+func (r *userReviewRepository) ListByTitle(ctx context.Context, mediaType string, mediaID int, sortBy, sortOrder string, offset, limit int) ([]models.UserReview, int, error) {
+	return r.list(ctx, "list title reviews",
+		`media_type = $1 AND media_id = $2 AND status = '`+models.ReviewStatusApproved+`'`,
+		reviewOrder(sortBy, sortOrder), offset, limit, mediaType, mediaID)
+}
+
+// List returns the reviews matching a filter
+func (r *userReviewRepository) List(ctx context.Context, filter models.ReviewFilter) ([]models.UserReview, int, error) {
+	var conditions []string
+	var args []any
+	where := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if filter.MediaID != nil {
+		where("media_id = $%d", *filter.MediaID)
+	}
+	if filter.MediaType != nil {
+		where("media_type = $%d", *filter.MediaType)
+	}
+	if filter.UserID != nil {
+		where("user_id = $%d", *filter.UserID)
+	}
+	if filter.MinRating != nil {
+		where("rating >= $%d", *filter.MinRating)
+	}
+	if filter.MaxRating != nil {
+		where("rating <= $%d", *filter.MaxRating)
+	}
+	if filter.HasSpoiler != nil {
+		where("spoiler = $%d", *filter.HasSpoiler)
+	}
+	if filter.Status != nil {
+		where("status = $%d", *filter.Status)
+	}
+	if len(conditions) == 0 {
+		conditions = append(conditions, "true")
+	}
+
+	return r.list(ctx, "list reviews", strings.Join(conditions, " AND "),
+		reviewOrder(filter.SortBy, filter.SortOrder), (filter.Page-1)*filter.Limit, filter.Limit, args...)
+}
+
+// ApprovedRatings returns a title's approved ratings
+func (r *userReviewRepository) ApprovedRatings(ctx context.Context, mediaType string, mediaID int) ([]float64, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT rating FROM user_reviews
+		WHERE media_type = $1 AND media_id = $2 AND status = $3`, mediaType, mediaID, models.ReviewStatusApproved)
+	if err != nil {
+		return nil, fmt.Errorf("list ratings: %w", err)
+	}
+	defer rows.Close()
+
+	ratings := []float64{}
+	for rows.Next() {
+		var rating float64
+		if err := rows.Scan(&rating); err != nil {
+			return nil, fmt.Errorf("scan rating: %w", err)
+		}
+		ratings = append(ratings, rating)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list ratings: %w", err)
+	}
+	return ratings, nil
+}
+
+// ListByUser returns a user's reviews
+func (r *userReviewRepository) ListByUser(ctx context.Context, userID string, offset, limit int) ([]models.UserReview, int, error) {
+	return r.list(ctx, "list user reviews", `user_id = $1`, reviewOrder("", ""), offset, limit, userID)
+}
+
+// StatusCounts counts a user's reviews by status
+func (r *userReviewRepository) StatusCounts(ctx context.Context, userID string) (map[string]int, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT status, count(*) FROM user_reviews
+		WHERE user_id = $1
+		GROUP BY status`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("count reviews: %w", err)
+	}
+	defer rows.Close()
+
+	counts := map[string]int{}
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("scan review count: %w", err)
+		}
+		counts[status] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("count reviews: %w", err)
+	}
+	return counts, nil
+}
+
+// Vote records, switches or removes a helpfulness vote
+func (r *userReviewRepository) Vote(ctx context.Context, reviewID, userID string, helpful *bool) (*models.ReviewHelpfulnessResponse, error) {
+	resp := &models.ReviewHelpfulnessResponse{ReviewID: reviewID}
+	err := inTx(ctx, r.db, "vote on review", func(tx *sql.Tx) error {
+		// Locking the review serializes concurrent votes on it, so the counters
+		// and the votes table cannot drift apart
+		var id string
+		err := tx.QueryRowContext(ctx, `SELECT id FROM user_reviews WHERE id = $1 FOR UPDATE`, reviewID).Scan(&id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		var previous *bool
+		err = tx.QueryRowContext(ctx, `
+			SELECT helpful FROM review_votes WHERE review_id = $1 AND user_id = $2`, reviewID, userID).Scan(&previous)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		next := helpful
+		if previous != nil && helpful != nil && *previous == *helpful {
+			next = nil
+		}
+		if next == nil {
+			_, err = tx.ExecContext(ctx, `DELETE FROM review_votes WHERE review_id = $1 AND user_id = $2`, reviewID, userID)
+		} else {
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO review_votes (review_id, user_id, helpful)
+				VALUES ($1, $2, $3)
+				ON CONFLICT (review_id, user_id) DO UPDATE SET helpful = EXCLUDED.helpful`,
+				reviewID, userID, *next)
+		}
+		if err != nil {
+			return err
+		}
+
+		helpfulDelta, notHelpfulDelta := voteDelta(previous, next)
+		err = tx.QueryRowContext(ctx, `
+			UPDATE user_reviews SET helpful = helpful + $2, not_helpful = not_helpful + $3
+			WHERE id = $1
+			RETURNING helpful, not_helpful`,
+			reviewID, helpfulDelta, notHelpfulDelta,
+		).Scan(&resp.Helpful, &resp.NotHelpful)
+		if err != nil {
+			return err
+		}
+
+		resp.UserAction = voteAction(next)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Queue returns pending reviews
+func (r *userReviewRepository) Queue(ctx context.Context, offset, limit int) ([]models.UserReview, int, error) {
+	var total int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT count(*) FROM user_reviews WHERE status = $1`, models.ReviewStatusPending).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list moderation queue: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+userReviewColumns+`
+		FROM user_reviews WHERE status = $1
+		ORDER BY flagged DESC, created_at, id
+		OFFSET $2 LIMIT $3`, models.ReviewStatusPending, offset, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list moderation queue: %w", err)
+	}
+	reviews, err := scanUserReviews(rows, "list moderation queue")
+	if err != nil {
+		return nil, 0, err
+	}
+	return reviews, total, nil
+}
+
+// Moderate approves or rejects a review
+func (r *userReviewRepository) Moderate(ctx context.Context, entry *models.ReviewModerationEntry, notification *models.Notification) (*models.UserReview, *models.UserReview, error) {
+	status := models.ReviewStatusApproved
+	if entry.Action == models.ReviewActionReject {
+		status = models.ReviewStatusRejected
+	}
+
+	var previous, review models.UserReview
+	err := inTx(ctx, r.db, "moderate review", func(tx *sql.Tx) error {
+		// Locking the review makes previous the version this decision replaces,
+		// even when it is edited or moderated concurrently
+		err := scanUserReview(tx.QueryRowContext(ctx, `
+			SELECT `+userReviewColumns+`
+			FROM user_reviews WHERE id = $1
+			FOR UPDATE`, entry.ReviewID), &previous)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		err = scanUserReview(tx.QueryRowContext(ctx, `
+			UPDATE user_reviews SET status = $2, moderation_reason = $3
+			WHERE id = $1
+			RETURNING `+userReviewColumns, entry.ReviewID, status, entry.Reason), &review)
+		if err != nil {
+			return err
+		}
+
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO review_moderation_log (review_id, moderator_id, action, reason)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id, created_at`,
+			entry.ReviewID, entry.ModeratorID, entry.Action, entry.Reason,
+		).Scan(&entry.ID, &entry.CreatedAt)
+		if err != nil {
+			return err
+		}
+
+		notification.UserID = review.UserID
+		return insertNotification(ctx, tx, notification)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &previous, &review, nil
+}
+
+// History returns a review's moderation log
+func (r *userReviewRepository) History(ctx context.Context, reviewID string) ([]models.ReviewModerationEntry, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, review_id, COALESCE(moderator_id::text, ''), action, reason, created_at
+		FROM review_moderation_log
+		WHERE review_id = $1
+		ORDER BY created_at, id`, reviewID)
+	if err != nil {
+		return nil, fmt.Errorf("list moderation history: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.ReviewModerationEntry{}
+	for rows.Next() {
+		var e models.ReviewModerationEntry
+		if err := rows.Scan(&e.ID, &e.ReviewID, &e.ModeratorID, &e.Action, &e.Reason, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan moderation entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list moderation history: %w", err)
+	}
+	return entries, nil
+}
+
+// list returns a page of the reviews matching where, whose placeholders are
+// bound to args, in orderBy order, and the number of matching reviews
+func (r *userReviewRepository) list(ctx context.Context, op, where, orderBy string, offset, limit int, args ...any) ([]models.UserReview, int, error) {
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT count(*) FROM user_reviews WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	n := len(args)
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT `+userReviewColumns+`
+		FROM user_reviews WHERE %s
+		ORDER BY %s
+		OFFSET $%d LIMIT $%d`, where, orderBy, n+1, n+2), append(args, offset, limit)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+	reviews, err := scanUserReviews(rows, op)
+	if err != nil {
+		return nil, 0, err
+	}
+	return reviews, total, nil
+}
+
+// wilsonLowerBound is ranking.WilsonLowerBound of a review's helpfulness votes
+var wilsonLowerBound = fmt.Sprintf(`CASE WHEN helpful + not_helpful = 0 THEN 0 ELSE
+	(helpful::float8 / (helpful + not_helpful) + %[1]g / (2 * (helpful + not_helpful))
+		- %[2]g * sqrt((helpful::float8 * not_helpful / (helpful + not_helpful) ^ 2 + %[1]g / (4 * (helpful + not_helpful))) / (helpful + not_helpful)))
+	/ (1 + %[1]g / (helpful + not_helpful)) END`, ranking.WilsonZ*ranking.WilsonZ, ranking.WilsonZ)
+
+// reviewOrder returns the ORDER BY clause for a models.ReviewFilter sort. Reviews
+// are sorted newest first by default, and ties are broken by recency and then ID
+// so that pages are stable.
+func reviewOrder(sortBy, sortOrder string) string {
+	direction := "DESC"
+	if sortOrder == "asc" {
+		direction = "ASC"
+	}
+	switch sortBy {
+	case "updated_at":
+		return "updated_at " + direction + ", id"
+	case "rating":
+		return "rating " + direction + ", created_at DESC, id"
+	case "helpful":
+		return wilsonLowerBound + " " + direction + ", created_at DESC, id"
+	default:
+		return "created_at " + direction + ", id"
+	}
+}
+
+// voteDelta returns the changes to a review's helpful and not_helpful counters
+// when a user's vote goes from previous to next, either of which may be nil
+func voteDelta(previous, next *bool) (helpful, notHelpful int) {
+	count := func(vote *bool, sign int) {
+		switch {
+		case vote == nil:
+		case *vote:
+			helpful += sign
+		default:
+			notHelpful += sign
+		}
+	}
+	count(previous, -1)
+	count(next, 1)
+	return helpful, notHelpful
+}
+
+// voteAction describes a user's vote for ReviewHelpfulnessResponse.UserAction
+func voteAction(vote *bool) string {
+	switch {
+	case vote == nil:
+		return models.ReviewVoteRemoved
+	case *vote:
+		return models.ReviewVoteHelpful
+	default:
+		return models.ReviewVoteNotHelpful
+	}
+}
+
+// scanUserReviews reads rows of userReviewColumns and closes rows
+func scanUserReviews(rows *sql.Rows, op string) ([]models.UserReview, error) {
+	defer rows.Close()
+
+	reviews := []models.UserReview{}
+	for rows.Next() {
+		var review models.UserReview
+		if err := scanUserReview(rows, &review); err != nil {
+			return nil, fmt.Errorf("scan review: %w", err)
+		}
+		reviews = append(reviews, review)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return reviews, nil
+}
+
+// scanUserReview reads a row of userReviewColumns into review
+func scanUserReview(row rowScanner, review *models.UserReview) error {
+	return row.Scan(&review.ID, &review.UserID, &review.MediaID, &review.MediaType, &review.Title, &review.Content,
+		&review.Rating, &review.Spoiler, &review.Helpful, &review.NotHelpful, &review.Status,
+		&review.Flagged, pq.Array(&review.FlagReasons), &review.ModerationReason,
+		&review.CreatedAt, &review.UpdatedAt)
+}
+
+// nonNil returns s, or an empty slice if s is nil, so that it is stored as an
+// empty array rather than NULL
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,55 @@
+package store
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+)
+
+func TestVoteDelta(t *testing.T) {
+	yes, no := true, false
+	tests := []struct {
+		name                string
+		previous, next      *bool
+		helpful, notHelpful int
+		action              string
+	}{
+		{"first helpful vote", nil, &yes, 1, 0, models.ReviewVoteHelpful},
+		{"first not helpful vote", nil, &no, 0, 1, models.ReviewVoteNotHelpful},
+		{"switch to not helpful", &yes, &no, -1, 1, models.ReviewVoteNotHelpful},
+		{"switch to helpful", &no, &yes, 1, -1, models.ReviewVoteHelpful},
+		{"remove helpful vote", &yes, nil, -1, 0, models.ReviewVoteRemoved},
+		{"remove missing vote", nil, nil, 0, 0, models.ReviewVoteRemoved},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helpful, notHelpful := voteDelta(tt.previous, tt.next)
+			if helpful != tt.helpful || notHelpful != tt.notHelpful {
+				t.Errorf("voteDelta() = %d, %d, want %d, %d", helpful, notHelpful, tt.helpful, tt.notHelpful)
+			}
+			if action := voteAction(tt.next); action != tt.action {
+				t.Errorf("voteAction() = %q, want %q", action, tt.action)
+			}
+		})
+	}
+}
+
+func TestReviewOrder(t *testing.T) {
+	tests := []struct {
+		sortBy, sortOrder string
+		prefix            string
+	}{
+		{"", "", "created_at DESC"},
+		{"created_at", "asc", "created_at ASC"},
+		{"rating", "", "rating DESC"},
+		{"helpful", "desc", "CASE WHEN helpful + not_helpful = 0"},
+	}
+
+	for _, tt := range tests {
+		if order := reviewOrder(tt.sortBy, tt.sortOrder); !strings.HasPrefix(order, tt.prefix) || !strings.HasSuffix(order, ", id") {
+			t.Errorf("reviewOrder(%q, %q) = %q, want a prefix of %q and an ID tie-break", tt.sortBy, tt.sortOrder, order, tt.prefix)
+		}
+	}
+}
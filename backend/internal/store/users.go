@@ -0,0 +1,82 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+)
+
+// UserRepository reads and writes the users table
+type UserRepository interface {
+	// Create inserts user, filling in its ID and timestamps. It returns ErrConflict
+	// if the email or username is taken.
+	Create(ctx context.Context, user *models.User) error
+	// GetByID returns the user with the given ID, or ErrNotFound
+	GetByID(ctx context.Context, id string) (*models.User, error)
+	// GetByEmail returns the user with the given email (case-insensitive), or ErrNotFound
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	// GetByUsername returns the user with the given username, or ErrNotFound
+	GetByUsername(ctx context.Context, username string) (*models.User, error)
+}
+
+// userRepository is the PostgreSQL UserRepository
+type userRepository struct {
+	db *sql.DB
+}
+
+// NewUserRepository creates a UserRepository on db
+func NewUserRepository(db *sql.DB) UserRepository {
+	return &userRepository{db: db}
+}
+
+const userColumns = `id, email, username, password_hash, is_active, is_moderator, created_at, updated_at`
+
+// Create inserts a new user
+func (r *userRepository) Create(ctx context.Context, user *models.User) error {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO users (email, username, password_hash, is_active)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at`,
+		user.Email, user.Username, user.PasswordHash, user.IsActive,
+	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("create user: %w", ErrConflict)
+	}
+	if err != nil {
+		return fmt.Errorf("create user: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns a user by ID
+func (r *userRepository) GetByID(ctx context.Context, id string) (*models.User, error) {
+	return r.getOne(ctx, `SELECT `+userColumns+` FROM users WHERE id = $1`, id)
+}
+
+// GetByEmail returns a user by email
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	return r.getOne(ctx, `SELECT `+userColumns+` FROM users WHERE email = $1`, email)
+}
+
+// GetByUsername returns a user by username
+func (r *userRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	return r.getOne(ctx, `SELECT `+userColumns+` FROM users WHERE username = $1`, username)
+}
+
+// getOne runs a query selecting userColumns and scans the single resulting user
+func (r *userRepository) getOne(ctx context.Context, query string, args ...interface{}) (*models.User, error) {
+	var user models.User
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(
+		&user.ID, &user.Email, &user.Username, &user.PasswordHash,
+		&user.IsActive, &user.IsModerator, &user.CreatedAt, &user.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+	return &user, nil
+}
@@ -0,0 +1,396 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+	"github.com/takeshi-arihori/movie-api/internal/textnorm"
+)
+
+// maxSlugBase bounds the part of a watchlist slug derived from its name
+const maxSlugBase = 48
+
+// WatchlistRepository reads and writes the watchlists and watchlist_items tables
+type WatchlistRepository interface {
+	// Create inserts list, filling in its ID, slug and timestamps. It returns
+	// ErrConflict if the user already has a watchlist with the same name.
+	Create(ctx context.Context, list *models.Watchlist) error
+	// Get returns a user's watchlist without its items, or ErrNotFound
+	Get(ctx context.Context, userID, id string) (*models.Watchlist, error)
+	// GetPublic returns the public watchlist with the given slug without its
+	// items, or ErrNotFound
+	GetPublic(ctx context.Context, slug string) (*models.Watchlist, error)
+	// List returns a user's watchlists without their items, most recently updated first
+	List(ctx context.Context, userID string) ([]models.Watchlist, error)
+	// Update saves list's name, description and visibility, assigning a slug the
+	// first time it is made public. It returns ErrNotFound if the user has no such
+	// watchlist and ErrConflict if the new name is taken.
+	Update(ctx context.Context, list *models.Watchlist) error
+	// Delete deletes a user's watchlist and its items, or returns ErrNotFound
+	Delete(ctx context.Context, userID, id string) error
+
+	// Items returns a watchlist's items in order
+	Items(ctx context.Context, watchlistID string) ([]models.WatchlistItem, error)
+	// AddItem appends item to a watchlist, filling in its ID, position and time
+	// added. If the title is already in the watchlist, item is filled in from the
+	// existing entry and added is false. It returns ErrLimitExceeded if adding the
+	// title would take the watchlist past models.MaxWatchlistItems.
+	AddItem(ctx context.Context, watchlistID string, item *models.WatchlistItem) (added bool, err error)
+	// UpdateItemNote replaces an item's note, or returns ErrNotFound
+	UpdateItemNote(ctx context.Context, watchlistID, itemID, note string) (*models.WatchlistItem, error)
+	// RemoveItem deletes an item and closes the gap in positions, or returns ErrNotFound
+	RemoveItem(ctx context.Context, watchlistID, itemID string) error
+	// Reorder positions a watchlist's items in the order of itemIDs. It returns
+	// ErrConflict unless itemIDs lists every item exactly once.
+	Reorder(ctx context.Context, watchlistID string, itemIDs []string) error
+}
+
+// watchlistRepository is the PostgreSQL WatchlistRepository
+type watchlistRepository struct {
+	db *sql.DB
+}
+
+// NewWatchlistRepository creates a WatchlistRepository on db
+func NewWatchlistRepository(db *sql.DB) WatchlistRepository {
+	return &watchlistRepository{db: db}
+}
+
+const watchlistColumns = `w.id, w.user_id, w.name, w.description, w.is_public, w.slug, w.created_at, w.updated_at,
+	(SELECT count(*) FROM watchlist_items i WHERE i.watchlist_id = w.id)`
+
+const watchlistItemColumns = `id, media_type, media_id, position, note, added_at`
+
+// Create inserts a watchlist
+func (r *watchlistRepository) Create(ctx context.Context, list *models.Watchlist) error {
+	var slug *string
+	if list.IsPublic {
+		generated, err := watchlistSlug(list.Name)
+		if err != nil {
+			return fmt.Errorf("create watchlist: %w", err)
+		}
+		slug = &generated
+	}
+
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO watchlists (user_id, name, description, is_public, slug)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, slug, created_at, updated_at`,
+		list.UserID, list.Name, list.Description, list.IsPublic, slug,
+	).Scan(&list.ID, &list.Slug, &list.CreatedAt, &list.UpdatedAt)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("create watchlist: %w", ErrConflict)
+	}
+	if err != nil {
+		return fmt.Errorf("create watchlist: %w", err)
+	}
+	return nil
+}
+
+// Get returns a user's watchlist
+func (r *watchlistRepository) Get(ctx context.Context, userID, id string) (*models.Watchlist, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+watchlistColumns+`
+		FROM watchlists w WHERE w.id = $1 AND w.user_id = $2`, id, userID)
+	return scanWatchlist(row, "get watchlist")
+}
+
+// GetPublic returns a public watchlist by slug
+func (r *watchlistRepository) GetPublic(ctx context.Context, slug string) (*models.Watchlist, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+watchlistColumns+`
+		FROM watchlists w WHERE w.slug = $1 AND w.is_public`, slug)
+	return scanWatchlist(row, "get public watchlist")
+}
+
+// List returns a user's watchlists
+func (r *watchlistRepository) List(ctx context.Context, userID string) ([]models.Watchlist, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+watchlistColumns+`
+		FROM watchlists w WHERE w.user_id = $1
+		ORDER BY w.updated_at DESC, w.id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list watchlists: %w", err)
+	}
+	defer rows.Close()
+
+	lists := []models.Watchlist{}
+	for rows.Next() {
+		list, err := scanWatchlist(rows, "list watchlists")
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, *list)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list watchlists: %w", err)
+	}
+	return lists, nil
+}
+
+// Update saves a watchlist's name, description and visibility
+func (r *watchlistRepository) Update(ctx context.Context, list *models.Watchlist) error {
+	slug, err := watchlistSlug(list.Name)
+	if err != nil {
+		return fmt.Errorf("update watchlist: %w", err)
+	}
+
+	err = r.db.QueryRowContext(ctx, `
+		UPDATE watchlists SET name = $3, description = $4, is_public = $5,
+			slug = CASE WHEN $5::boolean THEN COALESCE(slug, $6) ELSE slug END
+		WHERE id = $1 AND user_id = $2
+		RETURNING slug, updated_at`,
+		list.ID, list.UserID, list.Name, list.Description, list.IsPublic, slug,
+	).Scan(&list.Slug, &list.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if isUniqueViolation(err) {
+		return fmt.Errorf("update watchlist: %w", ErrConflict)
+	}
+	if err != nil {
+		return fmt.Errorf("update watchlist: %w", err)
+	}
+	return nil
+}
+
+// Delete deletes a watchlist
+func (r *watchlistRepository) Delete(ctx context.Context, userID, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM watchlists WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return fmt.Errorf("delete watchlist: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Items returns a watchlist's items
+func (r *watchlistRepository) Items(ctx context.Context, watchlistID string) ([]models.WatchlistItem, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+watchlistItemColumns+`
+		FROM watchlist_items WHERE watchlist_id = $1
+		ORDER BY position, id`, watchlistID)
+	if err != nil {
+		return nil, fmt.Errorf("list watchlist items: %w", err)
+	}
+	defer rows.Close()
+
+	items := []models.WatchlistItem{}
+	for rows.Next() {
+		var item models.WatchlistItem
+		if err := scanWatchlistItem(rows, &item); err != nil {
+			return nil, fmt.Errorf("scan watchlist item: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list watchlist items: %w", err)
+	}
+	return items, nil
+}
+
+// AddItem appends an item to a watchlist
+func (r *watchlistRepository) AddItem(ctx context.Context, watchlistID string, item *models.WatchlistItem) (bool, error) {
+	added := false
+	err := inTx(ctx, r.db, "add watchlist item", func(tx *sql.Tx) error {
+		// Touching the watchlist locks its row, so concurrent adds are counted one
+		// after the other
+		if err := touchWatchlist(ctx, tx, watchlistID); err != nil {
+			return err
+		}
+
+		var count int
+		var exists bool
+		err := tx.QueryRowContext(ctx, `
+			SELECT COUNT(*), COALESCE(BOOL_OR(media_type = $2 AND media_id = $3), false)
+			FROM watchlist_items WHERE watchlist_id = $1`,
+			watchlistID, item.MediaType, item.MediaID,
+		).Scan(&count, &exists)
+		if err != nil {
+			return err
+		}
+		if !exists && count >= models.MaxWatchlistItems {
+			return ErrLimitExceeded
+		}
+
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO watchlist_items (watchlist_id, media_type, media_id, note, position)
+			SELECT $1, $2, $3, $4, COALESCE(MAX(position), 0) + 1
+			FROM watchlist_items WHERE watchlist_id = $1
+			ON CONFLICT (watchlist_id, media_type, media_id) DO NOTHING
+			RETURNING `+watchlistItemColumns,
+			watchlistID, item.MediaType, item.MediaID, item.Note,
+		).Scan(&item.ID, &item.MediaType, &item.MediaID, &item.Position, &item.Note, &item.AddedAt)
+		if err == nil {
+			added = true
+			return nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		// Already in the list
+		return scanWatchlistItem(tx.QueryRowContext(ctx, `
+			SELECT `+watchlistItemColumns+`
+			FROM watchlist_items WHERE watchlist_id = $1 AND media_type = $2 AND media_id = $3`,
+			watchlistID, item.MediaType, item.MediaID), item)
+	})
+	return added, err
+}
+
+// UpdateItemNote replaces an item's note
+func (r *watchlistRepository) UpdateItemNote(ctx context.Context, watchlistID, itemID, note string) (*models.WatchlistItem, error) {
+	var item models.WatchlistItem
+	err := inTx(ctx, r.db, "update watchlist item", func(tx *sql.Tx) error {
+		if err := touchWatchlist(ctx, tx, watchlistID); err != nil {
+			return err
+		}
+		err := scanWatchlistItem(tx.QueryRowContext(ctx, `
+			UPDATE watchlist_items SET note = $3
+			WHERE id = $2 AND watchlist_id = $1
+			RETURNING `+watchlistItemColumns,
+			watchlistID, itemID, note), &item)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// RemoveItem deletes an item
+func (r *watchlistRepository) RemoveItem(ctx context.Context, watchlistID, itemID string) error {
+	return inTx(ctx, r.db, "remove watchlist item", func(tx *sql.Tx) error {
+		if err := touchWatchlist(ctx, tx, watchlistID); err != nil {
+			return err
+		}
+
+		var position int
+		err := tx.QueryRowContext(ctx, `
+			DELETE FROM watchlist_items WHERE id = $2 AND watchlist_id = $1
+			RETURNING position`, watchlistID, itemID).Scan(&position)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE watchlist_items SET position = position - 1
+			WHERE watchlist_id = $1 AND position > $2`, watchlistID, position)
+		return err
+	})
+}
+
+// Reorder positions a watchlist's items in the given order
+func (r *watchlistRepository) Reorder(ctx context.Context, watchlistID string, itemIDs []string) error {
+	return inTx(ctx, r.db, "reorder watchlist", func(tx *sql.Tx) error {
+		if err := touchWatchlist(ctx, tx, watchlistID); err != nil {
+			return err
+		}
+
+		var count int
+		if err := tx.QueryRowContext(ctx, `
+			SELECT count(*) FROM watchlist_items WHERE watchlist_id = $1`, watchlistID).Scan(&count); err != nil {
+			return err
+		}
+		if count != len(itemIDs) {
+			return ErrConflict
+		}
+
+		// Duplicate IDs update the same row once, so fewer rows than IDs are affected
+		result, err := tx.ExecContext(ctx, `
+			UPDATE watchlist_items w SET position = o.ordinality
+			FROM unnest($2::uuid[]) WITH ORDINALITY AS o(id, ordinality)
+			WHERE w.id = o.id AND w.watchlist_id = $1`, watchlistID, pq.Array(itemIDs))
+		if err != nil {
+			return err
+		}
+		if n, err := result.RowsAffected(); err != nil || int(n) != len(itemIDs) {
+			return ErrConflict
+		}
+		return nil
+	})
+}
+
+// touchWatchlist bumps a watchlist's updated_at, locking it for the rest of the
+// transaction so concurrent item changes cannot interleave positions
+func touchWatchlist(ctx context.Context, tx *sql.Tx, watchlistID string) error {
+	result, err := tx.ExecContext(ctx, `UPDATE watchlists SET updated_at = CURRENT_TIMESTAMP WHERE id = $1`, watchlistID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// scanWatchlist reads a row of watchlistColumns
+func scanWatchlist(row rowScanner, op string) (*models.Watchlist, error) {
+	var list models.Watchlist
+	err := row.Scan(&list.ID, &list.UserID, &list.Name, &list.Description, &list.IsPublic, &list.Slug,
+		&list.CreatedAt, &list.UpdatedAt, &list.ItemCount)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &list, nil
+}
+
+// scanWatchlistItem reads a row of watchlistItemColumns into item
+func scanWatchlistItem(row rowScanner, item *models.WatchlistItem) error {
+	return row.Scan(&item.ID, &item.MediaType, &item.MediaID, &item.Position, &item.Note, &item.AddedAt)
+}
+
+// watchlistSlug returns a URL slug for a watchlist name with a random suffix, such
+// as "ghibli-marathon-3f9a2c7d1e0b". Kana is romanized; other text outside
+// a-z and 0-9 only separates words.
+func watchlistSlug(name string) (string, error) {
+	normalized := textnorm.Normalize(name)
+	if romaji, ok := textnorm.ToRomaji(normalized); ok {
+		normalized = romaji
+	}
+
+	var b strings.Builder
+	separate := false
+	for _, r := range normalized {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			separate = true
+			continue
+		}
+		if b.Len() >= maxSlugBase {
+			break
+		}
+		if separate && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		b.WriteRune(r)
+		separate = false
+	}
+
+	suffix := make([]byte, 6)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("generate slug: %w", err)
+	}
+	if b.Len() > 0 {
+		b.WriteByte('-')
+	}
+	b.WriteString(hex.EncodeToString(suffix))
+	return b.String(), nil
+}
@@ -0,0 +1,28 @@
+package store
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestWatchlistSlug(t *testing.T) {
+	tests := map[string]string{
+		"Ghibli marathon":       "ghibli-marathon-",
+		"  2024 to watch!! ":    "2024-to-watch-",
+		"ジブリ マラソン":              "jiburi-marason-",
+		"千と千尋":                  "",
+		strings.Repeat("a", 80): strings.Repeat("a", maxSlugBase) + "-",
+	}
+	suffix := regexp.MustCompile(`^[0-9a-f]{12}$`)
+
+	for name, prefix := range tests {
+		slug, err := watchlistSlug(name)
+		if err != nil {
+			t.Fatalf("watchlistSlug(%q) failed: %v", name, err)
+		}
+		if !strings.HasPrefix(slug, prefix) || !suffix.MatchString(strings.TrimPrefix(slug, prefix)) {
+			t.Errorf("watchlistSlug(%q) = %q, expected %q followed by a random suffix", name, slug, prefix)
+		}
+	}
+}
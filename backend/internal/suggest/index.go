@@ -0,0 +1,153 @@
+// Package suggest provides search autocomplete backed by an in-memory prefix index
+// of popular titles and people, optionally blended with past search queries.
+package suggest
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+	"github.com/takeshi-arihori/movie-api/internal/textnorm"
+)
+
+// Entry is a title or person that can be suggested
+type Entry struct {
+	Text       string
+	Type       models.SearchItemType
+	ID         int
+	Popularity float64
+	Aliases    []string // Other names that should match, e.g. the original title
+}
+
+// Index is an immutable prefix index over entries. Every name is indexed from its
+// start and from the start of each later word, so "mat" finds "The Matrix".
+type Index struct {
+	entries []Entry
+	keys    []indexKey
+}
+
+type indexKey struct {
+	key   string
+	entry int
+	word  bool // Matches from a later word rather than the start of the name
+}
+
+// maxScan bounds how many index keys a single lookup inspects
+const maxScan = 1000
+
+// NewIndex builds an index over entries. Entries with the same type and ID are
+// merged, keeping the highest popularity.
+func NewIndex(entries []Entry) *Index {
+	ix := &Index{}
+	type entryID struct {
+		kind models.SearchItemType
+		id   int
+	}
+	positions := make(map[entryID]int, len(entries))
+	for _, e := range entries {
+		if strings.TrimSpace(e.Text) == "" {
+			continue
+		}
+		id := entryID{e.Type, e.ID}
+		if i, ok := positions[id]; ok {
+			if e.Popularity > ix.entries[i].Popularity {
+				ix.entries[i].Popularity = e.Popularity
+			}
+			continue
+		}
+		positions[id] = len(ix.entries)
+		ix.entries = append(ix.entries, e)
+	}
+
+	for i, e := range ix.entries {
+		seen := map[string]bool{}
+		for _, name := range append([]string{e.Text}, e.Aliases...) {
+			k := Key(name)
+			if k == "" || seen[k] {
+				continue
+			}
+			seen[k] = true
+			ix.keys = append(ix.keys, indexKey{key: k, entry: i})
+			for pos, r := range k {
+				if r == ' ' && pos+1 < len(k) {
+					ix.keys = append(ix.keys, indexKey{key: k[pos+1:], entry: i, word: true})
+				}
+			}
+		}
+	}
+
+	sort.Slice(ix.keys, func(a, b int) bool { return ix.keys[a].key < ix.keys[b].key })
+	return ix
+}
+
+// Len returns the number of distinct entries in the index
+func (ix *Index) Len() int {
+	if ix == nil {
+		return 0
+	}
+	return len(ix.entries)
+}
+
+// Lookup returns up to limit entries whose names start with prefix, best first.
+// Matches at the start of a name outrank matches on a later word, and exact
+// matches outrank both; popularity orders entries within the same kind of match.
+func (ix *Index) Lookup(prefix string, limit int) []models.SearchSuggestion {
+	p := Key(prefix)
+	if ix == nil || p == "" || limit <= 0 {
+		return nil
+	}
+
+	best := map[int]float64{}
+	start := sort.Search(len(ix.keys), func(i int) bool { return ix.keys[i].key >= p })
+	for i := start; i < len(ix.keys) && i-start < maxScan; i++ {
+		k := ix.keys[i]
+		if !strings.HasPrefix(k.key, p) {
+			break
+		}
+		weight := 2.0
+		switch {
+		case k.key == p:
+			weight = 3.0
+		case k.word:
+			weight = 1.0
+		}
+		score := weight * (1 + math.Log1p(ix.entries[k.entry].Popularity))
+		if score > best[k.entry] {
+			best[k.entry] = score
+		}
+	}
+
+	suggestions := make([]models.SearchSuggestion, 0, len(best))
+	for i, score := range best {
+		e := ix.entries[i]
+		suggestions = append(suggestions, models.SearchSuggestion{
+			Text:   e.Text,
+			Type:   e.Type,
+			ID:     e.ID,
+			Score:  score,
+			Source: SourceIndex,
+		})
+	}
+	rank(suggestions)
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions
+}
+
+// Key normalizes text for prefix matching: NFKC, lower-case, unified punctuation
+// and whitespace, and hiragana folded to katakana
+func Key(text string) string {
+	return textnorm.ToKatakana(textnorm.Normalize(text))
+}
+
+// rank orders suggestions by score, breaking ties alphabetically for stable output
+func rank(suggestions []models.SearchSuggestion) {
+	sort.SliceStable(suggestions, func(a, b int) bool {
+		if suggestions[a].Score != suggestions[b].Score {
+			return suggestions[a].Score > suggestions[b].Score
+		}
+		return suggestions[a].Text < suggestions[b].Text
+	})
+}
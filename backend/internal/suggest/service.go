@@ -0,0 +1,326 @@
+package suggest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/takeshi-arihori/movie-api/internal/cache"
+	"github.com/takeshi-arihori/movie-api/internal/models"
+	"github.com/takeshi-arihori/movie-api/internal/moderation"
+)
+
+const (
+	// DefaultLimit is the number of suggestions returned when no limit is given
+	DefaultLimit = 10
+	// MaxLimit is the largest number of suggestions a single request may ask for
+	MaxLimit = 20
+	// LatencyBudget bounds how long Suggest waits for the search history
+	LatencyBudget = 150 * time.Millisecond
+	// CacheTTL is how long a suggestion response is reused for the same prefix
+	CacheTTL = 30 * time.Second
+	// RefreshInterval is how often Run rebuilds the index from TMDb
+	RefreshInterval = 30 * time.Minute
+
+	// SourceIndex marks suggestions from the popular title and people index
+	SourceIndex = "index"
+	// SourceHistory marks suggestions from past search queries
+	SourceHistory = "history"
+
+	// refreshPages is the number of TMDb pages fetched from each list on refresh
+	refreshPages = 3
+	// cacheSize bounds the number of cached suggestion responses
+	cacheSize = 2000
+)
+
+// Source defines the TMDb lists the index is built from
+type Source interface {
+	GetPopularMovies(ctx context.Context, page int) (*models.PopularMovies, error)
+	GetTrendingMovies(ctx context.Context, timeWindow string, page int) (*models.SearchResponse[models.Movie], error)
+	GetTrendingTVShows(ctx context.Context, timeWindow string, page int) (*models.SearchResponse[models.TVShow], error)
+	GetTrendingPeople(ctx context.Context, timeWindow string, page int) (*models.SearchResponse[models.Person], error)
+}
+
+// HistorySource provides past search queries, such as the search_history table
+type HistorySource interface {
+	// PopularQueries returns the most searched queries starting with prefix
+	PopularQueries(ctx context.Context, prefix string, limit int) ([]models.HistoryQuery, error)
+}
+
+// Service serves ranked autocomplete suggestions
+type Service struct {
+	source   Source
+	history  HistorySource
+	screener *moderation.Screener // Drops abusive and spam queries from the history
+	index    atomic.Pointer[Index]
+	cache    *cache.Cache[string, models.SearchSuggestionsResponse]
+	budget   time.Duration
+}
+
+// NewService creates a Service whose index is built from source. The index is
+// empty until Refresh or Run is called.
+func NewService(source Source) *Service {
+	s := &Service{
+		source:   source,
+		screener: moderation.NewScreener(moderation.DefaultBannedWords),
+		cache:    cache.New[string, models.SearchSuggestionsResponse](CacheTTL, cacheSize),
+		budget:   LatencyBudget,
+	}
+	s.index.Store(NewIndex(nil))
+	return s
+}
+
+// SetHistory blends past search queries into suggestions. Queries containing a
+// moderation.DefaultBannedWords word are never suggested. It must be called
+// before the service starts serving requests.
+func (s *Service) SetHistory(history HistorySource) {
+	s.history = history
+}
+
+// Run refreshes the index immediately and then every interval until ctx is done
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Refresh(ctx); err != nil {
+			log.Printf("Suggestion index refresh failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh rebuilds the index from the popular and trending lists. Lists that fail
+// are skipped; the previous index is kept only if every list failed.
+func (s *Service) Refresh(ctx context.Context) error {
+	type fetchFunc func(ctx context.Context, page int) ([]Entry, error)
+	fetches := map[string]fetchFunc{
+		"popular movies": func(ctx context.Context, page int) ([]Entry, error) {
+			result, err := s.source.GetPopularMovies(ctx, page)
+			if err != nil {
+				return nil, err
+			}
+			return movieEntries(result.Results), nil
+		},
+		"trending movies": func(ctx context.Context, page int) ([]Entry, error) {
+			result, err := s.source.GetTrendingMovies(ctx, "week", page)
+			if err != nil {
+				return nil, err
+			}
+			return movieEntries(result.Results), nil
+		},
+		"trending TV shows": func(ctx context.Context, page int) ([]Entry, error) {
+			result, err := s.source.GetTrendingTVShows(ctx, "week", page)
+			if err != nil {
+				return nil, err
+			}
+			entries := make([]Entry, 0, len(result.Results))
+			for _, show := range result.Results {
+				entries = append(entries, Entry{
+					Text:       show.Name,
+					Type:       models.SearchItemTypeTV,
+					ID:         show.ID,
+					Popularity: show.Popularity,
+					Aliases:    []string{show.OriginalName},
+				})
+			}
+			return entries, nil
+		},
+		"trending people": func(ctx context.Context, page int) ([]Entry, error) {
+			result, err := s.source.GetTrendingPeople(ctx, "week", page)
+			if err != nil {
+				return nil, err
+			}
+			entries := make([]Entry, 0, len(result.Results))
+			for _, person := range result.Results {
+				entries = append(entries, Entry{
+					Text:       person.Name,
+					Type:       models.SearchItemTypePerson,
+					ID:         person.ID,
+					Popularity: person.Popularity,
+					Aliases:    []string{person.OriginalName},
+				})
+			}
+			return entries, nil
+		},
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var entries []Entry
+	var errs []error
+	attempts := 0
+
+	for name, fetch := range fetches {
+		for page := 1; page <= refreshPages; page++ {
+			attempts++
+			wg.Add(1)
+			go func(name string, fetch fetchFunc, page int) {
+				defer wg.Done()
+				pageEntries, err := fetch(ctx, page)
+
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s page %d: %w", name, page, err))
+					return
+				}
+				entries = append(entries, pageEntries...)
+			}(name, fetch, page)
+		}
+	}
+	wg.Wait()
+
+	if len(errs) == attempts {
+		return fmt.Errorf("all suggestion sources failed: %w", errors.Join(errs...))
+	}
+	for _, err := range errs {
+		log.Printf("Suggestion index refresh: %v", err)
+	}
+
+	index := NewIndex(entries)
+	s.index.Store(index)
+	s.cache.Clear()
+
+	log.Printf("Suggestion index refreshed: %d entries", index.Len())
+	return nil
+}
+
+// Suggest returns up to limit suggestions for the typed prefix query. Index
+// matches are always returned; history is included only if it arrives within
+// the latency budget, otherwise the response is marked partial and not cached.
+func (s *Service) Suggest(ctx context.Context, query string, limit int) models.SearchSuggestionsResponse {
+	query = strings.TrimSpace(query)
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+
+	response := models.SearchSuggestionsResponse{Query: query, Suggestions: []models.SearchSuggestion{}}
+	key := Key(query)
+	if key == "" {
+		return response
+	}
+
+	cacheKey := fmt.Sprintf("%d:%s", limit, key)
+	if cached, ok := s.cache.Get(cacheKey); ok {
+		cached.Query = query
+		return cached
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.budget)
+	defer cancel()
+
+	var history []models.HistoryQuery
+	historyDone := make(chan struct{})
+	if s.history != nil {
+		go func() {
+			defer close(historyDone)
+			queries, err := s.history.PopularQueries(ctx, query, limit)
+			if err != nil {
+				if !errors.Is(err, context.DeadlineExceeded) {
+					log.Printf("Suggestion history lookup failed: %v", err)
+				}
+				return
+			}
+			history = s.screenHistory(queries)
+		}()
+	} else {
+		close(historyDone)
+	}
+
+	// The index lookup is in memory, so run it while the history query is in flight
+	suggestions := s.index.Load().Lookup(query, limit)
+
+	select {
+	case <-historyDone:
+	case <-ctx.Done():
+		response.Partial = true
+	}
+	if !response.Partial {
+		suggestions = mergeHistory(suggestions, history)
+	}
+
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	response.Suggestions = append(response.Suggestions, suggestions...)
+
+	if !response.Partial {
+		s.cache.Set(cacheKey, response)
+	}
+	return response
+}
+
+// screenHistory returns the queries that moderation would not flag
+func (s *Service) screenHistory(queries []models.HistoryQuery) []models.HistoryQuery {
+	var kept []models.HistoryQuery
+	for _, q := range queries {
+		if len(s.screener.Screen(q.Query)) == 0 {
+			kept = append(kept, q)
+		}
+	}
+	return kept
+}
+
+// mergeHistory blends past queries into index suggestions. A query naming an
+// indexed title boosts that title; other queries become query suggestions.
+func mergeHistory(suggestions []models.SearchSuggestion, history []models.HistoryQuery) []models.SearchSuggestion {
+	if len(history) == 0 {
+		return suggestions
+	}
+
+	byKey := make(map[string]int, len(suggestions))
+	for i, suggestion := range suggestions {
+		byKey[Key(suggestion.Text)] = i
+	}
+
+	for _, h := range history {
+		k := Key(h.Query)
+		if k == "" || h.Count <= 0 {
+			continue
+		}
+		boost := math.Log1p(float64(h.Count))
+		if i, ok := byKey[k]; ok {
+			suggestions[i].Score += boost
+			continue
+		}
+		byKey[k] = len(suggestions)
+		suggestions = append(suggestions, models.SearchSuggestion{
+			Text:   h.Query,
+			Type:   models.SuggestionTypeQuery,
+			Score:  2 * boost,
+			Source: SourceHistory,
+		})
+	}
+
+	rank(suggestions)
+	return suggestions
+}
+
+// movieEntries converts movies into index entries
+func movieEntries(movies []models.Movie) []Entry {
+	entries := make([]Entry, 0, len(movies))
+	for _, movie := range movies {
+		entries = append(entries, Entry{
+			Text:       movie.Title,
+			Type:       models.SearchItemTypeMovie,
+			ID:         movie.ID,
+			Popularity: movie.Popularity,
+			Aliases:    []string{movie.OriginalTitle},
+		})
+	}
+	return entries
+}
@@ -0,0 +1,248 @@
+package suggest
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/takeshi-arihori/movie-api/internal/models"
+)
+
+// fakeSource serves fixed lists, or fails when err is set
+type fakeSource struct {
+	movies []models.Movie
+	shows  []models.TVShow
+	people []models.Person
+	err    error
+}
+
+func (f *fakeSource) GetPopularMovies(ctx context.Context, page int) (*models.PopularMovies, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &models.PopularMovies{Page: page, Results: f.movies}, nil
+}
+
+func (f *fakeSource) GetTrendingMovies(ctx context.Context, timeWindow string, page int) (*models.SearchResponse[models.Movie], error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &models.SearchResponse[models.Movie]{Page: page}, nil
+}
+
+func (f *fakeSource) GetTrendingTVShows(ctx context.Context, timeWindow string, page int) (*models.SearchResponse[models.TVShow], error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &models.SearchResponse[models.TVShow]{Page: page, Results: f.shows}, nil
+}
+
+func (f *fakeSource) GetTrendingPeople(ctx context.Context, timeWindow string, page int) (*models.SearchResponse[models.Person], error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &models.SearchResponse[models.Person]{Page: page, Results: f.people}, nil
+}
+
+// fakeHistory returns fixed queries after an optional delay
+type fakeHistory struct {
+	queries []models.HistoryQuery
+	delay   time.Duration
+	calls   atomic.Int32
+}
+
+func (f *fakeHistory) PopularQueries(ctx context.Context, prefix string, limit int) ([]models.HistoryQuery, error) {
+	f.calls.Add(1)
+	select {
+	case <-time.After(f.delay):
+		return f.queries, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func newTestSource() *fakeSource {
+	return &fakeSource{
+		movies: []models.Movie{
+			{ID: 603, Title: "The Matrix", OriginalTitle: "The Matrix", Popularity: 80},
+			{ID: 604, Title: "The Matrix Reloaded", OriginalTitle: "The Matrix Reloaded", Popularity: 40},
+			{ID: 9999, Title: "Matrimony", OriginalTitle: "Matrimony", Popularity: 1},
+			{ID: 129, Title: "千と千尋の神隠し", OriginalTitle: "千と千尋の神隠し", Popularity: 60},
+		},
+		shows: []models.TVShow{
+			{ID: 1429, Name: "進撃の巨人", OriginalName: "進撃の巨人", Popularity: 90},
+			{ID: 62, Name: "ドラゴンボール", OriginalName: "ドラゴンボール", Popularity: 50},
+		},
+		people: []models.Person{
+			{ID: 6384, Name: "Keanu Reeves", OriginalName: "Keanu Reeves", Popularity: 70},
+		},
+	}
+}
+
+// TestIndexLookup tests prefix matching, word matching and ranking
+func TestIndexLookup(t *testing.T) {
+	service := NewService(newTestSource())
+	if err := service.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	index := service.index.Load()
+
+	tests := []struct {
+		name     string
+		prefix   string
+		expected []int
+	}{
+		{name: "title prefix ranks popular first", prefix: "the mat", expected: []int{603, 604}},
+		{name: "word prefix", prefix: "matr", expected: []int{603, 604, 9999}},
+		{name: "case and width folded", prefix: "ＫＥＡＮＵ", expected: []int{6384}},
+		{name: "person surname", prefix: "reev", expected: []int{6384}},
+		{name: "hiragana matches katakana", prefix: "どらご", expected: []int{62}},
+		{name: "kanji prefix", prefix: "千と", expected: []int{129}},
+		{name: "no match", prefix: "zzz", expected: nil},
+		{name: "empty prefix", prefix: "  ", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			suggestions := index.Lookup(tt.prefix, 10)
+			if len(suggestions) != len(tt.expected) {
+				t.Fatalf("Expected %d suggestions, got %+v", len(tt.expected), suggestions)
+			}
+			for i, id := range tt.expected {
+				if suggestions[i].ID != id {
+					t.Errorf("Expected suggestion %d to be %d, got %+v", i, id, suggestions[i])
+				}
+			}
+		})
+	}
+
+	if got := index.Lookup("matr", 1); len(got) != 1 {
+		t.Errorf("Expected limit to cap suggestions, got %d", len(got))
+	}
+}
+
+// TestIndexMergesDuplicates tests that an entry seen in several lists is indexed once
+func TestIndexMergesDuplicates(t *testing.T) {
+	index := NewIndex([]Entry{
+		{Text: "The Matrix", Type: models.SearchItemTypeMovie, ID: 603, Popularity: 10},
+		{Text: "The Matrix", Type: models.SearchItemTypeMovie, ID: 603, Popularity: 80},
+		{Text: "The Matrix", Type: models.SearchItemTypeTV, ID: 603, Popularity: 5},
+	})
+
+	if index.Len() != 2 {
+		t.Fatalf("Expected 2 entries, got %d", index.Len())
+	}
+	suggestions := index.Lookup("matrix", 10)
+	if len(suggestions) != 2 || suggestions[0].Type != models.SearchItemTypeMovie {
+		t.Errorf("Expected the more popular movie entry first, got %+v", suggestions)
+	}
+}
+
+// TestRefreshFailure tests that a failed refresh keeps the previous index
+func TestRefreshFailure(t *testing.T) {
+	source := newTestSource()
+	service := NewService(source)
+	if err := service.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+
+	source.err = errors.New("upstream unavailable")
+	if err := service.Refresh(context.Background()); err == nil {
+		t.Fatal("Expected error when every source fails")
+	}
+	if service.index.Load().Len() == 0 {
+		t.Error("Expected previous index to be kept")
+	}
+}
+
+// TestSuggestHistory tests blending and caching of search history
+func TestSuggestHistory(t *testing.T) {
+	service := NewService(newTestSource())
+	if err := service.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	history := &fakeHistory{queries: []models.HistoryQuery{
+		{Query: "matrix resurrections", Count: 500},
+		{Query: "the matrix reloaded", Count: 100000},
+	}}
+	service.SetHistory(history)
+
+	response := service.Suggest(context.Background(), "matr", 10)
+	if response.Partial {
+		t.Fatal("Expected complete response")
+	}
+	if len(response.Suggestions) != 4 {
+		t.Fatalf("Expected 4 suggestions, got %+v", response.Suggestions)
+	}
+	if top := response.Suggestions[0]; top.ID != 604 {
+		t.Errorf("Expected frequently searched title to be boosted to the top, got %+v", top)
+	}
+
+	var query *models.SearchSuggestion
+	for i := range response.Suggestions {
+		if response.Suggestions[i].Type == models.SuggestionTypeQuery {
+			query = &response.Suggestions[i]
+		}
+	}
+	if query == nil || query.Text != "matrix resurrections" || query.Source != SourceHistory || query.ID != 0 {
+		t.Errorf("Expected history query suggestion, got %+v", query)
+	}
+
+	// The same prefix, however typed, is served from the cache
+	service.Suggest(context.Background(), " MATR", 10)
+	if history.calls.Load() != 1 {
+		t.Errorf("Expected cached response, history called %d times", history.calls.Load())
+	}
+}
+
+// TestSuggestHistoryScreened tests that queries with banned words are not suggested
+func TestSuggestHistoryScreened(t *testing.T) {
+	service := NewService(newTestSource())
+	if err := service.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	service.SetHistory(&fakeHistory{queries: []models.HistoryQuery{
+		{Query: "matrix casino bonus", Count: 900},
+		{Query: "matrix fucking sucks", Count: 800},
+		{Query: "matrix resurrections", Count: 500},
+	}})
+
+	response := service.Suggest(context.Background(), "matr", 10)
+	var queries []string
+	for _, suggestion := range response.Suggestions {
+		if suggestion.Type == models.SuggestionTypeQuery {
+			queries = append(queries, suggestion.Text)
+		}
+	}
+	if len(queries) != 1 || queries[0] != "matrix resurrections" {
+		t.Errorf("Expected only the clean query to be suggested, got %v", queries)
+	}
+}
+
+// TestSuggestLatencyBudget tests that slow history does not hold up index results
+func TestSuggestLatencyBudget(t *testing.T) {
+	service := NewService(newTestSource())
+	service.budget = 10 * time.Millisecond
+	if err := service.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	history := &fakeHistory{delay: time.Second}
+	service.SetHistory(history)
+
+	start := time.Now()
+	response := service.Suggest(context.Background(), "matr", 10)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected response within budget, took %v", elapsed)
+	}
+	if !response.Partial || len(response.Suggestions) != 3 {
+		t.Errorf("Expected partial index-only response, got %+v", response)
+	}
+
+	// Partial responses are not cached
+	service.Suggest(context.Background(), "matr", 10)
+	if history.calls.Load() != 2 {
+		t.Errorf("Expected partial response not to be cached, history called %d times", history.calls.Load())
+	}
+}
@@ -0,0 +1,109 @@
+package textnorm
+
+import (
+	"strings"
+	"unicode"
+)
+
+// romajiDigraphs maps katakana yōon and foreign-sound combinations to Hepburn romaji
+var romajiDigraphs = map[string]string{
+	"キャ": "kya", "キュ": "kyu", "キョ": "kyo",
+	"シャ": "sha", "シュ": "shu", "ショ": "sho", "シェ": "she",
+	"チャ": "cha", "チュ": "chu", "チョ": "cho", "チェ": "che",
+	"ニャ": "nya", "ニュ": "nyu", "ニョ": "nyo",
+	"ヒャ": "hya", "ヒュ": "hyu", "ヒョ": "hyo",
+	"ミャ": "mya", "ミュ": "myu", "ミョ": "myo",
+	"リャ": "rya", "リュ": "ryu", "リョ": "ryo",
+	"ギャ": "gya", "ギュ": "gyu", "ギョ": "gyo",
+	"ジャ": "ja", "ジュ": "ju", "ジョ": "jo", "ジェ": "je",
+	"ビャ": "bya", "ビュ": "byu", "ビョ": "byo",
+	"ピャ": "pya", "ピュ": "pyu", "ピョ": "pyo",
+	"ファ": "fa", "フィ": "fi", "フェ": "fe", "フォ": "fo",
+	"ティ": "ti", "ディ": "di", "デュ": "dyu", "トゥ": "tu",
+	"ウィ": "wi", "ウェ": "we", "ウォ": "wo", "ヴァ": "va", "ヴィ": "vi", "ヴェ": "ve", "ヴォ": "vo",
+}
+
+// romajiMonographs maps single katakana to Hepburn romaji
+var romajiMonographs = map[rune]string{
+	'ア': "a", 'イ': "i", 'ウ': "u", 'エ': "e", 'オ': "o",
+	'カ': "ka", 'キ': "ki", 'ク': "ku", 'ケ': "ke", 'コ': "ko",
+	'サ': "sa", 'シ': "shi", 'ス': "su", 'セ': "se", 'ソ': "so",
+	'タ': "ta", 'チ': "chi", 'ツ': "tsu", 'テ': "te", 'ト': "to",
+	'ナ': "na", 'ニ': "ni", 'ヌ': "nu", 'ネ': "ne", 'ノ': "no",
+	'ハ': "ha", 'ヒ': "hi", 'フ': "fu", 'ヘ': "he", 'ホ': "ho",
+	'マ': "ma", 'ミ': "mi", 'ム': "mu", 'メ': "me", 'モ': "mo",
+	'ヤ': "ya", 'ユ': "yu", 'ヨ': "yo",
+	'ラ': "ra", 'リ': "ri", 'ル': "ru", 'レ': "re", 'ロ': "ro",
+	'ワ': "wa", 'ヰ': "i", 'ヱ': "e", 'ヲ': "o", 'ン': "n",
+	'ガ': "ga", 'ギ': "gi", 'グ': "gu", 'ゲ': "ge", 'ゴ': "go",
+	'ザ': "za", 'ジ': "ji", 'ズ': "zu", 'ゼ': "ze", 'ゾ': "zo",
+	'ダ': "da", 'ヂ': "ji", 'ヅ': "zu", 'デ': "de", 'ド': "do",
+	'バ': "ba", 'ビ': "bi", 'ブ': "bu", 'ベ': "be", 'ボ': "bo",
+	'パ': "pa", 'ピ': "pi", 'プ': "pu", 'ペ': "pe", 'ポ': "po",
+	'ヴ': "vu",
+	'ァ': "a", 'ィ': "i", 'ゥ': "u", 'ェ': "e", 'ォ': "o",
+	'ャ': "ya", 'ュ': "yu", 'ョ': "yo", 'ヮ': "wa",
+}
+
+// ToRomaji transliterates kana to Hepburn romaji. Long-vowel marks are dropped,
+// which matches how Japanese titles are usually spelled in English (ラーメン → ramen).
+// ok is false when s contains no kana or contains characters, such as kanji, that
+// cannot be transliterated.
+func ToRomaji(s string) (string, bool) {
+	runes := []rune(ToKatakana(s))
+	var b strings.Builder
+	hasKana := false
+	geminate := false
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		if r == 'ッ' {
+			geminate = true
+			hasKana = true
+			continue
+		}
+		if r == 'ー' {
+			hasKana = true
+			continue
+		}
+
+		var syllable string
+		if i+1 < len(runes) {
+			if digraph, found := romajiDigraphs[string(runes[i:i+2])]; found {
+				syllable = digraph
+				i++
+			}
+		}
+		if syllable == "" {
+			if mono, found := romajiMonographs[r]; found {
+				syllable = mono
+			}
+		}
+
+		if syllable == "" {
+			if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana) {
+				return "", false
+			}
+			geminate = false
+			b.WriteRune(r)
+			continue
+		}
+
+		hasKana = true
+		if geminate {
+			if strings.HasPrefix(syllable, "ch") {
+				b.WriteByte('t')
+			} else {
+				b.WriteByte(syllable[0])
+			}
+			geminate = false
+		}
+		b.WriteString(syllable)
+	}
+
+	if !hasKana {
+		return "", false
+	}
+	return b.String(), true
+}
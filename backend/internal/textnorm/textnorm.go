@@ -0,0 +1,145 @@
+// Package textnorm normalizes search queries, with particular care for Japanese text.
+//
+// The pipeline applies Unicode NFKC (folding full-width Latin and half-width kana),
+// unifies long-vowel marks, collapses punctuation and whitespace, and can fold
+// between hiragana and katakana or transliterate kana to Hepburn romaji.
+package textnorm
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// VariantKind names the transformation that produced a query variant
+type VariantKind string
+
+const (
+	// Original is the query exactly as typed (trimmed)
+	Original VariantKind = "original"
+	// Normalized is the NFKC, punctuation- and whitespace-normalized query
+	Normalized VariantKind = "normalized"
+	// Katakana has hiragana folded to katakana
+	Katakana VariantKind = "katakana"
+	// Hiragana has katakana folded to hiragana
+	Hiragana VariantKind = "hiragana"
+	// LongVowel has word-final long-vowel marks removed (コンピューター → コンピュータ)
+	LongVowel VariantKind = "long_vowel"
+	// Romaji is the kana query transliterated to Hepburn romaji
+	Romaji VariantKind = "romaji"
+)
+
+// Variant is one candidate form of a query
+type Variant struct {
+	Kind  VariantKind `json:"kind"`
+	Query string      `json:"query"`
+}
+
+// Variants returns the distinct forms of query to try, most faithful first. The
+// first variant is always the original query.
+func Variants(query string) []Variant {
+	query = strings.TrimSpace(query)
+	variants := []Variant{{Kind: Original, Query: query}}
+	seen := map[string]bool{query: true}
+	add := func(kind VariantKind, q string) {
+		if q != "" && !seen[q] {
+			seen[q] = true
+			variants = append(variants, Variant{Kind: kind, Query: q})
+		}
+	}
+
+	normalized := Normalize(query)
+	add(Normalized, normalized)
+	add(Katakana, ToKatakana(normalized))
+	add(Hiragana, ToHiragana(normalized))
+	add(LongVowel, TrimLongVowels(normalized))
+	if romaji, ok := ToRomaji(normalized); ok {
+		add(Romaji, romaji)
+	}
+
+	return variants
+}
+
+// Normalize applies NFKC, lower-cases Latin text, unifies long-vowel marks after
+// kana, replaces punctuation with spaces and collapses runs of whitespace
+func Normalize(s string) string {
+	s = norm.NFKC.String(s)
+
+	var b strings.Builder
+	runes := []rune(s)
+	pendingSpace := false
+	for i, r := range runes {
+		switch {
+		case isLongVowelLike(r) && i > 0 && isKana(runes[i-1]):
+			r = 'ー'
+		case r == '-' && i > 0 && i+1 < len(runes) && isWordRune(runes[i-1]) && isWordRune(runes[i+1]):
+			// Keep hyphens inside words such as "spider-man"
+		case r == '\'' || r == '&':
+			// Keep apostrophes and ampersands, which are significant in titles
+		case unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r):
+			pendingSpace = true
+			continue
+		}
+		if pendingSpace && b.Len() > 0 {
+			b.WriteRune(' ')
+		}
+		pendingSpace = false
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// ToKatakana folds hiragana to katakana
+func ToKatakana(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'ぁ' && r <= 'ゖ', r == 'ゝ', r == 'ゞ':
+			return r + 0x60
+		}
+		return r
+	}, s)
+}
+
+// ToHiragana folds katakana to hiragana. Katakana without a hiragana counterpart
+// (such as ヷ) and the long-vowel mark are left unchanged.
+func ToHiragana(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'ァ' && r <= 'ヶ', r == 'ヽ', r == 'ヾ':
+			return r - 0x60
+		}
+		return r
+	}, s)
+}
+
+// TrimLongVowels removes long-vowel marks at the end of words, the most common
+// spelling variation in katakana loanwords
+func TrimLongVowels(s string) string {
+	words := strings.Split(s, " ")
+	for i, w := range words {
+		if len([]rune(w)) > 2 {
+			words[i] = strings.TrimRight(w, "ー")
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// isKana reports whether r is a hiragana or katakana character (including ー)
+func isKana(r rune) bool {
+	return unicode.In(r, unicode.Hiragana, unicode.Katakana) || r == 'ー'
+}
+
+// isLongVowelLike reports whether r is a dash or tilde commonly typed in place of ー
+func isLongVowelLike(r rune) bool {
+	switch r {
+	case '-', '‐', '‑', '–', '—', '―', '−', '~', '〜', '～':
+		return true
+	}
+	return false
+}
+
+// isWordRune reports whether r is a letter or digit
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,98 @@
+package textnorm
+
+import "testing"
+
+// TestNormalize tests NFKC, punctuation and whitespace normalization
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"ＳＰＹ×ＦＡＭＩＬＹ", "spy family"},
+		{"ｶｳﾎﾞｰｲﾋﾞﾊﾞｯﾌﾟ", "カウボーイビバップ"},
+		{"  君の名は。  ", "君の名は"},
+		{"ラ-メン", "ラーメン"},
+		{"コンピュ〜タ", "コンピュータ"},
+		{"Spider-Man:　Far From Home", "spider-man far from home"},
+		{"Schindler's List", "schindler's list"},
+		{"ハウルの動く城　（2004）", "ハウルの動く城 2004"},
+	}
+
+	for _, tt := range tests {
+		if got := Normalize(tt.input); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+// TestKanaFolding tests hiragana/katakana conversion
+func TestKanaFolding(t *testing.T) {
+	if got := ToKatakana("となりのトトロ"); got != "トナリノトトロ" {
+		t.Errorf("ToKatakana = %q", got)
+	}
+	if got := ToHiragana("トナリノトトロ"); got != "となりのととろ" {
+		t.Errorf("ToHiragana = %q", got)
+	}
+	if got := ToHiragana("ラーメン"); got != "らーめん" {
+		t.Errorf("ToHiragana should keep the long-vowel mark, got %q", got)
+	}
+}
+
+// TestTrimLongVowels tests removal of word-final long-vowel marks
+func TestTrimLongVowels(t *testing.T) {
+	if got := TrimLongVowels("コンピューター ウォーズ"); got != "コンピュータ ウォーズ" {
+		t.Errorf("TrimLongVowels = %q", got)
+	}
+}
+
+// TestToRomaji tests Hepburn transliteration
+func TestToRomaji(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   string
+		wantOK bool
+	}{
+		{"ととろ", "totoro", true},
+		{"ナルト", "naruto", true},
+		{"しんかんせん", "shinkansen", true},
+		{"きょうのわんこ", "kyounowanko", true},
+		{"ラーメン", "ramen", true},
+		{"はいきゅー", "haikyu", true},
+		{"まっちゃ", "matcha", true},
+		{"ジョジョ 2", "jojo 2", true},
+		{"進撃の巨人", "", false},
+		{"matrix", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ToRomaji(tt.input)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("ToRomaji(%q) = %q, %v; want %q, %v", tt.input, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+// TestVariants tests the ordered, de-duplicated variant list
+func TestVariants(t *testing.T) {
+	variants := Variants("ﾄﾄﾛ")
+
+	want := []Variant{
+		{Kind: Original, Query: "ﾄﾄﾛ"},
+		{Kind: Normalized, Query: "トトロ"},
+		{Kind: Hiragana, Query: "ととろ"},
+		{Kind: Romaji, Query: "totoro"},
+	}
+	if len(variants) != len(want) {
+		t.Fatalf("Expected %d variants, got %v", len(want), variants)
+	}
+	for i := range want {
+		if variants[i] != want[i] {
+			t.Errorf("Variant %d = %+v, want %+v", i, variants[i], want[i])
+		}
+	}
+
+	// Plain ASCII queries that are already normalized have a single variant
+	if got := Variants("matrix"); len(got) != 1 {
+		t.Errorf("Expected 1 variant for 'matrix', got %v", got)
+	}
+}
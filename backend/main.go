@@ -1,24 +1,52 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	_ "net/http/pprof"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/takeshi-arihori/movie-api/internal/analytics"
+	"github.com/takeshi-arihori/movie-api/internal/auth"
 	"github.com/takeshi-arihori/movie-api/internal/config"
+	"github.com/takeshi-arihori/movie-api/internal/connections"
 	"github.com/takeshi-arihori/movie-api/internal/handlers"
+	"github.com/takeshi-arihori/movie-api/internal/moderation"
+	"github.com/takeshi-arihori/movie-api/internal/reviewstats"
 	"github.com/takeshi-arihori/movie-api/internal/services"
+	"github.com/takeshi-arihori/movie-api/internal/store"
+	"github.com/takeshi-arihori/movie-api/internal/suggest"
 )
 
+// shutdownTimeout bounds how long a graceful shutdown waits for in-flight
+// requests and queued search history
+const shutdownTimeout = 15 * time.Second
+
 func main() {
+	migrate := flag.String("migrate", "", "run database migrations and exit: up, down or status")
+	flag.Parse()
+
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
+	if *migrate != "" {
+		if err := runMigrations(cfg.Database, *migrate); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+
 	fmt.Printf("Starting Movie API server on port %s\n", cfg.Server.Port)
 	fmt.Printf("Environment: %s\n", cfg.Server.Environment)
 	fmt.Printf("Log Level: %s\n", cfg.Logging.Level)
@@ -35,13 +63,81 @@ func main() {
 
 	// Initialize services
 	tmdbClient := services.NewTMDbClient(cfg)
-	searchHandler := handlers.NewSearchHandler(tmdbClient)
-	movieHandler := handlers.NewMovieHandler(tmdbClient)
+	suggestions := suggest.NewService(tmdbClient)
+	reviewStats := reviewstats.NewService(tmdbClient)
+	searchHandler := handlers.NewSearchHandler(tmdbClient, suggestions)
+	// Validated with the rest of the configuration
+	trustedProxies, _ := cfg.Server.TrustedProxyPrefixes()
+	searchHandler.SetTrustedProxies(trustedProxies)
+
+	// Database-backed features are optional; without a database the API still
+	// serves TMDb data
+	var adminHandler *handlers.AdminHandler
+	var authHandler *handlers.AuthHandler
+	var favoritesHandler *handlers.FavoritesHandler
+	var watchlistHandler *handlers.WatchlistHandler
+	var userReviewHandler *handlers.UserReviewHandler
+	var moderationHandler *handlers.ModerationHandler
+	var notificationHandler *handlers.NotificationHandler
+	var movieClient handlers.MovieClient = tmdbClient
+	var listClient handlers.ListClient = tmdbClient
+	var recorder *analytics.Recorder
+	var cachingClient *services.CachingClient
+	var compareClient handlers.CompareClient = tmdbClient
+	db, err := store.Connect(context.Background(), cfg.Database)
+	if err != nil {
+		log.Printf("Database unavailable, accounts, search history and title cache disabled: %v", err)
+	} else {
+		history := db.SearchHistory
+		recorder = analytics.NewRecorder(history, analytics.DefaultBufferSize)
+
+		searchHandler.SetDatabase(db)
+		searchHandler.SetRecorder(recorder)
+		suggestions.SetHistory(history)
+		accessTTL := time.Duration(cfg.Security.AccessTokenTTL) * time.Second
+		refreshTTL := time.Duration(cfg.Security.RefreshTokenTTL) * time.Second
+		authHandler = handlers.NewAuthHandler(auth.NewService(db.Users, db.RefreshTokens, cfg.Security.JWTSecret, accessTTL, refreshTTL))
+		if cfg.Security.AdminToken != "" {
+			adminHandler = handlers.NewAdminHandler(history, cfg.Security.AdminToken)
+		}
+		var titleClient handlers.TitleDetailsClient = tmdbClient
+		if cfg.Cache.Enabled {
+			staleAfter := time.Duration(cfg.Cache.StaleAfter) * time.Second
+			cachingClient = services.NewCachingClient(tmdbClient, db.Titles, staleAfter)
+			movieClient = cachingClient
+			listClient = cachingClient
+			compareClient = cachingClient
+			titleClient = cachingClient
+		}
+		favoritesHandler = handlers.NewFavoritesHandler(db.Favorites, titleClient)
+		watchlistHandler = handlers.NewWatchlistHandler(db.Watchlists, titleClient)
+		// Review changes go through reviewStats so its cached statistics stay current
+		reviewStats.SetRatings(db.UserReviews)
+		userReviews := reviewStats.Track(db.UserReviews)
+		userReviewHandler = handlers.NewUserReviewHandler(userReviews, titleClient, tmdbClient, moderation.NewScreener(moderation.DefaultBannedWords))
+		moderationHandler = handlers.NewModerationHandler(userReviews)
+		notificationHandler = handlers.NewNotificationHandler(db.Notifications)
+	}
+	go suggestions.Run(context.Background(), suggest.RefreshInterval)
+
+	movieHandler := handlers.NewMovieHandler(movieClient)
+	tvHandler := handlers.NewTVHandler(tmdbClient)
 	reviewHandler := handlers.NewReviewHandler(tmdbClient)
+	reviewHandler.SetCredits(tmdbClient)
+	reviewStatsHandler := handlers.NewReviewStatsHandler(reviewStats)
 	personHandler := handlers.NewPersonHandler(tmdbClient)
+	connectionsHandler := handlers.NewConnectionsHandler(connections.NewService(tmdbClient))
+	listHandler := handlers.NewListHandler(listClient)
+	compareHandler := handlers.NewCompareHandler(compareClient)
+	if db != nil {
+		// Fall back to the titles cached in the database when TMDb is unavailable
+		searchHandler.SetCatalogue(db.Titles)
+		movieHandler.SetCatalogue(db.Titles)
+		listHandler.SetCatalogue(db.Titles)
+	}
 
 	// Setup router
-	router := setupRouter(searchHandler, movieHandler, reviewHandler, personHandler)
+	router := setupRouter(searchHandler, movieHandler, tvHandler, reviewHandler, reviewStatsHandler, personHandler, connectionsHandler, listHandler, compareHandler, adminHandler, authHandler, favoritesHandler, watchlistHandler, userReviewHandler, moderationHandler, notificationHandler)
 
 	// Start server
 	addr := ":" + cfg.Server.Port
@@ -49,23 +145,139 @@ func main() {
 	fmt.Println("Available endpoints:")
 	fmt.Println("  GET /api/v1/health            - Health check")
 	fmt.Println("  GET /api/v1/search            - Multi search (movies, TV shows, people)")
+	fmt.Println("  GET /api/v1/search/suggestions - Search autocomplete")
+	fmt.Println("  GET /api/v1/popular           - Popular movies (limit/cursor)")
+	fmt.Println("  GET /api/v1/top-rated         - Top rated movies (limit/cursor)")
+	fmt.Println("  GET /api/v1/trending          - Trending movies or TV shows (limit/cursor)")
+	fmt.Println("  GET /api/v1/compare           - Compare 2-5 movies or TV shows side by side (movies or tv)")
 	fmt.Println("  GET /api/v1/movies/{id}       - Movie details")
-	fmt.Println("  GET /api/v1/movies/{id}/credits - Movie credits")
-	fmt.Println("  GET /api/v1/movies/{id}/reviews - Movie reviews")
-	fmt.Println("  GET /api/v1/tv/{id}/reviews   - TV show reviews")
+	fmt.Println("  GET /api/v1/movies/{id}/credits - Movie credits (department, job, limit, dedupe_crew, view=summary)")
+	fmt.Println("  GET /api/v1/tv/{id}/credits   - TV show credits (department, job, limit, dedupe_crew, view=summary)")
+	fmt.Println("  GET /api/v1/movies/{id}/reviews - Movie reviews (limit/cursor, or all=true with sort_by/sort_order; analyze, hide_spoilers)")
+	fmt.Println("  GET /api/v1/movies/{id}/review_stats - Movie review statistics")
+	fmt.Println("  GET /api/v1/tv/{id}/reviews   - TV show reviews (limit/cursor, or all=true with sort_by/sort_order; analyze, hide_spoilers)")
+	fmt.Println("  GET /api/v1/tv/{id}/review_stats - TV show review statistics")
 	fmt.Println("  GET /api/v1/people/{id}       - Person details")
 	fmt.Println("  GET /api/v1/people/{id}/movie_credits - Person movie credits")
 	fmt.Println("  GET /api/v1/people/{id}/tv_credits - Person TV credits")
 	fmt.Println("  GET /api/v1/people/{id}/combined_credits - Person combined credits")
+	fmt.Println("  GET /api/v1/people/{id}/filmography - Person filmography by year and department (media_type, department, min_vote_count)")
+	fmt.Println("  GET /api/v1/people/{id}/collaborators - People a person has worked with most (limit)")
+	fmt.Println("  GET /api/v1/people/connect    - Shortest chain of movies between two actors (from, to, max_depth, fan_out)")
+	fmt.Println("  GET /api/v1/people/shared_credits - Titles several people have credits on (ids, role, job)")
+	if authHandler != nil {
+		fmt.Println("  POST /api/v1/auth/register    - Create an account")
+		fmt.Println("  POST /api/v1/auth/login       - Log in (access + refresh token)")
+		fmt.Println("  POST /api/v1/auth/refresh     - Rotate refresh token")
+		fmt.Println("  POST /api/v1/auth/logout      - Revoke session")
+		fmt.Println("  GET /api/v1/me/favorites      - Your favorites (auth, limit/cursor, sort)")
+		fmt.Println("  POST /api/v1/me/favorites     - Add a favorite (auth)")
+		fmt.Println("  DELETE /api/v1/me/favorites/{media_type}/{id} - Remove a favorite (auth)")
+		fmt.Println("  GET|POST /api/v1/me/watchlists - Your watchlists (auth)")
+		fmt.Println("  GET|PATCH|DELETE /api/v1/me/watchlists/{id} - Watchlist with items (auth)")
+		fmt.Println("  POST /api/v1/me/watchlists/{id}/items - Add a title (auth)")
+		fmt.Println("  PATCH|DELETE /api/v1/me/watchlists/{id}/items/{item_id} - Edit note or remove (auth)")
+		fmt.Println("  PUT /api/v1/me/watchlists/{id}/order - Reorder items (auth)")
+		fmt.Println("  GET /api/v1/watchlists/{slug} - Public watchlist")
+		fmt.Println("  POST /api/v1/reviews          - Review a movie or TV show (auth)")
+		fmt.Println("  GET /api/v1/reviews           - Filter user reviews (media_id/media_type, user_id, min_rating/max_rating, has_spoiler, status, view=unified)")
+		fmt.Println("  GET /api/v1/reviews/{id}      - User review")
+		fmt.Println("  PATCH|DELETE /api/v1/reviews/{id} - Edit or delete your review (auth)")
+		fmt.Println("  POST|DELETE /api/v1/reviews/{id}/helpfulness - Vote on a review's helpfulness (auth)")
+		fmt.Println("  GET /api/v1/movies/{id}/user_reviews - Movie user reviews (page/limit, sort_by/sort_order)")
+		fmt.Println("  GET /api/v1/tv/{id}/user_reviews - TV show user reviews (page/limit, sort_by/sort_order)")
+		fmt.Println("  GET /api/v1/me/reviews        - Your reviews (auth, page/limit)")
+		fmt.Println("  GET /api/v1/me/notifications  - Your notifications (auth, page/limit)")
+		fmt.Println("  POST /api/v1/me/notifications/{id}/read - Mark a notification read (auth)")
+		fmt.Println("  GET /api/v1/moderation/reviews - Pending reviews, flagged first (moderator)")
+		fmt.Println("  GET /api/v1/moderation/reviews/{id}/history - Moderation audit log (moderator)")
+		fmt.Println("  POST /api/v1/moderation/decisions - Approve or reject a review (moderator)")
+	}
+	if adminHandler != nil {
+		fmt.Println("  GET /api/v1/admin/search/top-queries  - Most searched queries (admin)")
+		fmt.Println("  GET /api/v1/admin/search/zero-results - Queries with no results (admin)")
+		fmt.Println("  GET /api/v1/admin/search/volume       - Search volume over time (admin)")
+	}
 	fmt.Println("  GET /health                   - Simple health check")
 	
-	if err := http.ListenAndServe(addr, router); err != nil {
-		log.Fatalf("Server failed to start: %v", err)
+	server := &http.Server{Addr: addr, Handler: router}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Server failed to start: %v", err)
+		}
+	}()
+
+	// Shut down gracefully on SIGINT or SIGTERM: finish in-flight requests, then
+	// write the search history still queued and the titles still being cached
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	<-stop
+	log.Println("Shutting down server...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Server shutdown failed: %v", err)
+	}
+	if recorder != nil {
+		if err := recorder.Close(ctx); err != nil {
+			log.Printf("Failed to write queued search history: %v", err)
+		}
+	}
+	if cachingClient != nil {
+		cachingClient.Wait()
+	}
+	if db != nil {
+		db.Close()
 	}
 }
 
+// runMigrations applies (up), rolls back the latest (down) or lists (status) the
+// database migrations
+func runMigrations(dbConfig config.DatabaseConfig, command string) error {
+	db, err := store.Open(dbConfig)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	switch command {
+	case "up":
+		applied, err := store.MigrateUp(ctx, db)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Applied %d migration(s)\n", applied)
+	case "down":
+		reverted, err := store.MigrateDown(ctx, db, 1)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Rolled back %d migration(s)\n", reverted)
+	case "status":
+		states, err := store.MigrationStatus(ctx, db)
+		if err != nil {
+			return err
+		}
+		for _, state := range states {
+			applied := "pending"
+			if state.AppliedAt != nil {
+				applied = "applied " + state.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%04d_%s  %s\n", state.Version, state.Name, applied)
+		}
+	default:
+		return fmt.Errorf("unknown migrate command %q (valid: up, down, status)", command)
+	}
+	return nil
+}
+
+// uuidPattern matches the UUIDs used as database IDs in route variables
+const uuidPattern = `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`
+
 // setupRouter configures and returns the HTTP router
-func setupRouter(searchHandler *handlers.SearchHandler, movieHandler *handlers.MovieHandler, reviewHandler *handlers.ReviewHandler, personHandler *handlers.PersonHandler) *mux.Router {
+func setupRouter(searchHandler *handlers.SearchHandler, movieHandler *handlers.MovieHandler, tvHandler *handlers.TVHandler, reviewHandler *handlers.ReviewHandler, reviewStatsHandler *handlers.ReviewStatsHandler, personHandler *handlers.PersonHandler, connectionsHandler *handlers.ConnectionsHandler, listHandler *handlers.ListHandler, compareHandler *handlers.CompareHandler, adminHandler *handlers.AdminHandler, authHandler *handlers.AuthHandler, favoritesHandler *handlers.FavoritesHandler, watchlistHandler *handlers.WatchlistHandler, userReviewHandler *handlers.UserReviewHandler, moderationHandler *handlers.ModerationHandler, notificationHandler *handlers.NotificationHandler) *mux.Router {
 	router := mux.NewRouter()
 
 	// API v1 routes
@@ -76,19 +288,107 @@ func setupRouter(searchHandler *handlers.SearchHandler, movieHandler *handlers.M
 	api.HandleFunc("/health", searchHandler.HealthCheck).Methods("GET", "OPTIONS")
 	api.HandleFunc("/search/suggestions", searchHandler.GetSearchSuggestions).Methods("GET", "OPTIONS")
 
+	// List endpoints
+	api.HandleFunc("/popular", listHandler.GetPopular).Methods("GET", "OPTIONS")
+	api.HandleFunc("/top-rated", listHandler.GetTopRated).Methods("GET", "OPTIONS")
+	api.HandleFunc("/trending", listHandler.GetTrending).Methods("GET", "OPTIONS")
+
+	// Comparison endpoint
+	api.HandleFunc("/compare", compareHandler.Compare).Methods("GET", "OPTIONS")
+
 	// Movie endpoints
 	api.HandleFunc("/movies/{id:[0-9]+}", movieHandler.GetMovieDetails).Methods("GET", "OPTIONS")
 	api.HandleFunc("/movies/{id:[0-9]+}/credits", movieHandler.GetMovieCredits).Methods("GET", "OPTIONS")
 	api.HandleFunc("/movies/{id:[0-9]+}/reviews", reviewHandler.GetMovieReviews).Methods("GET", "OPTIONS")
+	api.HandleFunc("/movies/{id:[0-9]+}/review_stats", reviewStatsHandler.GetMovieReviewStats).Methods("GET", "OPTIONS")
 
 	// TV show endpoints
+	api.HandleFunc("/tv/{id:[0-9]+}/credits", tvHandler.GetTVCredits).Methods("GET", "OPTIONS")
 	api.HandleFunc("/tv/{id:[0-9]+}/reviews", reviewHandler.GetTVReviews).Methods("GET", "OPTIONS")
+	api.HandleFunc("/tv/{id:[0-9]+}/review_stats", reviewStatsHandler.GetTVReviewStats).Methods("GET", "OPTIONS")
 
 	// Person endpoints
 	api.HandleFunc("/people/{id:[0-9]+}", personHandler.GetPersonDetails).Methods("GET", "OPTIONS")
 	api.HandleFunc("/people/{id:[0-9]+}/movie_credits", personHandler.GetPersonMovieCredits).Methods("GET", "OPTIONS")
 	api.HandleFunc("/people/{id:[0-9]+}/tv_credits", personHandler.GetPersonTVCredits).Methods("GET", "OPTIONS")
 	api.HandleFunc("/people/{id:[0-9]+}/combined_credits", personHandler.GetPersonCombinedCredits).Methods("GET", "OPTIONS")
+	api.HandleFunc("/people/{id:[0-9]+}/filmography", personHandler.GetPersonFilmography).Methods("GET", "OPTIONS")
+	api.HandleFunc("/people/{id:[0-9]+}/collaborators", connectionsHandler.GetCollaborators).Methods("GET", "OPTIONS")
+	api.HandleFunc("/people/connect", connectionsHandler.Connect).Methods("GET", "OPTIONS")
+	api.HandleFunc("/people/shared_credits", personHandler.GetSharedCredits).Methods("GET", "OPTIONS")
+
+	// Auth endpoints (only when the database is available)
+	if authHandler != nil {
+		api.HandleFunc("/auth/register", authHandler.Register).Methods("POST", "OPTIONS")
+		api.HandleFunc("/auth/login", authHandler.Login).Methods("POST", "OPTIONS")
+		api.HandleFunc("/auth/refresh", authHandler.Refresh).Methods("POST", "OPTIONS")
+		api.HandleFunc("/auth/logout", authHandler.Logout).Methods("POST", "OPTIONS")
+
+		// Endpoints for the signed-in user
+		me := api.PathPrefix("/me").Subrouter()
+		me.Use(authHandler.RequireAuth)
+		if favoritesHandler != nil {
+			me.HandleFunc("/favorites", favoritesHandler.ListFavorites).Methods("GET", "OPTIONS")
+			me.HandleFunc("/favorites", favoritesHandler.AddFavorite).Methods("POST")
+			me.HandleFunc("/favorites/{media_type:movie|tv}/{id:[0-9]+}", favoritesHandler.RemoveFavorite).Methods("DELETE", "OPTIONS")
+		}
+		if watchlistHandler != nil {
+			me.HandleFunc("/watchlists", watchlistHandler.ListWatchlists).Methods("GET", "OPTIONS")
+			me.HandleFunc("/watchlists", watchlistHandler.CreateWatchlist).Methods("POST")
+			me.HandleFunc("/watchlists/{id:"+uuidPattern+"}", watchlistHandler.GetWatchlist).Methods("GET", "OPTIONS")
+			me.HandleFunc("/watchlists/{id:"+uuidPattern+"}", watchlistHandler.UpdateWatchlist).Methods("PATCH")
+			me.HandleFunc("/watchlists/{id:"+uuidPattern+"}", watchlistHandler.DeleteWatchlist).Methods("DELETE")
+			me.HandleFunc("/watchlists/{id:"+uuidPattern+"}/items", watchlistHandler.AddItem).Methods("POST", "OPTIONS")
+			me.HandleFunc("/watchlists/{id:"+uuidPattern+"}/items/{item_id:"+uuidPattern+"}", watchlistHandler.UpdateItem).Methods("PATCH", "OPTIONS")
+			me.HandleFunc("/watchlists/{id:"+uuidPattern+"}/items/{item_id:"+uuidPattern+"}", watchlistHandler.RemoveItem).Methods("DELETE")
+			me.HandleFunc("/watchlists/{id:"+uuidPattern+"}/order", watchlistHandler.ReorderItems).Methods("PUT", "OPTIONS")
+		}
+		if userReviewHandler != nil {
+			me.HandleFunc("/reviews", userReviewHandler.ListMyReviews).Methods("GET", "OPTIONS")
+
+			// Writing reviews needs a signed-in user; reading them does not
+			api.Handle("/reviews", authHandler.RequireAuth(http.HandlerFunc(userReviewHandler.CreateReview))).Methods("POST", "OPTIONS")
+			api.Handle("/reviews", authHandler.OptionalAuth(http.HandlerFunc(userReviewHandler.ListReviews))).Methods("GET")
+			api.Handle("/reviews/{id:"+uuidPattern+"}", authHandler.RequireAuth(http.HandlerFunc(userReviewHandler.UpdateReview))).Methods("PATCH")
+			api.Handle("/reviews/{id:"+uuidPattern+"}", authHandler.RequireAuth(http.HandlerFunc(userReviewHandler.DeleteReview))).Methods("DELETE")
+			api.Handle("/reviews/{id:"+uuidPattern+"}/helpfulness", authHandler.RequireAuth(http.HandlerFunc(userReviewHandler.VoteHelpful))).Methods("POST", "OPTIONS")
+			api.Handle("/reviews/{id:"+uuidPattern+"}/helpfulness", authHandler.RequireAuth(http.HandlerFunc(userReviewHandler.RemoveHelpfulVote))).Methods("DELETE")
+		}
+		if notificationHandler != nil {
+			me.HandleFunc("/notifications", notificationHandler.ListNotifications).Methods("GET", "OPTIONS")
+			me.HandleFunc("/notifications/{id:"+uuidPattern+"}/read", notificationHandler.MarkRead).Methods("POST", "OPTIONS")
+		}
+
+		// Review moderation, for users flagged as moderators
+		if moderationHandler != nil {
+			moderator := api.PathPrefix("/moderation").Subrouter()
+			moderator.Use(authHandler.RequireAuth, moderationHandler.RequireModerator)
+			moderator.HandleFunc("/reviews", moderationHandler.GetQueue).Methods("GET", "OPTIONS")
+			moderator.HandleFunc("/reviews/{id:"+uuidPattern+"}/history", moderationHandler.GetHistory).Methods("GET", "OPTIONS")
+			moderator.HandleFunc("/decisions", moderationHandler.ModerateReview).Methods("POST", "OPTIONS")
+		}
+	}
+
+	// User reviews (only when the database is available)
+	if userReviewHandler != nil {
+		api.HandleFunc("/reviews/{id:"+uuidPattern+"}", userReviewHandler.GetReview).Methods("GET", "OPTIONS")
+		api.HandleFunc("/movies/{id:[0-9]+}/user_reviews", userReviewHandler.GetMovieUserReviews).Methods("GET", "OPTIONS")
+		api.HandleFunc("/tv/{id:[0-9]+}/user_reviews", userReviewHandler.GetTVUserReviews).Methods("GET", "OPTIONS")
+	}
+
+	// Public watchlists
+	if watchlistHandler != nil {
+		api.HandleFunc("/watchlists/{slug:[a-z0-9-]+}", watchlistHandler.GetPublicWatchlist).Methods("GET", "OPTIONS")
+	}
+
+	// Admin endpoints (only when the database and an admin token are configured)
+	if adminHandler != nil {
+		admin := api.PathPrefix("/admin").Subrouter()
+		admin.Use(adminHandler.RequireAdmin)
+		admin.HandleFunc("/search/top-queries", adminHandler.GetTopQueries).Methods("GET", "OPTIONS")
+		admin.HandleFunc("/search/zero-results", adminHandler.GetZeroResultQueries).Methods("GET", "OPTIONS")
+		admin.HandleFunc("/search/volume", adminHandler.GetSearchVolume).Methods("GET", "OPTIONS")
+	}
 
 	// Legacy health check endpoint (for compatibility)
 	router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -111,7 +411,7 @@ func setupRouter(searchHandler *handlers.SearchHandler, movieHandler *handlers.M
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, Authorization")
 
 		if r.Method == "OPTIONS" {
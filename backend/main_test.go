@@ -0,0 +1,33 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCORSMiddleware_Preflight(t *testing.T) {
+	handler := corsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Expected the preflight request to be answered by the middleware")
+	}))
+
+	req := httptest.NewRequest(http.MethodOptions, "/api/v1/me/watchlists/1", nil)
+	req.Header.Set("Origin", "https://example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPatch)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	allowed := false
+	for _, method := range strings.Split(w.Header().Get("Access-Control-Allow-Methods"), ",") {
+		if strings.TrimSpace(method) == http.MethodPatch {
+			allowed = true
+		}
+	}
+	if !allowed {
+		t.Errorf("Expected PATCH in the allowed methods, got %q", w.Header().Get("Access-Control-Allow-Methods"))
+	}
+}
@@ -1,5 +1,7 @@
 -- Movie API Database Initialization Script
 -- This script is automatically executed when PostgreSQL container starts
+-- Schema changes after the initial schema belong in backend/internal/store/migrations,
+-- which the backend applies on startup (or with `-migrate up`)
 
 -- Create database if not exists (handled by POSTGRES_DB environment variable)
 
@@ -82,12 +84,16 @@ CREATE TABLE IF NOT EXISTS search_history (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     user_id UUID REFERENCES users(id) ON DELETE SET NULL,
     query VARCHAR(255) NOT NULL,
+    search_type VARCHAR(20),
     results_count INTEGER DEFAULT 0,
     ip_address INET,
     user_agent TEXT,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Add columns introduced after the initial schema (for existing databases)
+ALTER TABLE search_history ADD COLUMN IF NOT EXISTS search_type VARCHAR(20);
+
 -- Create indexes for better performance
 CREATE INDEX IF NOT EXISTS idx_movies_title ON movies USING GIN (to_tsvector('english', title));
 CREATE INDEX IF NOT EXISTS idx_movies_release_date ON movies(release_date);
@@ -106,6 +112,7 @@ CREATE INDEX IF NOT EXISTS idx_user_favorites_media ON user_favorites(media_type
 
 CREATE INDEX IF NOT EXISTS idx_search_history_user_id ON search_history(user_id);
 CREATE INDEX IF NOT EXISTS idx_search_history_created_at ON search_history(created_at);
+CREATE INDEX IF NOT EXISTS idx_search_history_query ON search_history(lower(query) text_pattern_ops, created_at);
 
 -- Create updated_at trigger function
 CREATE OR REPLACE FUNCTION update_updated_at_column()